@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/cel-go/common/env"
+)
+
+// ProgramCache is a bounded, concurrency-safe, least-recently-used cache of compiled programs.
+//
+// Entries are keyed by the expression text, the serialized configuration of the environment
+// which compiled the expression (see Env.ToConfig), and the set of ProgramOption values
+// configured alongside the cache via the ProgramCaching option. A single ProgramCache may be
+// shared by many environments.
+//
+// Note, environments which serialize to the same env.Config are assumed to produce equivalent
+// programs. Environments which differ only by state that is not captured in the config, such as
+// function implementations bound with different Go functions, should not share a cache.
+type ProgramCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[programCacheKey]*list.Element
+	lru        *list.List
+	stats      ProgramCacheStats
+}
+
+// ProgramCacheStats reports the usage of a ProgramCache.
+type ProgramCacheStats struct {
+	// Hits is the number of lookups which were served from the cache.
+	Hits uint64
+	// Misses is the number of lookups which required the expression to be compiled and planned.
+	Misses uint64
+	// Evictions is the number of entries removed to keep the cache within its size limit.
+	Evictions uint64
+	// Size is the number of entries currently held in the cache.
+	Size int
+}
+
+// NewProgramCache creates a ProgramCache which holds at most `maxEntries` programs.
+//
+// When the cache is full, the least recently used program is evicted to make room for new entries.
+func NewProgramCache(maxEntries int) (*ProgramCache, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("program cache size must be positive, got: %d", maxEntries)
+	}
+	return &ProgramCache{
+		maxEntries: maxEntries,
+		entries:    make(map[programCacheKey]*list.Element, maxEntries),
+		lru:        list.New(),
+	}, nil
+}
+
+// Stats returns a snapshot of the cache usage statistics.
+func (c *ProgramCache) Stats() ProgramCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+// Purge removes all entries from the cache without resetting the usage statistics.
+func (c *ProgramCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[programCacheKey]*list.Element, c.maxEntries)
+	c.lru.Init()
+}
+
+// get returns the program associated with the key, if present, and records the cache hit or miss.
+func (c *ProgramCache) get(key programCacheKey) (Program, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		c.stats.Hits++
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*programCacheEntry).prg, true
+	}
+	c.stats.Misses++
+	return nil, false
+}
+
+// add inserts the program into the cache, evicting the least recently used entry if necessary.
+//
+// If another caller has already populated the key, the existing program is returned so that all
+// callers observe the same Program instance.
+func (c *ProgramCache) add(key programCacheKey, prg Program) Program {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*programCacheEntry).prg
+	}
+	c.entries[key] = c.lru.PushFront(&programCacheEntry{key: key, prg: prg})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*programCacheEntry).key)
+		c.stats.Evictions++
+	}
+	return prg
+}
+
+type programCacheKey struct {
+	expr      string
+	envConfig string
+	optSetID  uint64
+}
+
+type programCacheEntry struct {
+	key programCacheKey
+	prg Program
+}
+
+// ProgramCaching configures the environment to store programs produced by Env.CachedProgram in the
+// provided cache. The `opts` are applied to every program created through the cache.
+//
+// Each call to ProgramCaching identifies a distinct set of ProgramOption values, so environments
+// which share both the cache and the EnvOption returned by this call are able to share entries.
+// Extended environments inherit the cache configuration of their parent.
+func ProgramCaching(cache *ProgramCache, opts ...ProgramOption) EnvOption {
+	optSetID := programOptionSetID.Add(1)
+	return func(e *Env) (*Env, error) {
+		if cache == nil {
+			return nil, errors.New("program caching requires a non-nil cache")
+		}
+		e.progCache = cache
+		e.progCacheOpts = opts
+		e.progCacheOptSetID = optSetID
+		return e, nil
+	}
+}
+
+// CachedProgram compiles the expression and plans a Program for it, or returns a previously
+// planned Program for the same expression, environment configuration, and ProgramOption set.
+//
+// The environment must be configured with the ProgramCaching option. Compilation errors are
+// returned as a Go error and are not cached.
+func (e *Env) CachedProgram(expr string) (Program, error) {
+	if e.progCache == nil {
+		return nil, errors.New("program cache not configured, see cel.ProgramCaching")
+	}
+	envConfig, err := e.programCacheEnvKey()
+	if err != nil {
+		return nil, err
+	}
+	key := programCacheKey{expr: expr, envConfig: envConfig, optSetID: e.progCacheOptSetID}
+	if prg, found := e.progCache.get(key); found {
+		return prg, nil
+	}
+	ast, iss := e.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	prg, err := e.Program(ast, e.progCacheOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return e.progCache.add(key, prg), nil
+}
+
+// programCacheEnvKey computes a digest of the serialized environment configuration once per Env.
+func (e *Env) programCacheEnvKey() (string, error) {
+	e.progCacheKeyOnce.Do(func() {
+		conf, err := e.ToConfig("")
+		if err != nil {
+			e.progCacheKeyErr = fmt.Errorf("program cache could not serialize environment: %w", err)
+			return
+		}
+		data, err := env.ConfigToYAML(conf)
+		if err != nil {
+			e.progCacheKeyErr = fmt.Errorf("program cache could not serialize environment: %w", err)
+			return
+		}
+		digest := sha256.Sum256(data)
+		e.progCacheKey = hex.EncodeToString(digest[:])
+	})
+	return e.progCacheKey, e.progCacheKeyErr
+}
+
+var (
+	// programOptionSetID is a monotonically increasing identifier assigned to each ProgramCaching option.
+	programOptionSetID atomic.Uint64
+)
@@ -0,0 +1,209 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestCachedProgram(t *testing.T) {
+	cache, err := NewProgramCache(10)
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	env, err := NewEnv(Variable("x", IntType), ProgramCaching(cache))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	prg, err := env.CachedProgram("x + 1")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	prg2, err := env.CachedProgram("x + 1")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	if prg != prg2 {
+		t.Error("CachedProgram() returned a different program for the same expression")
+	}
+	out, _, err := prg2.Eval(map[string]any{"x": 41})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if out != types.Int(42) {
+		t.Errorf("prg.Eval() got %v, wanted 42", out)
+	}
+	want := ProgramCacheStats{Hits: 1, Misses: 1, Size: 1}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("cache.Stats() got %+v, wanted %+v", stats, want)
+	}
+	cache.Purge()
+	want = ProgramCacheStats{Hits: 1, Misses: 1}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("cache.Stats() after Purge() got %+v, wanted %+v", stats, want)
+	}
+}
+
+func TestCachedProgramEviction(t *testing.T) {
+	cache, err := NewProgramCache(2)
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	env, err := NewEnv(ProgramCaching(cache))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	for _, expr := range []string{"1", "2", "1", "3", "1", "2"} {
+		if _, err := env.CachedProgram(expr); err != nil {
+			t.Fatalf("CachedProgram(%q) failed: %v", expr, err)
+		}
+	}
+	// "1" remains hot, "2" is evicted by "3", and "3" is evicted by the second "2".
+	want := ProgramCacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("cache.Stats() got %+v, wanted %+v", stats, want)
+	}
+}
+
+func TestCachedProgramKeys(t *testing.T) {
+	cache, err := NewProgramCache(10)
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	caching := ProgramCaching(cache)
+	intEnv, err := NewEnv(Variable("x", IntType), caching)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	sameEnv, err := NewEnv(Variable("x", IntType), caching)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	strEnv, err := NewEnv(Variable("x", StringType), caching)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	optEnv, err := intEnv.Extend(ProgramCaching(cache, EvalOptions(OptOptimize)))
+	if err != nil {
+		t.Fatalf("Extend() failed: %v", err)
+	}
+
+	intPrg, err := intEnv.CachedProgram("x")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	samePrg, err := sameEnv.CachedProgram("x")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	if intPrg != samePrg {
+		t.Error("environments with identical configs did not share a cache entry")
+	}
+	strPrg, err := strEnv.CachedProgram("x")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	if intPrg == strPrg {
+		t.Error("environments with different configs shared a cache entry")
+	}
+	optPrg, err := optEnv.CachedProgram("x")
+	if err != nil {
+		t.Fatalf("CachedProgram() failed: %v", err)
+	}
+	if intPrg == optPrg {
+		t.Error("different program option sets shared a cache entry")
+	}
+	want := ProgramCacheStats{Hits: 1, Misses: 3, Size: 3}
+	if stats := cache.Stats(); stats != want {
+		t.Errorf("cache.Stats() got %+v, wanted %+v", stats, want)
+	}
+}
+
+func TestCachedProgramConcurrent(t *testing.T) {
+	cache, err := NewProgramCache(4)
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	env, err := NewEnv(Variable("x", IntType), ProgramCaching(cache))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				expr := fmt.Sprintf("x + %d", (i+j)%8)
+				prg, err := env.CachedProgram(expr)
+				if err != nil {
+					t.Errorf("CachedProgram(%q) failed: %v", expr, err)
+					return
+				}
+				out, _, err := prg.Eval(map[string]any{"x": 1})
+				if err != nil {
+					t.Errorf("prg.Eval() failed: %v", err)
+					return
+				}
+				if want := types.Int(1 + (i+j)%8); out != want {
+					t.Errorf("prg.Eval() got %v, wanted %v", out, want)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	if stats.Hits+stats.Misses != 1600 {
+		t.Errorf("cache.Stats() got %d lookups, wanted 1600", stats.Hits+stats.Misses)
+	}
+	if stats.Size > 4 {
+		t.Errorf("cache.Stats() got size %d, wanted at most 4", stats.Size)
+	}
+}
+
+func TestCachedProgramErrors(t *testing.T) {
+	if _, err := NewProgramCache(0); err == nil {
+		t.Error("NewProgramCache(0) succeeded, wanted error")
+	}
+	env, err := NewEnv()
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, err := env.CachedProgram("1"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("CachedProgram() got %v, wanted cache not configured error", err)
+	}
+	if _, err := NewEnv(ProgramCaching(nil)); err == nil {
+		t.Error("NewEnv(ProgramCaching(nil)) succeeded, wanted error")
+	}
+	cache, err := NewProgramCache(1)
+	if err != nil {
+		t.Fatalf("NewProgramCache() failed: %v", err)
+	}
+	env, err = NewEnv(ProgramCaching(cache))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	if _, err := env.CachedProgram("undeclared"); err == nil {
+		t.Error("CachedProgram() succeeded, wanted compile error")
+	}
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Errorf("cache.Stats() got size %d, wanted compile errors not to be cached", stats.Size)
+	}
+}
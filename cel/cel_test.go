@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -33,6 +34,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common"
 	celast "github.com/google/cel-go/common/ast"
 	"github.com/google/cel-go/common/env"
 	"github.com/google/cel-go/common/operators"
@@ -48,6 +50,7 @@ import (
 	descpb "google.golang.org/protobuf/types/descriptorpb"
 	dynamicpb "google.golang.org/protobuf/types/dynamicpb"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 
@@ -382,6 +385,176 @@ func TestCrossTypeNumericComparisons(t *testing.T) {
 	}
 }
 
+func TestTypeNarrowing(t *testing.T) {
+	env, err := NewEnv(
+		Container("google.expr.proto3.test"),
+		Types(&proto3pb.TestAllTypes{}),
+		Variable("x", DynType),
+		Variable("xs", ListType(DynType)),
+		Variable("i", IntType),
+		TypeNarrowing(true),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		outType *Type
+		in      map[string]any
+		out     ref.Val
+	}{
+		{expr: `type(x) == int && x + 1 > 5`, outType: BoolType, in: map[string]any{"x": 5}, out: types.True},
+		{expr: `type(x) == int && x + 1 > 5`, outType: BoolType, in: map[string]any{"x": "5"}, out: types.False},
+		{expr: `type(x) == string ? x.size() : -1`, outType: IntType, in: map[string]any{"x": "abc"}, out: types.Int(3)},
+		{expr: `type(x) == string ? x.size() : -1`, outType: IntType, in: map[string]any{"x": 1.5}, out: types.Int(-1)},
+		{
+			expr:    `type(x) == TestAllTypes && x.single_int64 == 1`,
+			outType: BoolType,
+			in:      map[string]any{"x": &proto3pb.TestAllTypes{SingleInt64: 1}},
+			out:     types.True,
+		},
+		{
+			expr:    `type(x) == TestAllTypes && x.single_int64 == 1`,
+			outType: BoolType,
+			in:      map[string]any{"x": &proto3pb.NestedTestAllTypes{}},
+			out:     types.False,
+		},
+		{
+			expr:    `xs.filter(e, type(e) == string).map(e, e + '!')`,
+			outType: ListType(StringType),
+			in:      map[string]any{"xs": []any{"a", 1, "b"}},
+			out:     types.NewStringList(types.DefaultTypeAdapter, []string{"a!", "b!"}),
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			if !ast.OutputType().IsExactType(tc.outType) {
+				t.Errorf("env.Compile(%v) got type %v, wanted %v", tc.expr, ast.OutputType(), tc.outType)
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(tc.in)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Equal(tc.out) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+
+	// Narrowed references are checked against the narrowed type.
+	_, iss := env.Compile(`type(x) == int && x.startsWith('a')`)
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "found no matching overload for 'startsWith'") {
+		t.Errorf("env.Compile() got %v, wanted an overload error", iss.Err())
+	}
+	// Type tests which can never hold are reported as warnings alongside a usable Ast.
+	warned, iss := env.Compile(`type(i) == string && i > 1`)
+	if iss.Err() != nil || len(iss.Errors()) != 1 || iss.Errors()[0].Code != common.ErrorCodeUnsoundNarrowing {
+		t.Errorf("env.Compile() got issues %v, wanted an unsound narrowing warning", iss)
+	}
+	if warned == nil {
+		t.Fatal("env.Compile() got nil Ast, wanted a checked Ast with warnings")
+	}
+	prg, err := env.Program(warned)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"i": 2})
+	if err != nil || out != types.False {
+		t.Errorf("prg.Eval() got %v, %v, wanted false", out, err)
+	}
+	// Narrowing to a scalar type bounds the cost of the guarded expression.
+	narrowed, iss := env.Compile(`type(x) == int && x == xs[0]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	unnarrowedEnv, err := env.Extend(TypeNarrowing(false))
+	if err != nil {
+		t.Fatalf("env.Extend() failed: %v", err)
+	}
+	unnarrowed, iss := unnarrowedEnv.Compile(`type(x) == int && x == xs[0]`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	narrowedCost, err := env.EstimateCost(narrowed, testCostEstimator{hints: map[string]uint64{}})
+	if err != nil {
+		t.Fatalf("env.EstimateCost() failed: %v", err)
+	}
+	unnarrowedCost, err := unnarrowedEnv.EstimateCost(unnarrowed, testCostEstimator{hints: map[string]uint64{}})
+	if err != nil {
+		t.Fatalf("env.EstimateCost() failed: %v", err)
+	}
+	if narrowedCost.Max >= unnarrowedCost.Max {
+		t.Errorf("env.EstimateCost() got max cost %d with narrowing, wanted less than %d", narrowedCost.Max, unnarrowedCost.Max)
+	}
+}
+
+func TestUnionTypes(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", UnionType(StringType, ListType(StringType))),
+		Variable("y", UnionType(IntType, DoubleType)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		outType *Type
+		in      map[string]any
+		out     ref.Val
+	}{
+		{expr: `size(x)`, outType: IntType, in: map[string]any{"x": "abc"}, out: types.Int(3)},
+		{expr: `size(x)`, outType: IntType, in: map[string]any{"x": []string{"a", "b"}}, out: types.Int(2)},
+		{expr: `-y`, outType: UnionType(IntType, DoubleType), in: map[string]any{"y": 2}, out: types.Int(-2)},
+		{expr: `-y`, outType: UnionType(IntType, DoubleType), in: map[string]any{"y": 1.5}, out: types.Double(-1.5)},
+		{expr: `x == 'abc'`, outType: BoolType, in: map[string]any{"x": "abc"}, out: types.True},
+		{expr: `'abc' == x`, outType: BoolType, in: map[string]any{"x": "abc"}, out: types.True},
+		{expr: `'abc' == x`, outType: BoolType, in: map[string]any{"x": []string{"abc"}}, out: types.False},
+		{expr: `y == 1`, outType: BoolType, in: map[string]any{"y": 1}, out: types.True},
+		{expr: `1 == y`, outType: BoolType, in: map[string]any{"y": 1.0}, out: types.True},
+		{expr: `x != null`, outType: BoolType, in: map[string]any{"x": "abc"}, out: types.True},
+		{expr: `null != x`, outType: BoolType, in: map[string]any{"x": []string{}}, out: types.True},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			if !ast.OutputType().IsExactType(tc.outType) {
+				t.Errorf("env.Compile(%v) got type %v, wanted %v", tc.expr, FormatCELType(ast.OutputType()), FormatCELType(tc.outType))
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(tc.in)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Equal(tc.out) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+	if got := FormatCELType(UnionType(StringType, ListType(StringType))); got != "string | list(string)" {
+		t.Errorf("FormatCELType() got %s, wanted string | list(string)", got)
+	}
+	_, iss := env.Compile(`x.startsWith('a')`)
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "found no matching overload for 'startsWith'") {
+		t.Errorf("env.Compile(x.startsWith('a')) got %v, wanted no matching overload error", iss.Err())
+	}
+}
+
 func TestExtendStdlibFunction(t *testing.T) {
 	env := testEnv(t,
 		Function(overloads.Contains,
@@ -1063,109 +1236,855 @@ func TestCustomExistsMacro(t *testing.T) {
 	}
 }
 
-func TestAstIsChecked(t *testing.T) {
-	env := testEnv(t)
-	ast, iss := env.Compile("true")
+func TestAstIsChecked(t *testing.T) {
+	env := testEnv(t)
+	ast, iss := env.Compile("true")
+	if iss.Err() != nil {
+		t.Fatalf("e.Compile('true') failed: %v", iss.Err())
+	}
+	if !ast.IsChecked() {
+		t.Error("got ast.IsChecked() 'false', wanted 'true'.")
+	}
+	ce, err := AstToCheckedExpr(ast)
+	if err != nil {
+		t.Fatalf("AstToCheckedExpr(%v) failed: %v", ast, err)
+	}
+	ast2 := CheckedExprToAst(ce)
+	if !ast2.IsChecked() {
+		t.Error("got ast2.IsChecked() 'false', wanted 'true'")
+	}
+	if !proto.Equal(ast.Expr(), ast2.Expr()) {
+		t.Errorf("AST exprs did not roundtrip properly: ast1: %v, ast2: %v", ast, ast2)
+	}
+}
+
+func TestExhaustiveEval(t *testing.T) {
+	env := testEnv(t,
+		Variable("k", StringType),
+		Variable("v", BoolType),
+	)
+	ast, iss := env.Compile(`{k: true}[k] || v != false`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %s\n", err)
+	}
+	out, details, err := prg.Eval(
+		map[string]any{
+			"k": "key",
+			"v": true})
+	if err != nil {
+		t.Fatalf("runtime error: %s\n", err)
+	}
+	if out != types.True {
+		t.Errorf("got '%v', expected 'true'", out.Value())
+	}
+
+	// Test to see whether 'v != false' was resolved to a value.
+	// With short-circuiting it normally wouldn't be.
+	s := details.State()
+	lhsVal, found := s.Value(ast.Expr().GetCallExpr().GetArgs()[0].Id)
+	if !found {
+		t.Error("got not found, wanted evaluation of left hand side expression.")
+		return
+	}
+	if lhsVal != types.True {
+		t.Errorf("got '%v', expected 'true'", lhsVal)
+	}
+	rhsVal, found := s.Value(ast.Expr().GetCallExpr().GetArgs()[1].Id)
+	if !found {
+		t.Error("got not found, wanted evaluation of right hand side expression.")
+		return
+	}
+	if rhsVal != types.True {
+		t.Errorf("got '%v', expected 'true'", rhsVal)
+	}
+}
+
+func TestEvalTrace(t *testing.T) {
+	env := testEnv(t, Variable("xs", ListType(IntType)))
+	ast, iss := env.Compile(`xs.filter(x, x % 2 == 0).size() > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalTrace(), EvalOptions(OptTrackState, OptTrackCost))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, details, err := prg.Eval(map[string]any{"xs": []int{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if out != types.True {
+		t.Errorf("prg.Eval() got %v, wanted true", out)
+	}
+	trace := details.Trace()
+	if trace == nil || len(trace.Steps()) != 1 {
+		t.Fatalf("details.Trace() got %v, wanted a single root step", trace)
+	}
+	root := trace.Steps()[0]
+	if root.Function != operators.Greater || root.Overload != "greater_int64" {
+		t.Errorf("root step got function %q overload %q, wanted _>_ greater_int64", root.Function, root.Overload)
+	}
+	var modIterations []int
+	var walk func([]*interpreter.TraceStep)
+	walk = func(steps []*interpreter.TraceStep) {
+		for _, s := range steps {
+			if s.Function == operators.Modulo {
+				modIterations = append(modIterations, s.Iteration)
+			}
+			walk(s.Children)
+		}
+	}
+	walk(trace.Steps())
+	if !reflect.DeepEqual(modIterations, []int{0, 1, 2}) {
+		t.Errorf("got modulo iterations %v, wanted [0, 1, 2]", modIterations)
+	}
+	if _, err := json.Marshal(trace); err != nil {
+		t.Errorf("json.Marshal(trace) failed: %v", err)
+	}
+	// The trace must not interfere with the other observers.
+	if len(details.State().IDs()) == 0 {
+		t.Error("details.State() recorded no values")
+	}
+	if cost := details.ActualCost(); cost == nil || *cost == 0 {
+		t.Errorf("details.ActualCost() got %v, wanted non-zero cost", cost)
+	}
+
+	prg, err = env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, details, _ = prg.Eval(map[string]any{"xs": []int{}}); details.Trace() != nil {
+		t.Error("details.Trace() got non-nil trace without the EvalTrace option")
+	}
+}
+
+func TestProfileEval(t *testing.T) {
+	env := testEnv(t, Variable("xs", ListType(IntType)))
+	ast, iss := env.Compile(`xs.filter(x, x % 2 == 0).size() > 0`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	profiler, err := interpreter.NewProfiler()
+	if err != nil {
+		t.Fatalf("interpreter.NewProfiler() failed: %v", err)
+	}
+	prg, err := env.Program(ast, ProfileEval(profiler), EvalOptions(OptTrackCost))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, _, err := prg.Eval(map[string]any{"xs": []int{1, 2, 3, 4}}); err != nil {
+			t.Fatalf("prg.Eval() failed: %v", err)
+		}
+	}
+	stats := profiler.OverloadStats()
+	if got := stats["modulo_int64"].Count; got != 12 {
+		t.Errorf("OverloadStats()['modulo_int64'].Count got %d, wanted 12", got)
+	}
+	if got := stats["greater_int64"].Count; got != 3 {
+		t.Errorf("OverloadStats()['greater_int64'].Count got %d, wanted 3", got)
+	}
+	var buf bytes.Buffer
+	if err := profiler.WriteProfile(&buf); err != nil || buf.Len() == 0 {
+		t.Errorf("WriteProfile() got %d bytes, %v, wanted profile data", buf.Len(), err)
+	}
+
+	// A profiler may only be used with a single expression.
+	other, iss := env.Compile(`xs.size() > 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	if _, err := env.Program(other, ProfileEval(profiler)); err == nil {
+		t.Error("env.Program() with a profiler of a different expression succeeded, wanted error")
+	}
+}
+
+func TestEvalResolveNameWithError(t *testing.T) {
+	env := testEnv(t, Variable("req", MapType(StringType, StringType)), Variable("x", IntType))
+	ast, iss := env.Compile(`x > 0 && req.user == 'alice'`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	fetchErr := errors.New("decode failed")
+	prgOpts := [][]ProgramOption{
+		{},
+		{EvalOptions(OptTrackState, OptTrackCost)},
+		{EvalOptions(OptOptimize), InterruptCheckFrequency(10)},
+	}
+	for i, opts := range prgOpts {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			prg, err := env.Program(ast, opts...)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			in := map[string]any{
+				"x":   1,
+				"req": func() (any, error) { return nil, fetchErr },
+			}
+			out, _, err := prg.ContextEval(context.Background(), in)
+			if !errors.Is(err, fetchErr) {
+				t.Fatalf("prg.ContextEval() got %v, %v, wanted %v", out, err, fetchErr)
+			}
+			celErr, ok := out.(*types.Err)
+			if !ok {
+				t.Fatalf("prg.ContextEval() got %v, wanted types.Err", out)
+			}
+			// The error is labeled with the id of the `req` variable.
+			reqID := ast.NativeRep().Expr().AsCall().Args()[1].AsCall().Args()[0].AsSelect().Operand().ID()
+			if id := celErr.NodeID(); id != reqID {
+				t.Errorf("types.Err.NodeID() got %d, wanted %d", id, reqID)
+			}
+			in["req"] = func() (any, error) { return map[string]string{"user": "alice"}, nil }
+			if out, _, err = prg.Eval(in); err != nil || out != types.True {
+				t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+			}
+		})
+	}
+}
+
+func TestEvalSession(t *testing.T) {
+	env := testEnv(t,
+		Variable("req", MapType(StringType, DynType)),
+		Variable("resource", StringType),
+		Variable("unused", StringType),
+	)
+	var calls int
+	sess, err := NewEvalSession(map[string]any{
+		"req": func() any {
+			calls++
+			return map[string]any{"auth": map[string]any{"claims": map[string]any{"group": "admin"}}}
+		},
+		"resource": "docs",
+		"unused":   "unused",
+	})
+	if err != nil {
+		t.Fatalf("NewEvalSession() failed: %v", err)
+	}
+	exprs := []string{
+		`req.auth.claims.group == 'admin'`,
+		`req.auth.claims.group in ['admin', 'dev'] && resource == 'docs'`,
+		`has(req.auth.claims.group)`,
+	}
+	var prgs []Program
+	for _, expr := range exprs {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast, EvalOptions(OptOptimize, OptSessionAttributes), InterruptCheckFrequency(10))
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		prgs = append(prgs, prg)
+	}
+	// Populate the session, then evaluate the programs concurrently.
+	for _, prg := range prgs {
+		out, _, err := prg.ContextEval(context.Background(), sess)
+		if err != nil || out != types.True {
+			t.Errorf("prg.ContextEval() got %v, %v, wanted true", out, err)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		for _, prg := range prgs {
+			wg.Add(1)
+			go func(prg Program) {
+				defer wg.Done()
+				out, _, err := prg.ContextEval(context.Background(), sess)
+				if err != nil || out != types.True {
+					t.Errorf("prg.ContextEval() got %v, %v, wanted true", out, err)
+				}
+			}(prg)
+		}
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("lazy variable resolved %d times, wanted 1", calls)
+	}
+	stats := sess.Stats()
+	if !reflect.DeepEqual(stats.Variables, []string{"req", "resource"}) {
+		t.Errorf("sess.Stats().Variables got %v, wanted [req resource]", stats.Variables)
+	}
+	// The selection of `req.auth.claims.group` and its presence test are each computed once.
+	if stats.AttributeMisses != 2 || stats.AttributeLookups != 12 {
+		t.Errorf("sess.Stats() got %d attribute lookups, %d misses, wanted 12 lookups, 2 misses",
+			stats.AttributeLookups, stats.AttributeMisses)
+	}
+}
+
+func TestContextEval(t *testing.T) {
+	env := testEnv(t, Variable("items", ListType(IntType)))
+	ast, iss := env.Compile("items.map(i, i * 2).filter(i, i >= 50).size()")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, EvalOptions(OptOptimize|OptTrackState), InterruptCheckFrequency(100))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+
+	ctx := context.TODO()
+	items := make([]int64, 2000)
+	for i := int64(0); i < 2000; i++ {
+		items[i] = i
+	}
+	out, _, err := prg.ContextEval(ctx, map[string]any{"items": items})
+	if err != nil {
+		t.Fatalf("prg.ContextEval() failed: %v", err)
+	}
+	if out != types.Int(1975) {
+		t.Errorf("prg.ContextEval() got %v, wanted 1975", out)
+	}
+
+	evalCtx, cancel := context.WithTimeout(ctx, time.Microsecond)
+	defer cancel()
+
+	out, _, err = prg.ContextEval(evalCtx, map[string]any{"items": items})
+	if err == nil {
+		t.Errorf("Got result %v, wanted timeout error", out)
+	}
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got %v, wanted context deadline exceeded", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "operation interrupted") {
+		t.Errorf("Got %v, wanted error containing 'operation interrupted'", err)
+	}
+}
+
+func TestParallelComprehensions(t *testing.T) {
+	env := testEnv(t, Variable("items", ListType(IntType)))
+	ast, iss := env.Compile("items.map(i, i * 2).filter(i, i >= 50).size()")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	items := make([]int64, 2000)
+	for i := int64(0); i < 2000; i++ {
+		items[i] = i
+	}
+	var costs []uint64
+	for _, opts := range [][]ProgramOption{{}, {ParallelComprehensions(100, 4)}} {
+		prg, err := env.Program(ast, append(opts, CostLimit(100000), InterruptCheckFrequency(100))...)
+		if err != nil {
+			t.Fatalf("env.Program() failed: %v", err)
+		}
+		out, det, err := prg.ContextEval(context.Background(), map[string]any{"items": items})
+		if err != nil {
+			t.Fatalf("prg.ContextEval() failed: %v", err)
+		}
+		if out != types.Int(1975) {
+			t.Errorf("prg.ContextEval() got %v, wanted 1975", out)
+		}
+		costs = append(costs, *det.ActualCost())
+	}
+	if costs[0] != costs[1] {
+		t.Errorf("parallel evaluation cost %d, wanted sequential cost %d", costs[1], costs[0])
+	}
+
+	prg, err := env.Program(ast, ParallelComprehensions(100, 4), CostLimit(costs[0]/2))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(map[string]any{"items": items})
+	if err == nil || !strings.Contains(err.Error(), "actual cost limit exceeded") {
+		t.Errorf("prg.Eval() got %v, wanted cost limit error", err)
+	}
+
+	prg, err = env.Program(ast, ParallelComprehensions(100, 4), InterruptCheckFrequency(1))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = prg.ContextEval(ctx, map[string]any{"items": items})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("prg.ContextEval() got %v, wanted context canceled", err)
+	}
+
+	if _, err := env.Program(ast, ParallelComprehensions(0, 4)); err == nil {
+		t.Error("env.Program() succeeded with an invalid minimum range size")
+	}
+}
+
+func TestBytecodeEval(t *testing.T) {
+	env := testEnv(t,
+		Variable("items", ListType(IntType)),
+		Variable("limit", IntType),
+	)
+	ast, iss := env.Compile("items.filter(i, i < limit).map(i, i * 2).exists(i, i > 10) ? items.size() : limit")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	in := map[string]any{"items": []int64{1, 3, 5, 7, 9}, "limit": 8}
+	var costs []uint64
+	for _, opts := range [][]ProgramOption{{}, {EvalOptions(OptOptimize)}} {
+		for _, bytecode := range []bool{false, true} {
+			if bytecode {
+				opts = append(opts, BytecodeEval())
+			}
+			prg, err := env.Program(ast, append(opts, CostTracking(nil), EvalOptions(OptTrackState))...)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, det, err := prg.Eval(in)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out != types.Int(5) {
+				t.Errorf("prg.Eval() got %v, wanted 5", out)
+			}
+			if len(det.State().IDs()) == 0 {
+				t.Error("prg.Eval() did not track evaluation state")
+			}
+			costs = append(costs, *det.ActualCost())
+		}
+	}
+	if costs[0] != costs[1] || costs[2] != costs[3] {
+		t.Errorf("bytecode evaluation costs %d and %d, wanted tree evaluation costs %d and %d",
+			costs[1], costs[3], costs[0], costs[2])
+	}
+
+	prg, err := env.Program(ast, BytecodeEval(), CostLimit(costs[0]/2))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, _, err = prg.Eval(in)
+	if err == nil || !strings.Contains(err.Error(), "actual cost limit exceeded") {
+		t.Errorf("prg.Eval() got %v, wanted cost limit error", err)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	env := testEnv(t,
+		Variable("items", ListType(StringType)),
+	)
+	ast, iss := env.Compile("items.map(i, i + i).size() + (items + items).size()")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	in := map[string]any{"items": []string{"abc", "defg"}}
+	prg, err := env.Program(ast, MemoryLimit(1000))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(in)
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if out != types.Int(6) {
+		t.Errorf("prg.Eval() got %v, wanted 6", out)
+	}
+	// Two doubled strings, two single element lists appended to the result, and a four element list.
+	if mem := det.ActualMemory(); mem == nil || *mem != 14+2*16+2*16+4*16 {
+		t.Errorf("det.ActualMemory() got %v, wanted 142", mem)
+	}
+
+	prg, err = env.Program(ast, MemoryLimit(100))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err = prg.Eval(in)
+	var cancelled interpreter.EvalCancelledError
+	if !errors.As(err, &cancelled) || cancelled.Cause != interpreter.MemoryLimitExceeded {
+		t.Fatalf("prg.Eval() got %v, wanted memory limit exceeded", err)
+	}
+	if err.Error() != "operation cancelled: actual memory limit exceeded" {
+		t.Errorf("prg.Eval() got error %q, wanted actual memory limit exceeded", err)
+	}
+	if det.ActualMemory() == nil {
+		t.Error("det.ActualMemory() got nil, wanted the memory allocated before cancellation")
+	}
+}
+
+func TestContextFunctionBinding(t *testing.T) {
+	type tenantKey struct{}
+	env := testEnv(t,
+		Variable("key", StringType),
+		Function("tenant",
+			Overload("tenant_string", []*Type{StringType}, StringType,
+				ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
+					tenant, ok := ctx.Value(tenantKey{}).(string)
+					if !ok {
+						tenant = "default"
+					}
+					return types.String(tenant+"/") + arg.(types.String)
+				}))),
+		Function("wait",
+			Overload("wait_int", []*Type{IntType}, IntType,
+				ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
+					<-ctx.Done()
+					return arg
+				}))),
+	)
+	ast, iss := env.Compile(`tenant(key) + ':' + tenant('const')`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	folder, err := NewConstantFoldingOptimizer()
+	if err != nil {
+		t.Fatalf("NewConstantFoldingOptimizer() failed: %v", err)
+	}
+	opt, err := NewStaticOptimizer(folder)
+	if err != nil {
+		t.Fatalf("NewStaticOptimizer() failed: %v", err)
+	}
+	// Context-aware functions are not folded since their result depends on the evaluation context.
+	ast, iss = opt.Optimize(env, ast)
+	if iss.Err() != nil {
+		t.Fatalf("Optimize() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	vars := map[string]any{"key": "k"}
+	out, _, err := prg.Eval(vars)
+	if err != nil || out != types.String("default/k:default/const") {
+		t.Errorf("prg.Eval() got %v, %v, wanted 'default/k:default/const'", out, err)
+	}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	out, _, err = prg.ContextEval(ctx, vars)
+	if err != nil || out != types.String("acme/k:acme/const") {
+		t.Errorf("prg.ContextEval() got %v, %v, wanted 'acme/k:acme/const'", out, err)
+	}
+
+	ast, iss = env.Compile(`wait(1) + 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err = env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	out, _, err = prg.ContextEval(ctx, NoVars())
+	if err == nil {
+		t.Fatalf("prg.ContextEval() got %v, wanted deadline exceeded error", out)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("prg.ContextEval() got %v, wanted context deadline exceeded", err)
+	}
+	var cancelled interpreter.EvalCancelledError
+	if !errors.As(err, &cancelled) || cancelled.Cause != interpreter.ContextCancelled {
+		t.Errorf("prg.ContextEval() got %v, wanted EvalCancelledError", err)
+	}
+}
+
+func TestContextEvalInterruptCalls(t *testing.T) {
+	env := testEnv(t, Variable("s", StringType))
+	ast, iss := env.Compile(`s.matches('^a+$') || s.startsWith('b')`)
 	if iss.Err() != nil {
-		t.Fatalf("e.Compile('true') failed: %v", iss.Err())
-	}
-	if !ast.IsChecked() {
-		t.Error("got ast.IsChecked() 'false', wanted 'true'.")
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
 	}
-	ce, err := AstToCheckedExpr(ast)
+	prg, err := env.Program(ast, EvalOptions(OptOptimize), InterruptCheckFrequency(1))
 	if err != nil {
-		t.Fatalf("AstToCheckedExpr(%v) failed: %v", ast, err)
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	ast2 := CheckedExprToAst(ce)
-	if !ast2.IsChecked() {
-		t.Error("got ast2.IsChecked() 'false', wanted 'true'")
+	vars := map[string]any{"s": "aaa"}
+	if out, _, err := prg.ContextEval(context.Background(), vars); err != nil || out != types.True {
+		t.Errorf("prg.ContextEval() got %v, %v, wanted true", out, err)
 	}
-	if !proto.Equal(ast.Expr(), ast2.Expr()) {
-		t.Errorf("AST exprs did not roundtrip properly: ast1: %v, ast2: %v", ast, ast2)
+	// The expression has no comprehensions, so the interrupt is observed at function dispatch.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out, _, err := prg.ContextEval(ctx, vars)
+	if err == nil {
+		t.Fatalf("prg.ContextEval() got %v, wanted cancellation error", out)
+	}
+	var cancelled interpreter.EvalCancelledError
+	if !errors.As(err, &cancelled) || cancelled.Cause != interpreter.ContextCancelled {
+		t.Errorf("prg.ContextEval() got %v, wanted EvalCancelledError", err)
+	}
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, interpreter.InterruptError{}) {
+		t.Errorf("prg.ContextEval() got %v, wanted interrupted context cancellation", err)
 	}
 }
 
-func TestExhaustiveEval(t *testing.T) {
+func TestFunctionResultCacheSize(t *testing.T) {
+	var calls int
 	env := testEnv(t,
-		Variable("k", StringType),
-		Variable("v", BoolType),
+		Variable("name", StringType),
+		Function("lookup",
+			Overload("lookup_string", []*Type{StringType}, StringType,
+				OverloadIsDeterministic(),
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					calls++
+					return types.String("policy/") + arg.(types.String)
+				}))),
 	)
-	ast, iss := env.Compile(`{k: true}[k] || v != false`)
+	ast, iss := env.Compile(`lookup(name) == 'policy/' + name`)
 	if iss.Err() != nil {
 		t.Fatalf("env.Compile() failed: %v", iss.Err())
 	}
-	prg, err := env.Program(ast, EvalOptions(OptExhaustiveEval))
+	prg, err := env.Program(ast)
 	if err != nil {
-		t.Fatalf("env.Program() failed: %s\n", err)
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	out, details, err := prg.Eval(
-		map[string]any{
-			"k": "key",
-			"v": true})
+	for i, name := range []string{"a", "b", "a", "a"} {
+		out, det, err := prg.Eval(map[string]any{"name": name})
+		if err != nil || out != types.True {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+		}
+		stats := det.FunctionCacheStats()
+		if stats == nil || stats.Hits()+stats.Misses() != 1 {
+			t.Fatalf("eval %d got FunctionCacheStats() %v, wanted one lookup", i, stats)
+		}
+		if wantHit := i >= 2; (stats.Hits() == 1) != wantHit {
+			t.Errorf("eval %d got %d hits, wanted hit: %t", i, stats.Hits(), wantHit)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("lookup() called %d times, wanted 2", calls)
+	}
+
+	prg, err = env.Program(ast, FunctionResultCacheSize(0))
 	if err != nil {
-		t.Fatalf("runtime error: %s\n", err)
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	if out != types.True {
-		t.Errorf("got '%v', expected 'true'", out.Value())
+	_, det, err := prg.Eval(map[string]any{"name": "a"})
+	if err != nil || det.FunctionCacheStats() != nil || calls != 3 {
+		t.Errorf("prg.Eval() with memoization disabled got stats %v, %d calls, wanted no stats", det.FunctionCacheStats(), calls)
 	}
+	if _, err := env.Program(ast, FunctionResultCacheSize(-1)); err == nil {
+		t.Error("env.Program() with a negative cache size succeeded, wanted error")
+	}
+}
 
-	// Test to see whether 'v != false' was resolved to a value.
-	// With short-circuiting it normally wouldn't be.
-	s := details.State()
-	lhsVal, found := s.Value(ast.Expr().GetCallExpr().GetArgs()[0].Id)
-	if !found {
-		t.Error("got not found, wanted evaluation of left hand side expression.")
-		return
+func TestEvalBatch(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	ast, iss := env.Compile("x < 0 ? x / 0 : x * 2")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
 	}
-	if lhsVal != types.True {
-		t.Errorf("got '%v', expected 'true'", lhsVal)
+	prg, err := env.Program(ast, CostTracking(nil))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	rhsVal, found := s.Value(ast.Expr().GetCallExpr().GetArgs()[1].Id)
-	if !found {
-		t.Error("got not found, wanted evaluation of right hand side expression.")
-		return
+	inputs := make([]any, 100)
+	for i := range inputs {
+		if i%10 == 9 {
+			inputs[i] = map[string]any{"x": -i}
+		} else if i%10 == 5 {
+			act, err := NewActivation(map[string]any{"x": i})
+			if err != nil {
+				t.Fatalf("NewActivation() failed: %v", err)
+			}
+			inputs[i] = act
+		} else {
+			inputs[i] = map[string]any{"x": i}
+		}
 	}
-	if rhsVal != types.True {
-		t.Errorf("got '%v', expected 'true'", rhsVal)
+	for _, workers := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			results, err := EvalBatch(context.Background(), prg, slices.Values(inputs), BatchWorkers(workers))
+			if err != nil {
+				t.Fatalf("EvalBatch() failed: %v", err)
+			}
+			if len(results) != len(inputs) {
+				t.Fatalf("EvalBatch() got %d results, wanted %d", len(results), len(inputs))
+			}
+			for i, res := range results {
+				if i%10 == 9 {
+					if res.Err == nil || !strings.Contains(res.Err.Error(), "division by zero") {
+						t.Errorf("results[%d] got %v, wanted division by zero error", i, res.Err)
+					}
+					continue
+				}
+				if res.Err != nil {
+					t.Fatalf("results[%d] failed: %v", i, res.Err)
+				}
+				if res.Val != types.Int(i*2) {
+					t.Errorf("results[%d] got %v, wanted %d", i, res.Val, i*2)
+				}
+				if res.Details.ActualCost() == nil {
+					t.Errorf("results[%d] got nil cost, wanted cost tracking", i)
+				}
+			}
+		})
 	}
 }
 
-func TestContextEval(t *testing.T) {
+func TestEvalBatchInvalidInput(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	ast, iss := env.Compile("x")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	results, err := EvalBatch(context.Background(), prg, slices.Values([]any{map[string]any{"x": 1}, "x"}))
+	if err != nil {
+		t.Fatalf("EvalBatch() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Val != types.Int(1) || results[1].Err == nil {
+		t.Errorf("EvalBatch() got %v, wanted one result and one invalid input error", results)
+	}
+}
+
+func TestEvalBatchCancelled(t *testing.T) {
 	env := testEnv(t, Variable("items", ListType(IntType)))
 	ast, iss := env.Compile("items.map(i, i * 2).filter(i, i >= 50).size()")
 	if iss.Err() != nil {
 		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
 	}
-	prg, err := env.Program(ast, EvalOptions(OptOptimize|OptTrackState), InterruptCheckFrequency(100))
+	prg, err := env.Program(ast, InterruptCheckFrequency(1))
 	if err != nil {
 		t.Fatalf("env.Program() failed: %v", err)
 	}
-
-	ctx := context.TODO()
 	items := make([]int64, 2000)
 	for i := int64(0); i < 2000; i++ {
 		items[i] = i
 	}
-	out, _, err := prg.ContextEval(ctx, map[string]any{"items": items})
+	cancelled := errors.New("batch cancelled")
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			// The sequence is unbounded, so the batch only completes if the cancellation stops
+			// the consumption of inputs.
+			produced := 0
+			inputs := func(yield func(any) bool) {
+				for i := 0; ; i++ {
+					if i == 10 {
+						cancel(cancelled)
+					}
+					produced++
+					if !yield(map[string]any{"items": items}) {
+						return
+					}
+				}
+			}
+			results, err := EvalBatch(ctx, prg, inputs, BatchWorkers(workers))
+			if !errors.Is(err, cancelled) {
+				t.Errorf("EvalBatch() got error %v, wanted %v", err, cancelled)
+			}
+			if produced != 11 {
+				t.Errorf("EvalBatch() consumed %d inputs, wanted 11", produced)
+			}
+			if len(results) != 10 {
+				t.Fatalf("EvalBatch() got %d results, wanted 10", len(results))
+			}
+			for i, res := range results {
+				if i >= 10+workers && !errors.Is(res.Err, cancelled) {
+					t.Errorf("results[%d] got %v, wanted cancellation error %v", i, res.Err, cancelled)
+				}
+				if res.Err == nil && res.Val != types.Int(1975) {
+					t.Errorf("results[%d] got %v, wanted 1975", i, res.Val)
+				}
+				if res.Err != nil && !errors.Is(res.Err, cancelled) {
+					t.Errorf("results[%d] got error %v, wanted %v", i, res.Err, cancelled)
+				}
+			}
+		})
+	}
+}
+
+func TestEvalBatchInputPanic(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	ast, iss := env.Compile("x * 2")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
 	if err != nil {
-		t.Fatalf("prg.ContextEval() failed: %v", err)
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	if out != types.Int(1975) {
-		t.Errorf("prg.ContextEval() got %v, wanted 1975", out)
+	inputs := func(yield func(any) bool) {
+		for i := 0; i < 5; i++ {
+			if !yield(map[string]any{"x": i}) {
+				return
+			}
+		}
+		panic("input source failed")
+	}
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			results, err := EvalBatch(context.Background(), prg, inputs, BatchWorkers(workers))
+			if err == nil || !strings.Contains(err.Error(), "input source failed") {
+				t.Errorf("EvalBatch() got error %v, wanted input panic", err)
+			}
+			if len(results) != 5 {
+				t.Fatalf("EvalBatch() got %d results, wanted 5", len(results))
+			}
+			for i, res := range results {
+				if res.Val != types.Int(i*2) {
+					t.Errorf("results[%d] got %v, wanted %d", i, res.Val, i*2)
+				}
+			}
+		})
 	}
+}
 
-	evalCtx, cancel := context.WithTimeout(ctx, time.Microsecond)
-	defer cancel()
+// customProgram wraps a Program to verify that EvalBatch supports external implementations.
+type customProgram struct {
+	Program
+}
 
-	out, _, err = prg.ContextEval(evalCtx, map[string]any{"items": items})
-	if err == nil {
-		t.Errorf("Got result %v, wanted timeout error", out)
+func TestEvalBatchCustomProgram(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	ast, iss := env.Compile("x + 1")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile(expr) failed: %v", iss.Err())
 	}
-	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
-		t.Errorf("Got %v, wanted context deadline exceeded", err)
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
 	}
-	if err != nil && !strings.Contains(err.Error(), "operation interrupted") {
-		t.Errorf("Got %v, wanted error containing 'operation interrupted'", err)
+	results, err := EvalBatch(context.Background(), customProgram{prg},
+		slices.Values([]any{map[string]any{"x": 1}, map[string]any{"x": 2}}))
+	if err != nil {
+		t.Fatalf("EvalBatch() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Val != types.Int(2) || results[1].Val != types.Int(3) {
+		t.Errorf("EvalBatch() got %v, wanted [2, 3]", results)
+	}
+}
+
+// blockingProgram is a Program whose evaluations block until the context is done.
+type blockingProgram struct {
+	Program
+	started chan struct{}
+}
+
+func (p blockingProgram) ContextEval(ctx context.Context, _ any) (ref.Val, *EvalDetails, error) {
+	select {
+	case p.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return nil, nil, context.Cause(ctx)
+}
+
+func TestEvalBatchCancelledWhileBlocked(t *testing.T) {
+	cancelled := errors.New("batch cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	prg := blockingProgram{started: make(chan struct{}, 1)}
+	go func() {
+		<-prg.started
+		cancel(cancelled)
+	}()
+	// The workers are blocked on their evaluations, so the unbounded sequence is only released by
+	// the cancellation of the batch.
+	inputs := func(yield func(any) bool) {
+		for {
+			if !yield(map[string]any{}) {
+				return
+			}
+		}
+	}
+	results, err := EvalBatch(ctx, prg, inputs, BatchWorkers(2))
+	if !errors.Is(err, cancelled) {
+		t.Errorf("EvalBatch() got error %v, wanted %v", err, cancelled)
+	}
+	for i, res := range results {
+		if !errors.Is(res.Err, cancelled) {
+			t.Errorf("results[%d] got %v, wanted cancellation error %v", i, res.Err, cancelled)
+		}
 	}
 }
 
@@ -1355,12 +2274,78 @@ func TestResidualAstComplex(t *testing.T) {
 	}
 }
 
+func TestAttributeReads(t *testing.T) {
+	env := testEnv(t,
+		Container("google.expr.proto3.test"),
+		Types(&proto3pb.TestAllTypes{}),
+		Variable("ns.request", MapType(StringType, DynType)),
+		Variable("resource", MapType(StringType, DynType)),
+		Variable("msg", ObjectType("google.expr.proto3.test.TestAllTypes")),
+		OptionalTypes(),
+	)
+	ast, iss := env.Compile(`ns.request.user.groups.exists(g, g in resource.?labels.orValue([]))
+		&& resource.labels['env'] == 'prod'
+		&& msg.standalone_enum == TestAllTypes.NestedEnum.BAR`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	reads, err := AttributeReads(ast)
+	if err != nil {
+		t.Fatalf("AttributeReads() failed: %v", err)
+	}
+	want := map[string]bool{
+		"msg.standalone_enum":    false,
+		"ns.request.user.groups": true,
+		"resource.labels":        false,
+		"resource.labels.env":    false,
+	}
+	got := map[string]bool{}
+	for _, read := range reads {
+		got[read.Pattern.String()] = read.MustRead
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AttributeReads() got %v, wanted %v", got, want)
+	}
+
+	// The read set may be used to construct the unknown patterns of a partial activation.
+	prg, err := env.Program(ast, EvalOptions(OptPartialEval))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	var unknowns []*AttributePatternType
+	for _, read := range reads {
+		if read.Pattern.Variable() == "resource" {
+			unknowns = append(unknowns, read.Pattern)
+		}
+	}
+	vars, err := PartialVars(map[string]any{
+		"ns.request": map[string]any{"user": map[string]any{"groups": []string{"dev"}}},
+		"msg":        &proto3pb.TestAllTypes{StandaloneEnum: proto3pb.TestAllTypes_BAR},
+	}, unknowns...)
+	if err != nil {
+		t.Fatalf("PartialVars() failed: %v", err)
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil || !types.IsUnknown(out) {
+		t.Errorf("prg.Eval() got %v, %v, wanted unknown", out, err)
+	}
+
+	unchecked, iss := env.Parse(`a.b`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	if _, err := AttributeReads(unchecked); err == nil {
+		t.Error("AttributeReads() succeeded for an unchecked expression, wanted error")
+	}
+}
+
 func TestResidualAstMacros(t *testing.T) {
 	tests := []struct {
 		env      *Env
 		in       map[string]any
 		unks     []*interpreter.AttributePattern
 		expr     string
+		unroll   bool
 		residual string
 	}{
 		{
@@ -1385,6 +2370,33 @@ func TestResidualAstMacros(t *testing.T) {
 			expr:     `foo.exists(t, t == bar.baz.x)`,
 			residual: `{"a": "b"}.exists(t, t == bar.baz.x)`,
 		},
+		{
+			env: testEnv(t,
+				Variable("resources", ListType(MapType(StringType, DynType))),
+				Variable("user", MapType(StringType, StringType)),
+				EnableMacroCallTracking()),
+			in: map[string]any{"resources": []map[string]any{
+				{"owner": "alice", "public": true},
+				{"owner": "bob", "public": false},
+				{"owner": "carol", "public": true},
+				{"owner": "dave", "public": false},
+			}},
+			unks:     []*interpreter.AttributePattern{AttributePattern("user")},
+			expr:     `resources.all(r, r.public || r.owner == user.id)`,
+			unroll:   true,
+			residual: `"bob" == user.id && "dave" == user.id`,
+		},
+		{
+			env: testEnv(t,
+				Variable("groups", ListType(StringType)),
+				Variable("user", MapType(StringType, DynType)),
+				EnableMacroCallTracking()),
+			in:       map[string]any{"groups": []string{"admin", "dev", "ops"}},
+			unks:     []*interpreter.AttributePattern{AttributePattern("user").QualString("roles")},
+			expr:     `groups.exists(g, g.startsWith('d') && g in user.roles)`,
+			unroll:   true,
+			residual: `"dev" in user.roles`,
+		},
 	}
 
 	for i, tst := range tests {
@@ -1399,7 +2411,11 @@ func TestResidualAstMacros(t *testing.T) {
 			if iss.Err() != nil {
 				t.Fatalf("env.Compile() failed: %v", iss.Err())
 			}
-			prg, err := env.Program(ast, EvalOptions(OptTrackState, OptPartialEval))
+			opts := []EvalOption{OptTrackState, OptPartialEval}
+			if tc.unroll {
+				opts = append(opts, OptUnrollComprehensions)
+			}
+			prg, err := env.Program(ast, EvalOptions(opts...))
 			if err != nil {
 				t.Fatalf("env.Program() failed: %v", err)
 			}
@@ -1587,6 +2603,21 @@ func TestParseError(t *testing.T) {
 	}
 }
 
+func TestIssuesErrorCodes(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	_, iss := env.Compile("x.startsWith('a')")
+	if iss.Err() == nil {
+		t.Fatal("env.Compile() did not error")
+	}
+	errs := iss.Errors()
+	if len(errs) != 1 || errs[0].Code != common.ErrorCodeNoMatchingOverload {
+		t.Fatalf("iss.Errors() got %v, wanted a single no_matching_overload error", errs)
+	}
+	if fn := errs[0].Details["function"]; fn != "startsWith" {
+		t.Errorf("iss.Errors()[0].Details['function'] got %v, wanted startsWith", fn)
+	}
+}
+
 func TestParseWithMacroTracking(t *testing.T) {
 	env := testEnv(t, EnableMacroCallTracking())
 	ast, iss := env.Parse("has(a.b) && a.b.exists(c, c < 10)")
@@ -2238,6 +3269,108 @@ func TestContextProto(t *testing.T) {
 	}
 }
 
+func TestSchemaTypes(t *testing.T) {
+	address := mustSchemaType(t, "example.Address",
+		types.NewSchemaField("city", StringType),
+		types.NewSchemaField("zip", NullableType(StringType)),
+	)
+	person := mustSchemaType(t, "example.Person",
+		types.NewSchemaField("name", StringType),
+		types.NewSchemaField("age", IntType),
+		types.NewSchemaField("address", ObjectType("example.Address")),
+		types.NewSchemaField("friends", ListType(ObjectType("example.Person"))),
+		types.NewSchemaField("tags", MapType(StringType, StringType)),
+	)
+	env := testEnv(t,
+		Container("example"),
+		SchemaTypes(address, person),
+		Variable("p", ObjectType("example.Person")),
+	)
+	if ft, found := env.CELTypeProvider().FindStructFieldType("example.Person", "friends"); !found ||
+		!ft.Type.IsExactType(ListType(ObjectType("example.Person"))) {
+		t.Errorf("FindStructFieldType(example.Person, friends) got %v, %v", ft, found)
+	}
+	tests := []struct {
+		expr string
+		out  any
+	}{
+		{expr: `p.name == 'alice' && p.age == 0`, out: true},
+		{expr: `has(p.address) && !has(p.age) && !has(p.tags)`, out: true},
+		{expr: `p.address.city == 'Seattle' && p.address.zip == null`, out: true},
+		{expr: `p.friends.map(f, f.name) == ['bob', 'carol']`, out: true},
+		{expr: `p.friends[1].address.city`, out: ""},
+		{expr: `p.friends[0] == Person{name: 'bob'}`, out: true},
+		{expr: `Person{name: 'dan', address: Address{city: 'Paris'}}.address.city`, out: "Paris"},
+		{expr: `has(Person{address: null}.address)`, out: false},
+		{expr: `Person{}.tags.size()`, out: 0},
+		{expr: `Person{} == Person{age: 0}`, out: true},
+		{expr: `type(Person{}) == example.Person && type(p.address) == Address`, out: true},
+		{expr: `type(p) == example.Person`, out: true},
+		{expr: `p == Person{name: 'alice', address: Address{city: 'Seattle'},
+			friends: [Person{name: 'bob'}, Person{name: 'carol'}]}`, out: true},
+		{expr: `p == Person{name: 'alice'}`, out: false},
+	}
+	in := map[string]any{
+		"p": map[string]any{
+			"name":    "alice",
+			"address": map[string]any{"city": "Seattle"},
+			"friends": []any{
+				map[string]any{"name": "bob"},
+				map[string]any{"name": "carol"},
+			},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%s) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(in)
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out.Equal(env.CELTypeAdapter().NativeToValue(tc.out)) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+		})
+	}
+
+	// Schema objects created by expressions convert to Go maps and to JSON.
+	ast, iss := env.Compile(`Person{name: 'erin', address: Address{city: 'Oslo'}}`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(NoVars())
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	native, err := out.ConvertToNative(reflect.TypeOf(map[string]any{}))
+	if err != nil {
+		t.Fatalf("out.ConvertToNative() failed: %v", err)
+	}
+	wantNative := map[string]any{"name": "erin", "address": map[string]any{"city": "Oslo"}}
+	if !reflect.DeepEqual(native, wantNative) {
+		t.Errorf("out.ConvertToNative() got %v, wanted %v", native, wantNative)
+	}
+	jsonVal, err := out.ConvertToNative(types.JSONValueType)
+	if err != nil {
+		t.Fatalf("out.ConvertToNative(JSON) failed: %v", err)
+	}
+	if got := jsonVal.(*structpb.Value).GetStructValue().GetFields()["address"].GetStructValue().GetFields()["city"].GetStringValue(); got != "Oslo" {
+		t.Errorf("out.ConvertToNative(JSON) got %v, wanted address.city 'Oslo'", jsonVal)
+	}
+}
+
 func TestContextProtoJSONFieldNames(t *testing.T) {
 	descriptor := new(proto3pb.TestAllTypes).ProtoReflect().Descriptor()
 	env := testEnv(t, JSONFieldNames(true), DeclareContextProto(descriptor))
@@ -127,6 +127,15 @@ var (
 	ObjectType = types.NewObjectType
 	// TypeParamType creates a parameterized type instance.
 	TypeParamType = types.NewTypeParamType
+	// TypeParamTypeWithBounds creates a parameterized type instance which may only be bound to one
+	// of the provided types, e.g. TypeParamTypeWithBounds("T", IntType, UintType, DoubleType).
+	TypeParamTypeWithBounds = types.NewTypeParamTypeWithBounds
+	// TypeParamTypeWithTraits creates a parameterized type instance which may only be bound to types
+	// supporting all of the provided traits, e.g. TypeParamTypeWithTraits("T", traits.AdderType).
+	TypeParamTypeWithTraits = types.NewTypeParamTypeWithTraits
+	// UnionType creates a type-check time union of the member types, e.g.
+	// UnionType(StringType, ListType(StringType)).
+	UnionType = types.NewUnionType
 )
 
 // Type holds a reference to a runtime type with an optional type-checked set of type parameters.
@@ -340,6 +349,33 @@ func FunctionBinding(binding functions.FunctionOp) OverloadOpt {
 	return decls.FunctionBinding(binding)
 }
 
+// ContextUnaryBinding provides the implementation of a unary overload which receives the context.Context
+// supplied to Program.ContextEval, or context.Background() when the program is evaluated with Eval.
+//
+// If the context is done before or after the function is invoked, the evaluation is cancelled with an
+// interpreter.EvalCancelledError which wraps the cause of the cancellation.
+func ContextUnaryBinding(binding functions.ContextUnaryOp) OverloadOpt {
+	return decls.ContextUnaryBinding(binding)
+}
+
+// ContextBinaryBinding provides the implementation of a binary overload which receives the context.Context
+// supplied to Program.ContextEval, or context.Background() when the program is evaluated with Eval.
+//
+// If the context is done before or after the function is invoked, the evaluation is cancelled with an
+// interpreter.EvalCancelledError which wraps the cause of the cancellation.
+func ContextBinaryBinding(binding functions.ContextBinaryOp) OverloadOpt {
+	return decls.ContextBinaryBinding(binding)
+}
+
+// ContextFunctionBinding provides the implementation of a variadic overload which receives the context.Context
+// supplied to Program.ContextEval, or context.Background() when the program is evaluated with Eval.
+//
+// If the context is done before or after the function is invoked, the evaluation is cancelled with an
+// interpreter.EvalCancelledError which wraps the cause of the cancellation.
+func ContextFunctionBinding(binding functions.ContextFunctionOp) OverloadOpt {
+	return decls.ContextFunctionBinding(binding)
+}
+
 // LateFunctionBinding indicates that the function has a binding which is not known at compile time.
 // This is useful for functions which have side-effects or are not deterministically computable.
 func LateFunctionBinding() OverloadOpt {
@@ -353,6 +389,15 @@ func OverloadIsNonStrict() OverloadOpt {
 	return decls.OverloadIsNonStrict()
 }
 
+// OverloadIsDeterministic indicates that the overload always produces the same result for the same arguments and
+// has no side-effects. The results of calls to deterministic overloads are memoized across evaluations of a
+// program, see FunctionResultCacheSize.
+//
+// Note: the option has no effect on bindings which receive the evaluation context.
+func OverloadIsDeterministic() OverloadOpt {
+	return decls.OverloadIsDeterministic()
+}
+
 // OverloadOperandTrait configures a set of traits which the first argument to the overload must implement in order to be
 // successfully invoked.
 func OverloadOperandTrait(trait int) OverloadOpt {
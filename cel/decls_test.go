@@ -290,6 +290,58 @@ func TestSingletonUnaryBindingParameterized(t *testing.T) {
 	}
 }
 
+func TestConstrainedTypeParams(t *testing.T) {
+	numeric := TypeParamTypeWithBounds("T", IntType, UintType, DoubleType)
+	ordered := TypeParamTypeWithTraits("T", traits.ComparerType)
+	e, err := NewEnv(
+		Variable("x", DynType),
+		Function("sum", Overload("sum_list", []*Type{ListType(numeric)}, numeric)),
+		Function("greatest", Overload("greatest_T_T", []*Type{ordered, ordered}, ordered)),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr    string
+		outType *Type
+		err     string
+	}{
+		{expr: `sum([1, 2, 3])`, outType: IntType},
+		{expr: `sum(x)`, outType: DynType},
+		{expr: `greatest(timestamp(1), timestamp(2))`, outType: TimestampType},
+		{
+			expr: `sum(['a'])`,
+			err:  "type parameter 'T' of overload 'sum_list' requires one of int, uint, double, but found 'string'",
+		},
+		{
+			expr: `greatest({}, {})`,
+			err:  "type parameter 'T' of overload 'greatest_T_T' requires traits comparer, but found 'map(",
+		},
+		{
+			expr: `sum(1)`,
+			err:  "found no matching overload for 'sum' applied to '(int)'",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := e.Compile(tc.expr)
+			if tc.err != "" {
+				if iss.Err() == nil || !strings.Contains(iss.Err().Error(), tc.err) {
+					t.Fatalf("Compile(%q) got %v, wanted error containing %q", tc.expr, iss.Err(), tc.err)
+				}
+				return
+			}
+			if iss.Err() != nil {
+				t.Fatalf("Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			if !ast.OutputType().IsExactType(tc.outType) {
+				t.Errorf("Compile(%q) got type %v, wanted %v", tc.expr, ast.OutputType(), tc.outType)
+			}
+		})
+	}
+}
+
 func TestSingletonBinaryBinding(t *testing.T) {
 	_, err := NewCustomEnv(
 		Function("right",
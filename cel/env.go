@@ -162,6 +162,14 @@ type Env struct {
 
 	// Program options tied to the environment
 	progOpts []ProgramOption
+
+	// Program cache configured via the ProgramCaching option
+	progCache         *ProgramCache
+	progCacheOpts     []ProgramOption
+	progCacheOptSetID uint64
+	progCacheKeyOnce  sync.Once
+	progCacheKey      string
+	progCacheKeyErr   error
 }
 
 // ToConfig produces a YAML-serializable env.Config object from the given environment.
@@ -184,6 +192,11 @@ func (e *Env) ToConfig(name string) (*env.Config, error) {
 		conf.AddImports(env.NewImport(typeName))
 	}
 
+	// Serialize schema-defined struct types
+	if reg, isReg := e.provider.(*types.Registry); isReg {
+		conf.AddSchemaTypes(reg.SchemaTypes()...)
+	}
+
 	// Serialize features
 	for featID, enabled := range e.features {
 		featName, found := featureNameByID(featID)
@@ -404,7 +417,7 @@ func (e *Env) Check(ast *Ast) (*Ast, *Issues) {
 	}
 
 	checked, errs := checker.Check(ast.NativeRep(), ast.Source(), chk)
-	if len(errs.GetErrors()) > 0 {
+	if errs.HasErrors() {
 		return nil, NewIssuesWithSourceInfo(errs, ast.NativeRep().SourceInfo())
 	}
 	// Manually create the Ast to ensure that the Ast source information (which may be more
@@ -415,6 +428,10 @@ func (e *Env) Check(ast *Ast) (*Ast, *Issues) {
 
 	// Avoid creating a validator config if it's not needed.
 	if len(e.validators) == 0 {
+		// Surface any warnings reported by the checker alongside the checked Ast.
+		if len(errs.GetErrors()) > 0 {
+			return ast, NewIssuesWithSourceInfo(errs, ast.NativeRep().SourceInfo())
+		}
 		return ast, nil
 	}
 
@@ -433,6 +450,10 @@ func (e *Env) Check(ast *Ast) (*Ast, *Issues) {
 	if iss.Err() != nil {
 		return nil, iss
 	}
+	// Surface any warnings or notices reported by the validators alongside the checked Ast.
+	if len(iss.Errors()) > 0 {
+		return ast, iss
+	}
 	return ast, nil
 }
 
@@ -588,6 +609,10 @@ func (e *Env) Extend(opts ...EnvOption) (*Env, error) {
 		chkOpts:         chkOptsCopy,
 		prsrOpts:        prsrOptsCopy,
 		costOptions:     costOptsCopy,
+
+		progCache:         e.progCache,
+		progCacheOpts:     e.progCacheOpts,
+		progCacheOptSetID: e.progCacheOptSetID,
 	}
 	return ext.configure(opts)
 }
@@ -775,6 +800,11 @@ func (e *Env) PartialVars(vars any) (PartialActivation, error) {
 // interpreter.AttributePattern and the resulting ResidualAst would be reduced to only the parts
 // of the expression that reference the 'request'.
 //
+// When the program is evaluated with OptUnrollComprehensions, `all` and `exists` comprehensions
+// over known ranges are unrolled so that the residual only contains the loop bodies
+// of the iterations whose result is still unknown, e.g. `["a", "b"].exists(x, x == y)` becomes
+// `"a" == y || "b" == y`.
+//
 // Note, the expression ids within the residual AST generated through this method have no
 // correlation to the expression ids of the original AST.
 //
@@ -896,6 +926,8 @@ func (e *Env) initChecker() (*checker.Env, error) {
 				e.HasFeature(featureCrossTypeNumericComparisons)))
 		chkOpts = append(chkOpts,
 			checker.JSONFieldNames(e.HasFeature(featureJSONFieldNames)))
+		chkOpts = append(chkOpts,
+			checker.TypeNarrowing(e.HasFeature(featureTypeNarrowing)))
 
 		ce, err := checker.NewEnv(e.Container, e.provider, chkOpts...)
 		if err != nil {
@@ -957,9 +989,24 @@ func (e *Env) computeUnknownVars(vars Activation) []*interpreter.AttributePatter
 // Error type which references an expression id, a location within source, and a message.
 type Error = common.Error
 
+// Severity indicates the significance of an issue, see common.Severity.
+type Severity = common.Severity
+
+const (
+	// SeverityError indicates an issue which prevents the expression from being used.
+	SeverityError = common.SeverityError
+
+	// SeverityWarning indicates a likely problem which does not prevent the expression from being used.
+	SeverityWarning = common.SeverityWarning
+
+	// SeverityInfo indicates an informational notice, such as a style suggestion.
+	SeverityInfo = common.SeverityInfo
+)
+
 // Issues defines methods for inspecting the error details of parse and check calls.
 //
-// Note: in the future, non-fatal warnings and notices may be inspectable via the Issues struct.
+// Issues may contain non-fatal warnings and notices, such as those reported by ASTValidator
+// instances. Only issues with SeverityError are reported through the Err method.
 type Issues struct {
 	errs *common.Errors
 	info *celast.SourceInfo
@@ -991,17 +1038,22 @@ func NewIssuesWithSourceInfo(errs *common.Errors, info *celast.SourceInfo) *Issu
 }
 
 // Err returns an error value if the issues list contains one or more errors.
+//
+// Warnings and notices do not produce an error, though they are included in the error message
+// when reported alongside one or more errors.
 func (i *Issues) Err() error {
 	if i == nil {
 		return nil
 	}
-	if len(i.Errors()) > 0 {
+	if i.errs.HasErrors() {
 		return errors.New(i.String())
 	}
 	return nil
 }
 
-// Errors returns the collection of errors encountered in more granular detail.
+// Errors returns the collection of issues of all severities encountered in more granular detail.
+//
+// Use the Error.Severity field to distinguish errors from warnings and notices.
 func (i *Issues) Errors() []*Error {
 	if i == nil {
 		return []*Error{}
@@ -1028,6 +1080,39 @@ func (i *Issues) String() string {
 	return i.errs.ToDisplayString()
 }
 
+// Diagnostics returns the issues as serializable diagnostics which include the source range of the
+// expression associated with each issue, when known.
+func (i *Issues) Diagnostics() []*common.Diagnostic {
+	if i == nil {
+		return []*common.Diagnostic{}
+	}
+	return i.errs.Diagnostics(i.stopLocation)
+}
+
+// ToJSON encodes the issues as a JSON document. See common.Diagnostic for the schema.
+func (i *Issues) ToJSON() ([]byte, error) {
+	return common.MarshalDiagnosticsJSON(i.Diagnostics())
+}
+
+// ToSARIF encodes the issues as a SARIF 2.1.0 log attributed to the given tool.
+func (i *Issues) ToSARIF(tool common.SARIFTool) ([]byte, error) {
+	return common.MarshalDiagnosticsSARIF(tool, i.Diagnostics())
+}
+
+// stopLocation returns the exclusive end location of the expression associated with the error,
+// provided the error was reported at the start of the expression.
+func (i *Issues) stopLocation(err *Error) common.Location {
+	o, found := i.info.GetOffsetRange(err.ExprID)
+	if !found || o.Stop <= o.Start || err.Location == nil {
+		return nil
+	}
+	start := i.info.GetLocationByOffset(o.Start)
+	if start.Line() != err.Location.Line() || start.Column() != err.Location.Column() {
+		return nil
+	}
+	return i.info.GetLocationByOffset(o.Stop)
+}
+
 // ReportErrorAtID reports an error message with an optional set of formatting arguments.
 //
 // The source metadata for the expression at `id`, if present, is attached to the error report.
@@ -1036,6 +1121,20 @@ func (i *Issues) ReportErrorAtID(id int64, message string, args ...any) {
 	i.errs.ReportErrorAtID(id, i.info.GetStartLocation(id), message, args...)
 }
 
+// ReportWarningAtID reports a warning message with an optional set of formatting arguments.
+//
+// Warnings do not cause the Issues.Err method to return an error.
+func (i *Issues) ReportWarningAtID(id int64, message string, args ...any) {
+	i.errs.ReportWarningAtID(id, i.info.GetStartLocation(id), message, args...)
+}
+
+// ReportInfoAtID reports an informational message with an optional set of formatting arguments.
+//
+// Informational messages do not cause the Issues.Err method to return an error.
+func (i *Issues) ReportInfoAtID(id int64, message string, args ...any) {
+	i.errs.ReportInfoAtID(id, i.info.GetStartLocation(id), message, args...)
+}
+
 // getStdEnv lazy initializes the CEL standard environment.
 func getStdEnv() (*Env, error) {
 	stdEnvInit.Do(func() {
@@ -30,6 +30,7 @@ import (
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
 
 	"google.golang.org/protobuf/proto"
 
@@ -140,6 +141,44 @@ ERROR: <input>:1:2: Syntax error: mismatched input '<EOF>' expecting {'[', '{',
 	}
 }
 
+func TestIssuesDiagnostics(t *testing.T) {
+	e, err := NewEnv(Variable("x", IntType))
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	_, iss := e.CompileSource(common.NewStringSource("x +\n  size('a') + missing", "diag.cel"))
+	diags := iss.Diagnostics()
+	want := []*common.Diagnostic{{
+		Source:   "diag.cel",
+		Severity: "ERROR",
+		Code:     common.ErrorCodeUndeclaredReference,
+		Message:  "undeclared reference to 'missing' (in container '')",
+		ExprID:   6,
+		Start:    &common.Position{Line: 2, Column: 15, Offset: 18},
+		End:      &common.Position{Line: 2, Column: 22, Offset: 25},
+		Snippet:  "  size('a') + missing",
+		Details:  map[string]any{"name": "missing", "container": ""},
+	}}
+	if !reflect.DeepEqual(diags, want) {
+		t.Errorf("iss.Diagnostics() got %v, wanted %v", diags, want)
+	}
+	if _, err := iss.ToJSON(); err != nil {
+		t.Errorf("iss.ToJSON() failed: %v", err)
+	}
+	if _, err := iss.ToSARIF(common.SARIFTool{}); err != nil {
+		t.Errorf("iss.ToSARIF() failed: %v", err)
+	}
+
+	var nilIss *Issues
+	out, err := nilIss.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() on nil issues failed: %v", err)
+	}
+	if !strings.Contains(string(out), `"diagnostics": []`) {
+		t.Errorf("ToJSON() on nil issues got %s, wanted empty diagnostics", out)
+	}
+}
+
 func TestFormatCELTypeEquivalence(t *testing.T) {
 	values := []*Type{
 		AnyType,
@@ -370,6 +409,28 @@ func TestEnvToConfig(t *testing.T) {
 						[]*env.TypeDesc{env.NewTypeDesc("string")}, env.NewTypeDesc("string")),
 				)),
 		},
+		{
+			name: "std env - with constrained type params",
+			opts: []EnvOption{Function("sum",
+				Overload("sum_list",
+					[]*Type{ListType(TypeParamTypeWithBounds("T", IntType, DoubleType))},
+					TypeParamTypeWithBounds("T", IntType, DoubleType)),
+				Overload("sum_map",
+					[]*Type{MapType(StringType, TypeParamTypeWithTraits("V", traits.AdderType))},
+					TypeParamTypeWithTraits("V", traits.AdderType)),
+			)},
+			want: env.NewConfig("std env - with constrained type params").AddFunctions(
+				env.NewFunction("sum",
+					env.NewOverload("sum_list",
+						[]*env.TypeDesc{env.NewTypeDesc("list",
+							env.NewBoundedTypeParam("T", env.NewTypeDesc("int"), env.NewTypeDesc("double")))},
+						env.NewBoundedTypeParam("T", env.NewTypeDesc("int"), env.NewTypeDesc("double"))),
+					env.NewOverload("sum_map",
+						[]*env.TypeDesc{env.NewTypeDesc("map",
+							env.NewTypeDesc("string"), env.NewTraitTypeParam("V", "adder"))},
+						env.NewTraitTypeParam("V", "adder")),
+				)),
+		},
 		{
 			name: "optional lib",
 			opts: []EnvOption{
@@ -434,6 +495,21 @@ func TestEnvToConfig(t *testing.T) {
 				env.NewFeature("cel.feature.macro_call_tracking", true),
 			),
 		},
+		{
+			name: "struct types",
+			opts: []EnvOption{
+				SchemaTypes(mustSchemaType(t, "example.Node",
+					&types.SchemaField{Name: "value", Type: IntType, Description: "node value"},
+					types.NewSchemaField("next", ObjectType("example.Node")),
+				)),
+			},
+			want: env.NewConfig("struct types").AddStructTypes(
+				env.NewStructType("example.Node",
+					env.NewStructFieldWithDoc("value", env.NewTypeDesc("int"), "node value"),
+					env.NewStructField("next", env.NewTypeDesc("example.Node")),
+				),
+			),
+		},
 		{
 			name: "validators",
 			opts: []EnvOption{
@@ -513,6 +589,45 @@ func TestEnvFromConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "std env - struct types",
+			conf: env.NewConfig("std env - struct types").
+				SetContainer("example").
+				AddStructTypes(
+					env.NewStructType("example.Node",
+						env.NewStructField("value", env.NewTypeDesc("int")),
+						env.NewStructField("next", env.NewTypeDesc("example.Node")),
+					),
+					env.NewStructType("example.Tree",
+						env.NewStructField("name", env.NewTypeDesc("string")),
+						env.NewStructField("nodes", env.NewTypeDesc("list", env.NewTypeDesc("example.Node"))),
+					),
+				).
+				AddVariables(env.NewVariable("tree", env.NewTypeDesc("example.Tree"))),
+			exprs: []exprCase{
+				{
+					name: "struct literal",
+					expr: "Node{value: 1, next: Node{value: 2}}.next.value",
+					out:  types.Int(2),
+				},
+				{
+					name: "map input",
+					in: map[string]any{
+						"tree": map[string]any{
+							"name":  "t",
+							"nodes": []any{map[string]any{"value": 1}, map[string]any{"next": map[string]any{"value": 3}}},
+						},
+					},
+					expr: "tree.nodes.map(n, n.value + n.next.value) == [1, 3] && !has(tree.nodes[0].next)",
+					out:  types.True,
+				},
+				{
+					name: "undefined field",
+					expr: "Node{label: 'a'}",
+					iss:  errors.New("undefined field 'label'"),
+				},
+			},
+		},
 		{
 			name:       "std env - context proto",
 			beforeOpts: []EnvOption{Types(&proto3pb.TestAllTypes{})},
@@ -989,6 +1104,12 @@ func TestEnvFromConfigErrors(t *testing.T) {
 			conf: env.NewConfig("invalid context proto").SetContextVariable(env.NewContextVariable("invalid")),
 			want: errors.New("invalid context proto type"),
 		},
+		{
+			name: "undefined struct field type",
+			conf: env.NewConfig("undefined struct field type").AddStructTypes(
+				env.NewStructType("example.Node", env.NewStructField("value", env.NewTypeDesc("undefined")))),
+			want: errors.New(`invalid struct type "example.Node": field "value"`),
+		},
 		{
 			name: "undefined variable type",
 			conf: env.NewConfig("undefined variable type").AddVariables(env.NewVariable("undef", env.NewTypeDesc("undefined"))),
@@ -1209,6 +1330,15 @@ func mustContextProto(t *testing.T, pb proto.Message, opts ...types.RegistryOpti
 	return ctx
 }
 
+func mustSchemaType(t *testing.T, name string, fields ...*types.SchemaField) *types.SchemaType {
+	t.Helper()
+	st, err := types.NewSchemaType(name, fields...)
+	if err != nil {
+		t.Fatalf("types.NewSchemaType() failed: %v", err)
+	}
+	return st
+}
+
 type returnTypeValidator struct {
 	returnType *Type
 }
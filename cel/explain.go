@@ -0,0 +1,243 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+)
+
+// Explanation describes the sub-expressions which determined the result of a boolean expression.
+type Explanation struct {
+	// Result is the boolean result of the expression.
+	Result ref.Val
+
+	// Reasons contains the smallest set of sub-expressions which determined the result.
+	Reasons []*ExplainedExpr
+
+	source common.Source
+}
+
+// ExplainedExpr describes a sub-expression and the value observed for it during evaluation.
+type ExplainedExpr struct {
+	// ExprID is the id of the sub-expression.
+	ExprID int64
+
+	// Snippet is the text of the sub-expression.
+	Snippet string
+
+	// Location is the start of the sub-expression within the source, or common.NoLocation if
+	// unknown.
+	Location common.Location
+
+	// Value is the value observed for the sub-expression.
+	Value ref.Val
+
+	// Operands contains the values observed for the non-constant operands of the sub-expression.
+	Operands []*ExplainedExpr
+}
+
+// String renders each reason on its own line, for example:
+//
+//	<input>:1:1: `request.auth.claims.group == "admin"` was false because `request.auth.claims.group` was "dev"
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	for i, r := range e.Reasons {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if e.source != nil && r.Location.Line() > 0 {
+			fmt.Fprintf(&sb, "%s:%d:%d: ", e.source.Description(), r.Location.Line(), r.Location.Column()+1)
+		}
+		sb.WriteString(r.String())
+	}
+	return sb.String()
+}
+
+// String renders the sub-expression, its value, and the values of its operands.
+func (e *ExplainedExpr) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s` was %s", e.Snippet, types.Format(e.Value))
+	for i, op := range e.Operands {
+		if i == 0 {
+			sb.WriteString(" because ")
+		} else {
+			sb.WriteString(" and ")
+		}
+		fmt.Fprintf(&sb, "`%s` was %s", op.Snippet, types.Format(op.Value))
+	}
+	return sb.String()
+}
+
+// ExplainBool explains the boolean result of an evaluation in terms of the smallest set of
+// sub-expressions which determined it, following the short-circuiting behavior of the `&&`, `||`,
+// and `?:` operators.
+//
+// The details must come from evaluating a program created from the Ast with either the
+// OptTrackState or OptExhaustiveEval option. When evaluated exhaustively, the explanation reflects
+// the first term which determined the result of each logical operator.
+func ExplainBool(a *Ast, details *EvalDetails) (*Explanation, error) {
+	if details == nil || details.State() == nil {
+		return nil, errors.New("explanation requires evaluation state, use OptTrackState")
+	}
+	native := a.NativeRep()
+	info := native.SourceInfo()
+	state := details.State()
+	result, found := state.Value(native.Expr().ID())
+	if !found {
+		return nil, errors.New("no evaluation state recorded for the expression")
+	}
+	if _, isBool := result.(types.Bool); !isBool {
+		return nil, fmt.Errorf("expression result is not a bool: %s", types.Format(result))
+	}
+	dets := interpreter.ExplainBool(native.Expr(), state)
+	exp := &Explanation{
+		Result:  result,
+		Reasons: make([]*ExplainedExpr, 0, len(dets)),
+		source:  a.Source(),
+	}
+	for _, det := range dets {
+		reason := newExplainedExpr(det.ObservedExpr, info, a.Source())
+		for _, op := range det.Operands {
+			reason.Operands = append(reason.Operands, newExplainedExpr(op, info, a.Source()))
+		}
+		exp.Reasons = append(exp.Reasons, reason)
+	}
+	return exp, nil
+}
+
+func newExplainedExpr(obs interpreter.ObservedExpr, info *ast.SourceInfo, src common.Source) *ExplainedExpr {
+	snippet, err := exprSnippet(obs.Expr, info)
+	if err != nil {
+		// Expressions which cannot be unparsed, such as comprehensions other than all() and
+		// exists() without macro call tracking, are described by their source text.
+		snippet = sourceSnippet(obs.Expr, info, src)
+	}
+	return &ExplainedExpr{
+		ExprID:   obs.Expr.ID(),
+		Snippet:  snippet,
+		Location: exprStartLocation(obs.Expr, info),
+		Value:    obs.Value,
+	}
+}
+
+// exprSnippet renders the expression as text.
+//
+// Comprehensions can only be unparsed when macro call tracking is enabled, so the all() and
+// exists() macros are otherwise reconstructed from the structure of the comprehension.
+func exprSnippet(e ast.Expr, info *ast.SourceInfo) (string, error) {
+	if e.Kind() != ast.ComprehensionKind {
+		return ExprToString(e, info)
+	}
+	if _, found := info.GetMacroCall(e.ID()); found {
+		return ExprToString(e, info)
+	}
+	pred, deciding, found := interpreter.QuantifierPredicate(e)
+	if !found {
+		return "", fmt.Errorf("unsupported comprehension, enable macro call tracking: expr id %d", e.ID())
+	}
+	comp := e.AsComprehension()
+	rng, err := exprSnippet(comp.IterRange(), info)
+	if err != nil {
+		return "", err
+	}
+	body, err := exprSnippet(pred, info)
+	if err != nil {
+		return "", err
+	}
+	macro := "all"
+	if deciding == types.True {
+		macro = "exists"
+	}
+	return fmt.Sprintf("%s.%s(%s, %s)", rng, macro, comp.IterVar(), body), nil
+}
+
+// sourceSnippet returns the source text spanned by the expression, or a placeholder naming the
+// expression id if the source text is unknown.
+//
+// The offset ranges of calls only span the function name, so the brackets left open by the
+// spanned text are closed using the brackets which follow it in the source.
+func sourceSnippet(e ast.Expr, info *ast.SourceInfo, src common.Source) string {
+	start, stop := int32(-1), int32(-1)
+	ast.PostOrderVisit(e, ast.NewExprVisitor(func(sub ast.Expr) {
+		if o, found := info.GetOffsetRange(sub.ID()); found && o.Stop > o.Start {
+			if start < 0 || o.Start < start {
+				start = o.Start
+			}
+			if o.Stop > stop {
+				stop = o.Stop
+			}
+		}
+	}))
+	if src == nil || start < 0 {
+		return fmt.Sprintf("<expr %d>", e.ID())
+	}
+	text := []rune(src.Content())
+	if int(stop) > len(text) {
+		return fmt.Sprintf("<expr %d>", e.ID())
+	}
+	var open []rune
+	for _, r := range text[start:stop] {
+		switch r {
+		case '(':
+			open = append(open, ')')
+		case '[':
+			open = append(open, ']')
+		case '{':
+			open = append(open, '}')
+		case ')', ']', '}':
+			if len(open) != 0 && open[len(open)-1] == r {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	end := int(stop)
+	for i := end; i < len(text) && len(open) != 0; i++ {
+		r := text[i]
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			continue
+		}
+		if r != open[len(open)-1] {
+			break
+		}
+		open = open[:len(open)-1]
+		end = i + 1
+	}
+	return string(text[start:end])
+}
+
+// exprStartLocation returns the location of the left-most character of the expression, as the
+// offset ranges of operators only span the operator token.
+func exprStartLocation(e ast.Expr, info *ast.SourceInfo) common.Location {
+	start := int32(-1)
+	ast.PostOrderVisit(e, ast.NewExprVisitor(func(sub ast.Expr) {
+		if o, found := info.GetOffsetRange(sub.ID()); found && o.Stop > o.Start {
+			if start < 0 || o.Start < start {
+				start = o.Start
+			}
+		}
+	}))
+	if start < 0 {
+		return common.NoLocation
+	}
+	return info.GetLocationByOffset(start)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+)
+
+func TestExplainBool(t *testing.T) {
+	tests := []struct {
+		expr string
+		opts []EnvOption
+		out  types.Bool
+		want string
+	}{
+		{
+			expr: `request.auth.claims.group == 'admin'`,
+			out:  types.False,
+			want: "<input>:1:1: `request.auth.claims.group == \"admin\"` was false because `request.auth.claims.group` was \"dev\"",
+		},
+		{
+			expr: `xs.all(y, y > 0) || (m.a.b == 'dev' && !has(m.c))`,
+			out:  types.False,
+			want: "<input>:1:11: `y > 0` was false because `y` was -1\n" +
+				"<input>:1:45: `has(m.c)` was true",
+		},
+		{
+			expr: `xs.all(y, y > -5) && m.a.b == 'dev'`,
+			out:  types.True,
+			want: "<input>:1:1: `xs.all(y, y > -5)` was true because `xs` was [3, -1, 4]\n" +
+				"<input>:1:22: `m.a.b == \"dev\"` was true because `m.a.b` was \"dev\"",
+		},
+		{
+			expr: `xs.filter(y, y > 0).size() == 3 || xs.map(y, y * 2)[0] > 10`,
+			out:  types.False,
+			want: "<input>:1:1: `xs.filter(y, y > 0).size() == 3` was false because `xs.filter(y, y > 0).size()` was 2\n" +
+				"<input>:1:36: `xs.map(y, y * 2)[0] > 10` was false because `xs.map(y, y * 2)[0]` was 6",
+		},
+		{
+			expr: `xs.exists_one(y, y < 0)`,
+			out:  types.True,
+			want: "<input>:1:1: `xs.exists_one(y, y < 0)` was true because `xs` was [3, -1, 4]",
+		},
+		{
+			expr: `xs.exists(y, y > 5) && m.k`,
+			opts: []EnvOption{EnableMacroCallTracking()},
+			out:  types.False,
+			want: "<input>:1:1: `xs.exists(y, y > 5)` was false because `xs` was [3, -1, 4]",
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			opts := append([]EnvOption{
+				Variable("request", DynType),
+				Variable("xs", ListType(IntType)),
+				Variable("m", MapType(StringType, DynType)),
+			}, tc.opts...)
+			env := testEnv(t, opts...)
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", tc.expr, iss.Err())
+			}
+			prg, err := env.Program(ast, EvalOptions(OptTrackState))
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, det, err := prg.Eval(map[string]any{
+				"request": map[string]any{"auth": map[string]any{"claims": map[string]any{"group": "dev"}}},
+				"xs":      []int{3, -1, 4},
+				"m":       map[string]any{"a": map[string]any{"b": "dev"}, "c": 1, "k": true},
+			})
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if out != tc.out {
+				t.Fatalf("prg.Eval() got %v, wanted %v", out, tc.out)
+			}
+			exp, err := ExplainBool(ast, det)
+			if err != nil {
+				t.Fatalf("ExplainBool() failed: %v", err)
+			}
+			if exp.Result != tc.out {
+				t.Errorf("ExplainBool() got result %v, wanted %v", exp.Result, tc.out)
+			}
+			if exp.String() != tc.want {
+				t.Errorf("ExplainBool() got:\n%s\nwanted:\n%s", exp, tc.want)
+			}
+		})
+	}
+}
+
+func TestExplainBoolErrors(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	ast, iss := env.Compile(`x + 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, _ := prg.Eval(map[string]any{"x": 1})
+	if _, err := ExplainBool(ast, det); err == nil || !strings.Contains(err.Error(), "OptTrackState") {
+		t.Errorf("ExplainBool() got %v, wanted error requiring evaluation state", err)
+	}
+	prg, err = env.Program(ast, EvalOptions(OptTrackState))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, _ = prg.Eval(map[string]any{"x": 1})
+	if _, err := ExplainBool(ast, det); err == nil || !strings.Contains(err.Error(), "not a bool") {
+		t.Errorf("ExplainBool() got %v, wanted error for non-bool result", err)
+	}
+}
@@ -0,0 +1,541 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// CompileSet compiles a named set of expressions into an ExprSet whose program evaluates each
+// expression and reports the result of each one by name.
+//
+// Each expression is compiled using the name as the source description. If any expression fails
+// to compile, the issues for the first failing expression in name order are returned.
+//
+// See ComposeSet for details on how the expressions are combined.
+func (e *Env) CompileSet(exprs map[string]string) (*ExprSet, *Issues) {
+	asts := make(map[string]*Ast, len(exprs))
+	for _, name := range sortedNames(exprs) {
+		src, err := common.NewStringSourceWithLimit(exprs[name], name, e.configuredExpressionSizeLimit())
+		if err != nil {
+			return nil, ErrorAsIssues(err)
+		}
+		a, iss := e.CompileSource(src)
+		if iss.Err() != nil {
+			return nil, iss
+		}
+		asts[name] = a
+	}
+	return e.ComposeSet(asts)
+}
+
+// ComposeSet combines a named set of type-checked Asts into an ExprSet whose Ast evaluates all of
+// the expressions at once, e.g. `{"a": <expr a>, "b": <expr b>}`.
+//
+// Sub-expressions which occur more than once within the set, such as a field selection chain or
+// a function call over the same variables, are hoisted into cel.bind() variables so that they are
+// evaluated at most once per activation. Bound sub-expressions are evaluated lazily, so the
+// short-circuiting behavior of each expression is preserved.
+//
+// The result of each expression is captured separately, so an expression which evaluates to an
+// error or unknown does not affect the results of the other expressions in the set.
+func (e *Env) ComposeSet(asts map[string]*Ast) (*ExprSet, *Issues) {
+	src := common.NewTextSource("")
+	sources := make(map[string]Source, len(asts))
+	for _, name := range sortedNames(asts) {
+		if !asts[name].IsChecked() {
+			errs := common.NewErrors(src)
+			errs.ReportErrorAtID(0, common.NoLocation, "expression %q has not been type-checked", name)
+			return nil, NewIssues(errs)
+		}
+		sources[name] = asts[name].Source()
+	}
+	setEnv, err := e.Extend(
+		Function(exprSetCaptureFunction,
+			Overload("cel_@capture", []*Type{TypeParamType("T")}, DynType,
+				OverloadIsNonStrict(),
+				UnaryBinding(func(val ref.Val) ref.Val {
+					return exprSetValue{Val: val}
+				}))))
+	if err != nil {
+		return nil, ErrorAsIssues(err)
+	}
+	seed := &Ast{
+		source: src,
+		impl:   ast.NewAST(ast.NewExprFactory().NewMap(1, []ast.EntryExpr{}), ast.NewSourceInfo(src)),
+	}
+	opt, err := NewStaticOptimizer(&exprSetComposer{asts: asts}, &commonSubexprOptimizer{})
+	if err != nil {
+		return nil, ErrorAsIssues(err)
+	}
+	composed, iss := opt.Optimize(setEnv, seed)
+	if iss.Err() != nil {
+		return nil, iss
+	}
+	set := &ExprSet{
+		env:     setEnv,
+		ast:     composed,
+		sources: sources,
+		entries: map[int64]string{},
+		defs:    map[int64]string{},
+		refs:    map[string][]int64{},
+	}
+	set.indexExprs()
+	return set, nil
+}
+
+// exprSetCaptureFunction is the name of the internal function which captures the result of each
+// expression in a composed set, including errors and unknowns.
+const exprSetCaptureFunction = "cel.@capture"
+
+// ExprSet is a named set of expressions composed into a single Ast.
+type ExprSet struct {
+	env     *Env
+	ast     *Ast
+	sources map[string]Source
+
+	// entries maps the ids of the sub-expressions of each expression to the expression name.
+	entries map[int64]string
+	// defs maps the ids of the hoisted sub-expressions to the name of their cel.bind() variable.
+	defs map[int64]string
+	// refs contains the ids of the references to each cel.bind() variable.
+	refs map[string][]int64
+}
+
+// Ast returns the composed Ast of the set.
+//
+// The Ast refers to an internal function, and may only be evaluated using ExprSet.Program.
+func (s *ExprSet) Ast() *Ast {
+	return s.ast
+}
+
+// Program generates an evaluable instance of the composed set.
+func (s *ExprSet) Program(opts ...ProgramOption) (*ExprSetProgram, error) {
+	prg, err := s.env.Program(s.ast, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ExprSetProgram{prg: prg, set: s}, nil
+}
+
+// ExprSetResult is the outcome of a single expression within an ExprSet.
+type ExprSetResult struct {
+	// Value is the result of the expression, which may be an error or unknown.
+	Value ref.Val
+
+	// Err is the evaluation error of the expression, if any, prefixed with the location of the
+	// failing sub-expression within the source of the expression.
+	Err error
+}
+
+// ExprSetProgram evaluates all of the expressions of an ExprSet at once.
+type ExprSetProgram struct {
+	prg Program
+	set *ExprSet
+}
+
+// Eval evaluates the expressions of the set against the input and returns the result of each one
+// by name.
+//
+// The error is only non-nil if the evaluation of the set as a whole failed, such as when the
+// evaluation is cancelled or the input is invalid.
+func (p *ExprSetProgram) Eval(input any) (map[string]ExprSetResult, *EvalDetails, error) {
+	out, det, err := p.prg.Eval(input)
+	return p.results(out, det, err)
+}
+
+// ContextEval evaluates the expressions of the set against the input with support for
+// cancellation, and returns the result of each one by name.
+func (p *ExprSetProgram) ContextEval(ctx context.Context, input any) (map[string]ExprSetResult, *EvalDetails, error) {
+	out, det, err := p.prg.ContextEval(ctx, input)
+	return p.results(out, det, err)
+}
+
+func (p *ExprSetProgram) results(out ref.Val, det *EvalDetails, err error) (map[string]ExprSetResult, *EvalDetails, error) {
+	if err != nil {
+		return nil, det, err
+	}
+	captured, ok := out.(traits.Mapper)
+	if !ok {
+		return nil, det, fmt.Errorf("invalid expression set result: %v", out)
+	}
+	results := make(map[string]ExprSetResult, len(p.set.sources))
+	for name := range p.set.sources {
+		val, found := captured.Find(types.String(name))
+		if !found {
+			return nil, det, fmt.Errorf("no result for expression %q", name)
+		}
+		result := ExprSetResult{Value: val}
+		if v, ok := val.(exprSetValue); ok {
+			result.Value = v.Val
+		}
+		if e, ok := result.Value.(*types.Err); ok {
+			result.Err = p.set.locateErr(name, e)
+		}
+		results[name] = result
+	}
+	return results, det, nil
+}
+
+// locateErr prefixes the error with the location of the sub-expression which produced it within
+// the source of the named expression.
+func (s *ExprSet) locateErr(name string, err *types.Err) error {
+	id, found := s.exprNode(name, err.NodeID())
+	if !found {
+		return err
+	}
+	offset, found := s.ast.NativeRep().SourceInfo().GetOffsetRange(id)
+	if !found {
+		return err
+	}
+	src := s.sources[name]
+	loc, found := src.OffsetLocation(offset.Start)
+	if !found {
+		return err
+	}
+	return fmt.Errorf("%s:%d:%d: %w", src.Description(), loc.Line(), loc.Column()+1, err)
+}
+
+// exprNode returns the id of the sub-expression of the named expression which corresponds to the
+// given id.
+//
+// The metadata of a sub-expression hoisted into a cel.bind() variable refers to the source of the
+// expression it was copied from, so within other expressions the hoisted sub-expression
+// corresponds to the reference to its variable.
+func (s *ExprSet) exprNode(name string, id int64) (int64, bool) {
+	if owner, found := s.entries[id]; found {
+		return id, owner == name
+	}
+	varName, found := s.defs[id]
+	if !found {
+		return 0, false
+	}
+	if s.origin(varName) == name {
+		return id, true
+	}
+	for _, ref := range s.refs[varName] {
+		if refID, found := s.exprNode(name, ref); found {
+			return refID, true
+		}
+	}
+	return 0, false
+}
+
+// origin returns the name of the expression the definition of the cel.bind() variable was copied
+// from, which is the expression containing the first reference to the variable.
+func (s *ExprSet) origin(varName string) string {
+	refs := s.refs[varName]
+	if len(refs) == 0 {
+		return ""
+	}
+	if owner, found := s.entries[refs[0]]; found {
+		return owner
+	}
+	return s.origin(s.defs[refs[0]])
+}
+
+// indexExprs records the expression or cel.bind() variable each sub-expression of the composed
+// Ast belongs to, along with the references to each variable in the order in which the common
+// sub-expressions were found.
+func (s *ExprSet) indexExprs() {
+	root := s.ast.NativeRep().Expr()
+	index := func(e ast.Expr, owners map[int64]string, owner string) {
+		ast.PostOrderVisit(e, ast.NewExprVisitor(func(sub ast.Expr) {
+			owners[sub.ID()] = owner
+			if sub.Kind() == ast.IdentKind {
+				s.refs[sub.AsIdent()] = append(s.refs[sub.AsIdent()], sub.ID())
+			}
+		}))
+	}
+	for root.Kind() == ast.ComprehensionKind {
+		bind := root.AsComprehension()
+		index(bind.AccuInit(), s.defs, bind.AccuVar())
+		root = bind.Result()
+	}
+	if root.Kind() != ast.MapKind {
+		return
+	}
+	for _, entry := range root.AsMap().Entries() {
+		e := entry.AsMapEntry()
+		if name, ok := e.Key().AsLiteral().(types.String); ok {
+			index(e.Value(), s.entries, string(name))
+		}
+	}
+}
+
+// exprSetValue holds the captured result of an expression within the composed set so that errors
+// and unknowns are not propagated to the map literal of results.
+type exprSetValue struct {
+	ref.Val
+}
+
+// exprSetComposer replaces the root of the AST with a map literal of the captured results of the
+// named expressions.
+type exprSetComposer struct {
+	asts map[string]*Ast
+}
+
+func (opt *exprSetComposer) Optimize(ctx *OptimizerContext, a *ast.AST) *ast.AST {
+	names := sortedNames(opt.asts)
+	entries := make([]ast.EntryExpr, len(names))
+	for i, name := range names {
+		entries[i] = ctx.NewMapEntry(
+			ctx.NewLiteral(types.String(name)),
+			ctx.NewCall(exprSetCaptureFunction, ctx.CopyASTAndMetadata(opt.asts[name].NativeRep())),
+			false)
+	}
+	ctx.UpdateExpr(a.Expr(), ctx.NewMap(entries))
+	return a
+}
+
+// commonSubexprOptimizer hoists sub-expressions which occur more than once into cel.bind()
+// variables declared at the root of the AST.
+//
+// The largest repeated sub-expression is hoisted first, and the process repeats until no
+// sub-expression occurs more than once. Each new binding wraps the previous ones, so the
+// definitions of earlier bindings may refer to later ones.
+type commonSubexprOptimizer struct{}
+
+func (opt *commonSubexprOptimizer) Optimize(ctx *OptimizerContext, a *ast.AST) *ast.AST {
+	names := map[string]bool{}
+	ast.PostOrderVisit(a.Expr(), ast.NewExprVisitor(func(e ast.Expr) {
+		if e.Kind() == ast.ComprehensionKind {
+			names[e.AsComprehension().AccuVar()] = true
+		}
+	}))
+	varIndex := 0
+	for {
+		root := ast.NavigateAST(a)
+		matches := findCommonSubexpr(root)
+		if len(matches) < 2 {
+			return a
+		}
+		varName := fmt.Sprintf("@cse%d", varIndex)
+		for names[varName] {
+			varIndex++
+			varName = fmt.Sprintf("@cse%d", varIndex)
+		}
+		names[varName] = true
+		def := ctx.copyExpr(matches[0])
+		for _, match := range matches {
+			ctx.UpdateExpr(match, ctx.NewIdent(varName))
+		}
+		inlined, bindMacro := ctx.NewBindMacro(root.ID(), varName, def, root)
+		ctx.UpdateExpr(root, inlined)
+		ctx.SetMacroCall(root.ID(), bindMacro)
+	}
+}
+
+// findCommonSubexpr returns the occurrences of the largest sub-expression which occurs more than
+// once and may be evaluated independently of any enclosing comprehension.
+func findCommonSubexpr(root ast.NavigableExpr) []ast.NavigableExpr {
+	occurrences := map[string][]ast.NavigableExpr{}
+	sizes := map[string]int{}
+	var order []string
+	var visit func(e ast.NavigableExpr) subexprInfo
+	visit = func(e ast.NavigableExpr) subexprInfo {
+		children := e.Children()
+		infos := make([]subexprInfo, len(children))
+		for i, c := range children {
+			infos[i] = visit(c)
+		}
+		info := newSubexprInfo(e, infos)
+		if info.isCandidate(e) && !isShadowed(e, info.free) {
+			if _, found := occurrences[info.key]; !found {
+				order = append(order, info.key)
+			}
+			occurrences[info.key] = append(occurrences[info.key], e)
+			sizes[info.key] = info.size
+		}
+		return info
+	}
+	visit(root)
+	var best string
+	for _, key := range order {
+		if len(occurrences[key]) > 1 && sizes[key] > sizes[best] {
+			best = key
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return occurrences[best]
+}
+
+// subexprInfo describes the structure of a sub-expression.
+type subexprInfo struct {
+	// key is a canonical representation of the sub-expression, equal for structurally equal
+	// sub-expressions.
+	key string
+	// size is the number of nodes in the sub-expression.
+	size int
+	// free contains the identifiers referenced, but not declared, within the sub-expression.
+	free map[string]bool
+}
+
+func newSubexprInfo(e ast.NavigableExpr, children []subexprInfo) subexprInfo {
+	info := subexprInfo{size: 1, free: map[string]bool{}}
+	for _, c := range children {
+		info.size += c.size
+		for name := range c.free {
+			info.free[name] = true
+		}
+	}
+	var key strings.Builder
+	switch e.Kind() {
+	case ast.LiteralKind:
+		lit := e.AsLiteral()
+		fmt.Fprintf(&key, "%s(%s)", lit.Type().TypeName(), types.Format(lit))
+	case ast.IdentKind:
+		info.free[e.AsIdent()] = true
+		key.WriteString(e.AsIdent())
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		op := "."
+		if sel.IsTestOnly() {
+			op = ".has:"
+		}
+		fmt.Fprintf(&key, "(%s)%s%s", children[0].key, op, sel.FieldName())
+	case ast.CallKind:
+		call := e.AsCall()
+		fmt.Fprintf(&key, "%s[%t](", call.FunctionName(), call.IsMemberFunction())
+		writeChildKeys(&key, children)
+		key.WriteString(")")
+	case ast.ListKind:
+		fmt.Fprintf(&key, "list%v[", e.AsList().OptionalIndices())
+		writeChildKeys(&key, children)
+		key.WriteString("]")
+	case ast.MapKind:
+		key.WriteString("map{")
+		for _, entry := range e.AsMap().Entries() {
+			fmt.Fprintf(&key, "%t,", entry.AsMapEntry().IsOptional())
+		}
+		writeChildKeys(&key, children)
+		key.WriteString("}")
+	case ast.StructKind:
+		s := e.AsStruct()
+		fmt.Fprintf(&key, "%s{", s.TypeName())
+		for _, field := range s.Fields() {
+			fmt.Fprintf(&key, "%s:%t,", field.AsStructField().Name(), field.AsStructField().IsOptional())
+		}
+		writeChildKeys(&key, children)
+		key.WriteString("}")
+	case ast.ComprehensionKind:
+		comp := e.AsComprehension()
+		fmt.Fprintf(&key, "fold(%s,%s,%s;", comp.IterVar(), comp.IterVar2(), comp.AccuVar())
+		writeChildKeys(&key, children)
+		key.WriteString(")")
+		// Variables declared by the comprehension are not free. The iteration range and the
+		// accumulator initializer are outside the scope of the comprehension variables, and the
+		// result is only within the scope of the accumulator.
+		info.free = map[string]bool{}
+		for i, c := range children {
+			for name := range c.free {
+				isAccu := name == comp.AccuVar()
+				isIter := name == comp.IterVar() || name == comp.IterVar2()
+				if (i == 2 || i == 3) && (isAccu || isIter) || i == 4 && isAccu {
+					continue
+				}
+				info.free[name] = true
+			}
+		}
+	}
+	info.key = key.String()
+	return info
+}
+
+func writeChildKeys(key *strings.Builder, children []subexprInfo) {
+	for i, c := range children {
+		if i > 0 {
+			key.WriteString(",")
+		}
+		key.WriteString(c.key)
+	}
+}
+
+// isCandidate indicates whether the sub-expression is worth hoisting into a variable. Calls,
+// comprehensions, and selection chains which reference at least one variable are candidates.
+func (info subexprInfo) isCandidate(e ast.NavigableExpr) bool {
+	if len(info.free) == 0 {
+		return false
+	}
+	switch e.Kind() {
+	case ast.CallKind:
+		return e.AsCall().FunctionName() != exprSetCaptureFunction
+	case ast.ComprehensionKind:
+		return true
+	case ast.SelectKind:
+		return info.size > 2
+	}
+	return false
+}
+
+// isShadowed indicates whether any of the free identifiers in the sub-expression are declared by
+// an enclosing comprehension, in which case the sub-expression cannot be hoisted.
+func isShadowed(e ast.NavigableExpr, free map[string]bool) bool {
+	p, hasParent := e.Parent()
+	for hasParent {
+		if p.Kind() == ast.ComprehensionKind {
+			comp := p.AsComprehension()
+			if free[comp.IterVar()] || free[comp.IterVar2()] || free[comp.AccuVar()] {
+				return true
+			}
+		}
+		p, hasParent = p.Parent()
+	}
+	return false
+}
+
+// copyExpr creates a copy of the expression with fresh ids, propagating the macro and offset
+// metadata of the original sub-expressions.
+func (opt *optimizerExprFactory) copyExpr(e ast.Expr) ast.Expr {
+	idGen := newIDGenerator(opt.nextID())
+	defer func() { opt.seed = idGen.nextID() }()
+	var ids []int64
+	ast.PostOrderVisit(e, ast.NewExprVisitor(func(sub ast.Expr) {
+		ids = append(ids, sub.ID())
+	}))
+	copyExpr := opt.fac.CopyExpr(e)
+	copyExpr.RenumberIDs(idGen.renumberStable)
+	for _, id := range ids {
+		if call, found := opt.sourceInfo.GetMacroCall(id); found {
+			copyCall := opt.fac.CopyExpr(call)
+			copyCall.RenumberIDs(idGen.renumberStable)
+			opt.SetMacroCall(idGen.renumberStable(id), copyCall)
+		}
+		if offset, found := opt.sourceInfo.GetOffsetRange(id); found {
+			opt.sourceInfo.SetOffsetRange(idGen.renumberStable(id), offset)
+		}
+	}
+	return copyExpr
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
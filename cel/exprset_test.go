@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestCompileSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		exprs map[string]string
+		// optimized is the unparsed form of the composed expression.
+		optimized string
+		want      map[string]ref.Val
+	}{
+		{
+			name: "shared selection and call",
+			exprs: map[string]string{
+				"admin":   `req.auth.claims.group == 'admin' && size(xs) > 2`,
+				"dev":     `req.auth.claims.group in ['dev'] || size(xs) == 0`,
+				"present": `has(req.auth.claims.group)`,
+			},
+			optimized: `cel.bind(@cse2, size(xs), cel.bind(@cse1, req.auth.claims, cel.bind(@cse0, @cse1.group, ` +
+				`{"admin": cel.@capture(@cse0 == "admin" && @cse2 > 2), "dev": cel.@capture(@cse0 in ["dev"] || @cse2 == 0), ` +
+				`"present": cel.@capture(has(@cse1.group))})))`,
+			want: map[string]ref.Val{"admin": types.True, "dev": types.False, "present": types.True},
+		},
+		{
+			name: "shared comprehension",
+			exprs: map[string]string{
+				"a": `xs.exists(x, x > 5) && size(xs) > 1`,
+				"b": `!xs.exists(x, x > 5)`,
+			},
+			optimized: `cel.bind(@cse0, xs.exists(x, x > 5), {"a": cel.@capture(@cse0 && size(xs) > 1), "b": cel.@capture(!@cse0)})`,
+			want:      map[string]ref.Val{"a": types.True, "b": types.False},
+		},
+		{
+			name: "shadowed variables",
+			exprs: map[string]string{
+				"a": `[{'auth': {'claims': {'group': 'dev'}}}].all(req, req.auth.claims.group == 'dev')`,
+				"b": `req.auth.claims.group == 'dev'`,
+				"c": `xs.all(x, x * 2 > 0) && xs.exists(x, x * 2 > 10)`,
+			},
+			optimized: `{"a": cel.@capture([{"auth": {"claims": {"group": "dev"}}}].all(req, req.auth.claims.group == "dev")), ` +
+				`"b": cel.@capture(req.auth.claims.group == "dev"), "c": cel.@capture(xs.all(x, x * 2 > 0) && xs.exists(x, x * 2 > 10))}`,
+			want: map[string]ref.Val{"a": types.True, "b": types.False, "c": types.True},
+		},
+	}
+	env := testEnv(t,
+		Variable("req", MapType(StringType, DynType)),
+		Variable("xs", ListType(IntType)),
+		EnableMacroCallTracking(),
+	)
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.name, func(t *testing.T) {
+			set, iss := env.CompileSet(tc.exprs)
+			if iss.Err() != nil {
+				t.Fatalf("env.CompileSet() failed: %v", iss.Err())
+			}
+			optimized, err := AstToString(set.Ast())
+			if err != nil {
+				t.Fatalf("AstToString() failed: %v", err)
+			}
+			// The unparser wraps long expressions across lines.
+			optimized = strings.Join(strings.Fields(optimized), " ")
+			if optimized != tc.optimized {
+				t.Errorf("env.CompileSet() got %s, wanted %s", optimized, tc.optimized)
+			}
+			prg, err := set.Program()
+			if err != nil {
+				t.Fatalf("set.Program() failed: %v", err)
+			}
+			results, _, err := prg.Eval(map[string]any{
+				"req": map[string]any{"auth": map[string]any{"claims": map[string]any{"group": "admin"}}},
+				"xs":  []int{1, 5, 9},
+			})
+			if err != nil {
+				t.Fatalf("prg.Eval() failed: %v", err)
+			}
+			if len(results) != len(tc.want) {
+				t.Errorf("prg.Eval() got %v, wanted %v", results, tc.want)
+			}
+			for name, want := range tc.want {
+				if got := results[name]; got.Value != want || got.Err != nil {
+					t.Errorf("prg.Eval() got %s: %v, %v, wanted %v", name, got.Value, got.Err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileSetResults(t *testing.T) {
+	env := testEnv(t,
+		Variable("x", IntType),
+		Variable("y", IntType),
+		EnableMacroCallTracking(),
+	)
+	set, iss := env.CompileSet(map[string]string{
+		"div":     `x / y > 1`,
+		"div_alt": `y == 0 || x / y > 1`,
+		"div_lt":  `x  / y < 1`,
+		"ok":      `x > 1`,
+		"partial": `x > 1 && y > 1`,
+	})
+	if iss.Err() != nil {
+		t.Fatalf("env.CompileSet() failed: %v", iss.Err())
+	}
+	prg, err := set.Program(EvalOptions(OptPartialEval))
+	if err != nil {
+		t.Fatalf("set.Program() failed: %v", err)
+	}
+	results, _, err := prg.Eval(map[string]any{"x": 2, "y": 0})
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	// An error within one expression does not affect the results of the others, and is reported
+	// at its location within the source of the expression.
+	if got := results["div"]; got.Err == nil || got.Err.Error() != "div:1:3: division by zero" {
+		t.Errorf("prg.Eval() got div: %v, %v, wanted division by zero at div:1:3", got.Value, got.Err)
+	}
+	// Errors within hoisted sub-expressions are reported at the location within each expression.
+	if got := results["div_lt"]; got.Err == nil || got.Err.Error() != "div_lt:1:4: division by zero" {
+		t.Errorf("prg.Eval() got div_lt: %v, %v, wanted division by zero at div_lt:1:4", got.Value, got.Err)
+	}
+	if got := results["div_alt"]; got.Value != types.True || got.Err != nil {
+		t.Errorf("prg.Eval() got div_alt: %v, %v, wanted true", got.Value, got.Err)
+	}
+	if got := results["ok"]; got.Value != types.True || got.Err != nil {
+		t.Errorf("prg.Eval() got ok: %v, %v, wanted true", got.Value, got.Err)
+	}
+	if got := results["partial"]; got.Value != types.False || got.Err != nil {
+		t.Errorf("prg.Eval() got partial: %v, %v, wanted false", got.Value, got.Err)
+	}
+
+	// Unknowns are also reported per expression.
+	vars, err := PartialVars(map[string]any{"x": 2}, AttributePattern("y"))
+	if err != nil {
+		t.Fatalf("PartialVars() failed: %v", err)
+	}
+	results, _, err = prg.Eval(vars)
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if got := results["ok"]; got.Value != types.True || got.Err != nil {
+		t.Errorf("prg.Eval() got ok: %v, %v, wanted true", got.Value, got.Err)
+	}
+	if got := results["partial"]; !types.IsUnknown(got.Value) || got.Err != nil {
+		t.Errorf("prg.Eval() got partial: %v, %v, wanted unknown", got.Value, got.Err)
+	}
+}
+
+func TestCompileSetErrors(t *testing.T) {
+	env := testEnv(t, Variable("x", IntType))
+	_, iss := env.CompileSet(map[string]string{"ok": `x > 1`, "rule_b": `x > 'a'`})
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "rule_b:1:3") {
+		t.Errorf("env.CompileSet() got %v, wanted error in rule_b", iss.Err())
+	}
+	parsed, iss := env.Parse(`x > 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Parse() failed: %v", iss.Err())
+	}
+	_, iss = env.ComposeSet(map[string]*Ast{"parsed": parsed})
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "not been type-checked") {
+		t.Errorf("env.ComposeSet() got %v, wanted type-check error", iss.Err())
+	}
+}
@@ -174,7 +174,9 @@ func isLateBoundFunctionCall(ctx *OptimizerContext, expr ast.Expr) bool {
 	if function == nil {
 		return false
 	}
-	return function.HasLateBinding()
+	// Functions which receive the evaluation context may depend on request-scoped values and
+	// cannot be evaluated ahead of time.
+	return function.HasLateBinding() || function.HasContextBinding()
 }
 
 // maybePruneBranches inspects the non-strict call expression to determine whether
@@ -74,6 +74,9 @@ const (
 
 	// Enable accessing fields by JSON names within protobuf messages
 	featureJSONFieldNames
+
+	// Enable flow-sensitive narrowing of reference types within guarded expressions.
+	featureTypeNarrowing
 )
 
 var featureIDsToNames = map[int]string{
@@ -81,6 +84,7 @@ var featureIDsToNames = map[int]string{
 	featureCrossTypeNumericComparisons: "cel.feature.cross_type_numeric_comparisons",
 	featureIdentEscapeSyntax:           "cel.feature.backtick_escape_syntax",
 	featureJSONFieldNames:              "cel.feature.json_field_names",
+	featureTypeNarrowing:               "cel.feature.type_narrowing",
 }
 
 func featureNameByID(id int) (string, bool) {
@@ -359,6 +363,31 @@ func Types(addTypes ...any) EnvOption {
 	}
 }
 
+// schemaTypeRegistry is an internal-only interface for providers which support schema-defined
+// struct types.
+type schemaTypeRegistry interface {
+	RegisterSchemaType(...*types.SchemaType) error
+}
+
+// SchemaTypes adds one or more schema-defined struct types to the environment.
+//
+// Schema types are declared with named and typed fields and do not require a protobuf definition.
+// Values of a schema type may be created with struct literal syntax, e.g. `pkg.Point{x: 1, y: 2}`,
+// or provided as input as a map[string]any keyed by field name. Inputs to variables declared with
+// a schema type are adapted to the schema type at runtime.
+func SchemaTypes(schemaTypes ...*types.SchemaType) EnvOption {
+	return func(e *Env) (*Env, error) {
+		reg, isReg := e.provider.(schemaTypeRegistry)
+		if !isReg {
+			return nil, fmt.Errorf("schema types not supported by provider: %T", e.provider)
+		}
+		if err := reg.RegisterSchemaType(schemaTypes...); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+}
+
 // TypeDescs adds type declarations from any protoreflect.FileDescriptor, protoregistry.Files,
 // google.protobuf.FileDescriptorProto or google.protobuf.FileDescriptorSet provided.
 //
@@ -581,6 +610,22 @@ func configToEnvOptions(config *env.Config, provider types.Provider, optFactorie
 		}
 	}
 
+	// Configure schema-defined struct types
+	if len(config.StructTypes) != 0 {
+		// Struct types may refer to one another and to themselves, so the names of all declared
+		// struct types are resolvable prior to their registration.
+		provider = newStructTypeProvider(provider, config.StructTypes)
+		schemaTypes := make([]*types.SchemaType, 0, len(config.StructTypes))
+		for _, st := range config.StructTypes {
+			schemaType, err := st.AsSchemaType(provider)
+			if err != nil {
+				return nil, err
+			}
+			schemaTypes = append(schemaTypes, schemaType)
+		}
+		envOpts = append(envOpts, SchemaTypes(schemaTypes...))
+	}
+
 	// Configure the context variable declaration
 	if config.ContextVariable != nil {
 		typeName := config.ContextVariable.TypeName
@@ -663,6 +708,31 @@ func configToEnvOptions(config *env.Config, provider types.Provider, optFactorie
 	return envOpts, nil
 }
 
+// structTypeProvider resolves the struct types declared within a config ahead of their registration.
+type structTypeProvider struct {
+	types.Provider
+	structTypes map[string]*types.Type
+}
+
+func newStructTypeProvider(provider types.Provider, structTypes []*env.StructType) *structTypeProvider {
+	stp := &structTypeProvider{
+		Provider:    provider,
+		structTypes: make(map[string]*types.Type, len(structTypes)),
+	}
+	for _, st := range structTypes {
+		stp.structTypes[st.Name] = types.NewTypeTypeWithParam(types.NewObjectType(st.Name))
+	}
+	return stp
+}
+
+// FindStructType implements the types.Provider interface method.
+func (p *structTypeProvider) FindStructType(structType string) (*types.Type, bool) {
+	if t, found := p.structTypes[structType]; found {
+		return t, true
+	}
+	return p.Provider.FindStructType(structType)
+}
+
 func handleExtendedConfigOption(conf any, optFactories []ConfigOptionFactory) (EnvOption, bool) {
 	for _, optFac := range optFactories {
 		if opt, useOption := optFac(conf); useOption {
@@ -706,6 +776,22 @@ const (
 	//
 	// Deprecated: use ext.StringsValidateFormatCalls() as this option is now a no-op.
 	OptCheckStringFormat EvalOption = 1 << iota
+
+	// OptSessionAttributes memoizes the values of attributes with constant qualifiers, such as
+	// `req.auth.claims`, in the EvalSession supplied as the input to the Program Eval() call.
+	//
+	// Without this option an EvalSession only memoizes the resolution of variables, and attribute
+	// selections do not search the input for a session.
+	OptSessionAttributes EvalOption = 1 << iota
+
+	// OptUnrollComprehensions records the state of each iteration of the `all` and `exists`
+	// comprehensions during partial evaluation so that Env.ResidualAst may unroll comprehensions
+	// over known ranges into the loop bodies of the iterations whose result is still unknown.
+	//
+	// The option implies OptPartialEval and OptTrackState. Note, the evaluation state of every
+	// iteration is retained until the evaluation completes, so memory use grows with the number of
+	// iterations evaluated.
+	OptUnrollComprehensions EvalOption = 1<<iota | OptPartialEval | OptTrackState
 )
 
 // EvalOptions sets one or more evaluation options which may affect the evaluation or Result.
@@ -718,8 +804,14 @@ func EvalOptions(opts ...EvalOption) ProgramOption {
 	}
 }
 
-// InterruptCheckFrequency configures the number of iterations within a comprehension to evaluate
-// before checking whether the function evaluation has been interrupted.
+// InterruptCheckFrequency configures the number of comprehension iterations and function calls to
+// evaluate before checking whether the function evaluation has been interrupted.
+//
+// Interrupted evaluations fail with an interpreter.EvalCancelledError whose cause is
+// interpreter.ContextCancelled, and which wraps both an interpreter.InterruptError and the cause of
+// the context cancellation. The output value remains the `operation interrupted` error.
+// Long-running functions which receive the evaluation context, such as those of the ext libraries,
+// also check for cancellation while they run.
 func InterruptCheckFrequency(checkFrequency uint) ProgramOption {
 	return func(p *prog) (*prog, error) {
 		p.interruptCheckFrequency = checkFrequency
@@ -727,6 +819,61 @@ func InterruptCheckFrequency(checkFrequency uint) ProgramOption {
 	}
 }
 
+// ParallelComprehensions evaluates the iterations of comprehensions whose range contains at least
+// minSize elements across up to parallelism goroutines, or runtime.GOMAXPROCS goroutines when the
+// parallelism is not positive.
+//
+// Parallel evaluation applies to the `all`, `exists`, `map`, and `filter` macros as well as to the
+// `transformList`, `transformMap`, and `transformMapEntry` macros from ext.TwoVarComprehensions.
+// Results and errors are identical to sequential evaluation, and cost limits and interrupt checks
+// remain in effect. Comprehensions are evaluated sequentially when evaluation state is tracked.
+func ParallelComprehensions(minSize, parallelism int) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.plannerOptions = append(p.plannerOptions, interpreter.ParallelComprehensions(minSize, parallelism))
+		return p, nil
+	}
+}
+
+// BytecodeEval compiles programs which contain comprehensions into a register-based instruction
+// sequence which is executed by a small virtual machine rather than by walking the expression tree.
+//
+// Results, evaluation state, cost tracking, and interrupt checks are identical to tree-walking
+// evaluation. The option benefits expressions whose comprehensions iterate over many elements, and
+// programs without comprehensions are evaluated by walking the expression tree.
+func BytecodeEval() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.plannerOptions = append(p.plannerOptions, interpreter.BytecodeEval())
+		return p, nil
+	}
+}
+
+// EvalTrace records an ordered tree of the steps taken during each evaluation, including the
+// function overloads invoked, their arguments and results, the iteration index of steps within
+// comprehensions, and the time spent in each step.
+//
+// The trace is available from EvalDetails.Trace() and may be exported as JSON. Tracing adds
+// overhead to each evaluation step and is intended for debugging.
+func EvalTrace() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.evalTrace = true
+		return p, nil
+	}
+}
+
+// ProfileEval accumulates the number of evaluations, wall time, and optionally the heap allocations
+// of each expression node and function overload into the profiler across all evaluations of the
+// program.
+//
+// The profile may be exported in the pprof format with Profiler.WriteProfile and inspected with
+// `go tool pprof`. A profiler may be shared by the programs of a single expression, and profiling
+// adds overhead to each evaluation step.
+func ProfileEval(profiler *interpreter.Profiler) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.plannerOptions = append(p.plannerOptions, interpreter.ProfileObserver(profiler))
+		return p, nil
+	}
+}
+
 // CostEstimatorOptions configure type-check time options for estimating expression cost.
 func CostEstimatorOptions(costOpts ...checker.CostOption) EnvOption {
 	return func(e *Env) (*Env, error) {
@@ -766,6 +913,38 @@ func CostLimit(costLimit uint64) ProgramOption {
 	}
 }
 
+// MemoryLimit configures program evaluation to exit early with an "actual memory limit exceeded"
+// error if the bytes allocated by the strings, bytes, lists, and maps produced during evaluation
+// exceed the memoryLimit.
+//
+// Memory is accounted using the same size measure as runtime cost: one byte per string character or
+// byte, 16 bytes per list element, and 32 bytes per map entry. Only values produced by function calls
+// and constructors are accounted for; input variables and constants are not. The number of bytes
+// allocated is available from EvalDetails.ActualMemory(). Comprehensions are evaluated sequentially
+// when a memory limit is set.
+func MemoryLimit(memoryLimit uint64) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.memoryLimit = &memoryLimit
+		return p, nil
+	}
+}
+
+// FunctionResultCacheSize sets the maximum number of results of calls to deterministic function
+// overloads which are memoized across evaluations of the program, defaulting to 1024 results.
+//
+// Only overloads configured with OverloadIsDeterministic are memoized, and only when the arguments
+// to the call are primitive values, durations, or timestamps. The hits and misses of each evaluation
+// are available from EvalDetails.FunctionCacheStats(). A size of zero disables memoization.
+func FunctionResultCacheSize(size int) ProgramOption {
+	return func(p *prog) (*prog, error) {
+		if size < 0 {
+			return nil, fmt.Errorf("function result cache size must be non-negative: %d", size)
+		}
+		p.functionCacheSize = size
+		return p, nil
+	}
+}
+
 func fieldToCELType(field protoreflect.FieldDescriptor) (*Type, error) {
 	if field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind {
 		msgName := (string)(field.Message().FullName())
@@ -900,6 +1079,20 @@ func CrossTypeNumericComparisons(enabled bool) EnvOption {
 	return features(featureCrossTypeNumericComparisons, enabled)
 }
 
+// TypeNarrowing enables flow-sensitive type narrowing at the type-checker.
+//
+// Within the right-hand side of `&&`, the branches of `? :`, and the filters of comprehension
+// macros such as `filter` and `map`, variables and field selections are assigned more precise
+// types following tests such as `type(x) == int`, `has(m.f)`, and `x != null`. For example,
+// `type(x) == int && x > 5` resolves the `int` overload of `_>_` when `x` is `dyn`, and
+// `xs.filter(x, type(x) == string)` has type `list(string)`.
+//
+// Type tests which can never succeed given the declared type of the reference are reported as
+// warnings.
+func TypeNarrowing(enabled bool) EnvOption {
+	return features(featureTypeNarrowing, enabled)
+}
+
 // DefaultUTCTimeZone ensures that time-based operations use the UTC timezone rather than the
 // input time's local timezone.
 func DefaultUTCTimeZone(enabled bool) EnvOption {
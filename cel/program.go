@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"iter"
 	"sync"
 
 	"github.com/google/cel-go/common/ast"
@@ -49,6 +50,9 @@ type Program interface {
 	// to support cancellation and timeouts. This method must be used in conjunction with the
 	// InterruptCheckFrequency() option for cancellation interrupts to be impact evaluation.
 	//
+	// Cancelled evaluations return an interpreter.EvalCancelledError which wraps the cause of the
+	// context cancellation.
+	//
 	// The vars value may either be an `Activation` or `map[string]any`.
 	//
 	// The output contract for `ContextEval` is otherwise identical to the `Eval` method.
@@ -65,11 +69,14 @@ type Activation = interpreter.Activation
 //
 // The input `bindings` may either be of type `Activation` or `map[string]any`.
 //
-// Lazy bindings may be supplied within the map-based input in either of the following forms:
+// Lazy bindings may be supplied within the map-based input in any of the following forms:
 // - func() any
 // - func() ref.Val
+// - func() (any, error)
 //
-// The output of the lazy binding will overwrite the variable reference in the internal map.
+// The output of the lazy binding will overwrite the variable reference in the internal map. An
+// error returned by a lazy binding is reported as the result of the variable resolution, and the
+// binding is not overwritten.
 //
 // Values which are not represented as ref.Val types on input may be adapted to a ref.Val using
 // the types.Adapter configured in the environment.
@@ -97,6 +104,28 @@ func PartialVars(vars any,
 	return interpreter.NewPartialActivation(vars, unknowns...)
 }
 
+// EvalSession is an Activation which memoizes the variables resolved during evaluation, so that they
+// are computed once across all of the programs evaluated with the session. Programs configured with
+// OptSessionAttributes also memoize the attribute values with constant qualifiers in the session.
+type EvalSession = interpreter.EvalSession
+
+// EvalSessionStats reports the variables resolved by an EvalSession and the lookups it served.
+type EvalSessionStats = interpreter.EvalSessionStats
+
+// NewEvalSession returns an EvalSession which may be supplied as the input to any number of
+// Program.Eval and Program.ContextEval calls, including concurrent ones.
+//
+// The `vars` value may either be an Activation or any valid input to the NewActivation call. The
+// session is typically created once per request, and the Activation must not be used other than
+// through the session.
+func NewEvalSession(vars any) (*EvalSession, error) {
+	a, err := interpreter.NewActivation(vars)
+	if err != nil {
+		return nil, err
+	}
+	return interpreter.NewEvalSession(a), nil
+}
+
 // AttributePattern returns an AttributePattern that matches a top-level variable. The pattern is
 // mutable, and its methods support the specification of one or more qualifier patterns.
 //
@@ -120,10 +149,34 @@ func AttributePattern(varName string) *AttributePatternType {
 // about how to create and manipulate AttributePattern values.
 type AttributePatternType = interpreter.AttributePattern
 
+// AttributeRead describes an attribute which an expression may read during evaluation, along with
+// whether it is read by every evaluation of the expression.
+type AttributeRead = interpreter.AttributeRead
+
+// AttributeReads returns the attributes which a checked expression may read during evaluation,
+// such as `request.user.groups` or `resource.labels["env"]`, sorted by their patterns.
+//
+// Indices which are computed during evaluation are reported as wildcards, and each attribute is
+// classified as a must-read when it is read by every evaluation of the expression. The patterns of
+// the attributes may be used to prefetch the data required by the expression, or supplied to
+// PartialVars in order to treat the attributes as unknown.
+func AttributeReads(a *Ast) ([]*AttributeRead, error) {
+	if !a.IsChecked() {
+		return nil, errors.New("cannot compute the attribute reads of an unchecked expression")
+	}
+	return interpreter.AttributeReads(a.NativeRep()), nil
+}
+
 // EvalDetails holds additional information observed during the Eval() call.
 type EvalDetails struct {
-	state       interpreter.EvalState
-	costTracker *interpreter.CostTracker
+	state         interpreter.EvalState
+	costTracker   *interpreter.CostTracker
+	memoryTracker *interpreter.MemoryTracker
+	trace         *interpreter.EvalTrace
+	fnCacheStats  *interpreter.FunctionCacheStats
+
+	recording      *interpreter.EvalRecording
+	recordedResult ref.Val
 }
 
 // State of the evaluation, non-nil if the OptTrackState or OptExhaustiveEval is specified
@@ -145,6 +198,70 @@ func (ed *EvalDetails) ActualCost() *uint64 {
 	return &cost
 }
 
+// ActualMemory returns the number of bytes allocated by the strings, bytes, lists, and maps produced
+// during evaluation when `MemoryLimit` is enabled. Otherwise, returns nil.
+func (ed *EvalDetails) ActualMemory() *uint64 {
+	if ed == nil || ed.memoryTracker == nil {
+		return nil
+	}
+	mem := ed.memoryTracker.ActualMemory()
+	return &mem
+}
+
+// Trace returns the ordered tree of evaluation steps when the EvalTrace program option is
+// configured. Otherwise, returns nil.
+//
+// The trace may be exported as JSON using json.Marshal.
+func (ed *EvalDetails) Trace() *interpreter.EvalTrace {
+	if ed == nil {
+		return nil
+	}
+	return ed.trace
+}
+
+// FunctionCacheStats returns the number of calls to deterministic functions which were served from
+// the program's function result cache during the evaluation, and the number of calls which invoked
+// the function implementation. Returns nil if none of the functions in the environment are
+// deterministic, or if memoization is disabled with FunctionResultCacheSize(0).
+func (ed *EvalDetails) FunctionCacheStats() *interpreter.FunctionCacheStats {
+	if ed == nil {
+		return nil
+	}
+	return ed.fnCacheStats
+}
+
+// BatchResult holds the outcome of evaluating a single input within EvalBatch.
+//
+// The Val, Details, and Err fields follow the same contract as the return values of Program.Eval.
+// When cost tracking is enabled, the per-input cost is available from Details.ActualCost().
+type BatchResult struct {
+	Val     ref.Val
+	Details *EvalDetails
+	Err     error
+}
+
+// BatchOption configures the behavior of EvalBatch.
+type BatchOption func(*batchConfig) *batchConfig
+
+// BatchWorkers configures the number of goroutines used to evaluate inputs within a batch.
+//
+// By default, inputs are evaluated sequentially on the calling goroutine. When more than one worker
+// is configured, any Activation inputs must be safe for concurrent use with one another.
+func BatchWorkers(workers int) BatchOption {
+	return func(conf *batchConfig) *batchConfig {
+		conf.workers = workers
+		return conf
+	}
+}
+
+type batchConfig struct {
+	workers int
+}
+
+// defaultFunctionResultCacheSize is the default number of results of deterministic function calls
+// memoized by a program.
+const defaultFunctionResultCacheSize = 1024
+
 // prog is the internal implementation of the Program interface.
 type prog struct {
 	*Env
@@ -165,6 +282,13 @@ type prog struct {
 	callCostEstimator interpreter.ActualCostEstimator
 	costOptions       []interpreter.CostTrackerOption
 	costLimit         *uint64
+	memoryLimit       *uint64
+	evalTrace         bool
+	functionCacheSize int
+	recordEval        bool
+
+	// schemaAdapters adapt the values of variables declared with schema types by variable name.
+	schemaAdapters map[string]types.Adapter
 }
 
 // newProgram creates a program instance with an environment, an ast, and an optional list of
@@ -178,10 +302,11 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 	// Ensure the default attribute factory is set after the adapter and provider are
 	// configured.
 	p := &prog{
-		Env:            e,
-		plannerOptions: []interpreter.PlannerOption{},
-		dispatcher:     disp,
-		costOptions:    []interpreter.CostTrackerOption{},
+		Env:               e,
+		plannerOptions:    []interpreter.PlannerOption{},
+		dispatcher:        disp,
+		costOptions:       []interpreter.CostTrackerOption{},
+		functionCacheSize: defaultFunctionResultCacheSize,
 	}
 
 	// Configure the program via the ProgramOption values.
@@ -214,10 +339,23 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 		return nil, err
 	}
 
+	// Adapt the inputs of variables declared with schema-defined struct types.
+	if sap, ok := e.provider.(schemaAdapterProvider); ok {
+		for _, v := range e.variables {
+			if adapter, found := sap.SchemaAdapter(v.Type()); found {
+				if p.schemaAdapters == nil {
+					p.schemaAdapters = make(map[string]types.Adapter)
+				}
+				p.schemaAdapters[v.Name()] = adapter
+			}
+		}
+	}
+
 	// Set the attribute factory after the options have been set.
 	var attrFactory interpreter.AttributeFactory
 	attrFactorOpts := []interpreter.AttrFactoryOption{
 		interpreter.EnableErrorOnBadPresenceTest(p.HasFeature(featureEnableErrorOnBadPresenceTest)),
+		interpreter.EnableSessionAttributes(p.evalOpts&OptSessionAttributes == OptSessionAttributes),
 	}
 	if a.SourceInfo().HasExtension("json_name", ast.NewExtensionVersion(1, 1)) {
 		if !e.HasFeature(featureJSONFieldNames) {
@@ -237,10 +375,6 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 	plannerOptions := make([]interpreter.PlannerOption, len(p.plannerOptions))
 	copy(plannerOptions, p.plannerOptions)
 
-	// Enable interrupt checking if there's a non-zero check frequency
-	if p.interruptCheckFrequency > 0 {
-		plannerOptions = append(plannerOptions, interpreter.InterruptableEval())
-	}
 	// Enable constant folding first.
 	if p.evalOpts&OptOptimize == OptOptimize {
 		plannerOptions = append(plannerOptions, interpreter.Optimize())
@@ -250,6 +384,11 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 	if len(p.regexOptimizations) > 0 {
 		plannerOptions = append(plannerOptions, interpreter.CompileRegexConstants(p.regexOptimizations...))
 	}
+	// Enable interrupt checking if there's a non-zero check frequency. The checks are configured
+	// after the optimizations so that the function calls they replace are also checked.
+	if p.interruptCheckFrequency > 0 {
+		plannerOptions = append(plannerOptions, interpreter.InterruptableEval())
+	}
 
 	// Enable exhaustive eval, state tracking and cost tracking last since they require a factory.
 	if p.evalOpts&(OptExhaustiveEval|OptTrackState|OptTrackCost) != 0 {
@@ -269,6 +408,11 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 		if p.evalOpts&(OptExhaustiveEval|OptTrackState) != 0 {
 			// EvalStateObserver is required for OptExhaustiveEval.
 			observers = append(observers, interpreter.EvalStateObserver())
+			// Record the state of each comprehension iteration so that residual ASTs may unroll
+			// comprehensions over known ranges.
+			if p.evalOpts&OptUnrollComprehensions == OptUnrollComprehensions {
+				observers = append(observers, interpreter.TrackIterationState())
+			}
 		}
 		if p.evalOpts&OptTrackCost == OptTrackCost {
 			observers = append(observers, interpreter.CostObserver(interpreter.CostTrackerFactory(trackerFactory)))
@@ -281,9 +425,41 @@ func newProgram(e *Env, a *ast.AST, opts []ProgramOption) (Program, error) {
 			plannerOptions = append(plannerOptions, observers...)
 		}
 	}
+	if p.memoryLimit != nil {
+		memoryLimit := *p.memoryLimit
+		trackerFactory := func() (*interpreter.MemoryTracker, error) {
+			return interpreter.NewMemoryTracker(interpreter.MemoryTrackerLimit(memoryLimit))
+		}
+		plannerOptions = append(plannerOptions,
+			interpreter.MemoryObserver(interpreter.MemoryTrackerFactory(trackerFactory)))
+	}
+	if p.evalTrace {
+		plannerOptions = append(plannerOptions, interpreter.EvalTraceObserver())
+	}
+	// Memoize the results of deterministic functions within a cache scoped to the program.
+	if p.functionCacheSize > 0 && hasDeterministicBinding(e.functionBindings) {
+		cache, err := interpreter.NewFunctionResultCache(p.functionCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		plannerOptions = append(plannerOptions, interpreter.MemoizeDeterministicCalls(cache))
+	}
+	if p.recordEval {
+		plannerOptions = append(plannerOptions, interpreter.RecordEval(isRecordedFunction))
+	}
 	return p.initInterpretable(a, plannerOptions)
 }
 
+// hasDeterministicBinding indicates whether any of the function bindings may be memoized.
+func hasDeterministicBinding(bindings []*functions.Overload) bool {
+	for _, b := range bindings {
+		if b.Deterministic {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *prog) initInterpretable(a *ast.AST, plannerOptions []interpreter.PlannerOption) (*prog, error) {
 	// When the AST has been exprAST it contains metadata that can be used to speed up program execution.
 	interpretable, err := p.interpreter.NewInterpretable(a, plannerOptions...)
@@ -326,6 +502,9 @@ func (p *prog) Eval(input any) (out ref.Val, det *EvalDetails, err error) {
 	if p.defaultVars != nil {
 		vars = interpreter.NewHierarchicalActivation(p.defaultVars, vars)
 	}
+	if p.schemaAdapters != nil {
+		vars = &schemaActivation{parent: vars, adapters: p.schemaAdapters}
+	}
 	if p.observable != nil {
 		det = &EvalDetails{}
 		out = p.observable.ObserveEval(vars, func(observed any) {
@@ -334,8 +513,19 @@ func (p *prog) Eval(input any) (out ref.Val, det *EvalDetails, err error) {
 				det.state = o
 			case *interpreter.CostTracker:
 				det.costTracker = o
+			case *interpreter.MemoryTracker:
+				det.memoryTracker = o
+			case *interpreter.EvalTrace:
+				det.trace = o
+			case *interpreter.FunctionCacheStats:
+				det.fnCacheStats = o
+			case *interpreter.EvalRecording:
+				det.recording = o
 			}
 		})
+		if det.recording != nil {
+			det.recordedResult = out
+		}
 	} else {
 		out = p.interpretable.Eval(vars)
 	}
@@ -344,6 +534,11 @@ func (p *prog) Eval(input any) (out ref.Val, det *EvalDetails, err error) {
 	// RPC signature which allows for multiple errors to be returned, but should be sufficient.
 	if types.IsError(out) {
 		err = out.(*types.Err)
+		// Interrupted evaluations are reported as the cancellation of the evaluation.
+		var cancelled interpreter.EvalCancelledError
+		if errors.As(err, &cancelled) {
+			err = cancelled
+		}
 	}
 	return
 }
@@ -358,33 +553,212 @@ func (p *prog) ContextEval(ctx context.Context, input any) (ref.Val, *EvalDetail
 	var vars Activation
 	switch v := input.(type) {
 	case Activation:
-		vars = ctxActivationPool.Setup(v, ctx.Done(), p.interruptCheckFrequency)
+		vars = ctxActivationPool.Setup(v, ctx, p.interruptCheckFrequency)
 		defer ctxActivationPool.Put(vars)
 	case map[string]any:
 		rawVars := activationPool.Setup(v)
 		defer activationPool.Put(rawVars)
-		vars = ctxActivationPool.Setup(rawVars, ctx.Done(), p.interruptCheckFrequency)
+		vars = ctxActivationPool.Setup(rawVars, ctx, p.interruptCheckFrequency)
 		defer ctxActivationPool.Put(vars)
 	default:
 		return nil, nil, fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)
 	}
-	out, det, err := p.Eval(vars)
-	if err != nil && errors.Is(err, interpreter.InterruptError{}) {
-		return out, det, fmt.Errorf("%w: %w", err, context.Cause(ctx))
+	return p.Eval(vars)
+}
+
+// EvalBatch evaluates the program against a sequence of inputs and returns one BatchResult per
+// input in the order the inputs were produced by the sequence.
+//
+// Each input value may either be an `Activation` or `map[string]any`. Use `slices.Values` to
+// evaluate a slice of inputs.
+//
+// The context cancels the entire batch: the input sequence is not consumed any further, inputs
+// which were produced but not evaluated at the time of cancellation are reported with the
+// context's cancellation cause, and in-flight evaluations are interrupted when used in conjunction
+// with the InterruptCheckFrequency() option. When the batch is cancelled, the results of the
+// inputs produced before the cancellation are returned along with a non-nil error.
+//
+// A panic raised while producing the inputs stops the batch, and is returned as an error along
+// with the results of the inputs produced before the panic.
+func EvalBatch(ctx context.Context, prg Program, inputs iter.Seq[any], opts ...BatchOption) ([]BatchResult, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context can not be nil")
+	}
+	conf := &batchConfig{workers: 1}
+	for _, opt := range opts {
+		conf = opt(conf)
+	}
+	var results []BatchResult
+	var err error
+	if conf.workers <= 1 {
+		results, err = evalBatchSequential(ctx, prg, inputs)
+	} else {
+		results, err = evalBatchParallel(ctx, prg, inputs, conf.workers)
+	}
+	if err != nil {
+		return results, err
+	}
+	if ctx.Err() != nil {
+		return results, context.Cause(ctx)
+	}
+	return results, nil
+}
+
+// evalBatchSequential evaluates each input in order on the calling goroutine.
+func evalBatchSequential(ctx context.Context, prg Program, inputs iter.Seq[any]) ([]BatchResult, error) {
+	b := newBatchEvaluator(ctx, prg)
+	defer b.close()
+	var results []BatchResult
+	err := produceBatchInputs(ctx, inputs, func(_ int, input any) bool {
+		results = append(results, b.eval(input))
+		return true
+	})
+	return results, err
+}
+
+// evalBatchParallel fans the inputs out across a fixed number of workers and gathers the results
+// back into input order.
+func evalBatchParallel(ctx context.Context, prg Program, inputs iter.Seq[any], workers int) ([]BatchResult, error) {
+	type batchItem struct {
+		index int
+		input any
+	}
+	type batchOutput struct {
+		index  int
+		result BatchResult
+	}
+	items := make(chan batchItem, workers)
+	outputs := make(chan batchOutput, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := newBatchEvaluator(ctx, prg)
+			defer b.close()
+			for item := range items {
+				outputs <- batchOutput{index: item.index, result: b.eval(item.input)}
+			}
+		}()
+	}
+	// Once the batch is cancelled, the workers report the cancellation cause for the inputs which
+	// have been produced without evaluating them.
+	var produceErr error
+	go func() {
+		defer close(items)
+		produceErr = produceBatchInputs(ctx, inputs, func(index int, input any) bool {
+			select {
+			case items <- batchItem{index: index, input: input}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	go func() {
+		wg.Wait()
+		close(outputs)
+	}()
+	var results []BatchResult
+	for out := range outputs {
+		for len(results) <= out.index {
+			results = append(results, BatchResult{})
+		}
+		results[out.index] = out.result
+	}
+	return results, produceErr
+}
+
+// produceBatchInputs calls the consumer with each input and its index, recovering from any panic
+// raised by the input sequence.
+//
+// The sequence is no longer consumed once the context is done or the consumer returns false.
+func produceBatchInputs(ctx context.Context, inputs iter.Seq[any], consume func(int, any) bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("batch input sequence panicked: %v", r)
+		}
+	}()
+	index := 0
+	for input := range inputs {
+		if ctx.Err() != nil || !consume(index, input) {
+			break
+		}
+		index++
+	}
+	return nil
+}
+
+// batchEvaluator evaluates batch inputs using pooled activations which are reused across inputs.
+//
+// Programs other than those created by Env.Program are evaluated with Program.ContextEval.
+type batchEvaluator struct {
+	prg     Program
+	ctx     context.Context
+	vars    *evalActivation
+	ctxVars *ctxEvalActivation
+}
+
+func newBatchEvaluator(ctx context.Context, prg Program) *batchEvaluator {
+	b := &batchEvaluator{prg: prg, ctx: ctx}
+	if p, ok := prg.(*prog); ok {
+		b.vars = activationPool.Setup(nil)
+		b.ctxVars = ctxActivationPool.Setup(nil, ctx, p.interruptCheckFrequency)
+	}
+	return b
+}
+
+// eval evaluates a single input, reporting the context cancellation cause if the batch has been cancelled.
+func (b *batchEvaluator) eval(input any) BatchResult {
+	if b.ctx.Err() != nil {
+		return BatchResult{Err: context.Cause(b.ctx)}
+	}
+	if b.ctxVars == nil {
+		out, det, err := b.prg.ContextEval(b.ctx, input)
+		return BatchResult{Val: out, Details: det, Err: err}
+	}
+	var vars Activation
+	switch v := input.(type) {
+	case Activation:
+		vars = v
+	case map[string]any:
+		b.vars.vars = v
+		defer b.vars.reset()
+		vars = b.vars
+	default:
+		return BatchResult{Err: fmt.Errorf("invalid input, wanted Activation or map[string]any, got: (%T)%v", input, input)}
+	}
+	b.ctxVars.parent = vars
+	b.ctxVars.interruptCheckCount = 0
+	out, det, err := b.prg.Eval(b.ctxVars)
+	return BatchResult{Val: out, Details: det, Err: err}
+}
+
+// close returns the pooled activations used by the evaluator.
+func (b *batchEvaluator) close() {
+	if b.ctxVars == nil {
+		return
 	}
-	return out, det, err
+	activationPool.Put(b.vars)
+	b.ctxVars.parent = nil
+	ctxActivationPool.Put(b.ctxVars)
 }
 
 type ctxEvalActivation struct {
 	parent                  Activation
+	ctx                     context.Context
 	interrupt               <-chan struct{}
 	interruptCheckCount     uint
 	interruptCheckFrequency uint
 }
 
 // ResolveName implements the Activation interface method, but adds a special #interrupted variable
-// which is capable of testing whether a 'done' signal is provided from a context.Context channel.
+// which is capable of testing whether a 'done' signal is provided from a context.Context channel,
+// and a special #context variable which provides the context.Context to context-aware functions.
 func (a *ctxEvalActivation) ResolveName(name string) (any, bool) {
+	if name == "#context" {
+		return a.ctx, true
+	}
 	if name == "#interrupted" {
 		a.interruptCheckCount++
 		if a.interruptCheckCount%a.interruptCheckFrequency == 0 {
@@ -400,13 +774,22 @@ func (a *ctxEvalActivation) ResolveName(name string) (any, bool) {
 	return a.parent.ResolveName(name)
 }
 
+// ResolveNameWithError implements the interpreter.ErrorResolver interface method, proxying variable
+// lookups to the parent activation.
+func (a *ctxEvalActivation) ResolveNameWithError(name string) (any, bool, error) {
+	if name == "#interrupted" || name == "#context" {
+		obj, found := a.ResolveName(name)
+		return obj, found, nil
+	}
+	return interpreter.ResolveNameWithError(a.parent, name)
+}
+
 func (a *ctxEvalActivation) Parent() Activation {
 	return a.parent
 }
 
 func (a *ctxEvalActivation) AsPartialActivation() (interpreter.PartialActivation, bool) {
-	pa, ok := a.parent.(interpreter.PartialActivation)
-	return pa, ok
+	return interpreter.AsPartialActivation(a.parent)
 }
 
 func newCtxEvalActivationPool() *ctxEvalActivationPool {
@@ -424,15 +807,55 @@ type ctxEvalActivationPool struct {
 }
 
 // Setup initializes a pooled Activation with the ability check for context.Context cancellation
-func (p *ctxEvalActivationPool) Setup(vars Activation, done <-chan struct{}, interruptCheckRate uint) *ctxEvalActivation {
+func (p *ctxEvalActivationPool) Setup(vars Activation, ctx context.Context, interruptCheckRate uint) *ctxEvalActivation {
 	a := p.Pool.Get().(*ctxEvalActivation)
 	a.parent = vars
-	a.interrupt = done
+	a.ctx = ctx
+	a.interrupt = ctx.Done()
 	a.interruptCheckCount = 0
 	a.interruptCheckFrequency = interruptCheckRate
 	return a
 }
 
+// schemaAdapterProvider is an internal-only interface for providers which adapt input values to
+// schema-defined struct types.
+type schemaAdapterProvider interface {
+	SchemaAdapter(*types.Type) (types.Adapter, bool)
+}
+
+// schemaActivation adapts the values of variables declared with schema types, such that a
+// map[string]any provided as input has the declared schema type at runtime.
+type schemaActivation struct {
+	parent   Activation
+	adapters map[string]types.Adapter
+}
+
+// ResolveName implements the Activation interface method.
+func (a *schemaActivation) ResolveName(name string) (any, bool) {
+	obj, found, err := a.ResolveNameWithError(name)
+	if err != nil {
+		return types.WrapErr(err), true
+	}
+	return obj, found
+}
+
+// ResolveNameWithError implements the interpreter.ErrorResolver interface method.
+func (a *schemaActivation) ResolveNameWithError(name string) (any, bool, error) {
+	obj, found, err := interpreter.ResolveNameWithError(a.parent, name)
+	if !found || err != nil {
+		return obj, found, err
+	}
+	if adapter, isSchema := a.adapters[name]; isSchema {
+		return adapter.NativeToValue(obj), true, nil
+	}
+	return obj, true, nil
+}
+
+// Parent implements the Activation interface method.
+func (a *schemaActivation) Parent() Activation {
+	return a.parent
+}
+
 type evalActivation struct {
 	vars     map[string]any
 	lazyVars map[string]any
@@ -440,36 +863,57 @@ type evalActivation struct {
 
 // ResolveName looks up the value of the input variable name, if found.
 //
-// Lazy bindings may be supplied within the map-based input in either of the following forms:
+// Lazy bindings may be supplied within the map-based input in any of the following forms:
 // - func() any
 // - func() ref.Val
+// - func() (any, error)
 //
-// The lazy binding will only be invoked once per evaluation.
+// The lazy binding will only be invoked once per evaluation, unless it returns an error.
 //
 // Values which are not represented as ref.Val types on input may be adapted to a ref.Val using
 // the types.Adapter configured in the environment.
 func (a *evalActivation) ResolveName(name string) (any, bool) {
+	obj, found, err := a.ResolveNameWithError(name)
+	if err != nil {
+		return types.WrapErr(err), true
+	}
+	return obj, found
+}
+
+// ResolveNameWithError implements the interpreter.ErrorResolver interface method, reporting the
+// errors returned by lazy bindings of the form func() (any, error).
+func (a *evalActivation) ResolveNameWithError(name string) (any, bool, error) {
 	v, found := a.vars[name]
 	if !found {
-		return nil, false
+		return nil, false, nil
 	}
 	switch obj := v.(type) {
 	case func() ref.Val:
 		if resolved, found := a.lazyVars[name]; found {
-			return resolved, true
+			return resolved, true, nil
 		}
 		lazy := obj()
 		a.lazyVars[name] = lazy
-		return lazy, true
+		return lazy, true, nil
 	case func() any:
 		if resolved, found := a.lazyVars[name]; found {
-			return resolved, true
+			return resolved, true, nil
 		}
 		lazy := obj()
 		a.lazyVars[name] = lazy
-		return lazy, true
+		return lazy, true, nil
+	case func() (any, error):
+		if resolved, found := a.lazyVars[name]; found {
+			return resolved, true, nil
+		}
+		lazy, err := obj()
+		if err != nil {
+			return nil, true, err
+		}
+		a.lazyVars[name] = lazy
+		return lazy, true, nil
 	default:
-		return obj, true
+		return obj, true, nil
 	}
 }
 
@@ -478,6 +922,14 @@ func (a *evalActivation) Parent() Activation {
 	return nil
 }
 
+// reset clears the variables and any lazily resolved values from the activation.
+func (a *evalActivation) reset() {
+	a.vars = nil
+	for k := range a.lazyVars {
+		delete(a.lazyVars, k)
+	}
+}
+
 func newEvalActivationPool() *evalActivationPool {
 	return &evalActivationPool{
 		Pool: sync.Pool{
@@ -501,9 +953,7 @@ func (p *evalActivationPool) Setup(vars map[string]any) *evalActivation {
 
 func (p *evalActivationPool) Put(value any) {
 	a := value.(*evalActivation)
-	for k := range a.lazyVars {
-		delete(a.lazyVars, k)
-	}
+	a.reset()
 	p.Pool.Put(a)
 }
 
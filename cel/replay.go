@@ -0,0 +1,315 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/google/cel-go/common/stdlib"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+
+	celpb "cel.dev/expr"
+)
+
+// Divergence describes a difference between a replayed evaluation and its recording.
+type Divergence = interpreter.Divergence
+
+// EvalRecord is the serializable log of a single evaluation produced by a program configured with
+// the RecordEval option.
+//
+// Values are encoded as cel.expr.ExprValue messages using the same encoding as ExprValueAsProto,
+// and the record may be exported and imported as JSON.
+type EvalRecord struct {
+	// Variables contains the variables resolved during evaluation in the order of first resolution.
+	Variables []*RecordedVariable `json:"variables,omitempty"`
+
+	// Attributes contains the values of attributes in the order of evaluation.
+	Attributes []*RecordedAttribute `json:"attributes,omitempty"`
+
+	// Calls contains the invocations of custom functions in the order of evaluation.
+	Calls []*RecordedCall `json:"calls,omitempty"`
+
+	// Result is the output of the evaluation.
+	Result *RecordedValue `json:"result,omitempty"`
+}
+
+// RecordedVariable records the resolution of a variable name.
+type RecordedVariable struct {
+	Name  string         `json:"name"`
+	Found bool           `json:"found"`
+	Value *RecordedValue `json:"value,omitempty"`
+}
+
+// RecordedAttribute records the value of an attribute after all of its qualifiers were applied.
+type RecordedAttribute struct {
+	ID    int64          `json:"id"`
+	Value *RecordedValue `json:"value"`
+}
+
+// RecordedCall records the arguments and result of a call to a custom function.
+type RecordedCall struct {
+	ID       int64            `json:"id"`
+	Function string           `json:"function"`
+	Overload string           `json:"overload,omitempty"`
+	Args     []*RecordedValue `json:"args,omitempty"`
+	Result   *RecordedValue   `json:"result"`
+}
+
+// RecordedValue holds the proto encoding of a value, error, or unknown set within an EvalRecord.
+type RecordedValue struct {
+	Value *celpb.ExprValue
+}
+
+// MarshalJSON encodes the value using the proto JSON encoding of cel.expr.ExprValue.
+func (v *RecordedValue) MarshalJSON() ([]byte, error) {
+	return protojson.Marshal(v.Value)
+}
+
+// UnmarshalJSON decodes the value from the proto JSON encoding of cel.expr.ExprValue.
+func (v *RecordedValue) UnmarshalJSON(data []byte) error {
+	v.Value = &celpb.ExprValue{}
+	return protojson.Unmarshal(data, v.Value)
+}
+
+// RecordEval records the variable resolutions, attribute values, and the arguments and results of
+// calls to custom functions during each evaluation, where custom functions are those which are not
+// part of the CEL standard library or the optional types library.
+//
+// The record of each evaluation is available from EvalDetails.EvalRecord() and may be replayed
+// against the program with Replay. During replay the custom function implementations are not
+// invoked; the recorded results are returned instead. Values which cannot be converted with
+// ExprValueAsProto, such as opaque values, and optional values cannot be recorded, and attributes
+// with optional values are not recorded.
+func RecordEval() ProgramOption {
+	return func(p *prog) (*prog, error) {
+		p.recordEval = true
+		return p, nil
+	}
+}
+
+// EvalRecord returns the serializable record of the evaluation when the RecordEval program option
+// is configured. Otherwise, returns nil.
+func (ed *EvalDetails) EvalRecord() (*EvalRecord, error) {
+	if ed == nil || ed.recording == nil {
+		return nil, nil
+	}
+	rec := ed.recording
+	out := &EvalRecord{
+		Variables:  make([]*RecordedVariable, len(rec.Variables)),
+		Attributes: make([]*RecordedAttribute, len(rec.Attributes)),
+		Calls:      make([]*RecordedCall, len(rec.Calls)),
+	}
+	var err error
+	for i, v := range rec.Variables {
+		rv := &RecordedVariable{Name: v.Name, Found: v.Found}
+		if v.Found {
+			if rv.Value, err = recordValue(v.Value); err != nil {
+				return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+			}
+		}
+		out.Variables[i] = rv
+	}
+	for i, a := range rec.Attributes {
+		ra := &RecordedAttribute{ID: a.ID}
+		if ra.Value, err = recordValue(a.Value); err != nil {
+			return nil, fmt.Errorf("attribute %d: %w", a.ID, err)
+		}
+		out.Attributes[i] = ra
+	}
+	for i, c := range rec.Calls {
+		rc := &RecordedCall{
+			ID:       c.ID,
+			Function: c.Function,
+			Overload: c.Overload,
+			Args:     make([]*RecordedValue, len(c.Args)),
+		}
+		for j, arg := range c.Args {
+			if rc.Args[j], err = recordValue(arg); err != nil {
+				return nil, fmt.Errorf("call to %s: %w", c.Function, err)
+			}
+		}
+		if rc.Result, err = recordValue(c.Result); err != nil {
+			return nil, fmt.Errorf("call to %s: %w", c.Function, err)
+		}
+		out.Calls[i] = rc
+	}
+	if ed.recordedResult != nil {
+		if out.Result, err = recordValue(ed.recordedResult); err != nil {
+			return nil, fmt.Errorf("result: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// NewReplayActivation creates an Activation which resolves variables from the record and replays
+// the recorded results of custom function calls when evaluated by a program configured with
+// RecordEval. Divergences between the evaluation and the record are reported by the activation.
+func NewReplayActivation(adapter types.Adapter, rec *EvalRecord) (*interpreter.ReplayActivation, error) {
+	if rec == nil {
+		return nil, errors.New("eval record must be non-nil")
+	}
+	out := &interpreter.EvalRecording{
+		Variables:  make([]*interpreter.RecordedVariable, len(rec.Variables)),
+		Attributes: make([]*interpreter.RecordedAttribute, len(rec.Attributes)),
+		Calls:      make([]*interpreter.RecordedCall, len(rec.Calls)),
+	}
+	var err error
+	for i, v := range rec.Variables {
+		rv := &interpreter.RecordedVariable{Name: v.Name, Found: v.Found}
+		if v.Found {
+			if rv.Value, err = replayValue(adapter, v.Value); err != nil {
+				return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+			}
+		}
+		out.Variables[i] = rv
+	}
+	for i, a := range rec.Attributes {
+		ra := &interpreter.RecordedAttribute{ID: a.ID}
+		if ra.Value, err = replayValue(adapter, a.Value); err != nil {
+			return nil, fmt.Errorf("attribute %d: %w", a.ID, err)
+		}
+		out.Attributes[i] = ra
+	}
+	for i, c := range rec.Calls {
+		rc := &interpreter.RecordedCall{
+			ID:       c.ID,
+			Function: c.Function,
+			Overload: c.Overload,
+			Args:     make([]ref.Val, len(c.Args)),
+		}
+		for j, arg := range c.Args {
+			if rc.Args[j], err = replayValue(adapter, arg); err != nil {
+				return nil, fmt.Errorf("call to %s: %w", c.Function, err)
+			}
+		}
+		if rc.Result, err = replayValue(adapter, c.Result); err != nil {
+			return nil, fmt.Errorf("call to %s: %w", c.Function, err)
+		}
+		out.Calls[i] = rc
+	}
+	return interpreter.NewReplayActivation(out), nil
+}
+
+// Replay evaluates the program using only the values in the record, and returns the output of the
+// evaluation along with any divergences from the record, including a difference in the result, and
+// the error produced by the evaluation, if any.
+//
+// The program must be configured with the RecordEval option.
+func Replay(prg Program, rec *EvalRecord) (ref.Val, []*Divergence, error) {
+	p, ok := prg.(*prog)
+	if !ok || !p.recordEval {
+		return nil, nil, errors.New("replay requires a program configured with RecordEval")
+	}
+	vars, err := NewReplayActivation(p.CELTypeAdapter(), rec)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, _, evalErr := p.Eval(vars)
+	divergences := vars.Divergences()
+	if out != nil && rec.Result != nil {
+		want, err := replayValue(p.CELTypeAdapter(), rec.Result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("result: %w", err)
+		}
+		if !recordedValueEqual(want, out) {
+			divergences = append(divergences, &Divergence{
+				Message: fmt.Sprintf("result got %v, recorded %v", out, want)})
+		}
+	}
+	return out, divergences, evalErr
+}
+
+// isRecordedFunction indicates whether calls to the function are recorded, which is true for all
+// functions outside of the standard library and the optional types library.
+func isRecordedFunction(function string) bool {
+	_, found := unrecordedFunctionNames()[function]
+	return !found
+}
+
+var unrecordedFunctionNames = sync.OnceValue(func() map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, fn := range stdlib.Functions() {
+		names[fn.Name()] = struct{}{}
+	}
+	// The optional types library functions accept or produce optional values, which cannot be
+	// recorded.
+	for _, fn := range []string{optionalOfFunc, optionalOfNonZeroValueFunc, optionalNoneFunc,
+		valueFunc, hasValueFunc, "or", "orValue", "first", "last", optionalUnwrapFunc, unwrapOptFunc} {
+		names[fn] = struct{}{}
+	}
+	return names
+})
+
+func recordValue(val ref.Val) (*RecordedValue, error) {
+	// Optional values would otherwise be recorded as their underlying value.
+	if _, isOpt := val.(*types.Optional); isOpt {
+		return nil, fmt.Errorf("optional value %v cannot be recorded", val)
+	}
+	pb, err := ExprValueAsProto(val)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordedValue{Value: pb}, nil
+}
+
+func replayValue(adapter types.Adapter, val *RecordedValue) (ref.Val, error) {
+	if val == nil || val.Value == nil {
+		return nil, errors.New("missing recorded value")
+	}
+	switch kind := val.Value.GetKind().(type) {
+	case *celpb.ExprValue_Value:
+		return ProtoAsValue(adapter, kind.Value)
+	case *celpb.ExprValue_Error:
+		msgs := make([]string, len(kind.Error.GetErrors()))
+		for i, status := range kind.Error.GetErrors() {
+			msgs[i] = status.GetMessage()
+		}
+		return types.NewErrFromString(strings.Join(msgs, "\n")), nil
+	case *celpb.ExprValue_Unknown:
+		var unk *types.Unknown
+		for _, id := range kind.Unknown.GetExprs() {
+			unk = types.MergeUnknowns(unk, types.NewUnknown(id, nil))
+		}
+		if unk == nil {
+			return nil, errors.New("recorded unknown set is empty")
+		}
+		return unk, nil
+	}
+	return nil, fmt.Errorf("unsupported recorded value: %v", val.Value)
+}
+
+// recordedValueEqual compares a replayed value with a recorded value. Errors are compared by their
+// messages and unknown sets by the expression ids they contain.
+func recordedValueEqual(recorded, val ref.Val) bool {
+	switch v := val.(type) {
+	case *types.Err:
+		r, ok := recorded.(*types.Err)
+		return ok && r.Error() == v.Error()
+	case *types.Unknown:
+		r, ok := recorded.(*types.Unknown)
+		return ok && fmt.Sprint(r.IDs()) == fmt.Sprint(v.IDs())
+	}
+	if types.IsUnknownOrError(recorded) {
+		return false
+	}
+	return recorded.Equal(val) == types.True
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestRecordEvalReplay(t *testing.T) {
+	var calls int
+	env, err := NewEnv(
+		Variable("user", MapType(StringType, DynType)),
+		Variable("limit", IntType),
+		Function("quota",
+			Overload("quota_string", []*Type{StringType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					calls++
+					return types.Int(len(arg.(types.String)))
+				}),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`quota(user.name) < limit && user.tags.exists(t, t.startsWith('a'))`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, RecordEval())
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, det, err := prg.Eval(map[string]any{
+		"user":  map[string]any{"name": "alice", "tags": []string{"b", "admin"}},
+		"limit": 10,
+	})
+	if err != nil || out != types.True {
+		t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+	rec, err := det.EvalRecord()
+	if err != nil {
+		t.Fatalf("det.EvalRecord() failed: %v", err)
+	}
+	if len(rec.Variables) != 2 || len(rec.Calls) != 1 || rec.Calls[0].Function != "quota" {
+		t.Fatalf("det.EvalRecord() got %d variables and calls %v, wanted 2 variables and a call to quota",
+			len(rec.Variables), rec.Calls)
+	}
+
+	// The record survives a round trip through JSON.
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	var decoded EvalRecord
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal(%s) failed: %v", data, err)
+	}
+	out, divergences, err := Replay(prg, &decoded)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if out != types.True || len(divergences) != 0 {
+		t.Errorf("Replay() got %v, %v, wanted true without divergences", out, divergences)
+	}
+	if calls != 1 {
+		t.Errorf("Replay() invoked quota, got %d calls, wanted 1", calls)
+	}
+
+	// A change to the recorded inputs is flagged as a divergence.
+	for _, v := range decoded.Variables {
+		if v.Name == "limit" {
+			v.Value, err = recordValue(types.Int(2))
+			if err != nil {
+				t.Fatalf("recordValue() failed: %v", err)
+			}
+		}
+	}
+	out, divergences, err = Replay(prg, &decoded)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if out != types.False {
+		t.Errorf("Replay() got %v, wanted false", out)
+	}
+	var msgs []string
+	for _, d := range divergences {
+		msgs = append(msgs, d.String())
+	}
+	if !strings.Contains(strings.Join(msgs, "\n"), "result got false, recorded true") {
+		t.Errorf("Replay() got divergences %v, wanted a divergent result", msgs)
+	}
+
+	// Programs which do not record evaluations cannot be replayed.
+	plain, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, _, err := Replay(plain, &decoded); err == nil {
+		t.Error("Replay() of a program without RecordEval succeeded, wanted error")
+	}
+}
+
+func TestRecordEvalErrors(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		Function("fail",
+			Overload("fail_int", []*Type{IntType}, IntType,
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.NewErr("failed on %v", arg)
+				}),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`fail(x) == 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, RecordEval())
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err := prg.Eval(map[string]any{"x": 1})
+	if err == nil {
+		t.Fatal("prg.Eval() succeeded, wanted error")
+	}
+	rec, err := det.EvalRecord()
+	if err != nil {
+		t.Fatalf("det.EvalRecord() failed: %v", err)
+	}
+	out, divergences, err := Replay(prg, rec)
+	if err == nil || err.Error() != "failed on 1" {
+		t.Errorf("Replay() got error %v, wanted the evaluation error", err)
+	}
+	if !types.IsError(out) || out.(*types.Err).Error() != "failed on 1" || len(divergences) != 0 {
+		t.Errorf("Replay() got %v, %v, wanted the recorded error without divergences", out, divergences)
+	}
+}
+
+func TestRecordEvalOptionals(t *testing.T) {
+	var calls int
+	env, err := NewEnv(
+		OptionalTypes(),
+		Variable("m", MapType(StringType, IntType)),
+		Function("zero",
+			Overload("zero", []*Type{}, IntType,
+				FunctionBinding(func(args ...ref.Val) ref.Val {
+					calls++
+					return types.IntZero
+				}),
+			),
+		),
+		Function("maybe",
+			Overload("maybe_int", []*Type{IntType}, OptionalType(IntType),
+				UnaryBinding(func(arg ref.Val) ref.Val {
+					return types.OptionalOf(arg)
+				}),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr string
+		out  ref.Val
+	}{
+		{expr: `zero() == 0`, out: types.True},
+		{expr: `optional.none().hasValue()`, out: types.False},
+		{expr: `optional.of(1).orValue(2)`, out: types.Int(1)},
+		{expr: `m[?'a'].orValue(zero())`, out: types.Int(1)},
+		{expr: `m.?b.orValue(zero())`, out: types.IntZero},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			prg, err := env.Program(ast, RecordEval())
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, det, err := prg.Eval(map[string]any{"m": map[string]int{"a": 1}})
+			if err != nil || out.Equal(tc.out) != types.True {
+				t.Fatalf("prg.Eval() got %v, %v, wanted %v", out, err, tc.out)
+			}
+			rec, err := det.EvalRecord()
+			if err != nil {
+				t.Fatalf("det.EvalRecord() failed: %v", err)
+			}
+			calls = 0
+			out, divergences, err := Replay(prg, rec)
+			if err != nil {
+				t.Fatalf("Replay() failed: %v", err)
+			}
+			if out.Equal(tc.out) != types.True || len(divergences) != 0 {
+				t.Errorf("Replay() got %v, %v, wanted %v without divergences", out, divergences, tc.out)
+			}
+			if calls != 0 {
+				t.Errorf("Replay() invoked zero %d times, wanted none", calls)
+			}
+		})
+	}
+
+	// Custom functions which return optional values cannot be recorded.
+	ast, iss := env.Compile(`maybe(1).hasValue()`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast, RecordEval())
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err := prg.Eval(NoVars())
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	if _, err := det.EvalRecord(); err == nil {
+		t.Error("det.EvalRecord() succeeded, wanted an error for the optional result of maybe")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/google/cel-go/common/ast"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
 	"github.com/google/cel-go/common/types"
@@ -416,3 +417,96 @@ func TestValidatorConfig(t *testing.T) {
 		t.Error("config.Set() with incorrect value type did not fail")
 	}
 }
+
+func TestValidatorSeverities(t *testing.T) {
+	env, err := NewEnv(
+		Variable("x", IntType),
+		ASTValidators(severityValidator{}),
+	)
+	if err != nil {
+		t.Fatalf("NewEnv() failed: %v", err)
+	}
+	tests := []struct {
+		expr  string
+		iss   string
+		isErr bool
+	}{
+		{
+			expr: `x == 1`,
+		},
+		{
+			expr: `x == 2`,
+			iss: `WARNING: <input>:1:3: comparison against 2
+			| x == 2
+			| ..^`,
+		},
+		{
+			expr: `x == 3`,
+			iss: `INFO: <input>:1:3: comparison against 3
+			| x == 3
+			| ..^`,
+		},
+		{
+			expr: `x == 2 || x == 4`,
+			iss: `WARNING: <input>:1:3: comparison against 2
+			| x == 2 || x == 4
+			| ..^
+			ERROR: <input>:1:13: comparison against 4
+			| x == 2 || x == 4
+			| ............^`,
+			isErr: true,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			ast, iss := env.Compile(tc.expr)
+			if tc.isErr {
+				if iss.Err() == nil {
+					t.Fatalf("e.Compile(%v) returned ast, expected error: %v", tc.expr, tc.iss)
+				}
+				if !test.Compare(iss.Err().Error(), tc.iss) {
+					t.Fatalf("e.Compile(%v) returned %v, expected error: %v", tc.expr, iss.Err(), tc.iss)
+				}
+				return
+			}
+			if iss.Err() != nil {
+				t.Fatalf("e.Compile(%v) failed: %v", tc.expr, iss.Err())
+			}
+			if ast == nil {
+				t.Fatalf("e.Compile(%v) returned nil ast", tc.expr)
+			}
+			if tc.iss == "" {
+				if iss != nil {
+					t.Errorf("e.Compile(%v) returned issues %v, wanted none", tc.expr, iss)
+				}
+				return
+			}
+			if !test.Compare(iss.String(), tc.iss) {
+				t.Errorf("e.Compile(%v) returned issues %v, wanted %v", tc.expr, iss, tc.iss)
+			}
+		})
+	}
+}
+
+type severityValidator struct{}
+
+func (severityValidator) Name() string {
+	return "severities"
+}
+
+func (severityValidator) Validate(_ *Env, _ ValidatorConfig, a *ast.AST, iss *Issues) {
+	root := ast.NavigateAST(a)
+	consts := ast.MatchDescendants(root, ast.KindMatcher(ast.LiteralKind))
+	for _, c := range consts {
+		call, _ := c.Parent()
+		switch c.AsLiteral() {
+		case types.Int(2):
+			iss.ReportWarningAtID(call.ID(), "comparison against 2")
+		case types.Int(3):
+			iss.ReportInfoAtID(call.ID(), "comparison against 3")
+		case types.Int(4):
+			iss.ReportErrorAtID(call.ID(), "comparison against 4")
+		}
+	}
+}
@@ -38,6 +38,9 @@ type checker struct {
 	errors             *typeErrors
 	mappings           *mapping
 	freeTypeVarCounter int
+	typeNarrowing      bool
+	narrowing          *narrowingScope
+	unsoundTests       map[int64]struct{}
 }
 
 // Check performs type checking, giving a typed AST.
@@ -59,6 +62,7 @@ func Check(parsed *ast.AST, source common.Source, env *Env) (*ast.AST, *common.E
 		errors:             &typeErrors{errs: errs},
 		mappings:           newMapping(),
 		freeTypeVarCounter: 0,
+		typeNarrowing:      env.typeNarrowing,
 	}
 	c.check(c.Expr())
 
@@ -120,10 +124,10 @@ func (c *checker) checkIdent(e ast.Expr) {
 		if ident.requiresDisambiguation {
 			name = "." + name
 		}
-		c.setType(e, ident.Type())
-		c.setReference(e, ast.NewIdentReference(name, ident.Value()))
 		// Overwrite the identifier with its fully qualified name.
 		e.SetKindCase(c.NewIdent(e.ID(), name))
+		c.setType(e, c.narrowedType(e, ident.Type()))
+		c.setReference(e, ast.NewIdentReference(name, ident.Value()))
 		return
 	}
 
@@ -147,9 +151,9 @@ func (c *checker) checkSelect(e ast.Expr) {
 			if ident.requiresDisambiguation {
 				name = "." + name
 			}
-			c.setType(e, ident.Type())
-			c.setReference(e, ast.NewIdentReference(name, ident.Value()))
 			e.SetKindCase(c.NewIdent(e.ID(), name))
+			c.setType(e, c.narrowedType(e, ident.Type()))
+			c.setReference(e, ast.NewIdentReference(name, ident.Value()))
 			return
 		}
 	}
@@ -158,7 +162,7 @@ func (c *checker) checkSelect(e ast.Expr) {
 	if sel.IsTestOnly() {
 		resultType = types.BoolType
 	}
-	c.setType(e, substitute(c.mappings, resultType, false))
+	c.setType(e, c.narrowedType(e, substitute(c.mappings, resultType, false)))
 }
 
 // computeQualifiers computes the qualified names parts of a select expression.
@@ -220,6 +224,18 @@ func (c *checker) checkSelectField(e, operand ast.Expr, field string, optional b
 	// If the target type is 'optional', unwrap it for the sake of this check.
 	targetType, isOpt := maybeUnwrapOptional(operandType)
 
+	resultType := c.selectFieldType(e, targetType, field)
+
+	// If the target type was optional coming in, then the result must be optional going out.
+	if isOpt || optional {
+		return types.NewOptionalType(resultType)
+	}
+	return resultType
+}
+
+// selectFieldType returns the type of the field selected from the target type, reporting an error
+// if the target type does not support field selection.
+func (c *checker) selectFieldType(e ast.Expr, targetType *types.Type, field string) *types.Type {
 	// Assume error type by default as most types do not support field selection.
 	resultType := types.ErrorType
 	switch targetType.Kind() {
@@ -240,6 +256,17 @@ func (c *checker) checkSelectField(e, operand ast.Expr, field string, optional b
 		c.isAssignable(types.DynType, targetType)
 		// Also, set the result type to DYN.
 		resultType = types.DynType
+	case types.UnionKind:
+		// Unions yield the union of the selection result types of their members.
+		fieldTypes := make([]*types.Type, 0, len(targetType.Parameters()))
+		for _, member := range targetType.Parameters() {
+			fieldType := c.selectFieldType(e, member, field)
+			if isError(fieldType) {
+				return types.ErrorType
+			}
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+		resultType = types.NewUnionType(fieldTypes...)
 	default:
 		// Dynamic / error values are treated as DYN type. Errors are handled this way as well
 		// in order to allow forward progress on the check.
@@ -248,11 +275,6 @@ func (c *checker) checkSelectField(e, operand ast.Expr, field string, optional b
 		}
 		resultType = types.DynType
 	}
-
-	// If the target type was optional coming in, then the result must be optional going out.
-	if isOpt || optional {
-		return types.NewOptionalType(resultType)
-	}
 	return resultType
 }
 
@@ -268,9 +290,13 @@ func (c *checker) checkCall(e ast.Expr) {
 	}
 
 	args := call.Args()
-	// Traverse arguments.
-	for _, arg := range args {
-		c.check(arg)
+	// Traverse arguments, narrowing the types of references within guarded arguments.
+	if c.typeNarrowing && !call.IsMemberFunction() && isNarrowingOperator(fnName) {
+		c.checkNarrowedArgs(fnName, args)
+	} else {
+		for _, arg := range args {
+			c.check(arg)
+		}
 	}
 
 	// Regular static call with simple name.
@@ -349,6 +375,8 @@ func (c *checker) resolveOverload(
 
 	var resultType *types.Type
 	var checkedRef *ast.ReferenceInfo
+	var candidates []string
+	var violation *typeParamViolation
 	for _, overload := range fn.OverloadDecls() {
 		// Determine whether the overload is currently considered.
 		if c.env.isOverloadDisabled(overload.ID()) {
@@ -361,6 +389,7 @@ func (c *checker) resolveOverload(
 			// not a compatible call style.
 			continue
 		}
+		candidates = append(candidates, overload.ID())
 
 		// Alternative type-checking behavior when the logical operators are compacted into
 		// variadic AST representations.
@@ -382,19 +411,19 @@ func (c *checker) resolveOverload(
 			return newResolution(checkedRef, types.BoolType)
 		}
 
-		overloadType := newFunctionType(overload.ResultType(), overload.ArgTypes()...)
-		typeParams := overload.TypeParams()
-		if len(typeParams) != 0 {
-			// Instantiate overload's type with fresh type variables.
-			substitutions := newMapping()
-			for _, typePar := range typeParams {
-				substitutions.add(types.NewTypeParamType(typePar), c.newTypeVar())
-			}
-			overloadType = substitute(substitutions, overloadType, false)
+		overloadType := c.instantiateOverload(overload)
+		constrained := map[string]*types.Type{}
+		for _, t := range overload.ArgTypes() {
+			collectConstrainedTypeParams(constrained, t)
 		}
+		collectConstrainedTypeParams(constrained, overload.ResultType())
 
 		candidateArgTypes := overloadType.Parameters()[1:]
-		if c.isAssignableList(argTypes, candidateArgTypes) {
+		if !c.isAssignableList(argTypes, candidateArgTypes) {
+			if violation == nil && len(constrained) != 0 {
+				violation = c.findTypeParamViolation(overload, argTypes, constrained)
+			}
+		} else {
 			if checkedRef == nil {
 				checkedRef = ast.NewFunctionReference(overload.ID())
 			} else {
@@ -411,17 +440,107 @@ func (c *checker) resolveOverload(
 		}
 	}
 
+	if resultType == nil {
+		checkedRef, resultType = c.resolveUnionOverload(fn, target != nil, argTypes)
+	}
+	if resultType == nil && violation != nil {
+		c.errors.typeParamConstraint(call.ID(), c.location(call), fn.Name(), violation.overload,
+			violation.param, violation.actual)
+		return nil
+	}
 	if resultType == nil {
 		for i, argType := range argTypes {
 			argTypes[i] = substitute(c.mappings, argType, true)
 		}
-		c.errors.noMatchingOverload(call.ID(), c.location(call), fn.Name(), argTypes, target != nil)
+		c.errors.noMatchingOverload(call.ID(), c.location(call), fn.Name(), argTypes, target != nil, candidates)
 		return nil
 	}
 
 	return newResolution(checkedRef, resultType)
 }
 
+// resolveUnionOverload matches arguments of union type against the overloads member by member.
+//
+// The call resolves when every combination of the union members matches at least one overload,
+// and the result type is the union of the result types of the matching overloads.
+func (c *checker) resolveUnionOverload(fn *decls.FunctionDecl, isMember bool,
+	argTypes []*types.Type) (*ast.ReferenceInfo, *types.Type) {
+	combinations := unionArgCombinations(c.mappings, argTypes)
+	if len(combinations) == 0 {
+		return nil, nil
+	}
+	mappings := c.mappings.copy()
+	covered := make([]bool, len(combinations))
+	var checkedRef *ast.ReferenceInfo
+	var resultTypes []*types.Type
+	for i, combination := range combinations {
+		for _, overload := range fn.OverloadDecls() {
+			if c.env.isOverloadDisabled(overload.ID()) || overload.IsMemberFunction() != isMember {
+				continue
+			}
+			// Each combination is matched against a fresh instance of the overload type so
+			// the type parameters may be bound differently for each member.
+			overloadType := c.instantiateOverload(overload)
+			if !c.isAssignableList(combination, overloadType.Parameters()[1:]) {
+				continue
+			}
+			covered[i] = true
+			if checkedRef == nil {
+				checkedRef = ast.NewFunctionReference(overload.ID())
+			} else if !slices.Contains(checkedRef.OverloadIDs, overload.ID()) {
+				checkedRef.AddOverload(overload.ID())
+			}
+			resultTypes = append(resultTypes, substitute(c.mappings, overloadType.Parameters()[0], false))
+		}
+	}
+	if slices.Contains(covered, false) {
+		c.mappings = mappings
+		return nil, nil
+	}
+	return checkedRef, types.NewUnionType(resultTypes...)
+}
+
+// instantiateOverload returns the function type of the overload with its type parameters replaced
+// by fresh type variables which retain the constraints of the declared type parameters.
+func (c *checker) instantiateOverload(overload *decls.OverloadDecl) *types.Type {
+	overloadType := newFunctionType(overload.ResultType(), overload.ArgTypes()...)
+	typeParams := overload.TypeParams()
+	if len(typeParams) == 0 {
+		return overloadType
+	}
+	constrained := map[string]*types.Type{}
+	for _, t := range overloadType.Parameters() {
+		collectConstrainedTypeParams(constrained, t)
+	}
+	substitutions := newMapping()
+	for _, typePar := range typeParams {
+		substitutions.add(types.NewTypeParamType(typePar), c.newConstrainedTypeVar(constrained[typePar]))
+	}
+	return substitute(substitutions, overloadType, false)
+}
+
+// unionArgCombinations returns each combination of the union members of the argument types, or
+// nil if none of the arguments has a union type.
+func unionArgCombinations(m *mapping, argTypes []*types.Type) [][]*types.Type {
+	hasUnion := false
+	combinations := [][]*types.Type{{}}
+	for _, argType := range argTypes {
+		members := substitute(m, argType, false).UnionMembers()
+		hasUnion = hasUnion || len(members) > 1
+		next := make([][]*types.Type, 0, len(combinations)*len(members))
+		for _, combination := range combinations {
+			for _, member := range members {
+				next = append(next, append(slices.Clip(combination), member))
+			}
+		}
+		combinations = next
+	}
+	if !hasUnion {
+		return nil
+	}
+	return combinations
+}
+
 func (c *checker) checkCreateList(e ast.Expr) {
 	create := e.AsList()
 	var elemsType *types.Type
@@ -539,7 +658,7 @@ func (c *checker) checkCreateStruct(e ast.Expr) {
 				c.errors.typeMismatch(value.ID(), c.location(value), types.NewOptionalType(valType), valType)
 			}
 		}
-		if !c.isAssignable(fieldType, valType) {
+		if !c.isAssignableValue(valType, fieldType) {
 			c.errors.fieldTypeMismatch(f.ID(), c.locationByID(f.ID()), fieldName, fieldType, valType)
 		}
 	}
@@ -555,6 +674,7 @@ func (c *checker) checkComprehension(e ast.Expr) {
 	// This scope will contain the accumulation variable used to compute the result.
 	accuType := c.getType(comp.AccuInit())
 	c.env = c.env.enterScope()
+	c.enterNarrowingMask(comp.AccuVar(), comp.IterVar(), comp.IterVar2())
 	c.env.AddIdents(decls.NewVariable(comp.AccuVar(), accuType))
 
 	var varType, var2Type *types.Type
@@ -608,6 +728,7 @@ func (c *checker) checkComprehension(e ast.Expr) {
 	c.env = c.env.exitScope()
 	c.check(comp.Result())
 	// Exit the comprehension scope.
+	c.exitNarrowingMask()
 	c.env = c.env.exitScope()
 	c.setType(e, substitute(c.mappings, c.getType(comp.Result()), false))
 }
@@ -620,6 +741,10 @@ func (c *checker) joinTypes(e ast.Expr, previous, current *types.Type) *types.Ty
 	if c.isAssignable(previous, current) {
 		return mostGeneral(previous, current)
 	}
+	// Elements which are members of a union element type are joined into the union.
+	if previous.Kind() == types.UnionKind && c.isAssignable(current, previous) {
+		return previous
+	}
 	if c.dynAggregateLiteralElementTypesEnabled() {
 		return types.DynType
 	}
@@ -637,6 +762,54 @@ func (c *checker) newTypeVar() *types.Type {
 	return types.NewTypeParamType(fmt.Sprintf("_var%d", id))
 }
 
+// newConstrainedTypeVar returns a fresh type variable with the same bounds and required traits as
+// the type parameter, if any.
+func (c *checker) newConstrainedTypeVar(param *types.Type) *types.Type {
+	tv := c.newTypeVar()
+	if param == nil {
+		return tv
+	}
+	return types.NewTypeParamTypeWithBounds(tv.TypeName(), param.TypeParamBounds()...).
+		WithTraits(param.TypeParamTraits())
+}
+
+// typeParamViolation describes a type parameter bound to a type which does not satisfy the
+// parameter's constraint.
+type typeParamViolation struct {
+	overload string
+	param    *types.Type
+	actual   *types.Type
+}
+
+// findTypeParamViolation determines whether the argument types would match the overload if its
+// type parameters were unconstrained, and if so returns the first constraint which is violated.
+func (c *checker) findTypeParamViolation(overload *decls.OverloadDecl, argTypes []*types.Type,
+	constrained map[string]*types.Type) *typeParamViolation {
+	substitutions := newMapping()
+	typeVars := map[string]*types.Type{}
+	for _, typePar := range overload.TypeParams() {
+		typeVars[typePar] = c.newTypeVar()
+		substitutions.add(types.NewTypeParamType(typePar), typeVars[typePar])
+	}
+	candidateArgTypes := substitute(substitutions, newFunctionType(types.DynType, overload.ArgTypes()...), false).Parameters()[1:]
+	m := isAssignableList(c.mappings, argTypes, candidateArgTypes)
+	if m == nil {
+		return nil
+	}
+	names := make([]string, 0, len(constrained))
+	for name := range constrained {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		actual := substitute(m, typeVars[name], true)
+		if !satisfiesTypeParam(constrained[name], actual) {
+			return &typeParamViolation{overload: overload.ID(), param: constrained[name], actual: actual}
+		}
+	}
+	return nil
+}
+
 func (c *checker) isAssignable(t1, t2 *types.Type) bool {
 	subs := isAssignable(c.mappings, t1, t2)
 	if subs != nil {
@@ -647,6 +820,27 @@ func (c *checker) isAssignable(t1, t2 *types.Type) bool {
 	return false
 }
 
+// isAssignableValue returns whether a value of type valType may be used where a value of type
+// targetType is expected.
+//
+// Assignability is only directional when a union is involved, so the legacy argument order is
+// preserved for all other types.
+func (c *checker) isAssignableValue(valType, targetType *types.Type) bool {
+	if c.hasUnion(valType) || c.hasUnion(targetType) {
+		return c.isAssignable(valType, targetType)
+	}
+	return c.isAssignable(targetType, valType)
+}
+
+// hasUnion returns whether the type, or any of its type parameters, resolves to a union type.
+func (c *checker) hasUnion(t *types.Type) bool {
+	t = substitute(c.mappings, t, false)
+	if t.Kind() == types.UnionKind {
+		return true
+	}
+	return slices.ContainsFunc(t.Parameters(), c.hasUnion)
+}
+
 func (c *checker) isAssignableList(l1, l2 []*types.Type) bool {
 	subs := isAssignableList(c.mappings, l1, l2)
 	if subs != nil {
@@ -690,7 +884,7 @@ func (c *checker) setReference(e ast.Expr, r *ast.ReferenceInfo) {
 }
 
 func (c *checker) assertType(e ast.Expr, t *types.Type) {
-	if !c.isAssignable(t, c.getType(e)) {
+	if !c.isAssignableValue(c.getType(e), t) {
 		c.errors.typeMismatch(e.ID(), c.location(e), t, c.getType(e))
 	}
 }
@@ -724,12 +918,12 @@ func (c *checker) lookupFieldType(exprID int64, structType, fieldName string) (*
 
 	if ft, found := c.env.provider.FindStructFieldType(structType, fieldName); found {
 		if c.env.jsonFieldNames && !ft.IsJSONField {
-			c.errors.undefinedField(exprID, c.locationByID(exprID), fieldName)
+			c.errors.undefinedField(exprID, c.locationByID(exprID), structType, fieldName)
 		}
 		return ft.Type, found
 	}
 
-	c.errors.undefinedField(exprID, c.locationByID(exprID), fieldName)
+	c.errors.undefinedField(exprID, c.locationByID(exprID), structType, fieldName)
 	return nil, false
 }
 
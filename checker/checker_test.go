@@ -16,6 +16,7 @@ package checker
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/google/cel-go/common/decls"
 	"github.com/google/cel-go/common/stdlib"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/traits"
 	"github.com/google/cel-go/parser"
 	"github.com/google/cel-go/test"
 
@@ -1576,6 +1578,157 @@ _&&_(_==_(list~type(list(dyn))^list,
 			},
 			outType: types.BoolType,
 		},
+		{
+			in: `sum([1, 2])`,
+			out: `
+		sum(
+		  [
+		    1~int,
+		    2~int
+		  ]~list(int)
+		)~int^sum_list`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "sum",
+						decls.Overload("sum_list",
+							[]*types.Type{types.NewListType(numericTypeParam)},
+							numericTypeParam)),
+				},
+			},
+			outType: types.IntType,
+		},
+		{
+			in: `sum(['a'])`,
+			err: `
+		ERROR: <input>:1:4: type parameter 'T' of overload 'sum_list' requires one of int, uint, double, but found 'string'
+		  | sum(['a'])
+		  | ...^`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "sum",
+						decls.Overload("sum_list",
+							[]*types.Type{types.NewListType(numericTypeParam)},
+							numericTypeParam)),
+				},
+			},
+		},
+		{
+			in: `sum(x)`,
+			out: `
+		sum(
+		  x~dyn^x
+		)~dyn^sum_list`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.DynType),
+				},
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "sum",
+						decls.Overload("sum_list",
+							[]*types.Type{types.NewListType(numericTypeParam)},
+							numericTypeParam)),
+				},
+			},
+			outType: types.DynType,
+		},
+		{
+			in: `greatest('a', 'b')`,
+			out: `
+		greatest(
+		  "a"~string,
+		  "b"~string
+		)~string^greatest_T_T`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "greatest",
+						decls.Overload("greatest_T_T",
+							[]*types.Type{comparableTypeParam, comparableTypeParam},
+							comparableTypeParam)),
+				},
+			},
+			outType: types.StringType,
+		},
+		{
+			in: `greatest([1], [2])`,
+			err: `
+		ERROR: <input>:1:9: type parameter 'T' of overload 'greatest_T_T' requires traits comparer, but found 'list(int)'
+		  | greatest([1], [2])
+		  | ........^`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "greatest",
+						decls.Overload("greatest_T_T",
+							[]*types.Type{comparableTypeParam, comparableTypeParam},
+							comparableTypeParam)),
+				},
+			},
+		},
+		{
+			in: `sum([]) + 'a'`,
+			err: `
+		ERROR: <input>:1:9: found no matching overload for '_+_' applied to '(dyn, string)'
+		  | sum([]) + 'a'
+		  | ........^`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "sum",
+						decls.Overload("sum_list",
+							[]*types.Type{types.NewListType(numericTypeParam)},
+							numericTypeParam)),
+				},
+			},
+		},
+		{
+			in: `sum([]) + 1`,
+			out: `
+		_+_(
+		  sum(
+		    []~list(int)
+		  )~int^sum_list,
+		  1~int
+		)~int^add_int64`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "sum",
+						decls.Overload("sum_list",
+							[]*types.Type{types.NewListType(numericTypeParam)},
+							numericTypeParam)),
+				},
+			},
+			outType: types.IntType,
+		},
+		{
+			in: `greatest(1, 2)`,
+			out: `
+		greatest(
+		  1~int,
+		  2~int
+		)~int^greatest_T_T`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "greatest",
+						decls.Overload("greatest_T_T",
+							[]*types.Type{orderedTypeParam, orderedTypeParam},
+							orderedTypeParam)),
+				},
+			},
+			outType: types.IntType,
+		},
+		{
+			in: `greatest([1], [2])`,
+			err: `
+		ERROR: <input>:1:9: type parameter 'T' of overload 'greatest_T_T' requires one of int, list(int) with traits comparer, but found 'list(int)'
+		  | greatest([1], [2])
+		  | ........^`,
+			env: testEnv{
+				functions: []*decls.FunctionDecl{
+					testFunction(t, "greatest",
+						decls.Overload("greatest_T_T",
+							[]*types.Type{orderedTypeParam, orderedTypeParam},
+							orderedTypeParam)),
+				},
+			},
+		},
 		{
 			in: `set([1, 2]) == x`,
 			out: `
@@ -1853,6 +2006,267 @@ _&&_(_==_(list~type(list(dyn))^list,
 			)~bool^greater_equals_uint64_int64
 		  )~bool^logical_and`,
 		},
+		{
+			in:   `type(x) == int && x + 1 > 5`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("x", types.DynType)},
+			},
+			outType: types.BoolType,
+			out: `
+		_&&_(
+		  _==_(
+		    type(
+		      x~dyn^x
+		    )~type(dyn)^type,
+		    int~type(int)^int
+		  )~bool^equals,
+		  _>_(
+		    _+_(
+		      x~int^x,
+		      1~int
+		    )~int^add_int64,
+		    5~int
+		  )~bool^greater_int64
+		)~bool^logical_and`,
+		},
+		{
+			in:   `type(x) == string ? x.size() : 0`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("x", types.DynType)},
+			},
+			outType: types.IntType,
+			out: `
+		_?_:_(
+		  _==_(
+		    type(
+		      x~dyn^x
+		    )~type(dyn)^type,
+		    string~type(string)^string
+		  )~bool^equals,
+		  x~string^x.size()~int^string_size,
+		  0~int
+		)~int^conditional`,
+		},
+		{
+			in:   `!(type(x) == string) || x.startsWith('a')`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("x", types.DynType)},
+			},
+			outType: types.BoolType,
+			out: `
+		_||_(
+		  !_(
+		    _==_(
+		      type(
+		        x~dyn^x
+		      )~type(dyn)^type,
+		      string~type(string)^string
+		    )~bool^equals
+		  )~bool^logical_not,
+		  x~string^x.startsWith(
+		    "a"~string
+		  )~bool^starts_with_string
+		)~bool^logical_or`,
+		},
+		{
+			in:   `has(x.single_int64_wrapper) && x.single_string_wrapper != null ? x.single_int64_wrapper + size(x.single_string_wrapper) : 0`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewObjectType("google.expr.proto3.test.TestAllTypes")),
+				},
+			},
+			outType: types.IntType,
+			out: `
+		_?_:_(
+		  _&&_(
+		    x~google.expr.proto3.test.TestAllTypes^x.single_int64_wrapper~test-only~~bool,
+		    _!=_(
+		      x~google.expr.proto3.test.TestAllTypes^x.single_string_wrapper~wrapper(string),
+		      null~null
+		    )~bool^not_equals
+		  )~bool^logical_and,
+		  _+_(
+		    x~google.expr.proto3.test.TestAllTypes^x.single_int64_wrapper~int,
+		    size(
+		      x~google.expr.proto3.test.TestAllTypes^x.single_string_wrapper~string
+		    )~int^size_string
+		  )~int^add_int64,
+		  0~int
+		)~int^conditional`,
+		},
+		{
+			in:   `xs.filter(e, type(e) == int)`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("xs", types.NewListType(types.DynType))},
+			},
+			outType: types.NewListType(types.IntType),
+		},
+		{
+			in:   `xs.map(e, type(e) == string, e + '!')`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("xs", types.NewListType(types.DynType))},
+			},
+			outType: types.NewListType(types.StringType),
+		},
+		{
+			in:   `type(x) == int && xs.all(x, x.startsWith('a'))`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.DynType),
+					decls.NewVariable("xs", types.NewListType(types.DynType)),
+				},
+			},
+			outType: types.BoolType,
+		},
+		{
+			in:   `type(ii) == string && ii > 1`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env:  testEnvs(t)["default"],
+			err: `
+		WARNING: <input>:1:10: unsound type narrowing: 'ii' of type 'int' is never of type 'string'
+		 | type(ii) == string && ii > 1
+		 | .........^`,
+		},
+		{
+			in:   `type(x) == int && x.startsWith('a')`,
+			opts: []Option{CrossTypeNumericComparisons(true), TypeNarrowing(true)},
+			env: testEnv{
+				idents: []*decls.VariableDecl{decls.NewVariable("x", types.DynType)},
+			},
+			err: `
+		ERROR: <input>:1:31: found no matching overload for 'startsWith' applied to 'int.(string)'
+		 | type(x) == int && x.startsWith('a')
+		 | ..............................^`,
+		},
+		{
+			in: `size(x)`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.StringType, types.NewListType(types.StringType))),
+				},
+			},
+			out: `size(
+				x~string | list(string)^x
+			)~int^size_list|size_string`,
+			outType: types.IntType,
+		},
+		{
+			in: `-x`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.DoubleType)),
+				},
+			},
+			out: `-_(
+				x~int | double^x
+			)~int | double^negate_double|negate_int64`,
+			outType: types.NewUnionType(types.IntType, types.DoubleType),
+		},
+		{
+			in: `x + x`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			err: `
+		ERROR: <input>:1:3: found no matching overload for '_+_' applied to '(int | string, int | string)'
+		 | x + x
+		 | ..^`,
+		},
+		{
+			in: `x == 'hello' || x == 1`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			outType: types.BoolType,
+		},
+		{
+			in: `'hello' == x && 1 == x && null != x`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			outType: types.BoolType,
+		},
+		{
+			in: `1.5 == x`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			err: `
+		ERROR: <input>:1:5: found no matching overload for '_==_' applied to '(double, int | string)'
+		 | 1.5 == x
+		 | ....^`,
+		},
+		{
+			in: `[1].all(e, x)`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.BoolType, types.IntType)),
+				},
+			},
+			err: `
+		ERROR: <input>:1:12: expected type 'bool' but found 'bool | int'
+		 | [1].all(e, x)
+		 | ...........^`,
+		},
+		{
+			in: `x.single_int64`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(
+						types.NewObjectType("google.expr.proto3.test.TestAllTypes"),
+						types.NewMapType(types.StringType, types.StringType))),
+				},
+			},
+			outType: types.NewUnionType(types.IntType, types.StringType),
+		},
+		{
+			in: `x.startsWith('a')`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.StringType, types.NewListType(types.StringType))),
+				},
+			},
+			err: `
+		ERROR: <input>:1:13: found no matching overload for 'startsWith' applied to 'string | list(string).(string)'
+		 | x.startsWith('a')
+		 | ............^`,
+		},
+		{
+			in: `[x, 'a'][0]`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			outType: types.NewUnionType(types.IntType, types.StringType),
+		},
+		{
+			in: `google.expr.proto3.test.TestAllTypes{single_int64: x}`,
+			env: testEnv{
+				idents: []*decls.VariableDecl{
+					decls.NewVariable("x", types.NewUnionType(types.IntType, types.StringType)),
+				},
+			},
+			err: `
+		ERROR: <input>:1:50: expected type of field 'single_int64' is 'int' but provided type is 'int | string'
+		 | google.expr.proto3.test.TestAllTypes{single_int64: x}
+		 | .................................................^`,
+		},
 		{
 			in:      `[1].map(x, [x, x]).map(x, [x, x])`,
 			outType: types.NewListType(types.NewListType(types.NewListType(types.IntType))),
@@ -2475,6 +2889,13 @@ func testEnvs(t testing.TB) map[string]testEnv {
 	}
 }
 
+var (
+	numericTypeParam    = types.NewTypeParamTypeWithBounds("T", types.IntType, types.UintType, types.DoubleType)
+	comparableTypeParam = types.NewTypeParamTypeWithTraits("T", traits.ComparerType)
+	orderedTypeParam    = types.NewTypeParamTypeWithBounds("T", types.IntType, types.NewListType(types.IntType)).
+				WithTraits(traits.ComparerType)
+)
+
 type testInfo struct {
 	// in contains the expression to be parsed.
 	in string
@@ -2805,6 +3226,98 @@ func TestCheckErrorData(t *testing.T) {
 	}
 }
 
+func TestCheckErrorCodes(t *testing.T) {
+	tests := []struct {
+		expr    string
+		code    common.ErrorCode
+		details map[string]any
+	}{
+		{
+			expr:    `a || true`,
+			code:    common.ErrorCodeUndeclaredReference,
+			details: map[string]any{"name": "a", "container": "google.expr.proto3.test"},
+		},
+		{
+			expr: `size(1)`,
+			code: common.ErrorCodeNoMatchingOverload,
+			details: map[string]any{
+				"function":            "size",
+				"argument_types":      []string{"int"},
+				"candidate_overloads": []string{"size_bytes", "size_list", "size_map", "size_string"},
+			},
+		},
+		{
+			expr:    `TestAllTypes{}.missing`,
+			code:    common.ErrorCodeFieldNotFound,
+			details: map[string]any{"field": "missing", "type": "google.expr.proto3.test.TestAllTypes"},
+		},
+		{
+			expr: `TestAllTypes{single_int64: 'a'}`,
+			code: common.ErrorCodeFieldTypeMismatch,
+			details: map[string]any{
+				"field":         "single_int64",
+				"expected_type": "int",
+				"actual_type":   "string",
+			},
+		},
+		{
+			expr:    `1.all(x, x)`,
+			code:    common.ErrorCodeInvalidComprehensionRange,
+			details: map[string]any{"type": "int"},
+		},
+		{
+			expr:    `1.field`,
+			code:    common.ErrorCodeFieldSelectionUnsupported,
+			details: map[string]any{"type": "int"},
+		},
+		{
+			expr:    `int{}`,
+			code:    common.ErrorCodeNotAMessageType,
+			details: map[string]any{"type_name": "int"},
+		},
+	}
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...))
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	reg, err := types.NewProtoRegistry(types.ProtoTypeDefs(&proto3pb.TestAllTypes{}))
+	if err != nil {
+		t.Fatalf("types.NewProtoRegistry() failed: %v", err)
+	}
+	cont, err := containers.NewContainer(containers.Name("google.expr.proto3.test"))
+	if err != nil {
+		t.Fatalf("containers.NewContainer() failed: %v", err)
+	}
+	env, err := NewEnv(cont, reg)
+	if err != nil {
+		t.Fatalf("NewEnv(cont, reg) failed: %v", err)
+	}
+	if err := env.AddFunctions(stdlib.Functions()...); err != nil {
+		t.Fatalf("env.AddFunctions() failed: %v", err)
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			src := common.NewTextSource(tc.expr)
+			parsed, iss := p.Parse(src)
+			if len(iss.GetErrors()) != 0 {
+				t.Fatalf("Parse(%q) failed: %v", tc.expr, iss.ToDisplayString())
+			}
+			_, iss = Check(parsed, src, env)
+			if len(iss.GetErrors()) == 0 {
+				t.Fatalf("Check(%q) succeeded, wanted error", tc.expr)
+			}
+			celErr := iss.GetErrors()[0]
+			if celErr.Code != tc.code {
+				t.Errorf("Check(%q) got code %q, wanted %q: %v", tc.expr, celErr.Code, tc.code, iss.ToDisplayString())
+			}
+			if !reflect.DeepEqual(celErr.Details, tc.details) {
+				t.Errorf("Check(%q) got details %v, wanted %v", tc.expr, celErr.Details, tc.details)
+			}
+		})
+	}
+}
+
 func TestCheckInvalidOptSelectMember(t *testing.T) {
 	fac := ast.NewExprFactory()
 	target := fac.NewStruct(1, "Foo", nil)
@@ -15,9 +15,13 @@
 package checker
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/ast"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/traits"
 )
 
 // typeErrors is a specialization of Errors.
@@ -26,67 +30,147 @@ type typeErrors struct {
 }
 
 func (e *typeErrors) fieldTypeMismatch(id int64, l common.Location, name string, field, value *types.Type) {
-	e.errs.ReportErrorAtID(id, l, "expected type of field '%s' is '%s' but provided type is '%s'",
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeFieldTypeMismatch,
+		map[string]any{
+			"field":         name,
+			"expected_type": FormatCELType(field),
+			"actual_type":   FormatCELType(value),
+		},
+		"expected type of field '%s' is '%s' but provided type is '%s'",
 		name, FormatCELType(field), FormatCELType(value))
 }
 
 func (e *typeErrors) incompatibleType(id int64, l common.Location, ex ast.Expr, prev, next *types.Type) {
-	e.errs.ReportErrorAtID(id, l,
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeIncompatibleType, nil,
 		"incompatible type already exists for expression: %v(%d) old:%v, new:%v", ex, ex.ID(), prev, next)
 }
 
-func (e *typeErrors) noMatchingOverload(id int64, l common.Location, name string, args []*types.Type, isInstance bool) {
+func (e *typeErrors) noMatchingOverload(id int64, l common.Location, name string, args []*types.Type, isInstance bool, candidates []string) {
 	signature := formatFunctionDeclType(nil, args, isInstance)
-	e.errs.ReportErrorAtID(id, l, "found no matching overload for '%s' applied to '%s'", name, signature)
+	argTypes := make([]string, len(args))
+	for i, arg := range args {
+		argTypes[i] = FormatCELType(arg)
+	}
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeNoMatchingOverload,
+		map[string]any{
+			"function":            name,
+			"argument_types":      argTypes,
+			"candidate_overloads": candidates,
+		},
+		"found no matching overload for '%s' applied to '%s'", name, signature)
+}
+
+func (e *typeErrors) typeParamConstraint(id int64, l common.Location, name, overload string, param, actual *types.Type) {
+	var constraints []string
+	if bounds := param.TypeParamBounds(); len(bounds) != 0 {
+		boundNames := make([]string, len(bounds))
+		for i, b := range bounds {
+			boundNames[i] = FormatCELType(b)
+		}
+		constraints = append(constraints, fmt.Sprintf("one of %s", strings.Join(boundNames, ", ")))
+	}
+	if traitMask := param.TypeParamTraits(); traitMask != 0 {
+		constraints = append(constraints, fmt.Sprintf("traits %s", strings.Join(traits.Names(traitMask), ", ")))
+	}
+	constraint := strings.Join(constraints, " with ")
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeTypeParamConstraint,
+		map[string]any{
+			"function":    name,
+			"overload":    overload,
+			"type_param":  param.TypeName(),
+			"actual_type": FormatCELType(actual),
+		},
+		"type parameter '%s' of overload '%s' requires %s, but found '%s'",
+		param.TypeName(), overload, constraint, FormatCELType(actual))
 }
 
 func (e *typeErrors) notAComprehensionRange(id int64, l common.Location, t *types.Type) {
-	e.errs.ReportErrorAtID(id, l, "expression of type '%s' cannot be range of a comprehension (must be list, map, or dynamic)",
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeInvalidComprehensionRange,
+		map[string]any{"type": FormatCELType(t)},
+		"expression of type '%s' cannot be range of a comprehension (must be list, map, or dynamic)",
 		FormatCELType(t))
 }
 
 func (e *typeErrors) notAnOptionalFieldSelectionCall(id int64, l common.Location, err string) {
-	e.errs.ReportErrorAtID(id, l, "unsupported optional field selection: %s", err)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeInvalidOptionalFieldSelection, nil,
+		"unsupported optional field selection: %s", err)
 }
 
 func (e *typeErrors) notAnOptionalFieldSelection(id int64, l common.Location, field ast.Expr) {
-	e.errs.ReportErrorAtID(id, l, "unsupported optional field selection: %v", field)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeInvalidOptionalFieldSelection, nil,
+		"unsupported optional field selection: %v", field)
 }
 
 func (e *typeErrors) notAType(id int64, l common.Location, typeName string) {
-	e.errs.ReportErrorAtID(id, l, "'%s' is not a type", typeName)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeNotAType,
+		map[string]any{"type_name": typeName},
+		"'%s' is not a type", typeName)
 }
 
 func (e *typeErrors) notAMessageType(id int64, l common.Location, typeName string) {
-	e.errs.ReportErrorAtID(id, l, "'%s' is not a message type", typeName)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeNotAMessageType,
+		map[string]any{"type_name": typeName},
+		"'%s' is not a message type", typeName)
 }
 
 func (e *typeErrors) referenceRedefinition(id int64, l common.Location, ex ast.Expr, prev, next *ast.ReferenceInfo) {
-	e.errs.ReportErrorAtID(id, l,
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeReferenceRedefinition, nil,
 		"reference already exists for expression: %v(%d) old:%v, new:%v", ex, ex.ID(), prev, next)
 }
 
 func (e *typeErrors) typeDoesNotSupportFieldSelection(id int64, l common.Location, t *types.Type) {
-	e.errs.ReportErrorAtID(id, l, "type '%s' does not support field selection", FormatCELType(t))
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeFieldSelectionUnsupported,
+		map[string]any{"type": FormatCELType(t)},
+		"type '%s' does not support field selection", FormatCELType(t))
 }
 
 func (e *typeErrors) typeMismatch(id int64, l common.Location, expected, actual *types.Type) {
-	e.errs.ReportErrorAtID(id, l, "expected type '%s' but found '%s'",
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeTypeMismatch,
+		map[string]any{
+			"expected_type": FormatCELType(expected),
+			"actual_type":   FormatCELType(actual),
+		},
+		"expected type '%s' but found '%s'",
 		FormatCELType(expected), FormatCELType(actual))
 }
 
-func (e *typeErrors) undefinedField(id int64, l common.Location, field string) {
-	e.errs.ReportErrorAtID(id, l, "undefined field '%s'", field)
+func (e *typeErrors) undefinedField(id int64, l common.Location, structType, field string) {
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeFieldNotFound,
+		map[string]any{"field": field, "type": structType},
+		"undefined field '%s'", field)
 }
 
 func (e *typeErrors) undeclaredReference(id int64, l common.Location, container string, name string) {
-	e.errs.ReportErrorAtID(id, l, "undeclared reference to '%s' (in container '%s')", name, container)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeUndeclaredReference,
+		map[string]any{"name": name, "container": container},
+		"undeclared reference to '%s' (in container '%s')", name, container)
 }
 
 func (e *typeErrors) unexpectedFailedResolution(id int64, l common.Location, typeName string) {
-	e.errs.ReportErrorAtID(id, l, "unexpected failed resolution of '%s'", typeName)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeUnexpectedResolutionFailure,
+		map[string]any{"type_name": typeName},
+		"unexpected failed resolution of '%s'", typeName)
 }
 
 func (e *typeErrors) unexpectedASTType(id int64, l common.Location, kind, typeName string) {
-	e.errs.ReportErrorAtID(id, l, "unexpected %s type: %v", kind, typeName)
+	e.errs.ReportErrorWithCode(id, l, common.ErrorCodeUnexpectedASTType, nil,
+		"unexpected %s type: %v", kind, typeName)
+}
+
+func (e *typeErrors) unsoundNarrowing(id int64, l common.Location, ref string, refType, narrowed *types.Type) {
+	details := map[string]any{"reference": ref, "type": FormatCELType(refType)}
+	msg := fmt.Sprintf("unsound type narrowing: '%s' of type '%s' is never non-null", ref, FormatCELType(refType))
+	if narrowed != nil {
+		details["narrowed_type"] = FormatCELType(narrowed)
+		msg = fmt.Sprintf("unsound type narrowing: '%s' of type '%s' is never of type '%s'",
+			ref, FormatCELType(refType), FormatCELType(narrowed))
+	}
+	e.errs.ReportIssue(&common.Error{
+		ExprID:   id,
+		Location: l,
+		Message:  msg,
+		Severity: common.SeverityWarning,
+		Code:     common.ErrorCodeUnsoundNarrowing,
+		Details:  details,
+	})
 }
@@ -96,6 +96,10 @@ func FormatCheckedType(t *exprpb.Type) string {
 		for i, p := range params {
 			paramStrs[i] = FormatCheckedType(p)
 		}
+		// Union types are represented as abstract types in the checked expression format.
+		if at.GetName() == "union" && len(params) > 1 {
+			return strings.Join(paramStrs, " | ")
+		}
 		return fmt.Sprintf("%s(%s)", at.GetName(), strings.Join(paramStrs, ", "))
 	}
 	return t.String()
@@ -127,6 +131,12 @@ func FormatCELType(t any) string {
 			// whether the function is a member function is absent.
 			return formatFunctionDeclType(dt.Parameters()[0], dt.Parameters()[1:], false)
 		}
+	case types.UnionKind:
+		members := make([]string, len(dt.Parameters()))
+		for i, m := range dt.Parameters() {
+			members[i] = FormatCELType(m)
+		}
+		return strings.Join(members, " | ")
 	case types.UnspecifiedKind:
 		return ""
 	}
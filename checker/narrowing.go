@@ -0,0 +1,312 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+)
+
+// typeFact records a refinement of the type of a reference which holds within a guarded
+// sub-expression.
+//
+// References are identified by their path: the resolved variable name followed by zero or more
+// field names, e.g. `msg.payload.value`.
+type typeFact struct {
+	path string
+	// narrowedType is the type established by a `type(x) == T` test, or nil when the fact
+	// only establishes that the reference is not null.
+	narrowedType *types.Type
+}
+
+// apply refines the type of the reference according to the fact.
+func (f *typeFact) apply(t *types.Type) *types.Type {
+	if f.narrowedType == nil {
+		return nonNullType(t)
+	}
+	// Retain the current type when it is already at least as specific as the narrowed type,
+	// e.g. `list(string)` narrowed by `type(x) == list`.
+	if isEqualOrLessSpecific(f.narrowedType, t) {
+		return nonNullType(t)
+	}
+	return f.narrowedType
+}
+
+// narrowingScope is a stack of type facts which apply to the sub-expression being checked.
+//
+// Comprehensions push a scope which masks the variables they declare, so facts about an outer
+// reference with the same name do not apply to the comprehension variables.
+type narrowingScope struct {
+	parent *narrowingScope
+	facts  []*typeFact
+	masked []string
+}
+
+// narrow applies all facts in scope about the reference path to the declared type.
+func (s *narrowingScope) narrow(path string, t *types.Type) *types.Type {
+	var facts []*typeFact
+	root := referenceRoot(path)
+	for ; s != nil; s = s.parent {
+		for i := len(s.facts) - 1; i >= 0; i-- {
+			if s.facts[i].path == path {
+				facts = append(facts, s.facts[i])
+			}
+		}
+		if slices.Contains(s.masked, root) {
+			break
+		}
+	}
+	// Apply the facts from the outermost to the innermost scope.
+	for i := len(facts) - 1; i >= 0; i-- {
+		t = facts[i].apply(t)
+	}
+	return t
+}
+
+// narrowedType returns the type of the reference expression after applying the facts in scope.
+func (c *checker) narrowedType(e ast.Expr, t *types.Type) *types.Type {
+	if c.narrowing == nil {
+		return t
+	}
+	path, isRef := referencePath(e)
+	if !isRef {
+		return t
+	}
+	return c.narrowing.narrow(path, t)
+}
+
+// isNarrowingOperator returns whether the function guards the evaluation of its arguments.
+func isNarrowingOperator(fnName string) bool {
+	switch fnName {
+	case operators.LogicalAnd, operators.LogicalOr, operators.Conditional:
+		return true
+	}
+	return false
+}
+
+// checkNarrowedArgs checks the arguments of a logical operator or conditional, checking each
+// guarded argument with the facts established by its guard.
+//
+// The right-hand side of `a && b` is checked with the facts which hold when `a` is true, and
+// the right-hand side of `a || b` with the facts which hold when `a` is false. Since CEL logical
+// operators absorb errors from their other argument when short-circuiting, a guarded argument
+// which is evaluated with a value of an unexpected type does not affect the result.
+func (c *checker) checkNarrowedArgs(fnName string, args []ast.Expr) {
+	switch fnName {
+	case operators.LogicalAnd, operators.LogicalOr:
+		outcome := fnName == operators.LogicalAnd
+		var facts []*typeFact
+		for _, arg := range args {
+			c.checkWithFacts(arg, facts)
+			facts = append(facts, c.typeFacts(arg, outcome)...)
+		}
+	case operators.Conditional:
+		c.check(args[0])
+		c.checkWithFacts(args[1], c.typeFacts(args[0], true))
+		c.checkWithFacts(args[2], c.typeFacts(args[0], false))
+	}
+}
+
+// checkWithFacts checks the expression with additional type facts in scope.
+func (c *checker) checkWithFacts(e ast.Expr, facts []*typeFact) {
+	if len(facts) == 0 {
+		c.check(e)
+		return
+	}
+	c.narrowing = &narrowingScope{parent: c.narrowing, facts: facts}
+	c.check(e)
+	c.narrowing = c.narrowing.parent
+}
+
+// enterNarrowingMask masks the facts about the given variable names until the matching
+// exitNarrowingMask call.
+func (c *checker) enterNarrowingMask(names ...string) {
+	if c.narrowing == nil {
+		return
+	}
+	c.narrowing = &narrowingScope{
+		parent: c.narrowing,
+		masked: slices.DeleteFunc(names, func(name string) bool { return name == "" }),
+	}
+}
+
+func (c *checker) exitNarrowingMask() {
+	if c.narrowing == nil {
+		return
+	}
+	c.narrowing = c.narrowing.parent
+}
+
+// typeFacts returns the facts established by a checked boolean expression when it evaluates to
+// the given outcome.
+func (c *checker) typeFacts(e ast.Expr, outcome bool) []*typeFact {
+	switch e.Kind() {
+	case ast.SelectKind:
+		// has(m.f) establishes that m.f is not null.
+		sel := e.AsSelect()
+		if !sel.IsTestOnly() || !outcome {
+			return nil
+		}
+		if path, isRef := referencePath(sel.Operand()); isRef {
+			return []*typeFact{{path: path + "." + sel.FieldName()}}
+		}
+	case ast.CallKind:
+		call := e.AsCall()
+		args := call.Args()
+		if call.IsMemberFunction() {
+			return nil
+		}
+		switch call.FunctionName() {
+		case operators.LogicalNot:
+			return c.typeFacts(args[0], !outcome)
+		case operators.LogicalAnd, operators.LogicalOr:
+			// Conjunctions establish facts when true, disjunctions when false.
+			if outcome != (call.FunctionName() == operators.LogicalAnd) {
+				return nil
+			}
+			var facts []*typeFact
+			for _, arg := range args {
+				facts = append(facts, c.typeFacts(arg, outcome)...)
+			}
+			return facts
+		case operators.Equals, operators.NotEquals:
+			isEqual := outcome == (call.FunctionName() == operators.Equals)
+			if fact := c.typeTestFact(e, args[0], args[1], isEqual); fact != nil {
+				return []*typeFact{fact}
+			}
+			if fact := c.typeTestFact(e, args[1], args[0], isEqual); fact != nil {
+				return []*typeFact{fact}
+			}
+		}
+	}
+	return nil
+}
+
+// typeTestFact returns the fact established by comparing a reference against a type or null.
+//
+// When the comparison can never succeed given the type of the reference, an unsound narrowing
+// is reported and no fact is returned.
+func (c *checker) typeTestFact(e, lhs, rhs ast.Expr, isEqual bool) *typeFact {
+	// x != null
+	if !isEqual && rhs.Kind() == ast.LiteralKind && rhs.AsLiteral() == types.NullValue {
+		path, isRef := referencePath(lhs)
+		if !isRef {
+			return nil
+		}
+		refType := substitute(c.mappings, c.getType(lhs), false)
+		if refType.Kind() == types.NullTypeKind {
+			c.reportUnsoundNarrowing(e, path, refType, nil)
+			return nil
+		}
+		return &typeFact{path: path}
+	}
+	// type(x) == T
+	if !isEqual || lhs.Kind() != ast.CallKind {
+		return nil
+	}
+	call := lhs.AsCall()
+	if call.IsMemberFunction() || call.FunctionName() != overloads.TypeConvertType || len(call.Args()) != 1 {
+		return nil
+	}
+	path, isRef := referencePath(call.Args()[0])
+	if !isRef {
+		return nil
+	}
+	typeType := substitute(c.mappings, c.getType(rhs), false)
+	if typeType.Kind() != types.TypeKind || len(typeType.Parameters()) != 1 {
+		return nil
+	}
+	narrowed := typeType.Parameters()[0]
+	switch narrowed.Kind() {
+	case types.DynKind, types.ErrorKind, types.TypeParamKind:
+		return nil
+	}
+	refType := substitute(c.mappings, c.getType(call.Args()[0]), false)
+	if isAssignable(c.mappings, refType, narrowed) == nil && isAssignable(c.mappings, narrowed, refType) == nil {
+		c.reportUnsoundNarrowing(e, path, refType, narrowed)
+		return nil
+	}
+	return &typeFact{path: path, narrowedType: narrowed}
+}
+
+// reportUnsoundNarrowing reports a type test which can never succeed, once per test expression
+// as the facts of nested guards may be computed more than once.
+func (c *checker) reportUnsoundNarrowing(e ast.Expr, path string, refType, narrowed *types.Type) {
+	if c.unsoundTests == nil {
+		c.unsoundTests = make(map[int64]struct{})
+	}
+	if _, found := c.unsoundTests[e.ID()]; found {
+		return
+	}
+	c.unsoundTests[e.ID()] = struct{}{}
+	c.errors.unsoundNarrowing(e.ID(), c.location(e), path, refType, narrowed)
+}
+
+// referencePath returns the path of a checked variable reference or field selection, if the
+// expression is one.
+func referencePath(e ast.Expr) (string, bool) {
+	switch e.Kind() {
+	case ast.IdentKind:
+		return e.AsIdent(), true
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		if sel.IsTestOnly() {
+			return "", false
+		}
+		if path, isRef := referencePath(sel.Operand()); isRef {
+			return path + "." + sel.FieldName(), true
+		}
+	}
+	return "", false
+}
+
+// referenceRoot returns the variable name at the root of a reference path.
+//
+// Paths beginning with a leading dot refer to global variables which cannot be shadowed by
+// comprehension variables, and have no root.
+func referenceRoot(path string) string {
+	if strings.HasPrefix(path, ".") {
+		return ""
+	}
+	root, _, _ := strings.Cut(path, ".")
+	return root
+}
+
+// nonNullType returns the non-nullable form of a nullable primitive type.
+func nonNullType(t *types.Type) *types.Type {
+	if !t.IsAssignableType(types.NullType) {
+		return t
+	}
+	switch t.Kind() {
+	case types.BoolKind:
+		return types.BoolType
+	case types.BytesKind:
+		return types.BytesType
+	case types.DoubleKind:
+		return types.DoubleType
+	case types.IntKind:
+		return types.IntType
+	case types.StringKind:
+		return types.StringType
+	case types.UintKind:
+		return types.UintType
+	}
+	return t
+}
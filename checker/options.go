@@ -19,6 +19,7 @@ type options struct {
 	homogeneousAggregateLiterals bool
 	validatedDeclarations        *Scopes
 	jsonFieldNames               bool
+	typeNarrowing                bool
 }
 
 // Option is a functional option for configuring the type-checker
@@ -49,3 +50,13 @@ func JSONFieldNames(enabled bool) Option {
 		return nil
 	}
 }
+
+// TypeNarrowing enables flow-sensitive narrowing of the types of variables and field selections
+// within the guarded arguments of `&&`, `||` and `? :` following tests such as `type(x) == T`,
+// `has(m.f)` and `x != null`.
+func TypeNarrowing(enabled bool) Option {
+	return func(opts *options) error {
+		opts.typeNarrowing = enabled
+		return nil
+	}
+}
@@ -65,6 +65,10 @@ func isEqualOrLessSpecific(t1, t2 *types.Type) bool {
 	if isDyn(t2) || kind2 == types.TypeParamKind {
 		return false
 	}
+	// A union is less specific than its members.
+	if kind1 == types.UnionKind && kind2 != types.UnionKind {
+		return true
+	}
 	// Types must be of the same kind to be equal.
 	if kind1 != kind2 {
 		return false
@@ -111,6 +115,10 @@ func internalIsAssignable(m *mapping, t1, t2 *types.Type) bool {
 		if !valid && t2HasSub {
 			return false
 		}
+		// A constrained type parameter cannot be bound to a type outside of its constraint.
+		if kind1 != types.TypeParamKind && !satisfiesTypeParam(t2, substitute(m, t1, false)) {
+			return false
+		}
 		// Otherwise, fall through to check whether t1 is a possible substitution for t2.
 	}
 	if kind1 == types.TypeParamKind {
@@ -124,6 +132,9 @@ func internalIsAssignable(m *mapping, t1, t2 *types.Type) bool {
 	if isDynOrError(t1) || isDynOrError(t2) {
 		return true
 	}
+	if kind1 == types.UnionKind || kind2 == types.UnionKind {
+		return internalIsAssignableUnion(m, t1, t2)
+	}
 	// Preserve the nullness checks of the legacy type-checker.
 	if kind1 == types.NullTypeKind {
 		return internalIsAssignableNull(t2)
@@ -152,6 +163,59 @@ func internalIsAssignable(m *mapping, t1, t2 *types.Type) bool {
 	}
 }
 
+// internalIsAssignableUnion returns true if the type t1 is assignable to the type t2 when either
+// is a union.
+//
+// A union is assignable to a type when all of its members are assignable, and a type is assignable
+// to a union when it is assignable to any of its members. Function arguments of union type are
+// matched against overloads member by member, see checker.resolveUnionOverload.
+func internalIsAssignableUnion(m *mapping, t1, t2 *types.Type) bool {
+	if t1.Kind() == types.UnionKind {
+		return isAssignableMembers(m, t1.Parameters(), t2)
+	}
+	for _, member := range t2.Parameters() {
+		if mCopy := isAssignable(m, t1, member); mCopy != nil {
+			m.mapping = mCopy.mapping
+			return true
+		}
+	}
+	return false
+}
+
+// joinUnionSubstitution returns the union type a type parameter substitution is widened to when one
+// of the types is a union and the other is either null or assignable to the union, or nil otherwise.
+//
+// Values of union type compare like dyn values, so a type parameter shared between operands, such
+// as the one of _==_(A, A), binds the same way regardless of which operand has the union type.
+func joinUnionSubstitution(m *mapping, t1, t2 *types.Type) *types.Type {
+	if t2.Kind() == types.UnionKind {
+		t1, t2 = t2, t1
+	}
+	if t1.Kind() != types.UnionKind {
+		return nil
+	}
+	if t2.Kind() == types.NullTypeKind {
+		return t1
+	}
+	if mCopy := isAssignable(m, t2, t1); mCopy != nil {
+		m.mapping = mCopy.mapping
+		return t1
+	}
+	return nil
+}
+
+// isAssignableMembers returns true if all of the union members are assignable to the type.
+func isAssignableMembers(m *mapping, members []*types.Type, t *types.Type) bool {
+	mCopy := m.copy()
+	for _, member := range members {
+		if !internalIsAssignable(mCopy, member, t) {
+			return false
+		}
+	}
+	m.mapping = mCopy.mapping
+	return true
+}
+
 // isValidTypeSubstitution returns whether t2 (or its type substitution) is a valid type
 // substitution for t1, and whether t2 has a type substitution in mapping m.
 //
@@ -181,15 +245,107 @@ func isValidTypeSubstitution(m *mapping, t1, t2 *types.Type) (valid, hasSub bool
 			// acknowledge the type agreement, and that the substitution is already tracked.
 			return true, true
 		}
+		// Widen the substitution to the union if one of the types is a union the other fits into.
+		if union := joinUnionSubstitution(m, t1, t2Sub); union != nil && satisfiesTypeParam(t2, union) {
+			if notReferencedIn(m, t2, union) {
+				m.add(t2, union)
+			}
+			return true, true
+		}
 		return false, true
 	}
 	if notReferencedIn(m, t2, t1) {
-		m.add(t2, t1)
-		return true, false
+		t1Sub := substitute(m, t1, false)
+		if satisfiesTypeParam(t2, t1Sub) {
+			m.add(t2, t1)
+			return true, false
+		}
+		// A type variable with a weaker constraint than t2 is bound to t2 instead so that the
+		// constraint of t2 is checked once the type variable is resolved.
+		if t1Sub.Kind() == types.TypeParamKind && impliesTypeParam(t2, t1Sub) {
+			m.add(t1Sub, t2)
+			return true, false
+		}
 	}
 	return false, false
 }
 
+// satisfiesTypeParam returns whether the type may be bound to the type parameter given the bounds
+// and required traits of the parameter.
+//
+// Dynamic types and errors satisfy any constraint as their types are not known until runtime.
+// Unbound type parameters satisfy the constraint only if their own constraint is at least as
+// strict.
+func satisfiesTypeParam(param, t *types.Type) bool {
+	bounds, traitMask := param.TypeParamBounds(), param.TypeParamTraits()
+	if len(bounds) == 0 && traitMask == 0 {
+		return true
+	}
+	if isDynOrError(t) {
+		return true
+	}
+	if t.Kind() == types.TypeParamKind {
+		return impliesTypeParam(t, param)
+	}
+	// Each member of a union must satisfy the constraints.
+	if t.Kind() == types.UnionKind {
+		for _, member := range t.Parameters() {
+			if !satisfiesTypeParam(param, member) {
+				return false
+			}
+		}
+		return true
+	}
+	if !t.HasTrait(traitMask) {
+		return false
+	}
+	if len(bounds) == 0 {
+		return true
+	}
+	for _, b := range bounds {
+		if internalIsAssignable(newMapping(), t, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// impliesTypeParam returns whether every type which satisfies the constraint of the strict type
+// parameter also satisfies the constraint of the loose type parameter.
+func impliesTypeParam(strict, loose *types.Type) bool {
+	looseBounds, looseTraits := loose.TypeParamBounds(), loose.TypeParamTraits()
+	if len(looseBounds) == 0 && looseTraits == 0 {
+		return true
+	}
+	if bounds := strict.TypeParamBounds(); len(bounds) != 0 {
+		for _, b := range bounds {
+			if !b.HasTrait(strict.TypeParamTraits()) {
+				continue
+			}
+			if !satisfiesTypeParam(loose, b) {
+				return false
+			}
+		}
+		return true
+	}
+	strictTraits := strict.TypeParamTraits()
+	return len(looseBounds) == 0 && strictTraits&looseTraits == looseTraits
+}
+
+// collectConstrainedTypeParams records the type parameters within the type which have bounds or
+// required traits by their parameter name.
+func collectConstrainedTypeParams(params map[string]*types.Type, t *types.Type) {
+	if t.Kind() == types.TypeParamKind {
+		if len(t.TypeParamBounds()) != 0 || t.TypeParamTraits() != 0 {
+			params[t.TypeName()] = t
+		}
+		return
+	}
+	for _, p := range t.Parameters() {
+		collectConstrainedTypeParams(params, p)
+	}
+}
+
 // internalIsAssignableList returns true if the element types at each index in the list are
 // assignable from l1[i] to l2[i]. The list lengths must also agree for the lists to be
 // assignable.
@@ -260,7 +416,7 @@ func notReferencedIn(m *mapping, t, withinType *types.Type) bool {
 			return true
 		}
 		return notReferencedIn(m, t, wtSub)
-	case types.OpaqueKind, types.ListKind, types.MapKind, types.TypeKind:
+	case types.OpaqueKind, types.ListKind, types.MapKind, types.TypeKind, types.UnionKind:
 		for _, pt := range withinType.Parameters() {
 			if !notReferencedIn(m, t, pt) {
 				return false
@@ -296,6 +452,8 @@ func substitute(m *mapping, t *types.Type, typeParamToDyn bool) *types.Type {
 			return types.NewTypeTypeWithParam(substitute(m, tParam, typeParamToDyn))
 		}
 		return t
+	case types.UnionKind:
+		return types.NewUnionType(substituteParams(m, t.Parameters(), typeParamToDyn)...)
 	default:
 		return t
 	}
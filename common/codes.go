@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// ErrorCode is a stable, machine-readable identifier for the kind of issue reported in an Error.
+//
+// Error messages are intended for human consumption and may change between releases, whereas
+// error codes and the keys of the Error.Details map are stable and suitable for programmatic use.
+type ErrorCode string
+
+// Error codes reported by the parser.
+const (
+	// ErrorCodeSyntax indicates the expression does not conform to the CEL grammar.
+	ErrorCodeSyntax ErrorCode = "syntax_error"
+
+	// ErrorCodeInternal indicates the parser or checker failed for reasons other than the content
+	// of the expression, such as exceeding a recursion or lookahead limit.
+	ErrorCodeInternal ErrorCode = "internal_error"
+
+	// ErrorCodeExpressionSizeLimit indicates the expression exceeds the configured code point limit.
+	//
+	// Details: "size" and "limit" as int values.
+	ErrorCodeExpressionSizeLimit ErrorCode = "expression_size_limit_exceeded"
+
+	// ErrorCodeReservedIdentifier indicates a reserved word was used as an identifier.
+	//
+	// Details: "identifier" as a string.
+	ErrorCodeReservedIdentifier ErrorCode = "reserved_identifier"
+
+	// ErrorCodeInvalidIdentifier indicates an escaped identifier is malformed.
+	ErrorCodeInvalidIdentifier ErrorCode = "invalid_identifier"
+
+	// ErrorCodeInvalidLiteral indicates a literal value could not be parsed.
+	//
+	// Details: "literal_kind" as one of "int", "uint", "double", "string", or "bytes".
+	ErrorCodeInvalidLiteral ErrorCode = "invalid_literal"
+
+	// ErrorCodeUnsupportedSyntax indicates the expression uses syntax which has not been enabled.
+	//
+	// Details: "syntax" as a string.
+	ErrorCodeUnsupportedSyntax ErrorCode = "unsupported_syntax"
+
+	// ErrorCodeInvalidMacro indicates a macro was matched, but its arguments were not well-formed.
+	//
+	// Details: "macro" as the macro function name.
+	ErrorCodeInvalidMacro ErrorCode = "invalid_macro"
+)
+
+// Error codes reported by the type-checker.
+const (
+	// ErrorCodeUndeclaredReference indicates an identifier or function is not declared.
+	//
+	// Details: "name" and "container" as strings.
+	ErrorCodeUndeclaredReference ErrorCode = "undeclared_reference"
+
+	// ErrorCodeNoMatchingOverload indicates no overload of a function accepts the argument types.
+	//
+	// Details: "function" as a string, "argument_types" as a []string of formatted types, and
+	// "candidate_overloads" as a []string of the overload ids considered during resolution.
+	ErrorCodeNoMatchingOverload ErrorCode = "no_matching_overload"
+
+	// ErrorCodeTypeParamConstraint indicates the argument types match an overload except for a
+	// type parameter bound to a type which does not satisfy the parameter's constraint.
+	//
+	// Details: "function", "overload", "type_param", and "actual_type" as strings.
+	ErrorCodeTypeParamConstraint ErrorCode = "type_param_constraint"
+
+	// ErrorCodeFieldNotFound indicates a field is not defined on the selected type.
+	//
+	// Details: "field" as a string, and "type" as the struct type name, when known.
+	ErrorCodeFieldNotFound ErrorCode = "field_not_found"
+
+	// ErrorCodeFieldTypeMismatch indicates a field initializer does not match the field type.
+	//
+	// Details: "field", "expected_type", and "actual_type" as strings.
+	ErrorCodeFieldTypeMismatch ErrorCode = "field_type_mismatch"
+
+	// ErrorCodeTypeMismatch indicates an expression type differs from the expected type.
+	//
+	// Details: "expected_type" and "actual_type" as strings.
+	ErrorCodeTypeMismatch ErrorCode = "type_mismatch"
+
+	// ErrorCodeInvalidComprehensionRange indicates a comprehension ranges over a non-iterable type.
+	//
+	// Details: "type" as a string.
+	ErrorCodeInvalidComprehensionRange ErrorCode = "invalid_comprehension_range"
+
+	// ErrorCodeInvalidOptionalFieldSelection indicates a malformed optional field selection.
+	ErrorCodeInvalidOptionalFieldSelection ErrorCode = "invalid_optional_field_selection"
+
+	// ErrorCodeNotAType indicates an identifier used as a type does not refer to a type.
+	//
+	// Details: "type_name" as a string.
+	ErrorCodeNotAType ErrorCode = "not_a_type"
+
+	// ErrorCodeNotAMessageType indicates a struct literal refers to a type which is not a message.
+	//
+	// Details: "type_name" as a string.
+	ErrorCodeNotAMessageType ErrorCode = "not_a_message_type"
+
+	// ErrorCodeFieldSelectionUnsupported indicates a field selection on a type without fields.
+	//
+	// Details: "type" as a string.
+	ErrorCodeFieldSelectionUnsupported ErrorCode = "field_selection_unsupported"
+
+	// ErrorCodeIncompatibleType indicates the checker inferred conflicting types for an expression.
+	ErrorCodeIncompatibleType ErrorCode = "incompatible_type"
+
+	// ErrorCodeReferenceRedefinition indicates the checker resolved conflicting references for an expression.
+	ErrorCodeReferenceRedefinition ErrorCode = "reference_redefinition"
+
+	// ErrorCodeUnexpectedResolutionFailure indicates a type known to the checker could not be resolved.
+	//
+	// Details: "type_name" as a string.
+	ErrorCodeUnexpectedResolutionFailure ErrorCode = "unexpected_resolution_failure"
+
+	// ErrorCodeUnexpectedASTType indicates the AST contains an unsupported expression or literal kind.
+	ErrorCodeUnexpectedASTType ErrorCode = "unexpected_ast_type"
+
+	// ErrorCodeUnsoundNarrowing indicates a type test which can never succeed given the type of the
+	// tested reference, so the reference cannot be narrowed. Reported as a warning.
+	//
+	// Details: "reference" as a string, "type" and "narrowed_type" as CEL type strings.
+	ErrorCodeUnsoundNarrowing ErrorCode = "unsound_narrowing"
+)
@@ -16,6 +16,7 @@
 package decls
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -324,6 +325,19 @@ func (f *FunctionDecl) HasLateBinding() bool {
 	return false
 }
 
+// HasContextBinding returns true if any of the function overloads receives the evaluation context.
+func (f *FunctionDecl) HasContextBinding() bool {
+	if f == nil {
+		return false
+	}
+	for _, oID := range f.overloadOrdinals {
+		if f.overloads[oID].hasContextBinding() {
+			return true
+		}
+	}
+	return false
+}
+
 // Bindings produces a set of function bindings, if any are defined.
 func (f *FunctionDecl) Bindings() ([]*functions.Overload, error) {
 	var emptySet []*functions.Overload
@@ -332,21 +346,29 @@ func (f *FunctionDecl) Bindings() ([]*functions.Overload, error) {
 	}
 	overloads := []*functions.Overload{}
 	nonStrict := false
+	deterministic := true
 	hasLateBinding := false
+	hasContextBinding := false
 	for _, oID := range f.overloadOrdinals {
 		o := f.overloads[oID]
 		hasLateBinding = hasLateBinding || o.HasLateBinding()
 		if o.HasBinding() {
 			overload := &functions.Overload{
-				Operator:     o.ID(),
-				Unary:        o.guardedUnaryOp(f.Name(), f.disableTypeGuards),
-				Binary:       o.guardedBinaryOp(f.Name(), f.disableTypeGuards),
-				Function:     o.guardedFunctionOp(f.Name(), f.disableTypeGuards),
-				OperandTrait: o.OperandTrait(),
-				NonStrict:    o.IsNonStrict(),
+				Operator:        o.ID(),
+				Unary:           o.guardedUnaryOp(f.Name(), f.disableTypeGuards),
+				Binary:          o.guardedBinaryOp(f.Name(), f.disableTypeGuards),
+				Function:        o.guardedFunctionOp(f.Name(), f.disableTypeGuards),
+				ContextUnary:    o.guardedContextUnaryOp(f.Name(), f.disableTypeGuards),
+				ContextBinary:   o.guardedContextBinaryOp(f.Name(), f.disableTypeGuards),
+				ContextFunction: o.guardedContextFunctionOp(f.Name(), f.disableTypeGuards),
+				OperandTrait:    o.OperandTrait(),
+				NonStrict:       o.IsNonStrict(),
+				Deterministic:   o.IsDeterministic(),
 			}
 			overloads = append(overloads, overload)
 			nonStrict = nonStrict || o.IsNonStrict()
+			deterministic = deterministic && o.IsDeterministic()
+			hasContextBinding = hasContextBinding || o.hasContextBinding()
 		}
 	}
 	if f.singleton != nil {
@@ -376,43 +398,68 @@ func (f *FunctionDecl) Bindings() ([]*functions.Overload, error) {
 			return overloads, nil
 		}
 		return append(overloads, &functions.Overload{
-			Operator:     f.Name(),
-			Unary:        overloads[0].Unary,
-			Binary:       overloads[0].Binary,
-			Function:     overloads[0].Function,
-			NonStrict:    overloads[0].NonStrict,
-			OperandTrait: overloads[0].OperandTrait,
+			Operator:        f.Name(),
+			Unary:           overloads[0].Unary,
+			Binary:          overloads[0].Binary,
+			Function:        overloads[0].Function,
+			ContextUnary:    overloads[0].ContextUnary,
+			ContextBinary:   overloads[0].ContextBinary,
+			ContextFunction: overloads[0].ContextFunction,
+			NonStrict:       overloads[0].NonStrict,
+			Deterministic:   overloads[0].Deterministic,
+			OperandTrait:    overloads[0].OperandTrait,
 		}), nil
 	}
 	// All of the defined overloads are wrapped into a top-level function which
 	// performs dynamic dispatch to the proper overload based on the argument types.
 	bindings := append([]*functions.Overload{}, overloads...)
-	funcDispatch := func(args ...ref.Val) ref.Val {
+	funcDispatch := func(ctx context.Context, args ...ref.Val) ref.Val {
 		for _, oID := range f.overloadOrdinals {
 			o := f.overloads[oID]
 			// During dynamic dispatch over multiple functions, signature agreement checks
 			// are preserved in order to assist with the function resolution step.
+			if !o.matchesRuntimeSignature(f.disableTypeGuards, args...) {
+				continue
+			}
 			switch len(args) {
 			case 1:
-				if o.unaryOp != nil && o.matchesRuntimeSignature(f.disableTypeGuards, args...) {
+				if o.unaryOp != nil {
 					return o.unaryOp(args[0])
 				}
+				if o.contextUnaryOp != nil {
+					return o.contextUnaryOp(ctx, args[0])
+				}
 			case 2:
-				if o.binaryOp != nil && o.matchesRuntimeSignature(f.disableTypeGuards, args...) {
+				if o.binaryOp != nil {
 					return o.binaryOp(args[0], args[1])
 				}
+				if o.contextBinaryOp != nil {
+					return o.contextBinaryOp(ctx, args[0], args[1])
+				}
 			}
-			if o.functionOp != nil && o.matchesRuntimeSignature(f.disableTypeGuards, args...) {
+			if o.functionOp != nil {
 				return o.functionOp(args...)
 			}
+			if o.contextFunctionOp != nil {
+				return o.contextFunctionOp(ctx, args...)
+			}
 			// eventually this will fall through to the noSuchOverload below.
 		}
 		return MaybeNoSuchOverload(f.Name(), args...)
 	}
+	// Dynamic dispatch is only deterministic when all of the overloads are deterministic.
 	function := &functions.Overload{
-		Operator:  f.Name(),
-		Function:  funcDispatch,
-		NonStrict: nonStrict,
+		Operator:      f.Name(),
+		NonStrict:     nonStrict,
+		Deterministic: deterministic,
+	}
+	// The context of the evaluation is only requested when one of the overloads makes use of it.
+	if hasContextBinding {
+		function.ContextFunction = funcDispatch
+	} else {
+		function.Function = func(args ...ref.Val) ref.Val {
+			return funcDispatch(context.Background(), args...)
+		}
 	}
 	return append(bindings, function), nil
 }
@@ -610,6 +657,8 @@ type OverloadDecl struct {
 	hasLateBinding bool
 	// nonStrict indicates that the function will accept error and unknown arguments as inputs.
 	nonStrict bool
+	// deterministic indicates that the function always produces the same result for the same arguments.
+	deterministic bool
 	// operandTrait indicates whether the member argument should have a specific type-trait.
 	//
 	// This is useful for creating overloads which operate on a type-interface rather than a concrete type.
@@ -622,6 +671,13 @@ type OverloadDecl struct {
 	binaryOp functions.BinaryOp
 	// functionOp is a catch-all for zero-arity and three-plus arity functions.
 	functionOp functions.FunctionOp
+	// contextUnaryOp is a function binding that takes the evaluation context and a single argument.
+	contextUnaryOp functions.ContextUnaryOp
+	// contextBinaryOp is a function binding that takes the evaluation context and two arguments.
+	contextBinaryOp functions.ContextBinaryOp
+	// contextFunctionOp is a function binding that takes the evaluation context and any number of
+	// arguments.
+	contextFunctionOp functions.ContextFunctionOp
 }
 
 // Examples returns a list of string examples for the overload.
@@ -672,6 +728,14 @@ func (o *OverloadDecl) IsNonStrict() bool {
 	return o.nonStrict
 }
 
+// IsDeterministic returns whether the overload always produces the same result for the same arguments.
+func (o *OverloadDecl) IsDeterministic() bool {
+	if o == nil {
+		return false
+	}
+	return o.deterministic
+}
+
 // HasLateBinding returns whether the overload has a binding which is not known at compile time.
 func (o *OverloadDecl) HasLateBinding() bool {
 	if o == nil {
@@ -750,7 +814,12 @@ func (o *OverloadDecl) SignatureOverlaps(other *OverloadDecl) bool {
 
 // HasBinding indicates whether the overload already has a definition.
 func (o *OverloadDecl) HasBinding() bool {
-	return o != nil && (o.unaryOp != nil || o.binaryOp != nil || o.functionOp != nil)
+	return o != nil && (o.unaryOp != nil || o.binaryOp != nil || o.functionOp != nil || o.hasContextBinding())
+}
+
+// hasContextBinding indicates whether the overload definition receives the evaluation context.
+func (o *OverloadDecl) hasContextBinding() bool {
+	return o.contextUnaryOp != nil || o.contextBinaryOp != nil || o.contextFunctionOp != nil
 }
 
 // guardedUnaryOp creates an invocation guard around the provided unary operator, if one is defined.
@@ -792,6 +861,48 @@ func (o *OverloadDecl) guardedFunctionOp(funcName string, disableTypeGuards bool
 	}
 }
 
+// guardedContextUnaryOp creates an invocation guard around the provided context-aware unary
+// operator, if one is defined.
+func (o *OverloadDecl) guardedContextUnaryOp(funcName string, disableTypeGuards bool) functions.ContextUnaryOp {
+	if o.contextUnaryOp == nil {
+		return nil
+	}
+	return func(ctx context.Context, arg ref.Val) ref.Val {
+		if !o.matchesRuntimeUnarySignature(disableTypeGuards, arg) {
+			return MaybeNoSuchOverload(funcName, arg)
+		}
+		return o.contextUnaryOp(ctx, arg)
+	}
+}
+
+// guardedContextBinaryOp creates an invocation guard around the provided context-aware binary
+// operator, if one is defined.
+func (o *OverloadDecl) guardedContextBinaryOp(funcName string, disableTypeGuards bool) functions.ContextBinaryOp {
+	if o.contextBinaryOp == nil {
+		return nil
+	}
+	return func(ctx context.Context, arg1, arg2 ref.Val) ref.Val {
+		if !o.matchesRuntimeBinarySignature(disableTypeGuards, arg1, arg2) {
+			return MaybeNoSuchOverload(funcName, arg1, arg2)
+		}
+		return o.contextBinaryOp(ctx, arg1, arg2)
+	}
+}
+
+// guardedContextFunctionOp creates an invocation guard around the provided context-aware variadic
+// function binding, if one is provided.
+func (o *OverloadDecl) guardedContextFunctionOp(funcName string, disableTypeGuards bool) functions.ContextFunctionOp {
+	if o.contextFunctionOp == nil {
+		return nil
+	}
+	return func(ctx context.Context, args ...ref.Val) ref.Val {
+		if !o.matchesRuntimeSignature(disableTypeGuards, args...) {
+			return MaybeNoSuchOverload(funcName, args...)
+		}
+		return o.contextFunctionOp(ctx, args...)
+	}
+}
+
 // matchesRuntimeUnarySignature indicates whether the argument type is runtime assiganble to the overload's expected argument.
 func (o *OverloadDecl) matchesRuntimeUnarySignature(disableTypeGuards bool, arg ref.Val) bool {
 	return matchRuntimeArgType(o.IsNonStrict(), disableTypeGuards, o.ArgTypes()[0], arg) &&
@@ -897,6 +1008,63 @@ func FunctionBinding(binding functions.FunctionOp) OverloadOpt {
 	}
 }
 
+// ContextUnaryBinding provides the implementation of a unary overload which receives the
+// context.Context of the evaluation, or context.Background() when the program is not evaluated
+// with a context. The provided function is protected by a runtime type-guard which ensures
+// runtime type agreement between the overload signature and runtime argument types.
+func ContextUnaryBinding(binding functions.ContextUnaryOp) OverloadOpt {
+	return func(o *OverloadDecl) (*OverloadDecl, error) {
+		if o.HasBinding() {
+			return nil, fmt.Errorf("overload already has a binding: %s", o.ID())
+		}
+		if len(o.ArgTypes()) != 1 {
+			return nil, fmt.Errorf("unary function bound to non-unary overload: %s", o.ID())
+		}
+		if o.hasLateBinding {
+			return nil, fmt.Errorf("overload already has a late binding: %s", o.ID())
+		}
+		o.contextUnaryOp = binding
+		return o, nil
+	}
+}
+
+// ContextBinaryBinding provides the implementation of a binary overload which receives the
+// context.Context of the evaluation, or context.Background() when the program is not evaluated
+// with a context. The provided function is protected by a runtime type-guard which ensures
+// runtime type agreement between the overload signature and runtime argument types.
+func ContextBinaryBinding(binding functions.ContextBinaryOp) OverloadOpt {
+	return func(o *OverloadDecl) (*OverloadDecl, error) {
+		if o.HasBinding() {
+			return nil, fmt.Errorf("overload already has a binding: %s", o.ID())
+		}
+		if len(o.ArgTypes()) != 2 {
+			return nil, fmt.Errorf("binary function bound to non-binary overload: %s", o.ID())
+		}
+		if o.hasLateBinding {
+			return nil, fmt.Errorf("overload already has a late binding: %s", o.ID())
+		}
+		o.contextBinaryOp = binding
+		return o, nil
+	}
+}
+
+// ContextFunctionBinding provides the implementation of a variadic overload which receives the
+// context.Context of the evaluation, or context.Background() when the program is not evaluated
+// with a context. The provided function is protected by a runtime type-guard which ensures
+// runtime type agreement between the overload signature and runtime argument types.
+func ContextFunctionBinding(binding functions.ContextFunctionOp) OverloadOpt {
+	return func(o *OverloadDecl) (*OverloadDecl, error) {
+		if o.HasBinding() {
+			return nil, fmt.Errorf("overload already has a binding: %s", o.ID())
+		}
+		if o.hasLateBinding {
+			return nil, fmt.Errorf("overload already has a late binding: %s", o.ID())
+		}
+		o.contextFunctionOp = binding
+		return o, nil
+	}
+}
+
 // LateFunctionBinding indicates that the function has a binding which is not known at compile time.
 // This is useful for functions which have side-effects or are not deterministically computable.
 func LateFunctionBinding() OverloadOpt {
@@ -919,6 +1087,17 @@ func OverloadIsNonStrict() OverloadOpt {
 	}
 }
 
+// OverloadIsDeterministic indicates that the overload always produces the same result for the same arguments and
+// has no side-effects, allowing the result of a call to be memoized across evaluations of a program.
+//
+// Note: the option has no effect on bindings which receive the evaluation context.
+func OverloadIsDeterministic() OverloadOpt {
+	return func(o *OverloadDecl) (*OverloadDecl, error) {
+		o.deterministic = true
+		return o, nil
+	}
+}
+
 // OverloadOperandTrait configures a set of traits which the first argument to the overload must implement in order to be
 // successfully invoked.
 func OverloadOperandTrait(trait int) OverloadOpt {
@@ -15,6 +15,8 @@
 package decls
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -253,6 +255,116 @@ func TestFunctionSingletonBinding(t *testing.T) {
 	}
 }
 
+func TestFunctionContextBindings(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "-suffix")
+	suffix := func(ctx context.Context) types.String {
+		if s, ok := ctx.Value(ctxKey{}).(string); ok {
+			return types.String(s)
+		}
+		return ""
+	}
+	tag, err := NewFunction("tag",
+		Overload("tag_string", []*types.Type{types.StringType}, types.StringType,
+			ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
+				return arg.(types.String) + suffix(ctx)
+			}),
+		),
+		Overload("tag_string_string", []*types.Type{types.StringType, types.StringType}, types.StringType,
+			ContextBinaryBinding(func(ctx context.Context, lhs, rhs ref.Val) ref.Val {
+				return lhs.(types.String) + rhs.(types.String) + suffix(ctx)
+			}),
+		),
+		Overload("tag_int", []*types.Type{types.IntType}, types.StringType,
+			UnaryBinding(func(arg ref.Val) ref.Val {
+				return types.String(fmt.Sprint(arg))
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewFunction() failed: %v", err)
+	}
+	if !tag.HasContextBinding() {
+		t.Error("tag.HasContextBinding() got false, wanted true")
+	}
+	bindings, err := tag.Bindings()
+	if err != nil {
+		t.Fatalf("tag.Bindings() failed: %v", err)
+	}
+	if len(bindings) != 4 {
+		t.Fatalf("tag.Bindings() got %d bindings, wanted 4", len(bindings))
+	}
+	for _, b := range bindings {
+		switch b.Operator {
+		case "tag_string":
+			if b.ContextUnary == nil {
+				t.Fatalf("%s binding missing context unary op", b.Operator)
+			}
+			if out := b.ContextUnary(ctx, types.String("a")); out != types.String("a-suffix") {
+				t.Errorf("%s('a') got %v, wanted 'a-suffix'", b.Operator, out)
+			}
+			if out := b.ContextUnary(ctx, types.Int(1)); !types.IsError(out) {
+				t.Errorf("%s(1) got %v, wanted no such overload", b.Operator, out)
+			}
+		case "tag_string_string":
+			if b.ContextBinary == nil {
+				t.Fatalf("%s binding missing context binary op", b.Operator)
+			}
+			if out := b.ContextBinary(ctx, types.String("a"), types.String("b")); out != types.String("ab-suffix") {
+				t.Errorf("%s('a', 'b') got %v, wanted 'ab-suffix'", b.Operator, out)
+			}
+		case "tag_int":
+			if b.Unary == nil || b.ContextUnary != nil {
+				t.Errorf("%s binding got context op, wanted unary op", b.Operator)
+			}
+		case "tag":
+			if b.ContextFunction == nil || b.Function != nil {
+				t.Fatalf("%s binding got function op, wanted context function op", b.Operator)
+			}
+			if out := b.ContextFunction(ctx, types.String("a")); out != types.String("a-suffix") {
+				t.Errorf("tag('a') got %v, wanted 'a-suffix'", out)
+			}
+			if out := b.ContextFunction(ctx, types.Int(1)); out != types.String("1") {
+				t.Errorf("tag(1) got %v, wanted '1'", out)
+			}
+			if out := b.ContextFunction(ctx, types.Int(1), types.Int(2)); !types.IsError(out) {
+				t.Errorf("tag(1, 2) got %v, wanted no such overload", out)
+			}
+		default:
+			t.Errorf("unexpected binding: %s", b.Operator)
+		}
+	}
+}
+
+func TestFunctionSingleContextBinding(t *testing.T) {
+	deadline, err := NewFunction("deadline",
+		Overload("deadline", []*types.Type{}, types.BoolType,
+			ContextFunctionBinding(func(ctx context.Context, args ...ref.Val) ref.Val {
+				_, hasDeadline := ctx.Deadline()
+				return types.Bool(hasDeadline)
+			}),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewFunction() failed: %v", err)
+	}
+	bindings, err := deadline.Bindings()
+	if err != nil {
+		t.Fatalf("deadline.Bindings() failed: %v", err)
+	}
+	if len(bindings) != 1 {
+		t.Fatalf("deadline.Bindings() got %d bindings, wanted 1", len(bindings))
+	}
+	if bindings[0].ContextFunction == nil {
+		t.Fatal("deadline.Bindings() missing context function binding")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if out := bindings[0].ContextFunction(ctx); out != types.True {
+		t.Errorf("deadline() got %v, wanted true", out)
+	}
+}
+
 func TestVariableDocumentation(t *testing.T) {
 	v := NewVariableWithDoc("var", types.StringType, "string variable")
 	doc := v.Documentation()
@@ -713,6 +825,35 @@ func TestOverloadUnaryBindingArgCountMismatch(t *testing.T) {
 	}
 }
 
+func TestOverloadContextUnaryBindingArgCountMismatch(t *testing.T) {
+	_, err := NewFunction("id",
+		Overload("id_any", []*types.Type{}, types.AnyType,
+			ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
+				return arg
+			}),
+		),
+	)
+	if err == nil || !strings.Contains(err.Error(), "non-unary overload") {
+		t.Errorf("NewFunction() got %v, wanted non-unary overload", err)
+	}
+}
+
+func TestOverloadContextBindingRedefinition(t *testing.T) {
+	_, err := NewFunction("id",
+		Overload("id_any", []*types.Type{types.AnyType}, types.AnyType,
+			UnaryBinding(func(arg ref.Val) ref.Val {
+				return arg
+			}),
+			ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
+				return arg
+			}),
+		),
+	)
+	if err == nil || !strings.Contains(err.Error(), "already has a binding") {
+		t.Errorf("NewFunction() got %v, wanted overload binding redefinition error", err)
+	}
+}
+
 func TestOverloadBinaryBindingArgCountMismatch(t *testing.T) {
 	_, err := NewFunction("id",
 		Overload("id_any", []*types.Type{}, types.AnyType,
@@ -873,6 +1014,35 @@ func TestOverloadIsNonStrict(t *testing.T) {
 	}
 }
 
+func TestOverloadIsDeterministic(t *testing.T) {
+	fn, err := NewFunction("lookup",
+		Overload("lookup_string", []*types.Type{types.StringType}, types.StringType,
+			OverloadIsDeterministic(),
+			UnaryBinding(func(arg ref.Val) ref.Val { return arg }),
+		),
+		Overload("lookup_int", []*types.Type{types.IntType}, types.StringType,
+			UnaryBinding(func(arg ref.Val) ref.Val { return types.String("int") }),
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewFunction() failed: %v", err)
+	}
+	if !fn.OverloadDecls()[0].IsDeterministic() || fn.OverloadDecls()[1].IsDeterministic() {
+		t.Error("IsDeterministic() got the wrong value for the overloads")
+	}
+	bindings, err := fn.Bindings()
+	if err != nil {
+		t.Fatalf("fn.Bindings() failed: %v", err)
+	}
+	// The dynamic dispatch binding is only deterministic if all of the overloads are.
+	want := map[string]bool{"lookup_string": true, "lookup_int": false, "lookup": false}
+	for _, b := range bindings {
+		if b.Deterministic != want[b.Operator] {
+			t.Errorf("binding %s got Deterministic %t, wanted %t", b.Operator, b.Deterministic, want[b.Operator])
+		}
+	}
+}
+
 func TestOverloadOperandTrait(t *testing.T) {
 	fn, err := NewFunction("getOrDefault",
 		MemberOverload("get",
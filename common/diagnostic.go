@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// DiagnosticsJSONVersion is the version of the JSON document produced by MarshalDiagnosticsJSON.
+const DiagnosticsJSONVersion = "1"
+
+// Diagnostic is the serializable form of an Error resolved against the Source in which it was
+// reported.
+//
+// The JSON encoding of a Diagnostic is stable and has the following schema:
+//
+//	{
+//	  "source":   string,  // Source.Description(), typically a file path.
+//	  "severity": string,  // "ERROR", "WARNING", or "INFO".
+//	  "code":     string,  // ErrorCode, omitted when unknown.
+//	  "message":  string,
+//	  "exprId":   number,  // Expression id, omitted when the issue is not tied to an expression.
+//	  "start":    Position, // Omitted when the location is unknown.
+//	  "end":      Position, // Omitted when the extent of the expression is unknown.
+//	  "snippet":  string,  // The source line containing the start position.
+//	  "details":  object   // Structured details documented alongside the ErrorCode.
+//	}
+//
+// Where a Position is {"line": number, "column": number, "offset": number} with a 1-based line,
+// a 1-based column, and a 0-based offset, all measured in unicode code points. The end position
+// is exclusive and refers to the code point immediately following the expression.
+type Diagnostic struct {
+	Source   string         `json:"source"`
+	Severity string         `json:"severity"`
+	Code     ErrorCode      `json:"code,omitempty"`
+	Message  string         `json:"message"`
+	ExprID   int64          `json:"exprId,omitempty"`
+	Start    *Position      `json:"start,omitempty"`
+	End      *Position      `json:"end,omitempty"`
+	Snippet  string         `json:"snippet,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// Position is a location within a Source expressed using 1-based lines and columns.
+type Position struct {
+	Line   int   `json:"line"`
+	Column int   `json:"column"`
+	Offset int32 `json:"offset"`
+}
+
+// NewDiagnostic creates a Diagnostic for the error as reported against the given source.
+//
+// The end location is optional and indicates the exclusive end of the expression associated with
+// the error. It is ignored if it is not a valid location within the source or if it precedes the
+// error location.
+func NewDiagnostic(source Source, err *Error, end Location) *Diagnostic {
+	if source == nil {
+		source = NewTextSource("")
+	}
+	diag := &Diagnostic{
+		Source:   source.Description(),
+		Severity: err.Severity.String(),
+		Code:     err.Code,
+		Message:  err.Message,
+		ExprID:   err.ExprID,
+		Details:  err.Details,
+	}
+	start, found := newPosition(source, err.Location)
+	if !found {
+		return diag
+	}
+	diag.Start = start
+	if snippet, found := source.Snippet(start.Line); found && len(snippet) <= maxSnippetLength {
+		diag.Snippet = strings.TrimRight(snippet, "\r")
+	}
+	if stop, found := newPosition(source, end); found && stop.Offset >= start.Offset {
+		diag.End = stop
+	}
+	return diag
+}
+
+// Diagnostics returns the reported errors as Diagnostic values in the order they were reported.
+//
+// The optional end function computes the exclusive end location for the expression associated with
+// an error, and may return nil or NoLocation when the extent of the expression is not known.
+func (e *Errors) Diagnostics(end func(*Error) Location) []*Diagnostic {
+	errs := e.GetErrors()
+	diags := make([]*Diagnostic, len(errs))
+	for i, err := range errs {
+		var stop Location
+		if end != nil {
+			stop = end(err)
+		}
+		diags[i] = NewDiagnostic(e.source, err, stop)
+	}
+	return diags
+}
+
+// MarshalDiagnosticsJSON encodes the diagnostics as a JSON document of the form:
+//
+//	{"version": "1", "diagnostics": [Diagnostic, ...]}
+//
+// See Diagnostic for the schema of individual entries.
+func MarshalDiagnosticsJSON(diags []*Diagnostic) ([]byte, error) {
+	if diags == nil {
+		diags = []*Diagnostic{}
+	}
+	return marshalIndent(&diagnosticsDocument{
+		Version:     DiagnosticsJSONVersion,
+		Diagnostics: diags,
+	})
+}
+
+// marshalIndent encodes the value as indented JSON without escaping HTML characters, since
+// diagnostic messages routinely include comparison operators.
+func marshalIndent(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type diagnosticsDocument struct {
+	Version     string        `json:"version"`
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+}
+
+func newPosition(source Source, loc Location) (*Position, bool) {
+	if loc == nil || loc.Line() < 1 || loc.Column() < 0 {
+		return nil, false
+	}
+	offset, found := source.LocationOffset(loc)
+	if !found {
+		return nil, false
+	}
+	return &Position{
+		Line:   loc.Line(),
+		Column: loc.Column() + 1, // add one to the 0-based column for display
+		Offset: offset,
+	}, true
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	source := NewStringSource("a.b\n&& c < d", "diag-test")
+	errs := NewErrors(source)
+	errs.ReportErrorWithCode(3, NewLocation(2, 3), ErrorCodeUndeclaredReference,
+		map[string]any{"name": "c"}, "undeclared reference to '%s'", "c")
+	errs.ReportWarningAtID(1, NewLocation(1, 0), "deprecated field")
+	errs.ReportError(NoLocation, "no location")
+
+	diags := errs.Diagnostics(func(err *Error) Location {
+		switch err.ExprID {
+		case 3:
+			return NewLocation(2, 4)
+		case 1:
+			// Invalid stop locations are ignored.
+			return NewLocation(0, 0)
+		}
+		return nil
+	})
+	want := []*Diagnostic{
+		{
+			Source:   "diag-test",
+			Severity: "ERROR",
+			Code:     ErrorCodeUndeclaredReference,
+			Message:  "undeclared reference to 'c'",
+			ExprID:   3,
+			Start:    &Position{Line: 2, Column: 4, Offset: 7},
+			End:      &Position{Line: 2, Column: 5, Offset: 8},
+			Snippet:  "&& c < d",
+			Details:  map[string]any{"name": "c"},
+		},
+		{
+			Source:   "diag-test",
+			Severity: "WARNING",
+			Message:  "deprecated field",
+			ExprID:   1,
+			Start:    &Position{Line: 1, Column: 1, Offset: 0},
+			Snippet:  "a.b",
+		},
+		{
+			Source:   "diag-test",
+			Severity: "ERROR",
+			Message:  "no location",
+		},
+	}
+	if !reflect.DeepEqual(diags, want) {
+		t.Errorf("Diagnostics() got %v, wanted %v", diags, want)
+	}
+
+	out, err := MarshalDiagnosticsJSON(diags)
+	if err != nil {
+		t.Fatalf("MarshalDiagnosticsJSON() failed: %v", err)
+	}
+	var doc struct {
+		Version     string        `json:"version"`
+		Diagnostics []*Diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if doc.Version != DiagnosticsJSONVersion || !reflect.DeepEqual(doc.Diagnostics, want) {
+		t.Errorf("MarshalDiagnosticsJSON() round-tripped to %v, wanted %v", doc.Diagnostics, want)
+	}
+}
+
+func TestDiagnosticsSARIF(t *testing.T) {
+	source := NewStringSource("a < b", "sarif-test.cel")
+	errs := NewErrors(source)
+	errs.ReportErrorWithCode(2, NewLocation(1, 4), ErrorCodeUndeclaredReference,
+		map[string]any{"name": "b"}, "undeclared reference to 'b'")
+	errs.ReportInfoAtID(0, NewLocation(1, 0), "consider a simpler form")
+	diags := errs.Diagnostics(func(err *Error) Location {
+		if err.ExprID == 2 {
+			return NewLocation(1, 5)
+		}
+		return nil
+	})
+	out, err := MarshalDiagnosticsSARIF(SARIFTool{Name: "celc", Version: "1.0"}, diags)
+	if err != nil {
+		t.Fatalf("MarshalDiagnosticsSARIF() failed: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	var want map[string]any
+	err = json.Unmarshal([]byte(`{
+	  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+	  "version": "2.1.0",
+	  "runs": [{
+	    "tool": {"driver": {
+	      "name": "celc",
+	      "version": "1.0",
+	      "rules": [{"id": "undeclared_reference"}]
+	    }},
+	    "columnKind": "unicodeCodePoints",
+	    "results": [
+	      {
+	        "ruleId": "undeclared_reference",
+	        "level": "error",
+	        "message": {"text": "undeclared reference to 'b'"},
+	        "locations": [{"physicalLocation": {
+	          "artifactLocation": {"uri": "sarif-test.cel"},
+	          "region": {"startLine": 1, "startColumn": 5, "endLine": 1, "endColumn": 6},
+	          "contextRegion": {"startLine": 1, "snippet": {"text": "a < b"}}
+	        }}],
+	        "properties": {"exprId": 2, "details": {"name": "b"}}
+	      },
+	      {
+	        "level": "note",
+	        "message": {"text": "consider a simpler form"},
+	        "locations": [{"physicalLocation": {
+	          "artifactLocation": {"uri": "sarif-test.cel"},
+	          "region": {"startLine": 1, "startColumn": 1},
+	          "contextRegion": {"startLine": 1, "snippet": {"text": "a < b"}}
+	        }}]
+	      }
+	    ]
+	  }]
+	}`), &want)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MarshalDiagnosticsSARIF() got %s", out)
+	}
+}
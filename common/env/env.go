@@ -24,6 +24,7 @@ import (
 
 	"github.com/google/cel-go/common/decls"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/traits"
 )
 
 // NewConfig creates an instance of a YAML serializable CEL environment configuration.
@@ -46,6 +47,7 @@ type Config struct {
 	StdLib          *LibrarySubset   `yaml:"stdlib,omitempty"`
 	Extensions      []*Extension     `yaml:"extensions,omitempty"`
 	ContextVariable *ContextVariable `yaml:"context_variable,omitempty"`
+	StructTypes     []*StructType    `yaml:"struct_types,omitempty"`
 	Variables       []*Variable      `yaml:"variables,omitempty"`
 	Functions       []*Function      `yaml:"functions,omitempty"`
 	Validators      []*Validator     `yaml:"validators,omitempty"`
@@ -78,6 +80,17 @@ func (c *Config) Validate() error {
 	if c.ContextVariable != nil && len(c.Variables) != 0 {
 		errs = append(errs, errors.New("invalid config: either context variable or variables may be set, but not both"))
 	}
+	structTypeNames := map[string]bool{}
+	for _, st := range c.StructTypes {
+		if err := st.Validate(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if structTypeNames[st.Name] {
+			errs = append(errs, fmt.Errorf("invalid struct type %q: duplicate type name", st.Name))
+		}
+		structTypeNames[st.Name] = true
+	}
 	for _, v := range c.Variables {
 		if err := v.Validate(); err != nil {
 			errs = append(errs, err)
@@ -134,6 +147,29 @@ func (c *Config) AddVariables(vars ...*Variable) *Config {
 	return c
 }
 
+// AddSchemaTypes adds one or more schema-defined struct types to the config, converting them to
+// serializable values first.
+func (c *Config) AddSchemaTypes(schemaTypes ...*types.SchemaType) *Config {
+	convTypes := make([]*StructType, len(schemaTypes))
+	for i, st := range schemaTypes {
+		if st == nil {
+			continue
+		}
+		fields := make([]*StructField, len(st.Fields()))
+		for j, f := range st.Fields() {
+			fields[j] = NewStructFieldWithDoc(f.Name, SerializeTypeDesc(f.Type), f.Description)
+		}
+		convTypes[i] = NewStructType(st.TypeName(), fields...)
+	}
+	return c.AddStructTypes(convTypes...)
+}
+
+// AddStructTypes adds one or more struct types to the config.
+func (c *Config) AddStructTypes(structTypes ...*StructType) *Config {
+	c.StructTypes = append(c.StructTypes, structTypes...)
+	return c
+}
+
 // SetContextVariable configures the ContextVariable for this configuration.
 func (c *Config) SetContextVariable(ctx *ContextVariable) *Config {
 	c.ContextVariable = ctx
@@ -313,6 +349,97 @@ func (v *Variable) AsCELVariable(tp types.Provider) (*decls.VariableDecl, error)
 	return decls.NewVariableWithDoc(v.Name, t, v.Description), nil
 }
 
+// NewStructType returns a serializable struct type from a qualified type name and a set of fields.
+func NewStructType(name string, fields ...*StructField) *StructType {
+	return &StructType{Name: name, Fields: fields}
+}
+
+// StructType represents a schema-defined struct type which will be published via the
+// cel.SchemaTypes() option.
+//
+// Field types may refer to any struct type declared within the same config, including the
+// struct type itself.
+type StructType struct {
+	Name   string         `yaml:"name"`
+	Fields []*StructField `yaml:"fields,omitempty"`
+}
+
+// Validate validates the struct type configuration is well-formed.
+func (st *StructType) Validate() error {
+	if st == nil {
+		return errors.New("invalid struct type: nil")
+	}
+	if st.Name == "" {
+		return errors.New("invalid struct type: missing type name")
+	}
+	fieldNames := map[string]bool{}
+	var errs []error
+	for _, f := range st.Fields {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid struct type %q: %w", st.Name, err))
+			continue
+		}
+		if fieldNames[f.Name] {
+			errs = append(errs, fmt.Errorf("invalid struct type %q: duplicate field %q", st.Name, f.Name))
+		}
+		fieldNames[f.Name] = true
+	}
+	return errors.Join(errs...)
+}
+
+// AsSchemaType converts the serializable form of the StructType into a schema-defined struct type.
+func (st *StructType) AsSchemaType(tp types.Provider) (*types.SchemaType, error) {
+	if err := st.Validate(); err != nil {
+		return nil, err
+	}
+	fields := make([]*types.SchemaField, len(st.Fields))
+	for i, f := range st.Fields {
+		t, err := f.Type.AsCELType(tp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid struct type %q: field %q: %w", st.Name, f.Name, err)
+		}
+		fields[i] = &types.SchemaField{Name: f.Name, Type: t, Description: f.Description}
+	}
+	return types.NewSchemaType(st.Name, fields...)
+}
+
+// NewStructField returns a serializable struct field from a name and type definition.
+func NewStructField(name string, t *TypeDesc) *StructField {
+	return NewStructFieldWithDoc(name, t, "")
+}
+
+// NewStructFieldWithDoc returns a serializable struct field from a name, type definition, and doc string.
+func NewStructFieldWithDoc(name string, t *TypeDesc, doc string) *StructField {
+	return &StructField{Name: name, Type: t, Description: doc}
+}
+
+// StructField represents a named and typed field of a StructType.
+type StructField struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description,omitempty"`
+	Type        *TypeDesc `yaml:"type"`
+}
+
+// Validate validates the struct field configuration is well-formed.
+func (f *StructField) Validate() error {
+	if f == nil {
+		return errors.New("invalid field: nil")
+	}
+	if f.Name == "" {
+		return errors.New("invalid field: missing field name")
+	}
+	if f.Type == nil {
+		return fmt.Errorf("invalid field %q: missing type", f.Name)
+	}
+	if err := f.Type.Validate(); err != nil {
+		return fmt.Errorf("invalid field %q: %w", f.Name, err)
+	}
+	if f.Type.IsTypeParam {
+		return fmt.Errorf("invalid field %q: fields cannot be type parameters", f.Name)
+	}
+	return nil
+}
+
 // NewContextVariable returns a serializable context variable with a specific type name.
 func NewContextVariable(typeName string) *ContextVariable {
 	return &ContextVariable{TypeName: typeName}
@@ -784,11 +911,33 @@ func NewTypeParam(paramName string) *TypeDesc {
 	return &TypeDesc{TypeName: paramName, IsTypeParam: true}
 }
 
+// NewBoundedTypeParam describes a type-param type which may only be bound to one of the bounds.
+func NewBoundedTypeParam(paramName string, bounds ...*TypeDesc) *TypeDesc {
+	return &TypeDesc{TypeName: paramName, IsTypeParam: true, Bounds: bounds}
+}
+
+// NewTraitTypeParam describes a type-param type which may only be bound to types which support the
+// named traits, e.g. "adder" or "comparer".
+func NewTraitTypeParam(paramName string, traitNames ...string) *TypeDesc {
+	return &TypeDesc{TypeName: paramName, IsTypeParam: true, Traits: traitNames}
+}
+
+// NewUnionTypeDesc describes a union of two or more member types, e.g. "string | list<string>".
+func NewUnionTypeDesc(members ...*TypeDesc) *TypeDesc {
+	return &TypeDesc{TypeName: unionTypeName, Params: members}
+}
+
 // TypeDesc represents the serializable format of a CEL *types.Type value.
+//
+// Type parameters may be constrained either by a set of Bounds listing the types to which the
+// parameter may be bound, or by a set of Traits the bound type must support. The trait names
+// mirror the flags in the common/types/traits package, e.g. "adder" for traits.AdderType.
 type TypeDesc struct {
 	TypeName    string      `yaml:"type_name"`
 	Params      []*TypeDesc `yaml:"params,omitempty"`
 	IsTypeParam bool        `yaml:"is_type_param,omitempty"`
+	Bounds      []*TypeDesc `yaml:"bounds,omitempty"`
+	Traits      []string    `yaml:"traits,omitempty"`
 }
 
 // String implements the strings.Stringer interface method.
@@ -797,6 +946,9 @@ func (td *TypeDesc) String() string {
 	for i, p := range td.Params {
 		ps[i] = p.String()
 	}
+	if td.TypeName == unionTypeName && len(ps) != 0 {
+		return strings.Join(ps, " | ")
+	}
 	typeName := td.TypeName
 	if len(ps) != 0 {
 		typeName = fmt.Sprintf("%s(%s)", typeName, strings.Join(ps, ","))
@@ -815,6 +967,25 @@ func (td *TypeDesc) Validate() error {
 	if td.IsTypeParam && len(td.Params) != 0 {
 		return errors.New("invalid type: param type cannot have parameters")
 	}
+	if !td.IsTypeParam && (len(td.Bounds) != 0 || len(td.Traits) != 0) {
+		return fmt.Errorf("invalid type: only type params may have bounds or traits: %s", td.TypeName)
+	}
+	if len(td.Bounds) != 0 && len(td.Traits) != 0 {
+		return fmt.Errorf("invalid type: type param %s cannot have both bounds and traits", td.TypeName)
+	}
+	for _, b := range td.Bounds {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		if b.IsTypeParam {
+			return fmt.Errorf("invalid type: type param %s cannot be bounded by type param %s", td.TypeName, b.TypeName)
+		}
+	}
+	for _, name := range td.Traits {
+		if _, found := traits.FromName(name); !found {
+			return fmt.Errorf("invalid type: type param %s has unknown trait %q", td.TypeName, name)
+		}
+	}
 	switch td.TypeName {
 	case "list":
 		if len(td.Params) != 1 {
@@ -844,6 +1015,18 @@ func (td *TypeDesc) Validate() error {
 			return fmt.Errorf("invalid type: type expects 0 or 1 parameters, got %d", len(td.Params))
 		}
 		return td.Params[0].Validate()
+	case unionTypeName:
+		if len(td.Params) < 2 {
+			return fmt.Errorf("invalid type: union expects at least 2 parameters, got %d", len(td.Params))
+		}
+		for _, m := range td.Params {
+			if err := m.Validate(); err != nil {
+				return err
+			}
+			if m.IsTypeParam {
+				return fmt.Errorf("invalid type: union cannot have type param member %s", m.TypeName)
+			}
+		}
 	default:
 	}
 	return nil
@@ -855,8 +1038,17 @@ func formatSpecifierImpl(td *TypeDesc, sb *strings.Builder) {
 		sb.WriteString(td.TypeName)
 		return
 	}
-	sb.WriteString(td.TypeName)
 	l := len(td.Params)
+	if td.TypeName == unionTypeName && l != 0 {
+		for i, p := range td.Params {
+			formatSpecifierImpl(p, sb)
+			if i < l-1 {
+				sb.WriteString(" | ")
+			}
+		}
+		return
+	}
+	sb.WriteString(td.TypeName)
 	if l < 1 {
 		return
 	}
@@ -938,9 +1130,18 @@ func (td *TypeDesc) AsCELType(tp types.Provider) (*types.Type, error) {
 			return nil, err
 		}
 		return types.NewTypeTypeWithParam(pt), nil
+	case unionTypeName:
+		members := make([]*types.Type, len(td.Params))
+		for i, p := range td.Params {
+			members[i], err = p.AsCELType(tp)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return types.NewUnionType(members...), nil
 	default:
 		if td.IsTypeParam {
-			return types.NewTypeParamType(td.TypeName), nil
+			return td.typeParamAsCELType(tp)
 		}
 		if msgType, found := tp.FindStructType(td.TypeName); found {
 			// First parameter is the type name.
@@ -965,10 +1166,43 @@ func (td *TypeDesc) AsCELType(tp types.Provider) (*types.Type, error) {
 	}
 }
 
+func (td *TypeDesc) typeParamAsCELType(tp types.Provider) (*types.Type, error) {
+	if len(td.Traits) != 0 {
+		traitMask := 0
+		for _, name := range td.Traits {
+			trait, _ := traits.FromName(name)
+			traitMask |= trait
+		}
+		return types.NewTypeParamTypeWithTraits(td.TypeName, traitMask), nil
+	}
+	if len(td.Bounds) != 0 {
+		bounds := make([]*types.Type, len(td.Bounds))
+		for i, b := range td.Bounds {
+			bt, err := b.AsCELType(tp)
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = bt
+		}
+		return types.NewTypeParamTypeWithBounds(td.TypeName, bounds...), nil
+	}
+	return types.NewTypeParamType(td.TypeName), nil
+}
+
 // SerializeTypeDesc converts a CEL native *types.Type to a serializable TypeDesc.
 func SerializeTypeDesc(t *types.Type) *TypeDesc {
 	typeName := t.TypeName()
 	if t.Kind() == types.TypeParamKind {
+		if traitMask := t.TypeParamTraits(); traitMask != 0 {
+			return NewTraitTypeParam(typeName, traits.Names(traitMask)...)
+		}
+		if bounds := t.TypeParamBounds(); len(bounds) != 0 {
+			boundDescs := make([]*TypeDesc, len(bounds))
+			for i, b := range bounds {
+				boundDescs[i] = SerializeTypeDesc(b)
+			}
+			return NewBoundedTypeParam(typeName, boundDescs...)
+		}
 		return NewTypeParam(typeName)
 	}
 	if t != types.NullType && t.IsAssignableType(types.NullType) {
@@ -980,6 +1214,9 @@ func SerializeTypeDesc(t *types.Type) *TypeDesc {
 	for _, p := range t.Parameters() {
 		params = append(params, SerializeTypeDesc(p))
 	}
+	if t.Kind() == types.UnionKind {
+		return NewUnionTypeDesc(params...)
+	}
 	// Special types, these aren't useful for describing environments.
 	switch t.Kind() {
 	case types.ErrorKind:
@@ -992,6 +1229,8 @@ func SerializeTypeDesc(t *types.Type) *TypeDesc {
 	return NewTypeDesc(typeName, params...)
 }
 
+const unionTypeName = "union"
+
 var wrapperTypes = map[types.Kind]string{
 	types.BoolKind:   "google.protobuf.BoolValue",
 	types.BytesKind:  "google.protobuf.BytesValue",
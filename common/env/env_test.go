@@ -30,6 +30,7 @@ import (
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/traits"
 )
 
 func TestConfig(t *testing.T) {
@@ -359,6 +360,37 @@ func TestConfigValidateErrors(t *testing.T) {
 			in:   NewConfig("invalid variable").AddVariables(NewVariable("foo", NewTypeParam("X"))),
 			want: errors.New("variables cannot be type parameters"),
 		},
+		{
+			name: "invalid struct type",
+			in:   NewConfig("invalid struct type").AddStructTypes(NewStructType("")),
+			want: errors.New("invalid struct type: missing type name"),
+		},
+		{
+			name: "invalid struct field",
+			in: NewConfig("invalid struct field").AddStructTypes(
+				NewStructType("example.Node", NewStructField("next", nil))),
+			want: errors.New(`invalid struct type "example.Node": invalid field "next": missing type`),
+		},
+		{
+			name: "duplicate struct field",
+			in: NewConfig("duplicate struct field").AddStructTypes(
+				NewStructType("example.Node",
+					NewStructField("value", NewTypeDesc("int")),
+					NewStructField("value", NewTypeDesc("string")))),
+			want: errors.New(`duplicate field "value"`),
+		},
+		{
+			name: "type param struct field",
+			in: NewConfig("type param struct field").AddStructTypes(
+				NewStructType("example.Node", NewStructField("value", NewTypeParam("T")))),
+			want: errors.New("fields cannot be type parameters"),
+		},
+		{
+			name: "duplicate struct type",
+			in: NewConfig("duplicate struct type").AddStructTypes(
+				NewStructType("example.Node"), NewStructType("example.Node")),
+			want: errors.New(`invalid struct type "example.Node": duplicate type name`),
+		},
 		{
 			name: "colliding context variable",
 			in: NewConfig("colliding context variable").
@@ -669,6 +701,13 @@ func TestVariableAsCELVariable(t *testing.T) {
 			},
 			want: errors.New("expects 0 or 1 parameters"),
 		},
+		{
+			name: "union type",
+			v: NewVariable("union_var",
+				NewUnionTypeDesc(NewTypeDesc("string"), NewTypeDesc("list", NewTypeDesc("string")))),
+			want: decls.NewVariable("union_var",
+				types.NewUnionType(types.StringType, types.NewListType(types.StringType))),
+		},
 		{
 			name: "int type",
 			v:    NewVariable("int_var", NewTypeDesc("int")),
@@ -783,6 +822,7 @@ func TestTypeDescString(t *testing.T) {
 		{desc: NewTypeDesc("list", NewTypeParam("T")), want: "list(T)"},
 		{desc: NewTypeDesc("type", NewTypeParam("T")), want: "type(T)"},
 		{desc: NewTypeDesc("map", NewTypeDesc("string"), NewTypeParam("T")), want: "map(string,T)"},
+		{desc: NewUnionTypeDesc(NewTypeDesc("int"), NewTypeDesc("list", NewTypeDesc("string"))), want: "int | list(string)"},
 	}
 	for _, tc := range tests {
 		if tc.desc.String() != tc.want {
@@ -996,6 +1036,41 @@ func TestTypeDescAsCELTypeErrors(t *testing.T) {
 			t:    &TypeDesc{TypeName: "T", IsTypeParam: true, Params: []*TypeDesc{{TypeName: "string"}}},
 			want: errors.New("invalid type: param type"),
 		},
+		{
+			name: "bounds on concrete type",
+			t:    &TypeDesc{TypeName: "list", Bounds: []*TypeDesc{{TypeName: "int"}}},
+			want: errors.New("only type params may have bounds or traits"),
+		},
+		{
+			name: "bounds and traits",
+			t:    &TypeDesc{TypeName: "T", IsTypeParam: true, Bounds: []*TypeDesc{{TypeName: "int"}}, Traits: []string{"adder"}},
+			want: errors.New("cannot have both bounds and traits"),
+		},
+		{
+			name: "type param bound",
+			t:    NewBoundedTypeParam("T", NewTypeParam("U")),
+			want: errors.New("cannot be bounded by type param U"),
+		},
+		{
+			name: "unknown trait",
+			t:    NewTraitTypeParam("T", "addable"),
+			want: errors.New(`unknown trait "addable"`),
+		},
+		{
+			name: "undefined bound type",
+			t:    NewBoundedTypeParam("T", NewTypeDesc("undefined")),
+			want: errors.New("undefined type name"),
+		},
+		{
+			name: "single member union",
+			t:    NewUnionTypeDesc(NewTypeDesc("int")),
+			want: errors.New("union expects at least 2 parameters"),
+		},
+		{
+			name: "type param union member",
+			t:    NewUnionTypeDesc(NewTypeDesc("int"), NewTypeParam("T")),
+			want: errors.New("union cannot have type param member T"),
+		},
 		{
 			name: "invalid list",
 			t:    &TypeDesc{TypeName: "list"},
@@ -1073,6 +1148,67 @@ func TestTypeDescAsCELTypeErrors(t *testing.T) {
 	}
 }
 
+func TestTypeDescTypeParamConstraints(t *testing.T) {
+	tp, err := types.NewProtoRegistry()
+	if err != nil {
+		t.Fatalf("types.NewProtoRegistry() failed: %v", err)
+	}
+	bounded := NewBoundedTypeParam("T", NewTypeDesc("int"), NewTypeDesc("list", NewTypeDesc("string")))
+	bt, err := bounded.AsCELType(tp)
+	if err != nil {
+		t.Fatalf("AsCELType() failed: %v", err)
+	}
+	bounds := bt.TypeParamBounds()
+	if len(bounds) != 2 || bounds[0] != types.IntType || !bounds[1].IsExactType(types.NewListType(types.StringType)) {
+		t.Errorf("AsCELType() got bounds %v, wanted [int, list(string)]", bounds)
+	}
+	if got := SerializeTypeDesc(bt); !reflect.DeepEqual(got, bounded) {
+		t.Errorf("SerializeTypeDesc() got %v, wanted %v", got, bounded)
+	}
+
+	withTraits := NewTraitTypeParam("T", "adder", "comparer")
+	tt, err := withTraits.AsCELType(tp)
+	if err != nil {
+		t.Fatalf("AsCELType() failed: %v", err)
+	}
+	if tt.TypeParamTraits() != traits.AdderType|traits.ComparerType {
+		t.Errorf("AsCELType() got traits %d, wanted adder and comparer", tt.TypeParamTraits())
+	}
+	if got := SerializeTypeDesc(tt); !reflect.DeepEqual(got, withTraits) {
+		t.Errorf("SerializeTypeDesc() got %v, wanted %v", got, withTraits)
+	}
+}
+
+func TestTypeDescUnion(t *testing.T) {
+	tp, err := types.NewProtoRegistry()
+	if err != nil {
+		t.Fatalf("types.NewProtoRegistry() failed: %v", err)
+	}
+	union := NewUnionTypeDesc(NewTypeDesc("string"), NewTypeDesc("list", NewTypeDesc("string")))
+	if got := union.SpecifierFormat(); got != "string | list<string>" {
+		t.Errorf("SpecifierFormat() got %s, wanted string | list<string>", got)
+	}
+	ut, err := union.AsCELType(tp)
+	if err != nil {
+		t.Fatalf("AsCELType() failed: %v", err)
+	}
+	if !ut.IsExactType(types.NewUnionType(types.StringType, types.NewListType(types.StringType))) {
+		t.Errorf("AsCELType() got %v, wanted string | list(string)", ut)
+	}
+	if got := SerializeTypeDesc(ut); !reflect.DeepEqual(got, union) {
+		t.Errorf("SerializeTypeDesc() got %v, wanted %v", got, union)
+	}
+
+	// A union of a primitive type and null is the nullable primitive type.
+	nullable, err := NewUnionTypeDesc(NewTypeDesc("int"), NewTypeDesc("null")).AsCELType(tp)
+	if err != nil {
+		t.Fatalf("AsCELType() failed: %v", err)
+	}
+	if !nullable.IsExactType(types.NewNullableType(types.IntType)) {
+		t.Errorf("AsCELType() got %v, wanted wrapper(int)", nullable)
+	}
+}
+
 func TestLibrarySubsetValidate(t *testing.T) {
 	tests := []struct {
 		name string
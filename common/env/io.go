@@ -25,6 +25,8 @@ type internalTypeDesc struct {
 	TypeName    string      `yaml:"type_name"`
 	Params      []*TypeDesc `yaml:"params,omitempty"`
 	IsTypeParam bool        `yaml:"is_type_param,omitempty"`
+	Bounds      []*TypeDesc `yaml:"bounds,omitempty"`
+	Traits      []string    `yaml:"traits,omitempty"`
 }
 
 // Embedding TypeDesc in variable causes issues with customizing
@@ -104,6 +106,8 @@ func (td *TypeDesc) UnmarshalYAML(n *yaml.Node) error {
 	td.TypeName = buf.TypeName
 	td.Params = buf.Params
 	td.IsTypeParam = buf.IsTypeParam
+	td.Bounds = buf.Bounds
+	td.Traits = buf.Traits
 	return nil
 }
 
@@ -114,9 +118,11 @@ type typeDescParser struct {
 }
 
 // ParseTypeDesc parses a TypeDesc from the type specifier format: "map<string, int>"
+//
+// Union types are specified by separating the member types with '|', e.g. "string | list<string>".
 func ParseTypeDesc(text string) (*TypeDesc, error) {
 	p := &typeDescParser{text: text, length: len(text)}
-	res, err := p.parseTypeElem()
+	res, err := p.parseType()
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse type %q: %v", text, err)
 	}
@@ -137,7 +143,7 @@ func (p *typeDescParser) parseConcreteType() (*TypeDesc, error) {
 		var params []*TypeDesc
 		for {
 			p.skipWhitespace()
-			param, err := p.parseTypeElem()
+			param, err := p.parseType()
 			if err != nil {
 				return nil, err
 			}
@@ -158,6 +164,28 @@ func (p *typeDescParser) parseConcreteType() (*TypeDesc, error) {
 	return NewTypeDesc(id), nil
 }
 
+func (p *typeDescParser) parseType() (*TypeDesc, error) {
+	elem, err := p.parseTypeElem()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos >= p.length || p.text[p.pos] != '|' {
+		return elem, nil
+	}
+	members := []*TypeDesc{elem}
+	for p.pos < p.length && p.text[p.pos] == '|' {
+		p.pos++ // consume '|'
+		member, err := p.parseTypeElem()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+		p.skipWhitespace()
+	}
+	return NewUnionTypeDesc(members...), nil
+}
+
 func (p *typeDescParser) parseTypeElem() (*TypeDesc, error) {
 	p.skipWhitespace()
 	if p.pos < p.length && p.text[p.pos] == '~' {
@@ -51,6 +51,15 @@ func TestParseTypeDesc(t *testing.T) {
 			"map<int, list<string>>",
 			NewTypeDesc("map", NewTypeDesc("int"), NewTypeDesc("list", NewTypeDesc("string"))),
 		},
+		{
+			"string | list<string>",
+			NewUnionTypeDesc(NewTypeDesc("string"), NewTypeDesc("list", NewTypeDesc("string"))),
+		},
+		{
+			"map<string, int|string|null>",
+			NewTypeDesc("map", NewTypeDesc("string"),
+				NewUnionTypeDesc(NewTypeDesc("int"), NewTypeDesc("string"), NewTypeDesc("null"))),
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.text, func(t *testing.T) {
@@ -106,6 +115,14 @@ func TestParseTypeDescErrors(t *testing.T) {
 			"list<",
 			"missing identifier at position 5",
 		},
+		{
+			"int |",
+			"missing identifier at position 5",
+		},
+		{
+			"| int",
+			"identifier is expected, but '|' was found at position 0",
+		},
 	}
 	for _, tc := range tcs {
 		t.Run(tc.text, func(t *testing.T) {
@@ -206,6 +223,24 @@ variables:
       params:
         - type_name: int
         - type_name: string
+`,
+		},
+		{
+			name: "union specifier type",
+			yamlIn: `name: foo
+variables:
+    - name: foo
+      type: string | list<string>
+`,
+			yamlOut: `name: foo
+variables:
+    - name: foo
+      type_name: union
+      params:
+        - type_name: string
+        - type_name: list
+          params:
+            - type_name: string
 `,
 		},
 		{
@@ -235,6 +270,92 @@ functions:
           return:
             type_name: V
             is_type_param: true
+`,
+		},
+		{
+			name: "constrained type params",
+			yamlIn: `name: foo
+functions:
+    - name: sum
+      overloads:
+          - id: sum_list
+            args:
+                - type_name: list
+                  params:
+                    - type_name: T
+                      is_type_param: true
+                      bounds: [int, uint, double]
+            return:
+                type_name: T
+                is_type_param: true
+                bounds: [int, uint, double]
+          - id: sum_set
+            args:
+                - type_name: set
+                  params:
+                    - type_name: T
+                      is_type_param: true
+                      traits: [adder]
+            return: bool
+`,
+			yamlOut: `name: foo
+functions:
+    - name: sum
+      overloads:
+        - id: sum_list
+          args:
+            - type_name: list
+              params:
+                - type_name: T
+                  is_type_param: true
+                  bounds:
+                    - type_name: int
+                    - type_name: uint
+                    - type_name: double
+          return:
+            type_name: T
+            is_type_param: true
+            bounds:
+                - type_name: int
+                - type_name: uint
+                - type_name: double
+        - id: sum_set
+          args:
+            - type_name: set
+              params:
+                - type_name: T
+                  is_type_param: true
+                  traits:
+                    - adder
+          return:
+            type_name: bool
+`,
+		},
+		{
+			name: "struct types",
+			yamlIn: `name: foo
+struct_types:
+    - name: example.Node
+      fields:
+          - name: value
+            type: int
+            description: node value
+          - name: children
+            type: list<example.Node>
+`,
+			yamlOut: `name: foo
+struct_types:
+    - name: example.Node
+      fields:
+        - name: value
+          description: node value
+          type:
+            type_name: int
+        - name: children
+          type:
+            type_name: list
+            params:
+                - type_name: example.Node
 `,
 		},
 	}
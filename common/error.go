@@ -26,10 +26,47 @@ func NewError(id int64, message string, location Location) *Error {
 }
 
 // Error type which references an expression id, a location within source, and a message.
+//
+// The Severity of an Error defaults to SeverityError. Issues reported with a lesser severity are
+// informational and do not cause parsing, checking, or validation to fail.
 type Error struct {
 	Location Location
 	Message  string
 	ExprID   int64
+	Severity Severity
+
+	// Code identifies the kind of issue, if known. See the ErrorCode constants for details.
+	Code ErrorCode
+
+	// Details holds structured arguments associated with the issue, keyed by name. The keys
+	// reported for each ErrorCode are documented alongside the code.
+	Details map[string]any
+}
+
+// Severity indicates the significance of a reported issue.
+type Severity int
+
+const (
+	// SeverityError indicates an issue which prevents the expression from being used.
+	SeverityError Severity = iota
+
+	// SeverityWarning indicates a likely problem which does not prevent the expression from being used.
+	SeverityWarning
+
+	// SeverityInfo indicates an informational notice, such as a style suggestion.
+	SeverityInfo
+)
+
+// String returns the display name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityInfo:
+		return "INFO"
+	default:
+		return "ERROR"
+	}
 }
 
 const (
@@ -44,7 +81,8 @@ const (
 
 // ToDisplayString decorates the error message with the source location.
 func (e *Error) ToDisplayString(source Source) string {
-	var result = fmt.Sprintf("ERROR: %s:%d:%d: %s",
+	var result = fmt.Sprintf("%s: %s:%d:%d: %s",
+		e.Severity,
 		source.Description(),
 		e.Location.Line(),
 		e.Location.Column()+1, // add one to the 0-based column for display
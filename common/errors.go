@@ -53,60 +53,123 @@ func (e *Errors) ReportErrorString(l Location, message string) {
 
 // ReportErrorAtID records an error at a source location and expression id.
 func (e *Errors) ReportErrorAtID(id int64, l Location, format string, args ...any) {
-	e.numErrors++
-	if e.numErrors > e.maxErrorsToReport {
-		return
+	e.reportAtID(SeverityError, id, l, format, args...)
+}
+
+// ReportWarningAtID records a warning at a source location and expression id.
+//
+// Warnings are included in the display string, but do not count as errors for the purpose of HasErrors.
+func (e *Errors) ReportWarningAtID(id int64, l Location, format string, args ...any) {
+	e.reportAtID(SeverityWarning, id, l, format, args...)
+}
+
+// ReportInfoAtID records an informational notice at a source location and expression id.
+//
+// Notices are included in the display string, but do not count as errors for the purpose of HasErrors.
+func (e *Errors) ReportInfoAtID(id int64, l Location, format string, args ...any) {
+	e.reportAtID(SeverityInfo, id, l, format, args...)
+}
+
+// ReportErrorWithCode records an error with a stable error code and structured details at a source
+// location and expression id.
+func (e *Errors) ReportErrorWithCode(id int64, l Location, code ErrorCode, details map[string]any, format string, args ...any) {
+	e.reportWithCode(SeverityError, id, l, code, details, format, args...)
+}
+
+// ReportWarningWithCode records a warning with a stable error code and structured details at a
+// source location and expression id.
+func (e *Errors) ReportWarningWithCode(id int64, l Location, code ErrorCode, details map[string]any, format string, args ...any) {
+	e.reportWithCode(SeverityWarning, id, l, code, details, format, args...)
+}
+
+// ReportInfoWithCode records an informational notice with a stable error code and structured
+// details at a source location and expression id.
+func (e *Errors) ReportInfoWithCode(id int64, l Location, code ErrorCode, details map[string]any, format string, args ...any) {
+	e.reportWithCode(SeverityInfo, id, l, code, details, format, args...)
+}
+
+// ReportIssue records a fully specified issue.
+//
+// Only issues with SeverityError count toward the limit on the number of reported errors.
+func (e *Errors) ReportIssue(err *Error) {
+	if err.Severity == SeverityError {
+		e.numErrors++
+		if e.numErrors > e.maxErrorsToReport {
+			return
+		}
 	}
-	err := &Error{
+	e.errors = append(e.errors, err)
+}
+
+func (e *Errors) reportAtID(severity Severity, id int64, l Location, format string, args ...any) {
+	e.ReportIssue(&Error{
 		ExprID:   id,
 		Location: l,
 		Message:  fmt.Sprintf(format, args...),
-	}
-	e.errors = append(e.errors, err)
+		Severity: severity,
+	})
+}
+
+func (e *Errors) reportWithCode(severity Severity, id int64, l Location, code ErrorCode, details map[string]any, format string, args ...any) {
+	e.ReportIssue(&Error{
+		ExprID:   id,
+		Location: l,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: severity,
+		Code:     code,
+		Details:  details,
+	})
 }
 
-// GetErrors returns the list of observed errors.
+// GetErrors returns the list of observed issues of all severities.
 func (e *Errors) GetErrors() []*Error {
 	return e.errors[:]
 }
 
+// HasErrors returns whether any of the observed issues has SeverityError.
+func (e *Errors) HasErrors() bool {
+	return e.numErrors > 0
+}
+
 // Append creates a new Errors object with the current and input errors.
 func (e *Errors) Append(errs []*Error) *Errors {
+	numErrors := e.numErrors
+	for _, err := range errs {
+		if err.Severity == SeverityError {
+			numErrors++
+		}
+	}
 	return &Errors{
 		errors:            append(e.errors[:], errs...),
 		source:            e.source,
-		numErrors:         e.numErrors + len(errs),
+		numErrors:         numErrors,
 		maxErrorsToReport: e.maxErrorsToReport,
 	}
 }
 
 // ToDisplayString returns the error set to a newline delimited string.
 func (e *Errors) ToDisplayString() string {
-	errorsInString := e.maxErrorsToReport
-	if e.numErrors > e.maxErrorsToReport {
-		// add one more error to indicate the number of errors truncated.
-		errorsInString++
-	} else {
-		// otherwise the error set will just contain the number of errors.
-		errorsInString = e.numErrors
-	}
-
-	result := make([]string, errorsInString)
 	sort.SliceStable(e.errors, func(i, j int) bool {
 		ei := e.errors[i].Location
 		ej := e.errors[j].Location
 		return ei.Line() < ej.Line() ||
 			(ei.Line() == ej.Line() && ei.Column() < ej.Column())
 	})
-	for i, err := range e.errors {
-		// This can happen during the append of two errors objects
-		if i >= e.maxErrorsToReport {
-			break
+	result := make([]string, 0, len(e.errors)+1)
+	reportedErrors := 0
+	for _, err := range e.errors {
+		if err.Severity == SeverityError {
+			// This can happen during the append of two errors objects
+			if reportedErrors >= e.maxErrorsToReport {
+				continue
+			}
+			reportedErrors++
 		}
-		result[i] = err.ToDisplayString(e.source)
+		result = append(result, err.ToDisplayString(e.source))
 	}
 	if e.numErrors > e.maxErrorsToReport {
-		result[e.maxErrorsToReport] = fmt.Sprintf("%d more errors were truncated", e.numErrors-e.maxErrorsToReport)
+		// add one more error to indicate the number of errors truncated.
+		result = append(result, fmt.Sprintf("%d more errors were truncated", e.numErrors-e.maxErrorsToReport))
 	}
 	return strings.Join(result, "\n")
 }
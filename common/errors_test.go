@@ -46,6 +46,76 @@ func TestErrors(t *testing.T) {
 	}
 }
 
+func TestErrorsSeverity(t *testing.T) {
+	source := NewStringSource("a.b && c", "errors-test")
+	errors := NewErrors(source)
+	errors.ReportWarningAtID(1, NewLocation(1, 1), "deprecated field %s", "b")
+	errors.ReportInfoAtID(2, NewLocation(1, 7), "consider a simpler form")
+	if errors.HasErrors() {
+		t.Error("HasErrors() got true for warnings and notices only")
+	}
+	if len(errors.GetErrors()) != 2 {
+		t.Fatalf("GetErrors() got %d issues, wanted 2", len(errors.GetErrors()))
+	}
+	if errors.GetErrors()[0].Severity != SeverityWarning || errors.GetErrors()[1].Severity != SeverityInfo {
+		t.Errorf("GetErrors() got severities %v, %v, wanted WARNING, INFO",
+			errors.GetErrors()[0].Severity, errors.GetErrors()[1].Severity)
+	}
+	got := errors.ToDisplayString()
+	want :=
+		"WARNING: errors-test:1:2: deprecated field b\n" +
+			" | a.b && c\n" +
+			" | .^\n" +
+			"INFO: errors-test:1:8: consider a simpler form\n" +
+			" | a.b && c\n" +
+			" | .......^"
+	if got != want {
+		t.Errorf("ToDisplayString() got %s, wanted %s", got, want)
+	}
+	appended := errors.Append([]*Error{NewError(3, "fatal", NewLocation(1, 0))})
+	if !appended.HasErrors() {
+		t.Error("HasErrors() got false after appending an error")
+	}
+	if errors.HasErrors() {
+		t.Error("HasErrors() on the original errors got true after Append()")
+	}
+}
+
+func TestErrorsSeverityWithCode(t *testing.T) {
+	source := NewStringSource("a.b && c", "errors-test")
+	errors := NewErrors(source)
+	errors.ReportWarningWithCode(1, NewLocation(1, 1), ErrorCodeUndeclaredReference,
+		map[string]any{"name": "b"}, "deprecated field %s", "b")
+	errors.ReportInfoWithCode(2, NewLocation(1, 7), ErrorCodeInternal, nil, "consider a simpler form")
+	if errors.HasErrors() {
+		t.Error("HasErrors() got true for warnings and notices only")
+	}
+	errors.ReportErrorWithCode(3, NewLocation(1, 0), ErrorCodeSyntax, nil, "unexpected token")
+	if !errors.HasErrors() {
+		t.Error("HasErrors() got false after reporting an error")
+	}
+	issues := errors.GetErrors()
+	if len(issues) != 3 {
+		t.Fatalf("GetErrors() got %d issues, wanted 3", len(issues))
+	}
+	want := []struct {
+		severity Severity
+		code     ErrorCode
+	}{
+		{SeverityWarning, ErrorCodeUndeclaredReference},
+		{SeverityInfo, ErrorCodeInternal},
+		{SeverityError, ErrorCodeSyntax},
+	}
+	for i, w := range want {
+		if issues[i].Severity != w.severity || issues[i].Code != w.code {
+			t.Errorf("GetErrors()[%d] got %v %s, wanted %v %s", i, issues[i].Severity, issues[i].Code, w.severity, w.code)
+		}
+	}
+	if issues[0].Details["name"] != "b" {
+		t.Errorf("GetErrors()[0].Details got %v, wanted name: b", issues[0].Details)
+	}
+}
+
 func TestErrorsReportingLimit(t *testing.T) {
 	errors := NewErrors(NewTextSource("hello world"))
 	for i := 0; i < 2*errors.maxErrorsToReport; i++ {
@@ -56,6 +126,24 @@ func TestErrorsReportingLimit(t *testing.T) {
 	}
 }
 
+func TestErrorsReportingLimitWarnings(t *testing.T) {
+	errors := NewErrors(NewTextSource("hello world"))
+	for i := 0; i < 2*errors.maxErrorsToReport; i++ {
+		errors.ReportWarningAtID(0, NoLocation, "warning %d", i)
+	}
+	errors.ReportError(NoLocation, "error")
+	if !errors.HasErrors() {
+		t.Error("HasErrors() got false, wanted true")
+	}
+	if got := len(errors.GetErrors()); got != 2*errors.maxErrorsToReport+1 {
+		t.Errorf("GetErrors() got %d issues, wanted %d", got, 2*errors.maxErrorsToReport+1)
+	}
+	got := errors.ToDisplayString()
+	if !strings.Contains(got, "ERROR: <input>:-1:0: error") || strings.Contains(got, "truncated") {
+		t.Errorf("ToDisplayString() got %s, wanted the error without truncation", got)
+	}
+}
+
 func TestErrorsAppendReportingLimit(t *testing.T) {
 	errors := NewErrors(NewTextSource("hello world"))
 	for i := 0; i < 75; i++ {
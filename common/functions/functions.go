@@ -15,7 +15,11 @@
 // Package functions defines the standard builtin functions supported by the interpreter
 package functions
 
-import "github.com/google/cel-go/common/types/ref"
+import (
+	"context"
+
+	"github.com/google/cel-go/common/types/ref"
+)
 
 // Overload defines a named overload of a function, indicating an operand trait
 // which must be present on the first argument to the overload as well as one
@@ -45,9 +49,26 @@ type Overload struct {
 	// nil.
 	Function FunctionOp
 
+	// ContextUnary defines the overload with a ContextUnaryOp implementation.
+	// May be nil. Takes precedence over Unary when set.
+	ContextUnary ContextUnaryOp
+
+	// ContextBinary defines the overload with a ContextBinaryOp implementation.
+	// May be nil. Takes precedence over Binary when set.
+	ContextBinary ContextBinaryOp
+
+	// ContextFunction defines the overload with a ContextFunctionOp
+	// implementation. May be nil. Takes precedence over Function when set.
+	ContextFunction ContextFunctionOp
+
 	// NonStrict specifies whether the Overload will tolerate arguments that
 	// are types.Err or types.Unknown.
 	NonStrict bool
+
+	// Deterministic specifies whether the Overload always produces the same
+	// result for the same arguments, allowing its results to be memoized
+	// across evaluations.
+	Deterministic bool
 }
 
 // UnaryOp is a function that takes a single value and produces an output.
@@ -59,3 +80,15 @@ type BinaryOp func(lhs ref.Val, rhs ref.Val) ref.Val
 // FunctionOp is a function with accepts zero or more arguments and produces
 // a value or error as a result.
 type FunctionOp func(values ...ref.Val) ref.Val
+
+// ContextUnaryOp is a function that takes the context of the evaluation and a single value and
+// produces an output.
+type ContextUnaryOp func(ctx context.Context, value ref.Val) ref.Val
+
+// ContextBinaryOp is a function that takes the context of the evaluation and two values and
+// produces an output.
+type ContextBinaryOp func(ctx context.Context, lhs ref.Val, rhs ref.Val) ref.Val
+
+// ContextFunctionOp is a function which accepts the context of the evaluation and zero or more
+// arguments and produces a value or error as a result.
+type ContextFunctionOp func(ctx context.Context, values ...ref.Val) ref.Val
@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sort"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// SARIFTool describes the analysis tool recorded in a SARIF log.
+type SARIFTool struct {
+	Name           string
+	Version        string
+	InformationURI string
+}
+
+// MarshalDiagnosticsSARIF encodes the diagnostics as a SARIF 2.1.0 log containing a single run.
+//
+// Each distinct ErrorCode is recorded as a reporting rule, diagnostic severities are mapped to
+// the SARIF "error", "warning", and "note" levels, and columns are reported in unicode code
+// points. The expression id and structured details of a diagnostic are recorded in the result
+// property bag.
+func MarshalDiagnosticsSARIF(tool SARIFTool, diags []*Diagnostic) ([]byte, error) {
+	if tool.Name == "" {
+		tool.Name = "cel-go"
+	}
+	ruleIDs := map[ErrorCode]struct{}{}
+	results := make([]*sarifResult, 0, len(diags))
+	for _, d := range diags {
+		if d.Code != "" {
+			ruleIDs[d.Code] = struct{}{}
+		}
+		results = append(results, newSARIFResult(d))
+	}
+	rules := make([]*sarifRule, 0, len(ruleIDs))
+	for id := range ruleIDs {
+		rules = append(rules, &sarifRule{ID: string(id)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return marshalIndent(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []*sarifRun{{
+			Tool: &sarifToolComponent{
+				Driver: &sarifDriver{
+					Name:           tool.Name,
+					Version:        tool.Version,
+					InformationURI: tool.InformationURI,
+					Rules:          rules,
+				},
+			},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	})
+}
+
+func newSARIFResult(d *Diagnostic) *sarifResult {
+	res := &sarifResult{
+		RuleID:  string(d.Code),
+		Level:   sarifLevel(d.Severity),
+		Message: &sarifMessage{Text: d.Message},
+	}
+	if d.ExprID != 0 || len(d.Details) != 0 {
+		res.Properties = &sarifProperties{ExprID: d.ExprID, Details: d.Details}
+	}
+	loc := &sarifPhysicalLocation{}
+	if d.Source != "" {
+		loc.ArtifactLocation = &sarifArtifactLocation{URI: d.Source}
+	}
+	if d.Start != nil {
+		loc.Region = &sarifRegion{
+			StartLine:   d.Start.Line,
+			StartColumn: d.Start.Column,
+		}
+		if d.End != nil {
+			loc.Region.EndLine = d.End.Line
+			loc.Region.EndColumn = d.End.Column
+		}
+		// The context region must enclose the region, so the snippet is only recorded for
+		// single-line ranges.
+		if d.Snippet != "" && (d.End == nil || d.End.Line == d.Start.Line) {
+			loc.ContextRegion = &sarifRegion{
+				StartLine: d.Start.Line,
+				Snippet:   &sarifSnippet{Text: d.Snippet},
+			}
+		}
+	}
+	if loc.ArtifactLocation != nil || loc.Region != nil {
+		res.Locations = []*sarifLocation{{PhysicalLocation: loc}}
+	}
+	return res
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityWarning.String():
+		return "warning"
+	case SeverityInfo.String():
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       *sarifToolComponent `json:"tool"`
+	ColumnKind string              `json:"columnKind"`
+	Results    []*sarifResult      `json:"results"`
+}
+
+type sarifToolComponent struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId,omitempty"`
+	Level      string           `json:"level"`
+	Message    *sarifMessage    `json:"message"`
+	Locations  []*sarifLocation `json:"locations,omitempty"`
+	Properties *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation,omitempty"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+	ContextRegion    *sarifRegion           `json:"contextRegion,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifSnippet `json:"snippet,omitempty"`
+}
+
+type sarifSnippet struct {
+	Text string `json:"text"`
+}
+
+type sarifProperties struct {
+	ExprID  int64          `json:"exprId,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
@@ -380,6 +380,9 @@ func (m *mutableMap) Insert(k, v ref.Val) ref.Val {
 		return NewErr("insert failed: key %v already exists", k)
 	}
 	m.mutableValues[k] = v
+	// The size is cached on the base map, so it must track insertions for Size() and
+	// IsZeroValue() to reflect the entries added during a comprehension.
+	m.size++
 	return m
 }
 
@@ -1163,6 +1163,15 @@ func TestInsertMapKeyValue_MutableMapper(t *testing.T) {
 	}
 }
 
+func TestMutableMapInsertSize(t *testing.T) {
+	m := NewMutableMap(DefaultTypeAdapter, map[ref.Val]ref.Val{String("first"): Int(1)})
+	m.Insert(String("second"), Int(2))
+	m.Insert(String("second"), Int(3))
+	if m.Size() != Int(2) {
+		t.Errorf("m.Size() got %v, wanted 2 after insertion", m.Size())
+	}
+}
+
 func TestInsertMapKeyValue_Mapper(t *testing.T) {
 	m := NewRefValMap(DefaultTypeAdapter, map[ref.Val]ref.Val{String("first"): Int(1)})
 	modified := InsertMapKeyValue(m, String("second"), Int(2))
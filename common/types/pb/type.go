@@ -269,7 +269,8 @@ func (fd *FieldDescription) IsSet(target any) bool {
 		}
 		// Otherwise, fallback to a dynamic lookup of the field descriptor from the target
 		// instance as an attempt to use the cached field descriptor will result in a panic.
-		return pbRef.Has(pbDesc.Fields().ByName(protoreflect.Name(fd.Name())))
+		field := pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))
+		return field != nil && pbRef.Has(field)
 	default:
 		return false
 	}
@@ -296,7 +297,13 @@ func (fd *FieldDescription) GetFrom(target any) (any, error) {
 	} else {
 		// Otherwise, fallback to a dynamic lookup of the field descriptor from the target
 		// instance as an attempt to use the cached field descriptor will result in a panic.
-		fieldVal = pbRef.Get(pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))).Interface()
+		// The target may be a message of another type, e.g. when a field is selected from a
+		// value whose type was narrowed by a type test which did not hold.
+		field := pbDesc.Fields().ByName(protoreflect.Name(fd.Name()))
+		if field == nil {
+			return nil, fmt.Errorf("no such field '%s'", fd.Name())
+		}
+		fieldVal = pbRef.Get(field).Interface()
 	}
 	switch fv := fieldVal.(type) {
 	// Fast-path return for primitive types.
@@ -310,6 +310,11 @@ func TestFieldDescriptionIsSet(t *testing.T) {
 			field: "single_any",
 			isSet: false,
 		},
+		{
+			msg:   &proto3pb.NestedTestAllTypes{},
+			field: "single_bool",
+			isSet: false,
+		},
 	}
 	for _, tc := range tests {
 		f, found := td.FieldByName(tc.field)
@@ -322,6 +327,25 @@ func TestFieldDescriptionIsSet(t *testing.T) {
 	}
 }
 
+func TestFieldDescriptionGetFromOtherMessage(t *testing.T) {
+	pbdb := NewDb()
+	msg := &proto3pb.TestAllTypes{}
+	if _, err := pbdb.RegisterMessage(msg); err != nil {
+		t.Fatalf("pbdb.RegisterMessage() failed: %v", err)
+	}
+	td, found := pbdb.DescribeType(string(msg.ProtoReflect().Descriptor().FullName()))
+	if !found {
+		t.Fatal("pbdb.DescribeType(TestAllTypes) not found")
+	}
+	f, found := td.FieldByName("single_bool")
+	if !found {
+		t.Fatal("td.FieldByName(single_bool) not found")
+	}
+	if _, err := f.GetFrom(&proto3pb.NestedTestAllTypes{}); err == nil {
+		t.Error("field.GetFrom() on a message without the field succeeded, wanted error")
+	}
+}
+
 func TestTypeDescriptionMaybeUnwrap(t *testing.T) {
 	pbdb := NewDb()
 	_, err := pbdb.RegisterMessage(&proto3pb.TestAllTypes{})
@@ -17,6 +17,7 @@ package types
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"google.golang.org/protobuf/proto"
@@ -88,8 +89,9 @@ type FieldType struct {
 
 // Registry provides type information for a set of registered types.
 type Registry struct {
-	revTypeMap map[string]*Type
-	pbdb       *pb.Db
+	revTypeMap  map[string]*Type
+	pbdb        *pb.Db
+	schemaTypes map[string]*SchemaType
 }
 
 // NewRegistry accepts a list of proto message instances and returns a type
@@ -126,8 +128,9 @@ func ProtoTypeDefs(types ...proto.Message) RegistryOption {
 // NewProtoRegistry creates a proto-based registry with a set of configurable options.
 func NewProtoRegistry(opts ...RegistryOption) (*Registry, error) {
 	r := &Registry{
-		revTypeMap: make(map[string]*Type),
-		pbdb:       pb.NewDb(),
+		revTypeMap:  make(map[string]*Type),
+		pbdb:        pb.NewDb(),
+		schemaTypes: make(map[string]*SchemaType),
 	}
 	err := r.RegisterType(
 		BoolType,
@@ -164,20 +167,25 @@ func NewProtoRegistry(opts ...RegistryOption) (*Registry, error) {
 // NewEmptyRegistry returns a registry which is completely unconfigured.
 func NewEmptyRegistry() *Registry {
 	return &Registry{
-		revTypeMap: make(map[string]*Type),
-		pbdb:       pb.NewDb(),
+		revTypeMap:  make(map[string]*Type),
+		pbdb:        pb.NewDb(),
+		schemaTypes: make(map[string]*SchemaType),
 	}
 }
 
 // Copy copies the current state of the registry into its own memory space.
 func (p *Registry) Copy() *Registry {
 	copy := &Registry{
-		revTypeMap: make(map[string]*Type),
-		pbdb:       p.pbdb.Copy(),
+		revTypeMap:  make(map[string]*Type),
+		pbdb:        p.pbdb.Copy(),
+		schemaTypes: make(map[string]*SchemaType, len(p.schemaTypes)),
 	}
 	for k, v := range p.revTypeMap {
 		copy.revTypeMap[k] = v
 	}
+	for k, v := range p.schemaTypes {
+		copy.schemaTypes[k] = v
+	}
 	return copy
 }
 
@@ -236,6 +244,13 @@ func (p *Registry) FindFieldType(structType, fieldName string) (*ref.FieldType,
 // FindStructFieldNames returns the set of field names for the given struct type,
 // if the type exists in the registry.
 func (p *Registry) FindStructFieldNames(structType string) ([]string, bool) {
+	if st, found := p.schemaTypes[structType]; found {
+		fields := make([]string, len(st.fields))
+		for i, f := range st.fields {
+			fields[i] = f.Name
+		}
+		return fields, true
+	}
 	msgType, found := p.pbdb.DescribeType(structType)
 	if !found {
 		return []string{}, false
@@ -253,6 +268,13 @@ func (p *Registry) FindStructFieldNames(structType string) ([]string, bool) {
 // FindStructFieldType returns the field type for a checked type value. Returns
 // false if the field could not be found.
 func (p *Registry) FindStructFieldType(structType, fieldName string) (*FieldType, bool) {
+	if st, found := p.schemaTypes[structType]; found {
+		f, found := st.fieldMap[fieldName]
+		if !found {
+			return nil, false
+		}
+		return p.schemaFieldType(f), true
+	}
 	msgType, found := p.pbdb.DescribeType(structType)
 	if !found {
 		return nil, false
@@ -272,6 +294,13 @@ func (p *Registry) FindStructFieldType(structType, fieldName string) (*FieldType
 // FindStructFieldDescription returns documentation for a field if available.
 // Returns false if the field could not be found.
 func (p *Registry) FindStructFieldDescription(structType, fieldName string) (string, bool) {
+	if st, found := p.schemaTypes[structType]; found {
+		f, found := st.fieldMap[fieldName]
+		if !found {
+			return "", false
+		}
+		return f.Description, true
+	}
 	msgType, found := p.pbdb.DescribeType(structType)
 	if !found {
 		return "", false
@@ -319,6 +348,9 @@ func (p *Registry) FindType(structType string) (*exprpb.Type, bool) {
 //
 // Returns false if not found.
 func (p *Registry) FindStructType(structType string) (*Type, bool) {
+	if st, found := p.schemaTypes[structType]; found {
+		return NewTypeTypeWithParam(st.celType), true
+	}
 	if _, found := p.pbdb.DescribeType(structType); !found {
 		return nil, false
 	}
@@ -335,6 +367,9 @@ func (p *Registry) FindStructType(structType string) (*Type, bool) {
 // to convert the Val to the field's native type. If an error occurs during
 // conversion, the NewValue will be a types.Err.
 func (p *Registry) NewValue(structType string, fields map[string]ref.Val) ref.Val {
+	if st, found := p.schemaTypes[structType]; found {
+		return p.newSchemaValue(st, fields)
+	}
 	td, found := p.pbdb.DescribeType(structType)
 	if !found {
 		return NewErr("unknown type '%s'", structType)
@@ -371,6 +406,48 @@ func (p *Registry) RegisterMessage(message proto.Message) error {
 	return p.registerAllTypes(fd)
 }
 
+// RegisterSchemaType registers one or more schema-defined struct types with the registry.
+//
+// Schema types may not share a name with a protobuf message type, and a schema type name may only
+// be registered once unless the same *SchemaType instance is provided.
+func (p *Registry) RegisterSchemaType(schemaTypes ...*SchemaType) error {
+	if p.schemaTypes == nil {
+		p.schemaTypes = make(map[string]*SchemaType)
+	}
+	for _, st := range schemaTypes {
+		if _, found := p.pbdb.DescribeType(st.name); found {
+			return fmt.Errorf("type registration conflict. found protobuf message type: %s", st.name)
+		}
+		if existing, found := p.schemaTypes[st.name]; found {
+			if existing != st {
+				return fmt.Errorf("type registration conflict. found schema type: %s", st.name)
+			}
+			continue
+		}
+		if err := p.RegisterType(st.celType); err != nil {
+			return err
+		}
+		p.schemaTypes[st.name] = st
+	}
+	return nil
+}
+
+// FindSchemaType returns the schema-defined struct type with the given name, if registered.
+func (p *Registry) FindSchemaType(typeName string) (*SchemaType, bool) {
+	st, found := p.schemaTypes[typeName]
+	return st, found
+}
+
+// SchemaTypes returns the registered schema-defined struct types sorted by name.
+func (p *Registry) SchemaTypes() []*SchemaType {
+	out := make([]*SchemaType, 0, len(p.schemaTypes))
+	for _, st := range p.schemaTypes {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
 // RegisterType registers a type value with the provider which ensures the provider is aware of how to
 // map the type to an identifier.
 //
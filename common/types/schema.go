@@ -0,0 +1,644 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+// SchemaField describes a named and typed field of a SchemaType.
+type SchemaField struct {
+	// Name is the simple name of the field.
+	Name string
+
+	// Type is the CEL type of the field value.
+	Type *Type
+
+	// Description is optional documentation for the field.
+	Description string
+}
+
+// NewSchemaField creates a field with the given name and type.
+func NewSchemaField(name string, t *Type) *SchemaField {
+	return &SchemaField{Name: name, Type: t}
+}
+
+// SchemaType describes a struct type whose fields are defined by a schema rather than by a
+// protobuf message descriptor.
+//
+// Values of a schema type are backed by Go maps keyed by field name. Fields which are absent from
+// the map or which have a null value are unset, and selecting an unset field returns the default
+// value of the field type: the zero value for primitive types, an empty list or map, an empty
+// object for schema types, and null for all other types.
+type SchemaType struct {
+	name     string
+	fields   []*SchemaField
+	fieldMap map[string]*SchemaField
+	celType  *Type
+}
+
+// NewSchemaType creates a schema-defined struct type with the given fully qualified name and fields.
+//
+// Field types may refer to other schema types, including the type being defined, by using
+// NewObjectType with the referenced type name.
+func NewSchemaType(name string, fields ...*SchemaField) (*SchemaType, error) {
+	if name == "" {
+		return nil, errors.New("invalid schema type: missing type name")
+	}
+	st := &SchemaType{
+		name:     name,
+		fields:   fields,
+		fieldMap: make(map[string]*SchemaField, len(fields)),
+		celType:  NewObjectType(name),
+	}
+	for _, f := range fields {
+		if f == nil || f.Name == "" {
+			return nil, fmt.Errorf("invalid schema type %s: missing field name", name)
+		}
+		if f.Type == nil {
+			return nil, fmt.Errorf("invalid schema type %s: missing type for field %s", name, f.Name)
+		}
+		if _, found := st.fieldMap[f.Name]; found {
+			return nil, fmt.Errorf("invalid schema type %s: duplicate field %s", name, f.Name)
+		}
+		st.fieldMap[f.Name] = f
+	}
+	return st, nil
+}
+
+// TypeName returns the fully qualified name of the schema type.
+func (st *SchemaType) TypeName() string {
+	return st.name
+}
+
+// CELType returns the struct type reference for the schema type.
+func (st *SchemaType) CELType() *Type {
+	return st.celType
+}
+
+// Fields returns the fields of the schema type in declaration order.
+func (st *SchemaType) Fields() []*SchemaField {
+	return st.fields
+}
+
+// FieldByName returns the field with the given name, if present.
+func (st *SchemaType) FieldByName(name string) (*SchemaField, bool) {
+	f, found := st.fieldMap[name]
+	return f, found
+}
+
+// NewSchemaObject creates a value of a schema type registered with the registry from a map of
+// field names to native Go or CEL values.
+func (p *Registry) NewSchemaObject(typeName string, fields map[string]any) ref.Val {
+	st, found := p.schemaTypes[typeName]
+	if !found {
+		return NewErr("unknown type '%s'", typeName)
+	}
+	for name := range fields {
+		if _, found := st.fieldMap[name]; !found {
+			return NewErr("no such field: %s", name)
+		}
+	}
+	return &schemaObject{reg: p, typeDesc: st, value: fields}
+}
+
+// SchemaAdapter returns an adapter which converts native values to values of the given type when
+// the type is or refers to a registered schema type, e.g. from a map[string]any keyed by field name
+// to an object of the schema type.
+//
+// Returns false if the type does not refer to a schema type.
+func (p *Registry) SchemaAdapter(t *Type) (Adapter, bool) {
+	if !p.hasSchemaType(t) {
+		return nil, false
+	}
+	return &schemaElemAdapter{reg: p, elemType: t}, true
+}
+
+// hasSchemaType indicates whether the type is or contains a reference to a schema type.
+func (p *Registry) hasSchemaType(t *Type) bool {
+	if _, found := p.schemaTypes[t.TypeName()]; found && t.Kind() == StructKind {
+		return true
+	}
+	for _, param := range t.Parameters() {
+		if p.hasSchemaType(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaFieldType returns the field type with presence testing and field selection functions which
+// accept either a schema object value or a map of field names to values.
+func (p *Registry) schemaFieldType(f *SchemaField) *FieldType {
+	return &FieldType{
+		Type: f.Type,
+		IsSet: func(target any) bool {
+			_, found, err := p.schemaLookup(target, f.Name)
+			return found && err == nil
+		},
+		GetFrom: func(target any) (any, error) {
+			v, found, err := p.schemaLookup(target, f.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				return p.schemaZeroValue(f.Type), nil
+			}
+			return p.schemaFieldValue(f.Type, v), nil
+		},
+	}
+}
+
+// schemaLookup finds the raw value of a field within a schema object value. Null values are
+// treated as unset.
+func (p *Registry) schemaLookup(target any, name string) (any, bool, error) {
+	var v any
+	var found bool
+	switch obj := target.(type) {
+	case *schemaObject:
+		return p.schemaLookup(obj.value, name)
+	case map[string]any:
+		v, found = obj[name]
+	default:
+		m, ok := p.NativeToValue(target).(traits.Mapper)
+		if !ok {
+			return nil, false, fmt.Errorf("unsupported schema object value: %T", target)
+		}
+		v, found = m.Find(String(name))
+	}
+	if !found || v == nil || v == NullValue {
+		return nil, false, nil
+	}
+	return v, true, nil
+}
+
+// schemaFieldValue adapts a field value to CEL, wrapping maps as schema objects and adapting the
+// elements of native lists and maps to the declared element types.
+//
+// Values decoded from JSON are converted to the declared field type where possible: whole numbers
+// to int and uint, numbers to double, RFC 3339 strings to timestamps, ISO 8601 and Go duration
+// strings to durations, and base64 strings to bytes.
+func (p *Registry) schemaFieldValue(t *Type, v any) ref.Val {
+	if val, ok := v.(ref.Val); ok {
+		// CEL maps provided where a schema type is expected are wrapped as schema objects.
+		if _, isMap := val.(traits.Mapper); !isMap || t.Kind() != StructKind {
+			return val
+		}
+	}
+	switch t.Kind() {
+	case StructKind:
+		if st, found := p.schemaTypes[t.TypeName()]; found {
+			return &schemaObject{reg: p, typeDesc: st, value: v}
+		}
+	// The elements of lists and maps are adapted whenever their type is more specific than dyn,
+	// rather than only when they refer to schema types, since scalars decoded from JSON also need
+	// to be converted to the declared element type, e.g. float64 values within a list(int).
+	case ListKind:
+		elemType := t.Parameters()[0]
+		rv := reflect.ValueOf(v)
+		if elemType.Kind() != DynKind && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) {
+			return NewDynamicList(&schemaElemAdapter{reg: p, elemType: elemType}, v)
+		}
+	case MapKind:
+		valType := t.Parameters()[1]
+		rv := reflect.ValueOf(v)
+		if valType.Kind() != DynKind && rv.Kind() == reflect.Map {
+			return p.newSchemaMap(valType, rv)
+		}
+	}
+	return schemaJSONValue(t, p.NativeToValue(v))
+}
+
+// schemaJSONValue converts a value decoded from JSON to the declared scalar type, if possible.
+func schemaJSONValue(t *Type, val ref.Val) ref.Val {
+	switch t.Kind() {
+	case IntKind:
+		if d, ok := val.(Double); ok && d == Double(math.Trunc(float64(d))) {
+			return d.ConvertToType(IntType)
+		}
+	case UintKind:
+		if d, ok := val.(Double); ok && d == Double(math.Trunc(float64(d))) {
+			return d.ConvertToType(UintType)
+		}
+		if i, ok := val.(Int); ok && i >= 0 {
+			return Uint(i)
+		}
+	case DoubleKind:
+		if _, ok := val.(Int); ok {
+			return val.ConvertToType(DoubleType)
+		}
+		if _, ok := val.(Uint); ok {
+			return val.ConvertToType(DoubleType)
+		}
+	case BytesKind:
+		if str, ok := val.(String); ok {
+			if b, err := base64.StdEncoding.DecodeString(string(str)); err == nil {
+				return Bytes(b)
+			}
+		}
+	case DurationKind:
+		if str, ok := val.(String); ok {
+			if conv := val.ConvertToType(DurationType); !IsError(conv) {
+				return conv
+			}
+			d, err := parseISO8601Duration(string(str))
+			if err != nil {
+				return WrapErr(err)
+			}
+			return durationOf(d)
+		}
+	case TimestampKind:
+		if _, ok := val.(String); ok {
+			if conv := val.ConvertToType(TimestampType); !IsError(conv) {
+				return conv
+			}
+		}
+	}
+	return val
+}
+
+// iso8601DurationUnits are the units of an ISO 8601 duration with a fixed length, in the order in
+// which they must appear. Minutes are only valid in the time part of the duration, and the other
+// units only in the part in which they are listed.
+var iso8601DurationUnits = []struct {
+	unit     byte
+	timePart bool
+	length   time.Duration
+}{
+	{unit: 'W', length: 7 * 24 * time.Hour},
+	{unit: 'D', length: 24 * time.Hour},
+	{unit: 'H', timePart: true, length: time.Hour},
+	{unit: 'M', timePart: true, length: time.Minute},
+	{unit: 'S', timePart: true, length: time.Second},
+}
+
+// parseISO8601Duration parses a duration in the ISO 8601 format used by the JSON Schema duration
+// format, e.g. `PT1H30M` or `P1DT0.5S`, where a day is 24 hours and a week is 7 days.
+//
+// Durations with years or months are not supported as they do not have a fixed length.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	rest, neg := strings.CutPrefix(s, "-")
+	rest, found := strings.CutPrefix(rest, "P")
+	if !found || rest == "" {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+	var total time.Duration
+	timePart := false
+	next := 0
+	for rest != "" {
+		if rest[0] == 'T' && !timePart {
+			timePart = true
+			rest = rest[1:]
+			if rest == "" {
+				return 0, fmt.Errorf("invalid duration: %q", s)
+			}
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		num, unit := strings.Replace(rest[:end], ",", ".", 1), rest[end]
+		rest = rest[end+1:]
+		if !timePart && (unit == 'Y' || unit == 'M') {
+			return 0, fmt.Errorf("unsupported duration: %q: years and months have no fixed length", s)
+		}
+		i := next
+		for i < len(iso8601DurationUnits) &&
+			(iso8601DurationUnits[i].unit != unit || iso8601DurationUnits[i].timePart != timePart) {
+			i++
+		}
+		if i == len(iso8601DurationUnits) {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		next = i + 1
+		secs, err := time.ParseDuration(num + "s")
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		scale := int64(iso8601DurationUnits[i].length / time.Second)
+		if int64(secs) > (math.MaxInt64-int64(total))/scale {
+			return 0, fmt.Errorf("duration out of range: %q", s)
+		}
+		total += secs * time.Duration(scale)
+	}
+	if neg {
+		total = -total
+	}
+	return total, nil
+}
+
+// schemaZeroValue returns the value of an unset field of the given type.
+func (p *Registry) schemaZeroValue(t *Type) ref.Val {
+	if t.Kind() != NullTypeKind && !t.isDyn() && t.IsAssignableType(NullType) {
+		return NullValue
+	}
+	switch t.Kind() {
+	case BoolKind:
+		return False
+	case BytesKind:
+		return Bytes([]byte{})
+	case DoubleKind:
+		return Double(0)
+	case DurationKind:
+		return Duration{Duration: 0}
+	case IntKind:
+		return IntZero
+	case ListKind:
+		return NewRefValList(p, []ref.Val{})
+	case MapKind:
+		return NewRefValMap(p, map[ref.Val]ref.Val{})
+	case StringKind:
+		return String("")
+	case StructKind:
+		if st, found := p.schemaTypes[t.TypeName()]; found {
+			return &schemaObject{reg: p, typeDesc: st, value: map[string]any{}}
+		}
+	case TimestampKind:
+		return Timestamp{Time: time.Unix(0, 0).UTC()}
+	case UintKind:
+		return Uint(0)
+	}
+	return NullValue
+}
+
+// newSchemaValue creates a schema object from the field values of a struct literal.
+func (p *Registry) newSchemaValue(st *SchemaType, fields map[string]ref.Val) ref.Val {
+	value := make(map[string]any, len(fields))
+	for name, v := range fields {
+		f, found := st.fieldMap[name]
+		if !found {
+			return NewErr("no such field: %s", name)
+		}
+		if v == NullValue {
+			// Assigning null to a field leaves the field unset.
+			continue
+		}
+		if !f.Type.IsAssignableRuntimeType(v) {
+			return NewErr("field '%s' of type '%s' cannot be assigned a value of type '%s'",
+				name, f.Type, v.Type().TypeName())
+		}
+		value[name] = v
+	}
+	return &schemaObject{reg: p, typeDesc: st, value: value}
+}
+
+// newSchemaMap creates a map whose values are adapted to the declared value type as they are
+// accessed.
+//
+// Keys are adapted by the registry rather than to the value type, since a string key would
+// otherwise be converted along with the values of a map(string, bytes), for example.
+func (p *Registry) newSchemaMap(valType *Type, rv reflect.Value) traits.Mapper {
+	return &baseMap{
+		Adapter: p,
+		mapAccessor: &schemaMapAccessor{
+			reflectMapAccessor: &reflectMapAccessor{
+				Adapter:  &schemaElemAdapter{reg: p, elemType: valType},
+				refValue: rv,
+				keyType:  rv.Type().Key(),
+			},
+			keyAdapter: p,
+		},
+		value: rv.Interface(),
+		size:  rv.Len(),
+	}
+}
+
+// schemaMapAccessor finds the values of a native map adapted to the declared value type, and
+// iterates over the keys adapted by the keyAdapter.
+type schemaMapAccessor struct {
+	*reflectMapAccessor
+	keyAdapter Adapter
+}
+
+// Iterator implements the mapAccessor interface method.
+func (m *schemaMapAccessor) Iterator() traits.Iterator {
+	return &mapIterator{
+		Adapter: m.keyAdapter,
+		mapKeys: m.refValue.MapRange(),
+		len:     m.refValue.Len(),
+	}
+}
+
+// Fold implements the mapAccessor interface method.
+func (m *schemaMapAccessor) Fold(f traits.Folder) {
+	mapRange := m.refValue.MapRange()
+	for mapRange.Next() {
+		k := m.keyAdapter.NativeToValue(mapRange.Key().Interface())
+		if !f.FoldEntry(k, m.NativeToValue(mapRange.Value().Interface())) {
+			break
+		}
+	}
+}
+
+// schemaElemAdapter adapts values to a type which refers to schema types, such as the elements of
+// lists and maps nested within a schema object.
+type schemaElemAdapter struct {
+	reg      *Registry
+	elemType *Type
+}
+
+// NativeToValue implements the Adapter interface method.
+func (a *schemaElemAdapter) NativeToValue(value any) ref.Val {
+	return a.reg.schemaFieldValue(a.elemType, value)
+}
+
+// schemaObject is a value of a schema type which is backed by a map of field names to values, or
+// by any value which the registry adapts to a CEL map.
+type schemaObject struct {
+	reg      *Registry
+	typeDesc *SchemaType
+	value    any
+}
+
+// ConvertToNative implements the ref.Val interface method.
+//
+// Schema objects may be converted to a map[string]any, in which case nested schema objects are
+// converted to maps as well, or to JSON.
+func (o *schemaObject) ConvertToNative(typeDesc reflect.Type) (any, error) {
+	if reflect.TypeOf(o).AssignableTo(typeDesc) {
+		return o, nil
+	}
+	switch typeDesc {
+	case stringAnyMapType:
+		out := make(map[string]any)
+		for _, f := range o.typeDesc.fields {
+			if o.isSet(f) {
+				v := o.get(f)
+				if IsError(v) {
+					return nil, v.(*Err)
+				}
+				if nested, ok := v.(*schemaObject); ok {
+					native, err := nested.ConvertToNative(typeDesc)
+					if err != nil {
+						return nil, err
+					}
+					out[f.Name] = native
+					continue
+				}
+				out[f.Name] = v.Value()
+			}
+		}
+		return out, nil
+	case JSONValueType, JSONStructType:
+		fields := make(map[string]*structpb.Value)
+		for _, f := range o.typeDesc.fields {
+			if !o.isSet(f) {
+				continue
+			}
+			v := o.get(f)
+			if IsError(v) {
+				return nil, v.(*Err)
+			}
+			json, err := v.ConvertToNative(JSONValueType)
+			if err != nil {
+				return nil, err
+			}
+			fields[f.Name] = json.(*structpb.Value)
+		}
+		s := &structpb.Struct{Fields: fields}
+		if typeDesc == JSONStructType {
+			return s, nil
+		}
+		return structpb.NewStructValue(s), nil
+	}
+	return nil, fmt.Errorf("type conversion error from '%s' to '%v'", o.typeDesc.name, typeDesc)
+}
+
+// ConvertToType implements the ref.Val interface method.
+func (o *schemaObject) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case TypeType:
+		return o.Type().(ref.Val)
+	default:
+		if o.Type().TypeName() == typeVal.TypeName() {
+			return o
+		}
+	}
+	return NewErr("type conversion error from '%s' to '%s'", o.typeDesc.name, typeVal)
+}
+
+// Equal implements the ref.Val interface method.
+//
+// Two schema objects are equal when they have the same type and all of their fields have equal
+// values, where unset fields take on the default value of the field type.
+func (o *schemaObject) Equal(other ref.Val) ref.Val {
+	otherObj, ok := other.(*schemaObject)
+	if !ok || otherObj.typeDesc.name != o.typeDesc.name {
+		return False
+	}
+	for _, f := range o.typeDesc.fields {
+		if !o.isSet(f) && !otherObj.isSet(f) {
+			continue
+		}
+		if o.get(f).Equal(otherObj.get(f)) != True {
+			return False
+		}
+	}
+	return True
+}
+
+// IsZeroValue returns true if all fields of the object have default values.
+func (o *schemaObject) IsZeroValue() bool {
+	return o.Equal(&schemaObject{reg: o.reg, typeDesc: o.typeDesc, value: map[string]any{}}) == True
+}
+
+// IsSet implements the traits.FieldTester interface method.
+func (o *schemaObject) IsSet(field ref.Val) ref.Val {
+	name, ok := field.(String)
+	if !ok {
+		return MaybeNoSuchOverloadErr(field)
+	}
+	f, found := o.typeDesc.fieldMap[string(name)]
+	if !found {
+		return NewErr("no such field '%s'", field)
+	}
+	if _, _, err := o.reg.schemaLookup(o.value, f.Name); err != nil {
+		return WrapErr(err)
+	}
+	return Bool(o.isSet(f))
+}
+
+// Get implements the traits.Indexer interface method.
+func (o *schemaObject) Get(index ref.Val) ref.Val {
+	name, ok := index.(String)
+	if !ok {
+		return MaybeNoSuchOverloadErr(index)
+	}
+	f, found := o.typeDesc.fieldMap[string(name)]
+	if !found {
+		return NewErr("no such field '%s'", index)
+	}
+	return o.get(f)
+}
+
+// Type implements the ref.Val interface method.
+func (o *schemaObject) Type() ref.Type {
+	return o.typeDesc.celType
+}
+
+// Value implements the ref.Val interface method.
+//
+// The value is the underlying map of field names to values, where the values may be either native
+// Go values or CEL values.
+func (o *schemaObject) Value() any {
+	return o.value
+}
+
+func (o *schemaObject) isSet(f *SchemaField) bool {
+	_, found, err := o.reg.schemaLookup(o.value, f.Name)
+	return found && err == nil
+}
+
+func (o *schemaObject) get(f *SchemaField) ref.Val {
+	v, err := o.reg.schemaFieldType(f).GetFrom(o.value)
+	if err != nil {
+		return WrapErr(err)
+	}
+	return v.(ref.Val)
+}
+
+func (o *schemaObject) format(sb *strings.Builder) {
+	sb.WriteString(o.typeDesc.name)
+	sb.WriteString("{")
+	first := true
+	for _, f := range o.typeDesc.fields {
+		if !o.isSet(f) {
+			continue
+		}
+		if !first {
+			sb.WriteString(", ")
+		}
+		first = false
+		fmt.Fprintf(sb, "%s: ", f.Name)
+		formatTo(sb, o.get(f))
+	}
+	sb.WriteString("}")
+}
+
+var stringAnyMapType = reflect.TypeOf(map[string]any{})
@@ -0,0 +1,300 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	proto3pb "github.com/google/cel-go/test/proto3pb"
+)
+
+func TestNewSchemaTypeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []*SchemaField
+		err    string
+	}{
+		{name: "", err: "missing type name"},
+		{name: "example.T", fields: []*SchemaField{NewSchemaField("", IntType)}, err: "missing field name"},
+		{name: "example.T", fields: []*SchemaField{NewSchemaField("f", nil)}, err: "missing type for field f"},
+		{
+			name:   "example.T",
+			fields: []*SchemaField{NewSchemaField("f", IntType), NewSchemaField("f", StringType)},
+			err:    "duplicate field f",
+		},
+	}
+	for _, tc := range tests {
+		if _, err := NewSchemaType(tc.name, tc.fields...); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("NewSchemaType(%q) got error %v, wanted %q", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestRegistrySchemaTypes(t *testing.T) {
+	reg := newTestRegistry(t, ProtoTypeDefs(&proto3pb.TestAllTypes{}))
+	node := newTestSchemaType(t, "example.Node",
+		NewSchemaField("value", IntType),
+		&SchemaField{Name: "next", Type: NewObjectType("example.Node"), Description: "the next node"},
+	)
+	if err := reg.RegisterSchemaType(node, node); err != nil {
+		t.Fatalf("RegisterSchemaType() failed: %v", err)
+	}
+	if err := reg.RegisterSchemaType(newTestSchemaType(t, "example.Node")); err == nil {
+		t.Error("RegisterSchemaType() with a conflicting schema type succeeded, wanted error")
+	}
+	if err := reg.RegisterSchemaType(newTestSchemaType(t, "google.expr.proto3.test.TestAllTypes")); err == nil {
+		t.Error("RegisterSchemaType() with a conflicting proto type succeeded, wanted error")
+	}
+
+	if st, found := reg.FindStructType("example.Node"); !found || !st.IsExactType(NewTypeTypeWithParam(NewObjectType("example.Node"))) {
+		t.Errorf("FindStructType(example.Node) got %v, %v", st, found)
+	}
+	if names, found := reg.FindStructFieldNames("example.Node"); !found || strings.Join(names, ",") != "value,next" {
+		t.Errorf("FindStructFieldNames(example.Node) got %v, %v", names, found)
+	}
+	if doc, found := reg.FindStructFieldDescription("example.Node", "next"); !found || doc != "the next node" {
+		t.Errorf("FindStructFieldDescription(example.Node, next) got %v, %v", doc, found)
+	}
+	if _, found := reg.FindStructFieldType("example.Node", "missing"); found {
+		t.Error("FindStructFieldType(example.Node, missing) found an undefined field")
+	}
+	if ident, found := reg.FindIdent("example.Node"); !found || ident.(*Type).TypeName() != "example.Node" {
+		t.Errorf("FindIdent(example.Node) got %v, %v", ident, found)
+	}
+	if sts := reg.Copy().SchemaTypes(); len(sts) != 1 || sts[0] != node {
+		t.Errorf("Copy().SchemaTypes() got %v, wanted [example.Node]", sts)
+	}
+
+	// Field access on plain maps applies the schema defaults to unset fields.
+	ft, found := reg.FindStructFieldType("example.Node", "next")
+	if !found {
+		t.Fatal("FindStructFieldType(example.Node, next) not found")
+	}
+	in := map[string]any{"value": 1, "next": map[string]any{"value": 2}}
+	if !ft.IsSet(in) || ft.IsSet(map[string]any{"next": nil}) {
+		t.Error("IsSet() did not report presence based on the map keys")
+	}
+	next, err := ft.GetFrom(in)
+	if err != nil {
+		t.Fatalf("GetFrom() failed: %v", err)
+	}
+	nextObj := next.(traits.Indexer)
+	if nextObj.Get(String("value")) != Int(2) || nextObj.Get(String("next")).(traits.Indexer).Get(String("value")) != IntZero {
+		t.Errorf("GetFrom() got %v, wanted a node with value 2", next)
+	}
+	if out := nextObj.(traits.FieldTester).IsSet(String("missing")); !IsError(out) || out.(*Err).Error() != "no such field 'missing'" {
+		t.Error("IsSet() of an undefined field did not produce an error")
+	}
+}
+
+func TestSchemaObjectNewValue(t *testing.T) {
+	reg := newTestRegistry(t)
+	point := newTestSchemaType(t, "example.Point",
+		NewSchemaField("x", IntType),
+		NewSchemaField("y", IntType),
+		NewSchemaField("label", NewNullableType(StringType)),
+	)
+	if err := reg.RegisterSchemaType(point); err != nil {
+		t.Fatalf("RegisterSchemaType() failed: %v", err)
+	}
+	p := reg.NewValue("example.Point", map[string]ref.Val{"x": Int(1), "label": NullValue})
+	if IsError(p) {
+		t.Fatalf("NewValue() failed: %v", p)
+	}
+	if p.Type().TypeName() != "example.Point" || p.ConvertToType(TypeType).(*Type).TypeName() != "example.Point" {
+		t.Errorf("NewValue() got type %v, wanted example.Point", p.Type())
+	}
+	// Null values leave fields unset, as with protobuf wrapper fields.
+	if p.(traits.FieldTester).IsSet(String("label")) != False || p.(traits.Indexer).Get(String("label")) != NullValue {
+		t.Error("NewValue() did not leave the nullable field unset")
+	}
+	if got := Format(p); got != "example.Point{x: 1}" {
+		t.Errorf("Format() got %q", got)
+	}
+	if p.Equal(reg.NewSchemaObject("example.Point", map[string]any{"x": 1})) != True {
+		t.Error("Equal() of objects with default-valued fields got false, wanted true")
+	}
+	if p.Equal(reg.NewSchemaObject("example.Point", map[string]any{"x": 1, "y": 2})) != False {
+		t.Error("Equal() of objects with different fields got true, wanted false")
+	}
+	if p.(traits.Zeroer).IsZeroValue() || !reg.NewSchemaObject("example.Point", map[string]any{"y": 0}).(traits.Zeroer).IsZeroValue() {
+		t.Error("IsZeroValue() did not report default-valued objects")
+	}
+
+	errs := []struct {
+		fields map[string]ref.Val
+		err    string
+	}{
+		{fields: map[string]ref.Val{"z": Int(1)}, err: "no such field: z"},
+		{fields: map[string]ref.Val{"x": String("1")}, err: "field 'x' of type 'int' cannot be assigned a value of type 'string'"},
+	}
+	for _, tc := range errs {
+		out := reg.NewValue("example.Point", tc.fields)
+		if !IsError(out) || out.(*Err).Error() != tc.err {
+			t.Errorf("NewValue(%v) got %v, wanted error %q", tc.fields, out, tc.err)
+		}
+	}
+	if out := reg.NewSchemaObject("example.Point", map[string]any{"z": 1}); !IsError(out) {
+		t.Errorf("NewSchemaObject() with an undefined field got %v, wanted error", out)
+	}
+}
+
+func TestRegistrySchemaAdapter(t *testing.T) {
+	reg := newTestRegistry(t)
+	point := newTestSchemaType(t, "example.Point", NewSchemaField("x", IntType))
+	if err := reg.RegisterSchemaType(point); err != nil {
+		t.Fatalf("RegisterSchemaType() failed: %v", err)
+	}
+	if _, found := reg.SchemaAdapter(NewMapType(StringType, IntType)); found {
+		t.Error("SchemaAdapter(map(string, int)) found an adapter for a type without schema types")
+	}
+	want := reg.NewSchemaObject("example.Point", map[string]any{"x": 1})
+	adapter, found := reg.SchemaAdapter(point.CELType())
+	if !found {
+		t.Fatal("SchemaAdapter(example.Point) not found")
+	}
+	for _, in := range []any{map[string]any{"x": 1}, NewStringInterfaceMap(reg, map[string]any{"x": 1})} {
+		if got := adapter.NativeToValue(in); got.Type() != point.CELType() || got.Equal(want) != True {
+			t.Errorf("NativeToValue(%v) got %v, wanted %v", in, got, want)
+		}
+	}
+	adapter, found = reg.SchemaAdapter(NewListType(point.CELType()))
+	if !found {
+		t.Fatal("SchemaAdapter(list(example.Point)) not found")
+	}
+	list := adapter.NativeToValue([]any{map[string]any{"x": 1}}).(traits.Lister)
+	if got := list.Get(IntZero); got.Equal(want) != True {
+		t.Errorf("NativeToValue([{x: 1}])[0] got %v, wanted %v", got, want)
+	}
+}
+
+func TestSchemaObjectJSONValues(t *testing.T) {
+	reg := newTestRegistry(t)
+	event := newTestSchemaType(t, "example.Event",
+		NewSchemaField("count", IntType),
+		NewSchemaField("size", UintType),
+		NewSchemaField("ratio", DoubleType),
+		NewSchemaField("payload", BytesType),
+		NewSchemaField("at", TimestampType),
+		NewSchemaField("ttl", DurationType),
+		NewSchemaField("timeout", DurationType),
+		NewSchemaField("period", DurationType),
+		NewSchemaField("scores", NewMapType(StringType, IntType)),
+		NewSchemaField("blobs", NewMapType(StringType, BytesType)),
+		NewSchemaField("sizes", NewListType(UintType)),
+	)
+	if err := reg.RegisterSchemaType(event); err != nil {
+		t.Fatalf("RegisterSchemaType() failed: %v", err)
+	}
+	obj := reg.NewSchemaObject("example.Event", map[string]any{
+		"count":   float64(2),
+		"size":    float64(3),
+		"ratio":   1,
+		"payload": "aGk=",
+		"at":      "2026-01-02T03:04:05Z",
+		"ttl":     "1m",
+		"timeout": "PT1H30M",
+		"period":  "P1Y",
+		"scores":  map[string]any{"a": float64(1)},
+		"blobs":   map[string]any{"abcd": "aGk="},
+		"sizes":   []any{float64(4)},
+	}).(traits.Indexer)
+	tests := map[string]ref.Val{
+		"count":   Int(2),
+		"size":    Uint(3),
+		"ratio":   Double(1),
+		"payload": Bytes("hi"),
+		"at":      String("2026-01-02T03:04:05Z").ConvertToType(TimestampType),
+		"ttl":     String("1m").ConvertToType(DurationType),
+		"timeout": durationOf(90 * time.Minute),
+	}
+	for field, want := range tests {
+		if got := obj.Get(String(field)); got.Type() != want.Type() || got.Equal(want) != True {
+			t.Errorf("Get(%s) got %v, wanted %v", field, got, want)
+		}
+	}
+	if got := obj.Get(String("period")); !IsError(got) || !strings.Contains(got.(*Err).Error(), "no fixed length") {
+		t.Errorf("Get(period) got %v, wanted an unsupported duration error", got)
+	}
+	if got := obj.Get(String("scores")).(traits.Indexer).Get(String("a")); got != Int(1) {
+		t.Errorf("Get(scores)['a'] got %v, wanted 1", got)
+	}
+	// Map keys are not converted to the value type.
+	blobs := obj.Get(String("blobs")).(traits.Mapper)
+	if it := blobs.Iterator(); it.HasNext() != True || it.Next() != String("abcd") {
+		t.Errorf("Get(blobs) got keys %v, wanted ['abcd']", blobs)
+	}
+	if got := blobs.Get(String("abcd")); got.Equal(Bytes("hi")) != True {
+		t.Errorf("Get(blobs)['abcd'] got %v, wanted b'hi'", got)
+	}
+	if got := obj.Get(String("sizes")).(traits.Indexer).Get(IntZero); got != Uint(4) {
+		t.Errorf("Get(sizes)[0] got %v, wanted 4u", got)
+	}
+}
+
+func TestParseISO8601Duration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  string
+	}{
+		{in: "PT1H30M", want: 90 * time.Minute},
+		{in: "P1DT12H", want: 36 * time.Hour},
+		{in: "P2W", want: 14 * 24 * time.Hour},
+		{in: "PT0.5S", want: 500 * time.Millisecond},
+		{in: "PT1,5M", want: 90 * time.Second},
+		{in: "P1.5D", want: 36 * time.Hour},
+		{in: "-PT10S", want: -10 * time.Second},
+		{in: "PT0S", want: 0},
+		{in: "P1Y", err: "years and months have no fixed length"},
+		{in: "P1M", err: "years and months have no fixed length"},
+		{in: "P", err: "invalid duration"},
+		{in: "PT", err: "invalid duration"},
+		{in: "1H", err: "invalid duration"},
+		{in: "PT1S1M", err: "invalid duration"},
+		{in: "P1H", err: "invalid duration"},
+		{in: "PT1D", err: "invalid duration"},
+		{in: "PTT1H", err: "invalid duration"},
+		{in: "P1D2D", err: "invalid duration"},
+		{in: "P1.2.3D", err: "invalid duration"},
+		{in: "P200000D", err: "duration out of range"},
+	}
+	for _, tc := range tests {
+		got, err := parseISO8601Duration(tc.in)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseISO8601Duration(%q) got %v, %v, wanted error %q", tc.in, got, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("parseISO8601Duration(%q) got %v, %v, wanted %v", tc.in, got, err, tc.want)
+		}
+	}
+}
+
+func newTestSchemaType(t *testing.T, name string, fields ...*SchemaField) *SchemaType {
+	t.Helper()
+	st, err := NewSchemaType(name, fields...)
+	if err != nil {
+		t.Fatalf("NewSchemaType() failed: %v", err)
+	}
+	return st
+}
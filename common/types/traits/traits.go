@@ -77,3 +77,44 @@ const (
 	// The MapperType is syntactic sugar and not intended to be a perfect reflection of all Map operators.
 	MapperType = ContainerType | IndexerType | IterableType | SizerType
 )
+
+// traitNames lists the names of the traits in the order of their flag values.
+var traitNames = []string{
+	"adder",
+	"comparer",
+	"container",
+	"divider",
+	"field_tester",
+	"indexer",
+	"iterable",
+	"iterator",
+	"matcher",
+	"modder",
+	"multiplier",
+	"negator",
+	"receiver",
+	"sizer",
+	"subtractor",
+	"foldable",
+}
+
+// Names returns the names of the traits set within the trait mask, e.g. "adder" for AdderType.
+func Names(traitMask int) []string {
+	var names []string
+	for i, name := range traitNames {
+		if traitMask&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// FromName returns the trait flag with the given name, if one exists.
+func FromName(name string) (int, bool) {
+	for i, n := range traitNames {
+		if n == name {
+			return 1 << i, true
+		}
+	}
+	return 0, false
+}
@@ -95,6 +95,10 @@ const (
 
 	// UnknownKind represents an unknown value type.
 	UnknownKind
+
+	// UnionKind represents a type-check time union of two or more member types, e.g.
+	// `string | list(string)`. Values of a union type have the runtime type of one of its members.
+	UnionKind
 )
 
 var (
@@ -237,7 +241,14 @@ type Type struct {
 	isAssignableRuntimeType func(other ref.Val) bool
 
 	// traitMask is a mask of flags which indicate the capabilities of the type.
+	//
+	// For type parameters, the traitMask indicates the capabilities required of the types to which
+	// the parameter may be bound.
 	traitMask int
+
+	// bounds holds the set of types to which a type parameter may be bound. An empty set places no
+	// restriction on the type parameter beyond its traitMask.
+	bounds []*Type
 }
 
 // ConvertToNative implements ref.Val.ConvertToNative.
@@ -349,6 +360,9 @@ func (t *Type) Parameters() []*Type {
 
 // DeclaredTypeName indicates the fully qualified and parameterized type-check type name.
 func (t *Type) DeclaredTypeName() string {
+	if t.Kind() == UnionKind {
+		return t.String()
+	}
 	// if the type itself is neither null, nor dyn, but is assignable to null, then it's a wrapper type.
 	if t.Kind() != NullTypeKind && !t.isDyn() && t.IsAssignableType(NullType) {
 		return fmt.Sprintf("wrapper(%s)", t.TypeName())
@@ -394,6 +408,7 @@ func (t *Type) WithTraits(traits int) *Type {
 		isAssignableType:        t.isAssignableType,
 		isAssignableRuntimeType: t.isAssignableRuntimeType,
 		traitMask:               traits,
+		bounds:                  t.bounds,
 	}
 }
 
@@ -402,6 +417,13 @@ func (t *Type) String() string {
 	if t.Kind() == TypeParamKind {
 		return fmt.Sprintf("<%s>", t.DeclaredTypeName())
 	}
+	if t.Kind() == UnionKind {
+		members := make([]string, len(t.Parameters()))
+		for i, m := range t.Parameters() {
+			members[i] = m.String()
+		}
+		return strings.Join(members, " | ")
+	}
 	if len(t.Parameters()) == 0 {
 		return t.DeclaredTypeName()
 	}
@@ -521,6 +543,113 @@ func NewNullableType(wrapped *Type) *Type {
 	}
 }
 
+const unionTypeName = "union"
+
+// NewUnionType creates a type-check time union of the member types, e.g. `string | list(string)`.
+//
+// Nested unions are flattened and duplicate members are removed. A union with a dyn member is
+// dyn, a union of a primitive type and null is the nullable form of the primitive type, and a
+// union with a single distinct member is that member. The union supports the traits common to
+// all of its members.
+func NewUnionType(members ...*Type) *Type {
+	var flattened []*Type
+	for _, m := range members {
+		if m.Kind() == UnionKind {
+			flattened = append(flattened, m.Parameters()...)
+		} else {
+			flattened = append(flattened, m)
+		}
+	}
+	var unique []*Type
+	for _, m := range flattened {
+		if m.isDyn() && m.Kind() != TypeParamKind {
+			return DynType
+		}
+		isDuplicate := false
+		for _, u := range unique {
+			if u.IsExactType(m) && u.DeclaredTypeName() == m.DeclaredTypeName() {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			unique = append(unique, m)
+		}
+	}
+	switch len(unique) {
+	case 0:
+		return DynType
+	case 1:
+		return unique[0]
+	case 2:
+		if nullable := maybeNullableType(unique[0], unique[1]); nullable != nil {
+			return nullable
+		}
+		if nullable := maybeNullableType(unique[1], unique[0]); nullable != nil {
+			return nullable
+		}
+	}
+	traitMask := unique[0].traitMask
+	for _, m := range unique[1:] {
+		traitMask &= m.traitMask
+	}
+	return &Type{
+		kind:            UnionKind,
+		parameters:      unique,
+		runtimeTypeName: unionTypeName,
+		traitMask:       traitMask,
+		isAssignableType: func(other *Type) bool {
+			if other.Kind() == UnionKind {
+				for _, om := range other.Parameters() {
+					if !isAssignableToMember(unique, om) {
+						return false
+					}
+				}
+				return true
+			}
+			return isAssignableToMember(unique, other)
+		},
+		isAssignableRuntimeType: func(other ref.Val) bool {
+			for _, m := range unique {
+				if m.IsAssignableRuntimeType(other) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// UnionMembers returns the member types of a union type, or the type itself if it is not a union.
+func (t *Type) UnionMembers() []*Type {
+	if t.Kind() != UnionKind {
+		return []*Type{t}
+	}
+	return t.Parameters()
+}
+
+func isAssignableToMember(members []*Type, t *Type) bool {
+	for _, m := range members {
+		if m.IsAssignableType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeNullableType returns the nullable form of the primitive type t when the other type is
+// null, or nil otherwise.
+func maybeNullableType(t, other *Type) *Type {
+	if other.Kind() != NullTypeKind {
+		return nil
+	}
+	switch t.Kind() {
+	case BoolKind, BytesKind, DoubleKind, IntKind, StringKind, UintKind:
+		return NewNullableType(t)
+	}
+	return nil
+}
+
 // NewOptionalType creates an abstract parameterized type instance corresponding to CEL's notion of optional.
 func NewOptionalType(param *Type) *Type {
 	return NewOpaqueType("optional_type", param)
@@ -588,6 +717,47 @@ func NewTypeParamType(paramName string) *Type {
 	}
 }
 
+// NewTypeParamTypeWithBounds creates a type parameter which may only be bound to one of the
+// provided types during type-checking, e.g. a `T` which may be an int, uint, or double.
+func NewTypeParamTypeWithBounds(paramName string, bounds ...*Type) *Type {
+	return &Type{
+		kind:            TypeParamKind,
+		runtimeTypeName: paramName,
+		bounds:          bounds,
+	}
+}
+
+// NewTypeParamTypeWithTraits creates a type parameter which may only be bound to types which
+// support all of the provided traits during type-checking, e.g. traits.ComparerType.
+func NewTypeParamTypeWithTraits(paramName string, traits ...int) *Type {
+	traitMask := 0
+	for _, trait := range traits {
+		traitMask |= trait
+	}
+	return &Type{
+		kind:            TypeParamKind,
+		runtimeTypeName: paramName,
+		traitMask:       traitMask,
+	}
+}
+
+// TypeParamBounds returns the set of types to which a type parameter may be bound, if restricted.
+func (t *Type) TypeParamBounds() []*Type {
+	if t.Kind() != TypeParamKind {
+		return nil
+	}
+	return t.bounds
+}
+
+// TypeParamTraits returns the mask of traits required of the types to which a type parameter may
+// be bound, if restricted.
+func (t *Type) TypeParamTraits() int {
+	if t.Kind() != TypeParamKind {
+		return 0
+	}
+	return t.traitMask
+}
+
 // NewTypeTypeWithParam creates a type with a type parameter.
 // Used for type-checking purposes, but equivalent to TypeType otherwise.
 func NewTypeTypeWithParam(param *Type) *Type {
@@ -670,6 +840,18 @@ func TypeToExprType(t *Type) (*exprpb.Type, error) {
 		return chkdecls.NewTypeType(nil), nil
 	case UintKind:
 		return maybeWrapper(t, chkdecls.Uint), nil
+	case UnionKind:
+		// The checked expression format has no union type, so unions are represented as an
+		// abstract type with the member types as its parameters.
+		params := make([]*exprpb.Type, len(t.Parameters()))
+		for i, p := range t.Parameters() {
+			pt, err := TypeToExprType(p)
+			if err != nil {
+				return nil, err
+			}
+			params[i] = pt
+		}
+		return chkdecls.NewAbstractType(unionTypeName, params...), nil
 	}
 	return nil, fmt.Errorf("missing type conversion to proto: %v", t)
 }
@@ -702,6 +884,9 @@ func ProtoAsType(t *celpb.Type) (*Type, error) {
 			}
 			paramTypes[i] = pt
 		}
+		if t.GetAbstractType().GetName() == unionTypeName && len(paramTypes) > 1 {
+			return NewUnionType(paramTypes...), nil
+		}
 		return NewOpaqueType(t.GetAbstractType().GetName(), paramTypes...), nil
 	case *celpb.Type_ListType_:
 		et, err := ProtoAsType(t.GetListType().GetElemType())
@@ -85,6 +85,10 @@ func TestTypeString(t *testing.T) {
 			in:  NewTypeParamType("T"),
 			out: "<T>",
 		},
+		{
+			in:  NewUnionType(StringType, NewListType(StringType)),
+			out: "string | list(string)",
+		},
 		// nil-safety tests
 		{
 			in:  nil,
@@ -106,6 +110,46 @@ func TestTypeString(t *testing.T) {
 	}
 }
 
+func TestNewUnionType(t *testing.T) {
+	tests := []struct {
+		in  *Type
+		out *Type
+	}{
+		{
+			in:  NewUnionType(IntType),
+			out: IntType,
+		},
+		{
+			in:  NewUnionType(IntType, IntType),
+			out: IntType,
+		},
+		{
+			in:  NewUnionType(IntType, DynType),
+			out: DynType,
+		},
+		{
+			in:  NewUnionType(StringType, NullType),
+			out: NewNullableType(StringType),
+		},
+		{
+			in:  NewUnionType(NewUnionType(IntType, StringType), NewUnionType(StringType, DoubleType)),
+			out: NewUnionType(IntType, StringType, DoubleType),
+		},
+	}
+	for _, tst := range tests {
+		if !tst.in.IsExactType(tst.out) || tst.in.String() != tst.out.String() {
+			t.Errorf("NewUnionType() got %v, wanted %v", tst.in, tst.out)
+		}
+	}
+	union := NewUnionType(IntType, StringType)
+	if !union.HasTrait(traits.AdderType) || union.HasTrait(traits.NegatorType) {
+		t.Errorf("NewUnionType(int, string) got traits %d, wanted the traits common to int and string", union.traitMask)
+	}
+	if members := union.UnionMembers(); len(members) != 2 || members[0] != IntType || members[1] != StringType {
+		t.Errorf("UnionMembers() got %v, wanted [int, string]", members)
+	}
+}
+
 func TestTypeIsExactType(t *testing.T) {
 	tests := []struct {
 		t1      *Type
@@ -270,6 +314,26 @@ func TestTypeIsAssignableType(t *testing.T) {
 			t2:           NewObjectType("my.msg.MsgName2"),
 			isAssignable: false,
 		},
+		{
+			t1:           NewUnionType(IntType, StringType),
+			t2:           IntType,
+			isAssignable: true,
+		},
+		{
+			t1:           NewUnionType(IntType, StringType),
+			t2:           DoubleType,
+			isAssignable: false,
+		},
+		{
+			t1:           NewUnionType(IntType, StringType, DoubleType),
+			t2:           NewUnionType(StringType, IntType),
+			isAssignable: true,
+		},
+		{
+			t1:           NewUnionType(IntType, StringType),
+			t2:           NewUnionType(StringType, DoubleType),
+			isAssignable: false,
+		},
 	}
 	for _, tst := range tests {
 		if tst.t1.IsAssignableType(tst.t2) != tst.isAssignable {
@@ -349,6 +413,16 @@ func TestTypeIsAssignableRuntimeType(t *testing.T) {
 			v:                   map[string]int32{"one": 1},
 			isRuntimeAssignable: false,
 		},
+		{
+			t:                   NewUnionType(IntType, StringType),
+			v:                   "hello",
+			isRuntimeAssignable: true,
+		},
+		{
+			t:                   NewUnionType(IntType, StringType),
+			v:                   1.0,
+			isRuntimeAssignable: false,
+		},
 	}
 	for _, tst := range tests {
 		val := DefaultTypeAdapter.NativeToValue(tst.v)
@@ -368,6 +442,10 @@ func TestTypeToExprType(t *testing.T) {
 			in:  NewOpaqueType("vector", DoubleType, DoubleType),
 			out: chkdecls.NewAbstractType("vector", chkdecls.Double, chkdecls.Double),
 		},
+		{
+			in:  NewUnionType(IntType, NewListType(StringType)),
+			out: chkdecls.NewAbstractType("union", chkdecls.Int, chkdecls.NewListType(chkdecls.String)),
+		},
 		{
 			in:  AnyType,
 			out: chkdecls.Any,
@@ -67,6 +67,7 @@ func (t *skipTestsFlag) Get() any {
 
 var (
 	dashboard bool
+	bytecode  bool
 	tests     testsFlag
 	skipTests skipTestsFlag
 
@@ -76,6 +77,7 @@ var (
 
 func init() {
 	flag.BoolVar(&dashboard, "dashboard", false, "Dashboard.")
+	flag.BoolVar(&bytecode, "bytecode", false, "Evaluate programs with the bytecode backend.")
 	flag.Var(&tests, "tests", "Paths to run, separate by a comma.")
 	flag.Var(&skipTests, "skip_tests", "Tests to skip, separate by a comma.")
 
@@ -236,7 +238,11 @@ func conformanceTest(t *testing.T, name string, pb *testpb.SimpleTest) {
 		}
 		return
 	}
-	program, err := env.Program(ast)
+	var prgOpts []cel.ProgramOption
+	if bytecode {
+		prgOpts = append(prgOpts, cel.BytecodeEval())
+	}
+	program, err := env.Program(ast, prgOpts...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -274,6 +274,16 @@ func (sa *dynamicSlotActivation) ResolveName(name string) (any, bool) {
 	return sa.Activation.ResolveName(name)
 }
 
+// ResolveNameWithError implements the interpreter.ErrorResolver interface method, proxying lookups
+// of names other than the `@index` slots to the underlying activation.
+func (sa *dynamicSlotActivation) ResolveNameWithError(name string) (any, bool, error) {
+	if _, found := matchSlot(name, sa.slotCount); found {
+		obj, found := sa.ResolveName(name)
+		return obj, found, nil
+	}
+	return interpreter.ResolveNameWithError(sa.Activation, name)
+}
+
 func (sa *dynamicSlotActivation) reset() {
 	sa.Activation = nil
 	for _, sv := range sa.slotVals {
@@ -325,6 +335,15 @@ func (sa constantSlotActivation) ResolveName(name string) (any, bool) {
 	return sa.Activation.ResolveName(name)
 }
 
+// ResolveNameWithError implements the interpreter.ErrorResolver interface method, proxying lookups
+// of names other than the `@index` slots to the underlying activation.
+func (sa constantSlotActivation) ResolveNameWithError(name string) (any, bool, error) {
+	if idx, found := matchSlot(name, sa.slotCount); found {
+		return sa.slots.Get(types.Int(idx)), true, nil
+	}
+	return interpreter.ResolveNameWithError(sa.Activation, name)
+}
+
 func matchSlot(name string, slotCount int) (int, bool) {
 	if idx, found := strings.CutPrefix(name, indexPrefix); found {
 		idx, err := strconv.Atoi(idx)
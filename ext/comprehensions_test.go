@@ -22,6 +22,7 @@ import (
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
 	"github.com/google/cel-go/interpreter"
 )
 
@@ -533,6 +534,88 @@ func TestTwoVarComprehensionsRuntimeErrors(t *testing.T) {
 	}
 }
 
+func TestTwoVarComprehensionsParallel(t *testing.T) {
+	tests := []string{
+		"xs.all(i, v, i == v)",
+		"xs.exists(i, v, i != v)",
+		"xs.transformList(i, v, v * i)",
+		"xs.transformList(i, v, i % 2 == 0, v / 2)",
+		"xs.transformList(i, v, 10 / (v - 250))",
+		"xs.transformMap(i, v, v + 1)",
+		"xs.transformMapEntry(i, v, {v * 2: i})",
+		"xs.transformMapEntry(i, v, {v % 10: i})",
+		"m.transformMap(k, v, k + v)",
+		"m.transformMap(k, v, k % 3 == 0, k * v)",
+	}
+	xs := make([]int, 500)
+	m := make(map[int]int, 200)
+	for i := range xs {
+		xs[i] = i
+		m[i%200] = i % 200
+	}
+	env := testCompreEnv(t,
+		cel.Variable("xs", cel.ListType(cel.IntType)),
+		cel.Variable("m", cel.MapType(cel.IntType, cel.IntType)),
+	)
+	for _, tst := range tests {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			ast, iss := env.Compile(expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile(%q) failed: %v", expr, iss.Err())
+			}
+			var outs []ref.Val
+			var errs []error
+			for _, opts := range [][]cel.ProgramOption{{}, {cel.ParallelComprehensions(10, 4)}} {
+				prg, err := env.Program(ast, opts...)
+				if err != nil {
+					t.Fatalf("env.Program() failed: %v", err)
+				}
+				out, _, err := prg.Eval(map[string]any{"xs": xs, "m": m})
+				outs = append(outs, out)
+				errs = append(errs, err)
+			}
+			if errs[0] != nil || errs[1] != nil {
+				if errs[0] == nil || errs[1] == nil || errs[0].Error() != errs[1].Error() {
+					t.Errorf("prg.Eval() got error %v, wanted %v", errs[1], errs[0])
+				}
+				return
+			}
+			if outs[1].Equal(outs[0]) != types.True {
+				t.Errorf("prg.Eval() got %v, wanted %v", outs[1], outs[0])
+			}
+		})
+	}
+}
+
+func TestTwoVarComprehensionsMemoryLimit(t *testing.T) {
+	env := testCompreEnv(t, cel.Variable("xs", cel.ListType(cel.StringType)))
+	ast, iss := env.Compile("xs.transformMap(i, v, v + v)")
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	in := map[string]any{"xs": []string{"ab", "cd", "ef"}}
+	prg, err := env.Program(ast, cel.MemoryLimit(1000))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	_, det, err := prg.Eval(in)
+	if err != nil {
+		t.Fatalf("prg.Eval() failed: %v", err)
+	}
+	// Three four character strings and three map entries inserted into the result.
+	if mem := det.ActualMemory(); mem == nil || *mem != 3*4+3*32 {
+		t.Errorf("det.ActualMemory() got %v, wanted 108", mem)
+	}
+	prg, err = env.Program(ast, cel.MemoryLimit(100))
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	if _, _, err := prg.Eval(in); err == nil || !strings.Contains(err.Error(), "memory limit exceeded") {
+		t.Errorf("prg.Eval() got %v, wanted memory limit exceeded error", err)
+	}
+}
+
 func TestTwoVarComprehensionsVersion(t *testing.T) {
 	_, err := cel.NewEnv(TwoVarComprehensions(TwoVarComprehensionsVersion(0)))
 	if err != nil {
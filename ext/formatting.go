@@ -15,6 +15,7 @@
 package ext
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -716,11 +717,18 @@ func (e formatError) Is(target error) bool {
 
 // stringArgList implements the formatListArgs interface.
 type stringArgList struct {
+	ctx  context.Context
 	args traits.Lister
 }
 
 // Arg implements formatListArgs.Arg.
+//
+// Arguments are retrieved once per formatting clause, so the evaluation context is checked for
+// cancellation before each clause is formatted.
 func (c *stringArgList) Arg(index int64) (ref.Val, error) {
+	if c.ctx.Err() != nil {
+		return nil, context.Cause(c.ctx)
+	}
 	if index >= c.args.Size().Value().(int64) {
 		return nil, fmt.Errorf("index %d out of range", index)
 	}
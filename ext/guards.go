@@ -15,6 +15,8 @@
 package ext
 
 import (
+	"context"
+
 	"github.com/google/cel-go/common/ast"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
@@ -50,6 +52,31 @@ func listStringOrError(strs []string, err error) ref.Val {
 	return types.DefaultTypeAdapter.NativeToValue(strs)
 }
 
+// cancellationCheckInterval is the number of iterations between checks of the evaluation context
+// within long-running extension functions.
+const cancellationCheckInterval = 64
+
+// cancellationCheck cooperatively checks whether the evaluation context is done.
+//
+// Extension functions which receive the evaluation context should check for cancellation within
+// loops whose iteration count depends on the size of the inputs. The error value returned by the
+// function is discarded when the context is done, as the evaluation is cancelled once the call
+// completes.
+type cancellationCheck struct {
+	ctx   context.Context
+	count int
+}
+
+// err returns the cause of the context cancellation, checking the context once per
+// cancellationCheckInterval invocations.
+func (c *cancellationCheck) err() error {
+	c.count++
+	if c.count%cancellationCheckInterval != 0 || c.ctx.Err() == nil {
+		return nil
+	}
+	return context.Cause(c.ctx)
+}
+
 func extractIdent(target ast.Expr) (string, bool) {
 	switch target.Kind() {
 	case ast.IdentKind:
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+)
+
+func TestCancellationChecks(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.OptionalTypes(),
+		Lists(),
+		Regex(),
+		Strings(),
+		cel.Variable("xs", cel.ListType(cel.IntType)),
+		cel.Variable("xss", cel.ListType(cel.ListType(cel.IntType))),
+		cel.Variable("s", cel.StringType),
+		cel.Variable("f", cel.StringType),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	xs := make([]int, 1000)
+	xss := make([][]int, 1000)
+	for i := range xs {
+		xs[i] = len(xs) - i
+		xss[i] = []int{i}
+	}
+	vars := map[string]any{
+		"xs":  xs,
+		"xss": xss,
+		"s":   strings.Repeat("a1", 1000),
+		"f":   strings.Repeat("%d ", 1000),
+	}
+	tests := []string{
+		`xs.sort().size() == 1000`,
+		`xs.sortBy(x, -x).size() == 1000`,
+		`xss.flatten().size() == 1000`,
+		`regex.extractAll(s, '\\d').size() == 1000`,
+		`regex.replace(s, '\\d', 'b') != s`,
+		`f.format(xs) != f`,
+	}
+	for _, tst := range tests {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			ast, iss := env.Compile(expr)
+			if iss.Err() != nil {
+				t.Fatalf("env.Compile() failed: %v", iss.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				t.Fatalf("env.Program() failed: %v", err)
+			}
+			out, _, err := prg.Eval(vars)
+			if err != nil || out != types.True {
+				t.Fatalf("prg.Eval() got %v, %v, wanted true", out, err)
+			}
+			// The context is cancelled while the extension function is running.
+			ctx := &countdownContext{Context: context.Background(), remaining: 2}
+			out, _, err = prg.ContextEval(ctx, vars)
+			if err == nil {
+				t.Fatalf("prg.ContextEval() got %v, wanted cancellation error", out)
+			}
+			var cancelled interpreter.EvalCancelledError
+			if !errors.As(err, &cancelled) || cancelled.Cause != interpreter.ContextCancelled {
+				t.Errorf("prg.ContextEval() got %v, wanted EvalCancelledError", err)
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("prg.ContextEval() got %v, wanted context canceled", err)
+			}
+		})
+	}
+}
+
+// countdownContext reports that it is cancelled once the context error has been checked more than
+// the configured number of times.
+type countdownContext struct {
+	context.Context
+	remaining int
+}
+
+func (ctx *countdownContext) Err() error {
+	if ctx.remaining <= 0 {
+		return context.Canceled
+	}
+	ctx.remaining--
+	return nil
+}
@@ -0,0 +1,615 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+)
+
+// JSONSchemaProvider is a types.Provider whose types are derived from a JSON Schema or an
+// OpenAPI v3 document.
+//
+// Schemas are mapped to CEL types as follows:
+//
+//   - Objects with properties become schema-defined struct types whose fields are the properties.
+//   - Objects with only additionalProperties become map(string, V), and objects which permit
+//     arbitrary properties become map(string, dyn).
+//   - Arrays become list(T) of the items type.
+//   - Strings become string, except for the date-time format which becomes timestamp, the
+//     duration format which becomes duration, and the byte format which becomes bytes. Values of
+//     the duration format are ISO 8601 durations, e.g. PT1H30M, and durations with years or
+//     months are reported as errors at runtime as they do not have a fixed length.
+//   - Integers become int, numbers become double, and booleans become bool.
+//   - Enums without a type take the type of their values.
+//   - Nullable primitive types become wrapper types which are assignable from null.
+//   - Schemas which cannot be described by a single CEL type, such as anyOf and oneOf, as well as
+//     Kubernetes x-kubernetes-int-or-string and x-kubernetes-preserve-unknown-fields schemas,
+//     become dyn.
+//
+// Object schemas which are defined under $defs, definitions, or components.schemas are named by
+// their definition name qualified by the package set with JSONSchemaPackage. Inline object schemas
+// are named by the path of property names from the nearest named schema.
+//
+// Values of struct types are backed by Go maps, such as those produced by decoding JSON documents,
+// so JSON values may be type-checked and evaluated without any protobuf descriptors.
+type JSONSchemaProvider struct {
+	*types.Registry
+
+	opts        jsonSchemaOptions
+	defs        map[string]*jsonSchema
+	defTypes    map[string]*types.Type
+	inProgress  map[string]bool
+	root        *jsonSchema
+	rootType    *types.Type
+	schemaTypes []*types.SchemaType
+	structs     map[string]*jsonSchema
+}
+
+// JSONSchemaOption configures the mapping of a JSON Schema or OpenAPI document to CEL types.
+type JSONSchemaOption func(*jsonSchemaOptions) error
+
+type jsonSchemaOptions struct {
+	pkg      string
+	rootName string
+}
+
+// JSONSchemaPackage sets the package name which qualifies the names of the struct types derived
+// from the document.
+func JSONSchemaPackage(pkg string) JSONSchemaOption {
+	return func(opts *jsonSchemaOptions) error {
+		opts.pkg = pkg
+		return nil
+	}
+}
+
+// JSONSchemaRootType sets the name of the struct type derived from the root schema of a JSON
+// Schema document. The default name is 'Root'.
+func JSONSchemaRootType(name string) JSONSchemaOption {
+	return func(opts *jsonSchemaOptions) error {
+		if name == "" {
+			return fmt.Errorf("invalid root type name: %q", name)
+		}
+		opts.rootName = name
+		return nil
+	}
+}
+
+// NewJSONSchemaProvider creates a types.Provider from a JSON Schema or an OpenAPI v3 document in
+// JSON or YAML form.
+//
+// Documents with an 'openapi' property are treated as OpenAPI documents whose schemas are defined
+// within components.schemas. All other documents are treated as JSON Schema documents whose root
+// schema, as well as any schemas within $defs or definitions, are mapped to CEL types.
+//
+// The struct types of the provider may be added to an environment with cel.SchemaTypes, or the
+// provider may be used directly with cel.CustomTypeProvider.
+func NewJSONSchemaProvider(doc []byte, opts ...JSONSchemaOption) (*JSONSchemaProvider, error) {
+	p := &JSONSchemaProvider{
+		opts:       jsonSchemaOptions{rootName: "Root"},
+		defs:       make(map[string]*jsonSchema),
+		defTypes:   make(map[string]*types.Type),
+		inProgress: make(map[string]bool),
+		structs:    make(map[string]*jsonSchema),
+	}
+	for _, opt := range opts {
+		if err := opt(&p.opts); err != nil {
+			return nil, err
+		}
+	}
+	reg, err := types.NewProtoRegistry()
+	if err != nil {
+		return nil, err
+	}
+	p.Registry = reg
+
+	var parsed jsonSchemaDocument
+	if err := unmarshalJSONOrYAML(doc, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid schema document: %w", err)
+	}
+	if parsed.OpenAPI != "" {
+		for name, s := range parsed.Components.Schemas {
+			p.defs[name] = s
+		}
+	} else {
+		for name, s := range parsed.Definitions {
+			p.defs[name] = s
+		}
+		for name, s := range parsed.Defs {
+			p.defs[name] = s
+		}
+		p.root = &parsed.jsonSchema
+	}
+
+	defNames := make([]string, 0, len(p.defs))
+	for name := range p.defs {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		if _, err := p.defType(name); err != nil {
+			return nil, err
+		}
+	}
+	if p.root != nil {
+		if p.rootType, err = p.celType(p.root, p.qualify(p.opts.rootName)); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.Registry.RegisterSchemaType(p.schemaTypes...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RootType returns the CEL type of the root schema of a JSON Schema document.
+//
+// Returns nil for OpenAPI documents.
+func (p *JSONSchemaProvider) RootType() *types.Type {
+	return p.rootType
+}
+
+// DefinitionType returns the CEL type of the schema with the given name under $defs,
+// definitions, or components.schemas.
+func (p *JSONSchemaProvider) DefinitionType(name string) (*types.Type, bool) {
+	t, found := p.defTypes[name]
+	return t, found
+}
+
+// SchemaTypes returns the struct types derived from the document.
+func (p *JSONSchemaProvider) SchemaTypes() []*types.SchemaType {
+	return p.schemaTypes
+}
+
+// CostEstimator returns a checker.CostEstimator which estimates the sizes of strings, bytes,
+// lists, and maps reachable from the given variables using the minLength, maxLength, minItems,
+// maxItems, minProperties, and maxProperties constraints of their schemas.
+//
+// Only variables whose types are struct types derived from the document provide size estimates.
+func (p *JSONSchemaProvider) CostEstimator(vars ...*decls.VariableDecl) checker.CostEstimator {
+	est := &jsonSchemaCostEstimator{provider: p, vars: make(map[string]*types.Type, len(vars))}
+	for _, v := range vars {
+		est.vars[v.Name()] = v.Type()
+	}
+	return est
+}
+
+func (p *JSONSchemaProvider) qualify(name string) string {
+	if p.opts.pkg == "" {
+		return name
+	}
+	return p.opts.pkg + "." + name
+}
+
+// defType returns the CEL type of a named definition.
+func (p *JSONSchemaProvider) defType(name string) (*types.Type, error) {
+	if t, found := p.defTypes[name]; found {
+		return t, nil
+	}
+	s, found := p.defs[name]
+	if !found {
+		return nil, fmt.Errorf("undefined schema reference: %q", name)
+	}
+	typeName := p.qualify(name)
+	if p.inProgress[name] {
+		// Recursive references to object schemas refer to the struct type by name. All other
+		// recursive schemas cannot be described statically.
+		if s.isStruct() {
+			return types.NewObjectType(typeName), nil
+		}
+		return types.DynType, nil
+	}
+	p.inProgress[name] = true
+	defer delete(p.inProgress, name)
+	t, err := p.celType(s, typeName)
+	if err != nil {
+		return nil, fmt.Errorf("schema %q: %w", name, err)
+	}
+	p.defTypes[name] = t
+	return t, nil
+}
+
+// celType maps a schema to a CEL type, where the name is used for struct types derived from
+// object schemas.
+func (p *JSONSchemaProvider) celType(s *jsonSchema, name string) (*types.Type, error) {
+	if s == nil {
+		return types.DynType, nil
+	}
+	if s.Ref != "" {
+		defName, err := refDefName(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		return p.defType(defName)
+	}
+	if len(s.AllOf) == 1 && s.schemaType() == "" {
+		return p.celType(s.AllOf[0], name)
+	}
+	if s.IntOrString || len(s.AllOf) != 0 || len(s.AnyOf) != 0 || len(s.OneOf) != 0 {
+		return types.DynType, nil
+	}
+	var t *types.Type
+	switch s.schemaType() {
+	case "object":
+		switch {
+		case s.isStruct():
+			return p.structType(s, name)
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			vt, err := p.celType(s.AdditionalProperties.Schema, name)
+			if err != nil {
+				return nil, err
+			}
+			return types.NewMapType(types.StringType, vt), nil
+		case s.PreserveUnknownFields:
+			return types.DynType, nil
+		}
+		return types.NewMapType(types.StringType, types.DynType), nil
+	case "array":
+		et, err := p.celType(s.Items, name)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewListType(et), nil
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return types.TimestampType, nil
+		case "duration":
+			return types.DurationType, nil
+		case "byte":
+			t = types.BytesType
+		default:
+			t = types.StringType
+		}
+	case "integer":
+		t = types.IntType
+	case "number":
+		t = types.DoubleType
+	case "boolean":
+		t = types.BoolType
+	case "null":
+		return types.NullType, nil
+	default:
+		return types.DynType, nil
+	}
+	if s.isNullable() {
+		return types.NewNullableType(t), nil
+	}
+	return t, nil
+}
+
+func (p *JSONSchemaProvider) structType(s *jsonSchema, name string) (*types.Type, error) {
+	if _, found := p.structs[name]; found {
+		return types.NewObjectType(name), nil
+	}
+	p.structs[name] = s
+	propNames := make([]string, 0, len(s.Properties))
+	for prop := range s.Properties {
+		propNames = append(propNames, prop)
+	}
+	sort.Strings(propNames)
+	fields := make([]*types.SchemaField, len(propNames))
+	for i, prop := range propNames {
+		ps := s.Properties[prop]
+		ft, err := p.celType(ps, name+"."+jsonSchemaIdent(prop))
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = &types.SchemaField{Name: prop, Type: ft, Description: ps.Description}
+	}
+	st, err := types.NewSchemaType(name, fields...)
+	if err != nil {
+		return nil, err
+	}
+	p.schemaTypes = append(p.schemaTypes, st)
+	return st.CELType(), nil
+}
+
+// resolve follows schema references, returning nil for unresolvable references.
+func (p *JSONSchemaProvider) resolve(s *jsonSchema) *jsonSchema {
+	for i := 0; s != nil && s.Ref != "" && i < len(p.defs); i++ {
+		defName, err := refDefName(s.Ref)
+		if err != nil {
+			return nil
+		}
+		s = p.defs[defName]
+	}
+	if s != nil && len(s.AllOf) == 1 && s.schemaType() == "" {
+		return p.resolve(s.AllOf[0])
+	}
+	return s
+}
+
+// jsonSchemaCostEstimator provides size estimates from the length constraints of schemas.
+type jsonSchemaCostEstimator struct {
+	provider *JSONSchemaProvider
+	vars     map[string]*types.Type
+}
+
+// EstimateSize implements the checker.CostEstimator interface method.
+func (e *jsonSchemaCostEstimator) EstimateSize(node checker.AstNode) *checker.SizeEstimate {
+	path := node.Path()
+	if len(path) == 0 {
+		return nil
+	}
+	t, found := e.vars[path[0]]
+	if !found {
+		return nil
+	}
+	s := e.provider.structs[t.TypeName()]
+	for _, elem := range path[1:] {
+		if s == nil {
+			return nil
+		}
+		switch elem {
+		case "@items":
+			s = e.provider.resolve(s.Items)
+		case "@values":
+			if s.AdditionalProperties == nil {
+				return nil
+			}
+			s = e.provider.resolve(s.AdditionalProperties.Schema)
+		case "@keys":
+			return nil
+		default:
+			s = e.provider.resolve(s.Properties[elem])
+		}
+	}
+	if s == nil {
+		return nil
+	}
+	var minSize, maxSize *uint64
+	switch s.schemaType() {
+	case "string":
+		minSize, maxSize = s.MinLength, s.MaxLength
+	case "array":
+		minSize, maxSize = s.MinItems, s.MaxItems
+	case "object":
+		minSize, maxSize = s.MinProperties, s.MaxProperties
+	}
+	if maxSize == nil {
+		return nil
+	}
+	est := &checker.SizeEstimate{Max: *maxSize}
+	if minSize != nil && *minSize <= *maxSize {
+		est.Min = *minSize
+	}
+	return est
+}
+
+// EstimateCallCost implements the checker.CostEstimator interface method.
+func (e *jsonSchemaCostEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// jsonSchemaDocument is a JSON Schema or OpenAPI v3 document.
+type jsonSchemaDocument struct {
+	jsonSchema
+
+	OpenAPI    string `json:"openapi"`
+	Components struct {
+		Schemas map[string]*jsonSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// jsonSchema is the subset of JSON Schema and the OpenAPI v3 schema object which informs the
+// mapping to CEL types.
+type jsonSchema struct {
+	Ref                   string                 `json:"$ref"`
+	Type                  jsonSchemaTypeList     `json:"type"`
+	Format                string                 `json:"format"`
+	Description           string                 `json:"description"`
+	Properties            map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties  *jsonSchemaOrBool      `json:"additionalProperties"`
+	Items                 *jsonSchema            `json:"items"`
+	Enum                  []any                  `json:"enum"`
+	Nullable              bool                   `json:"nullable"`
+	AllOf                 []*jsonSchema          `json:"allOf"`
+	AnyOf                 []*jsonSchema          `json:"anyOf"`
+	OneOf                 []*jsonSchema          `json:"oneOf"`
+	MinLength             *uint64                `json:"minLength"`
+	MaxLength             *uint64                `json:"maxLength"`
+	MinItems              *uint64                `json:"minItems"`
+	MaxItems              *uint64                `json:"maxItems"`
+	MinProperties         *uint64                `json:"minProperties"`
+	MaxProperties         *uint64                `json:"maxProperties"`
+	Defs                  map[string]*jsonSchema `json:"$defs"`
+	Definitions           map[string]*jsonSchema `json:"definitions"`
+	IntOrString           bool                   `json:"x-kubernetes-int-or-string"`
+	PreserveUnknownFields bool                   `json:"x-kubernetes-preserve-unknown-fields"`
+}
+
+// schemaType returns the single non-null type of the schema, inferring the type from the
+// properties, items, or enum values when the type is not declared. Returns an empty string if
+// the schema does not describe a single type.
+func (s *jsonSchema) schemaType() string {
+	var nonNull []string
+	for _, t := range s.Type {
+		if t != "null" {
+			nonNull = append(nonNull, t)
+		}
+	}
+	switch {
+	case len(nonNull) == 1:
+		return nonNull[0]
+	case len(nonNull) > 1:
+		return ""
+	case len(s.Type) == 1:
+		return "null"
+	case len(s.Properties) != 0 || s.AdditionalProperties != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	case len(s.Enum) != 0:
+		return enumType(s.Enum)
+	}
+	return ""
+}
+
+// isStruct indicates whether the schema is an object schema with declared properties.
+func (s *jsonSchema) isStruct() bool {
+	return s.schemaType() == "object" && len(s.Properties) != 0
+}
+
+// isNullable indicates whether the schema permits null values.
+func (s *jsonSchema) isNullable() bool {
+	if s.Nullable {
+		return true
+	}
+	for _, t := range s.Type {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// enumType returns the JSON type shared by all enum values, if any.
+func enumType(values []any) string {
+	typeName := ""
+	for _, v := range values {
+		var t string
+		switch v := v.(type) {
+		case string:
+			t = "string"
+		case bool:
+			t = "boolean"
+		case float64:
+			t = "integer"
+			if v != math.Trunc(v) {
+				t = "number"
+			}
+		case nil:
+			continue
+		default:
+			return ""
+		}
+		switch {
+		case typeName == "" || typeName == t:
+			typeName = t
+		case typeName == "integer" && t == "number", typeName == "number" && t == "integer":
+			typeName = "number"
+		default:
+			return ""
+		}
+	}
+	return typeName
+}
+
+// jsonSchemaTypeList is the value of the 'type' keyword which may be a single type name or a list.
+type jsonSchemaTypeList []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface method.
+func (tl *jsonSchemaTypeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*tl = jsonSchemaTypeList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("invalid schema type: %s", data)
+	}
+	*tl = list
+	return nil
+}
+
+// jsonSchemaOrBool is the value of the 'additionalProperties' keyword which may be a schema or a
+// boolean.
+type jsonSchemaOrBool struct {
+	Allowed bool
+	Schema  *jsonSchema
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface method.
+func (sb *jsonSchemaOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &sb.Allowed); err == nil {
+		return nil
+	}
+	sb.Allowed = true
+	sb.Schema = &jsonSchema{}
+	return json.Unmarshal(data, sb.Schema)
+}
+
+// refDefName returns the definition name referenced by a local schema reference.
+func refDefName(ref string) (string, error) {
+	for _, prefix := range []string{"#/$defs/", "#/definitions/", "#/components/schemas/"} {
+		if name, found := strings.CutPrefix(ref, prefix); found {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported schema reference: %q", ref)
+}
+
+// jsonSchemaIdent converts a property name into a valid CEL identifier for use in a type name.
+func jsonSchemaIdent(name string) string {
+	ident := []rune(name)
+	for i, r := range ident {
+		isAlpha := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isAlpha && (i == 0 || r < '0' || r > '9') {
+			ident[i] = '_'
+		}
+	}
+	return string(ident)
+}
+
+// unmarshalJSONOrYAML decodes a JSON document, or a YAML document by way of its JSON form.
+func unmarshalJSONOrYAML(doc []byte, out any) error {
+	trimmed := bytes.TrimSpace(doc)
+	if len(trimmed) != 0 && trimmed[0] == '{' {
+		return json.Unmarshal(trimmed, out)
+	}
+	var yamlDoc any
+	if err := yaml.Unmarshal(doc, &yamlDoc); err != nil {
+		return err
+	}
+	jsonDoc, err := json.Marshal(jsonCompatibleYAML(yamlDoc))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonDoc, out)
+}
+
+// jsonCompatibleYAML converts YAML mappings with non-string keys, such as OpenAPI response codes,
+// into mappings with string keys.
+func jsonCompatibleYAML(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = jsonCompatibleYAML(elem)
+		}
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, elem := range v {
+			m[fmt.Sprint(k)] = jsonCompatibleYAML(elem)
+		}
+		return m
+	case []any:
+		for i, elem := range v {
+			v[i] = jsonCompatibleYAML(elem)
+		}
+	}
+	return v
+}
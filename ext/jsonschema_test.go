@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ext
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+)
+
+const testOpenAPIDoc = `
+openapi: 3.0.0
+paths:
+  /pods:
+    get:
+      responses:
+        200:
+          description: ok
+components:
+  schemas:
+    Pod:
+      type: object
+      properties:
+        metadata:
+          type: object
+          properties:
+            name:
+              type: string
+              maxLength: 63
+            labels:
+              type: object
+              additionalProperties:
+                type: string
+              maxProperties: 16
+        spec:
+          type: object
+          properties:
+            replicas:
+              type: integer
+              nullable: true
+            created:
+              type: string
+              format: date-time
+            timeout:
+              type: string
+              format: duration
+            phase:
+              enum: [Pending, Running]
+            port:
+              x-kubernetes-int-or-string: true
+            containers:
+              type: array
+              maxItems: 10
+              items:
+                $ref: '#/components/schemas/Container'
+    Container:
+      type: object
+      properties:
+        name:
+          type: string
+          minLength: 1
+          maxLength: 32
+        args:
+          type: array
+          items:
+            type: string
+    Tree:
+      type: object
+      properties:
+        value:
+          type: number
+        children:
+          type: array
+          items:
+            $ref: '#/components/schemas/Tree'
+`
+
+func TestJSONSchemaProviderTypes(t *testing.T) {
+	p, err := NewJSONSchemaProvider([]byte(testOpenAPIDoc), JSONSchemaPackage("k8s"))
+	if err != nil {
+		t.Fatalf("NewJSONSchemaProvider() failed: %v", err)
+	}
+	if p.RootType() != nil {
+		t.Errorf("p.RootType() got %v, wanted nil for an OpenAPI document", p.RootType())
+	}
+	tests := []struct {
+		typeName string
+		field    string
+		want     *types.Type
+	}{
+		{typeName: "k8s.Pod", field: "metadata", want: types.NewObjectType("k8s.Pod.metadata")},
+		{typeName: "k8s.Pod.metadata", field: "labels", want: types.NewMapType(types.StringType, types.StringType)},
+		{typeName: "k8s.Pod.spec", field: "replicas", want: types.NewNullableType(types.IntType)},
+		{typeName: "k8s.Pod.spec", field: "created", want: types.TimestampType},
+		{typeName: "k8s.Pod.spec", field: "timeout", want: types.DurationType},
+		{typeName: "k8s.Pod.spec", field: "phase", want: types.StringType},
+		{typeName: "k8s.Pod.spec", field: "port", want: types.DynType},
+		{typeName: "k8s.Pod.spec", field: "containers", want: types.NewListType(types.NewObjectType("k8s.Container"))},
+		{typeName: "k8s.Tree", field: "children", want: types.NewListType(types.NewObjectType("k8s.Tree"))},
+	}
+	for _, tc := range tests {
+		ft, found := p.FindStructFieldType(tc.typeName, tc.field)
+		if !found {
+			t.Errorf("p.FindStructFieldType(%s, %s) not found", tc.typeName, tc.field)
+			continue
+		}
+		if !ft.Type.IsExactType(tc.want) || ft.Type.String() != tc.want.String() {
+			t.Errorf("p.FindStructFieldType(%s, %s) got %v, wanted %v", tc.typeName, tc.field, ft.Type, tc.want)
+		}
+	}
+	if podType, found := p.DefinitionType("Pod"); !found || podType.TypeName() != "k8s.Pod" {
+		t.Errorf("p.DefinitionType(Pod) got %v, %v", podType, found)
+	}
+}
+
+func TestJSONSchemaProviderEval(t *testing.T) {
+	p, err := NewJSONSchemaProvider([]byte(testOpenAPIDoc), JSONSchemaPackage("k8s"))
+	if err != nil {
+		t.Fatalf("NewJSONSchemaProvider() failed: %v", err)
+	}
+	podType, _ := p.DefinitionType("Pod")
+	env, err := cel.NewEnv(
+		cel.Container("k8s"),
+		cel.SchemaTypes(p.SchemaTypes()...),
+		cel.Variable("pod", podType),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	var pod map[string]any
+	err = json.Unmarshal([]byte(`{
+		"metadata": {"name": "web", "labels": {"app": "web"}},
+		"spec": {
+			"replicas": 3,
+			"created": "2026-01-02T03:04:05Z",
+			"timeout": "PT1M30S",
+			"phase": "Running",
+			"port": "http",
+			"containers": [{"name": "nginx", "args": ["-g"]}, {"name": "sidecar"}]
+		}
+	}`), &pod)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	exprs := []string{
+		`pod.metadata.name == 'web' && pod.metadata.labels['app'] == 'web'`,
+		`pod.spec.replicas + 1 == 4`,
+		`pod.spec.created < timestamp('2027-01-01T00:00:00Z') && pod.spec.timeout == duration('90s')`,
+		`pod.spec.containers.map(c, c.name) == ['nginx', 'sidecar']`,
+		`pod.spec.containers[1].args.size() == 0 && !has(pod.spec.containers[1].args)`,
+		`pod.spec.port == 'http'`,
+		`Container{name: 'init'}.args == []`,
+	}
+	for _, expr := range exprs {
+		ast, iss := env.Compile(expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%s) failed: %v", expr, iss.Err())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			t.Fatalf("env.Program(%s) failed: %v", expr, err)
+		}
+		out, _, err := prg.Eval(map[string]any{"pod": pod})
+		if err != nil {
+			t.Fatalf("prg.Eval(%s) failed: %v", expr, err)
+		}
+		if out != types.True {
+			t.Errorf("prg.Eval(%s) got %v, wanted true", expr, out)
+		}
+	}
+	_, iss := env.Compile(`pod.spec.replicas == 'three'`)
+	if iss.Err() == nil || !strings.Contains(iss.Err().Error(), "found no matching overload for '_==_'") {
+		t.Errorf("env.Compile() got %v, wanted a type-check error", iss.Err())
+	}
+}
+
+func TestJSONSchemaProviderCost(t *testing.T) {
+	p, err := NewJSONSchemaProvider([]byte(testOpenAPIDoc), JSONSchemaPackage("k8s"))
+	if err != nil {
+		t.Fatalf("NewJSONSchemaProvider() failed: %v", err)
+	}
+	podType, _ := p.DefinitionType("Pod")
+	env, err := cel.NewEnv(
+		cel.SchemaTypes(p.SchemaTypes()...),
+		cel.Variable("pod", podType),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	estimator := p.CostEstimator(decls.NewVariable("pod", podType))
+	tests := []struct {
+		expr string
+		max  uint64
+	}{
+		{expr: `pod.spec.containers.all(c, c.name.startsWith('a'))`, max: 64},
+		{expr: `pod.metadata.name.contains('a')`, max: 10},
+		{expr: `pod.metadata.labels.exists(k, k == 'app')`, max: 100},
+	}
+	for _, tc := range tests {
+		ast, iss := env.Compile(tc.expr)
+		if iss.Err() != nil {
+			t.Fatalf("env.Compile(%s) failed: %v", tc.expr, iss.Err())
+		}
+		cost, err := env.EstimateCost(ast, estimator)
+		if err != nil {
+			t.Fatalf("env.EstimateCost(%s) failed: %v", tc.expr, err)
+		}
+		if cost.Max != tc.max {
+			t.Errorf("env.EstimateCost(%s) got max cost %d, wanted %d", tc.expr, cost.Max, tc.max)
+		}
+		// Without the schema constraints, the sizes are unbounded.
+		unbounded, err := env.EstimateCost(ast, p.CostEstimator())
+		if err != nil {
+			t.Fatalf("env.EstimateCost(%s) failed: %v", tc.expr, err)
+		}
+		if unbounded.Max <= cost.Max {
+			t.Errorf("env.EstimateCost(%s) without size estimates got max cost %d, wanted more than %d",
+				tc.expr, unbounded.Max, cost.Max)
+		}
+	}
+}
+
+func TestJSONSchemaProviderRoot(t *testing.T) {
+	doc := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": ["string", "null"]},
+			"kind": {"enum": [1, 2, 3]},
+			"score": {"enum": [0.5, 1]},
+			"data": {"type": "string", "format": "byte"},
+			"extra": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+			"attrs": {"type": "object"},
+			"next": {"$ref": "#/$defs/Node"}
+		},
+		"$defs": {
+			"Node": {
+				"type": "object",
+				"properties": {"next": {"$ref": "#/$defs/Node"}, "value": {"type": "integer"}}
+			}
+		}
+	}`
+	p, err := NewJSONSchemaProvider([]byte(doc), JSONSchemaRootType("Doc"))
+	if err != nil {
+		t.Fatalf("NewJSONSchemaProvider() failed: %v", err)
+	}
+	if p.RootType().TypeName() != "Doc" {
+		t.Errorf("p.RootType() got %v, wanted Doc", p.RootType())
+	}
+	tests := map[string]*types.Type{
+		"id":    types.NewNullableType(types.StringType),
+		"kind":  types.IntType,
+		"score": types.DoubleType,
+		"data":  types.BytesType,
+		"extra": types.DynType,
+		"attrs": types.NewMapType(types.StringType, types.DynType),
+		"next":  types.NewObjectType("Node"),
+	}
+	for field, want := range tests {
+		ft, found := p.FindStructFieldType("Doc", field)
+		if !found || ft.Type.String() != want.String() {
+			t.Errorf("p.FindStructFieldType(Doc, %s) got %v, wanted %v", field, ft, want)
+		}
+	}
+
+	// The provider may be used directly as the type provider of an environment.
+	env, err := cel.NewEnv(
+		cel.CustomTypeProvider(p),
+		cel.CustomTypeAdapter(p),
+		cel.Variable("doc", p.RootType()),
+	)
+	if err != nil {
+		t.Fatalf("cel.NewEnv() failed: %v", err)
+	}
+	ast, iss := env.Compile(`doc.data == b'hi' && doc.next.next.value == 2 && !has(doc.next.next.next) && doc.kind == 1`)
+	if iss.Err() != nil {
+		t.Fatalf("env.Compile() failed: %v", iss.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("env.Program() failed: %v", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"doc": map[string]any{
+		"data": "aGk=",
+		"kind": 1.0,
+		"next": map[string]any{"next": map[string]any{"value": 2.0}},
+	}})
+	if err != nil || out != types.True {
+		t.Errorf("prg.Eval() got %v, %v, wanted true", out, err)
+	}
+}
+
+func TestJSONSchemaProviderErrors(t *testing.T) {
+	tests := []struct {
+		doc string
+		err string
+	}{
+		{doc: `{"type": 1}`, err: "invalid schema type"},
+		{doc: `{"properties": {"a": {"$ref": "#/$defs/Missing"}}}`, err: `undefined schema reference: "Missing"`},
+		{doc: `{"properties": {"a": {"$ref": "other.json#/a"}}}`, err: "unsupported schema reference"},
+		{doc: "openapi: [", err: "invalid schema document"},
+	}
+	for _, tc := range tests {
+		_, err := NewJSONSchemaProvider([]byte(tc.doc))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("NewJSONSchemaProvider(%s) got error %v, wanted %q", tc.doc, err, tc.err)
+		}
+	}
+	if _, err := NewJSONSchemaProvider([]byte(`{}`), JSONSchemaRootType("")); err == nil {
+		t.Error("NewJSONSchemaProvider() with an empty root type name succeeded, wanted error")
+	}
+}
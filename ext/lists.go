@@ -15,6 +15,7 @@
 package ext
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -215,13 +216,13 @@ func (lib listsLib) CompileOptions() []cel.EnvOption {
 			cel.Function("flatten",
 				cel.MemberOverload("list_flatten",
 					[]*cel.Type{listListType}, listType,
-					cel.UnaryBinding(func(arg ref.Val) ref.Val {
+					cel.ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
 						// double-check as type-guards disabled
 						list, ok := arg.(traits.Lister)
 						if !ok {
 							return types.ValOrErr(arg, "no such overload: %v.flatten()", arg.Type())
 						}
-						flatList, err := flatten(list, 1)
+						flatList, err := flatten(&cancellationCheck{ctx: ctx}, list, 1)
 						if err != nil {
 							return types.WrapErr(err)
 						}
@@ -231,7 +232,7 @@ func (lib listsLib) CompileOptions() []cel.EnvOption {
 				),
 				cel.MemberOverload("list_flatten_int",
 					[]*cel.Type{listDyn, types.IntType}, listDyn,
-					cel.BinaryBinding(func(arg1, arg2 ref.Val) ref.Val {
+					cel.ContextBinaryBinding(func(ctx context.Context, arg1, arg2 ref.Val) ref.Val {
 						// double-check as type-guards disabled
 						list, ok := arg1.(traits.Lister)
 						if !ok {
@@ -241,7 +242,7 @@ func (lib listsLib) CompileOptions() []cel.EnvOption {
 						if !ok {
 							return types.ValOrErr(arg1, "no such overload: %v.flatten(%v)", arg1.Type(), arg2.Type())
 						}
-						flatList, err := flatten(list, int64(depth))
+						flatList, err := flatten(&cancellationCheck{ctx: ctx}, list, int64(depth))
 						if err != nil {
 							return types.WrapErr(err)
 						}
@@ -258,55 +259,43 @@ func (lib listsLib) CompileOptions() []cel.EnvOption {
 	}
 	if lib.version >= 2 {
 		sortDecl := cel.Function("sort",
-			append(
-				templatedOverloads(comparableTypes, func(t *cel.Type) cel.FunctionOpt {
-					return cel.MemberOverload(
-						fmt.Sprintf("list_%s_sort", t.TypeName()),
-						[]*cel.Type{cel.ListType(t)}, cel.ListType(t),
-					)
-				}),
-				cel.SingletonUnaryBinding(
-					func(arg ref.Val) ref.Val {
+			templatedOverloads(comparableTypes, func(t *cel.Type) cel.FunctionOpt {
+				return cel.MemberOverload(
+					fmt.Sprintf("list_%s_sort", t.TypeName()),
+					[]*cel.Type{cel.ListType(t)}, cel.ListType(t),
+					cel.ContextUnaryBinding(func(ctx context.Context, arg ref.Val) ref.Val {
 						// validated by type-guards
 						list := arg.(traits.Lister)
-						sorted, err := sortList(list)
+						sorted, err := sortList(&cancellationCheck{ctx: ctx}, list)
 						if err != nil {
 							return types.WrapErr(err)
 						}
 
 						return sorted
-					},
-					// List traits
-					traits.ListerType,
-				),
-			)...,
+					}),
+				)
+			})...,
 		)
 		opts = append(opts, sortDecl)
 		opts = append(opts, cel.Macros(cel.ReceiverMacro("sortBy", 2, sortByMacro)))
 		opts = append(opts, cel.Function("@sortByAssociatedKeys",
-			append(
-				templatedOverloads(comparableTypes, func(u *cel.Type) cel.FunctionOpt {
-					return cel.MemberOverload(
-						fmt.Sprintf("list_%s_sortByAssociatedKeys", u.TypeName()),
-						[]*cel.Type{listType, cel.ListType(u)}, listType,
-					)
-				}),
-				cel.SingletonBinaryBinding(
-					func(arg1, arg2 ref.Val) ref.Val {
+			templatedOverloads(comparableTypes, func(u *cel.Type) cel.FunctionOpt {
+				return cel.MemberOverload(
+					fmt.Sprintf("list_%s_sortByAssociatedKeys", u.TypeName()),
+					[]*cel.Type{listType, cel.ListType(u)}, listType,
+					cel.ContextBinaryBinding(func(ctx context.Context, arg1, arg2 ref.Val) ref.Val {
 						// validated by type-guards
 						list := arg1.(traits.Lister)
 						keys := arg2.(traits.Lister)
-						sorted, err := sortListByAssociatedKeys(list, keys)
+						sorted, err := sortListByAssociatedKeys(&cancellationCheck{ctx: ctx}, list, keys)
 						if err != nil {
 							return types.WrapErr(err)
 						}
 
 						return sorted
-					},
-					// List traits
-					traits.ListerType,
-				),
-			)...,
+					}),
+				)
+			})...,
 		))
 
 		opts = append(opts, cel.Function("lists.range",
@@ -441,7 +430,7 @@ func slice(list traits.Lister, start, end types.Int) (ref.Val, error) {
 	return types.DefaultTypeAdapter.NativeToValue(newList), nil
 }
 
-func flatten(list traits.Lister, depth int64) ([]ref.Val, error) {
+func flatten(check *cancellationCheck, list traits.Lister, depth int64) ([]ref.Val, error) {
 	if depth < 0 {
 		return nil, fmt.Errorf("level must be non-negative")
 	}
@@ -450,6 +439,9 @@ func flatten(list traits.Lister, depth int64) ([]ref.Val, error) {
 	iter := list.Iterator()
 
 	for iter.HasNext() == types.True {
+		if err := check.err(); err != nil {
+			return nil, err
+		}
 		val := iter.Next()
 		nestedList, isList := val.(traits.Lister)
 
@@ -457,7 +449,7 @@ func flatten(list traits.Lister, depth int64) ([]ref.Val, error) {
 			newList = append(newList, val)
 			continue
 		} else {
-			flattenedList, err := flatten(nestedList, depth-1)
+			flattenedList, err := flatten(check, nestedList, depth-1)
 			if err != nil {
 				return nil, err
 			}
@@ -469,8 +461,8 @@ func flatten(list traits.Lister, depth int64) ([]ref.Val, error) {
 	return newList, nil
 }
 
-func sortList(list traits.Lister) (ref.Val, error) {
-	return sortListByAssociatedKeys(list, list)
+func sortList(check *cancellationCheck, list traits.Lister) (ref.Val, error) {
+	return sortListByAssociatedKeys(check, list, list)
 }
 
 // Internal function used for the implementation of sort() and sortBy().
@@ -485,7 +477,7 @@ func sortList(list traits.Lister) (ref.Val, error) {
 // Example:
 //
 //	["foo", "bar", "baz"].@sortByAssociatedKeys([3, 1, 2]) // return ["bar", "baz", "foo"]
-func sortListByAssociatedKeys(list, keys traits.Lister) (ref.Val, error) {
+func sortListByAssociatedKeys(check *cancellationCheck, list, keys traits.Lister) (ref.Val, error) {
 	listLength := list.Size().(types.Int)
 	keysLength := keys.Size().(types.Int)
 	if listLength != keysLength {
@@ -510,6 +502,12 @@ func sortListByAssociatedKeys(list, keys traits.Lister) (ref.Val, error) {
 
 	var err error
 	sort.Slice(sortedIndices, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		if err = check.err(); err != nil {
+			return false
+		}
 		iKey := keys.Get(sortedIndices[i])
 		jKey := keys.Get(sortedIndices[j])
 		if iKey.Type() != elem.Type() || jKey.Type() != elem.Type() {
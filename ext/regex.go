@@ -15,6 +15,7 @@
 package ext
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -146,13 +147,13 @@ func (r *regexLib) CompileOptions() []cel.EnvOption {
 
 		cel.Function(regexExtractAll,
 			cel.Overload("regex_extractAll_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.ListType(cel.StringType),
-				cel.BinaryBinding(extractAll))),
+				cel.ContextBinaryBinding(extractAll))),
 
 		cel.Function(regexReplace,
 			cel.Overload("regex_replace_string_string_string", []*cel.Type{cel.StringType, cel.StringType, cel.StringType}, cel.StringType,
-				cel.FunctionBinding(regReplace)),
+				cel.ContextFunctionBinding(regReplace)),
 			cel.Overload("regex_replace_string_string_string_int", []*cel.Type{cel.StringType, cel.StringType, cel.StringType, cel.IntType}, cel.StringType,
-				cel.FunctionBinding((regReplaceN))),
+				cel.ContextFunctionBinding(regReplaceN)),
 		),
 		cel.CostEstimatorOptions(
 			checker.OverloadCostEstimate("regex_extract_string_string", estimateExtractCost()),
@@ -177,15 +178,15 @@ func (r *regexLib) ProgramOptions() []cel.ProgramOption {
 	}
 }
 
-func regReplace(args ...ref.Val) ref.Val {
+func regReplace(ctx context.Context, args ...ref.Val) ref.Val {
 	target := args[0].(types.String)
 	regexStr := args[1].(types.String)
 	replaceStr := args[2].(types.String)
 
-	return regReplaceN(target, regexStr, replaceStr, types.Int(-1))
+	return regReplaceN(ctx, target, regexStr, replaceStr, types.Int(-1))
 }
 
-func regReplaceN(args ...ref.Val) ref.Val {
+func regReplaceN(ctx context.Context, args ...ref.Val) ref.Val {
 	target := string(args[0].(types.String))
 	regexStr := string(args[1].(types.String))
 	replaceStr := string(args[2].(types.String))
@@ -211,10 +212,14 @@ func regReplaceN(args ...ref.Val) ref.Val {
 
 	matches := re.FindAllStringSubmatchIndex(target, -1)
 
+	check := &cancellationCheck{ctx: ctx}
 	for _, match := range matches {
 		if replaceCount != -1 && counter >= replaceCount {
 			break
 		}
+		if err := check.err(); err != nil {
+			return types.WrapErr(err)
+		}
 
 		processedReplacement, err := replaceStrValidator(target, re, match, replaceStr)
 		if err != nil {
@@ -301,7 +306,7 @@ func extract(target, regexStr ref.Val) ref.Val {
 	return types.OptionalOf(types.String(matches[0]))
 }
 
-func extractAll(target, regexStr ref.Val) ref.Val {
+func extractAll(ctx context.Context, target, regexStr ref.Val) ref.Val {
 	t := string(target.(types.String))
 	r := string(regexStr.(types.String))
 	re, err := regexp.Compile(r)
@@ -320,14 +325,21 @@ func extractAll(target, regexStr ref.Val) ref.Val {
 		return types.NewStringList(types.DefaultTypeAdapter, result)
 	}
 
+	check := &cancellationCheck{ctx: ctx}
 	if groupCount != 1 {
 		for _, match := range matches {
+			if err := check.err(); err != nil {
+				return types.WrapErr(err)
+			}
 			result = append(result, match[0])
 		}
 		return types.NewStringList(types.DefaultTypeAdapter, result)
 	}
 
 	for _, match := range matches {
+		if err := check.err(); err != nil {
+			return types.WrapErr(err)
+		}
 		if match[1] != "" {
 			result = append(result, match[1])
 		}
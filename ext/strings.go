@@ -18,6 +18,7 @@
 package ext
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"reflect"
@@ -515,18 +516,18 @@ func (lib *stringLib) CompileOptions() []cel.EnvOption {
 		if lib.version >= 4 {
 			opts = append(opts, cel.Function("format",
 				cel.MemberOverload("string_format", []*cel.Type{cel.StringType, cel.ListType(cel.DynType)}, cel.StringType,
-					cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					cel.ContextFunctionBinding(func(ctx context.Context, args ...ref.Val) ref.Val {
 						s := string(args[0].(types.String))
 						formatArgs := args[1].(traits.Lister)
-						return stringOrError(parseFormatStringV2(s, &stringFormatterV2{}, &stringArgList{formatArgs}, maxPrecision))
+						return stringOrError(parseFormatStringV2(s, &stringFormatterV2{}, &stringArgList{ctx: ctx, args: formatArgs}, maxPrecision))
 					}))))
 		} else {
 			opts = append(opts, cel.Function("format",
 				cel.MemberOverload("string_format", []*cel.Type{cel.StringType, cel.ListType(cel.DynType)}, cel.StringType,
-					cel.FunctionBinding(func(args ...ref.Val) ref.Val {
+					cel.ContextFunctionBinding(func(ctx context.Context, args ...ref.Val) ref.Val {
 						s := string(args[0].(types.String))
 						formatArgs := args[1].(traits.Lister)
-						return stringOrError(parseFormatString(s, &stringFormatter{}, &stringArgList{formatArgs}, formatLocale, maxPrecision))
+						return stringOrError(parseFormatString(s, &stringFormatter{}, &stringArgList{ctx: ctx, args: formatArgs}, formatLocale, maxPrecision))
 					}))))
 		}
 		opts = append(opts,
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
 )
 
@@ -34,6 +35,26 @@ type Activation interface {
 	Parent() Activation
 }
 
+// ErrorResolver is an optional interface implemented by Activations which are able to report why
+// the value of a variable could not be produced, for example when a lazily fetched value fails to
+// load or decode.
+type ErrorResolver interface {
+	// ResolveNameWithError returns a value from the activation by qualified name, false if the
+	// name could not be found, or an error if the name was found but its value could not be
+	// produced.
+	ResolveNameWithError(name string) (any, bool, error)
+}
+
+// ResolveNameWithError resolves a name using the ErrorResolver interface when implemented by the
+// Activation, and with the Activation.ResolveName method otherwise.
+func ResolveNameWithError(vars Activation, name string) (any, bool, error) {
+	if er, ok := vars.(ErrorResolver); ok {
+		return er.ResolveNameWithError(name)
+	}
+	obj, found := vars.ResolveName(name)
+	return obj, found, nil
+}
+
 // EmptyActivation returns a variable-free activation.
 func EmptyActivation() Activation {
 	return emptyActivation{}
@@ -50,11 +71,14 @@ func (emptyActivation) Parent() Activation             { return nil }
 //
 // The input `bindings` may either be of type `Activation` or `map[string]any`.
 //
-// Lazy bindings may be supplied within the map-based input in either of the following forms:
+// Lazy bindings may be supplied within the map-based input in any of the following forms:
 // - func() any
 // - func() ref.Val
+// - func() (any, error)
 //
-// The output of the lazy binding will overwrite the variable reference in the internal map.
+// The output of the lazy binding will overwrite the variable reference in the internal map. An
+// error returned by a lazy binding is reported as the result of the variable resolution, and the
+// binding is not overwritten.
 //
 // Values which are not represented as ref.Val types on input may be adapted to a ref.Val using
 // the types.Adapter configured in the environment.
@@ -90,9 +114,18 @@ func (a *mapActivation) Parent() Activation {
 
 // ResolveName implements the Activation interface method.
 func (a *mapActivation) ResolveName(name string) (any, bool) {
+	obj, found, err := a.ResolveNameWithError(name)
+	if err != nil {
+		return types.WrapErr(err), true
+	}
+	return obj, found
+}
+
+// ResolveNameWithError implements the ErrorResolver interface method.
+func (a *mapActivation) ResolveNameWithError(name string) (any, bool, error) {
 	obj, found := a.bindings[name]
 	if !found {
-		return nil, false
+		return nil, false, nil
 	}
 	fn, isLazy := obj.(func() ref.Val)
 	if isLazy {
@@ -104,7 +137,16 @@ func (a *mapActivation) ResolveName(name string) (any, bool) {
 		obj = fnRaw()
 		a.bindings[name] = obj
 	}
-	return obj, found
+	fnErr, isLazy := obj.(func() (any, error))
+	if isLazy {
+		val, err := fnErr()
+		if err != nil {
+			return nil, true, err
+		}
+		obj = val
+		a.bindings[name] = obj
+	}
+	return obj, found, nil
 }
 
 // hierarchicalActivation which implements Activation and contains a parent and
@@ -127,6 +169,14 @@ func (a *hierarchicalActivation) ResolveName(name string) (any, bool) {
 	return a.parent.ResolveName(name)
 }
 
+// ResolveNameWithError implements the ErrorResolver interface method.
+func (a *hierarchicalActivation) ResolveNameWithError(name string) (any, bool, error) {
+	if object, found, err := ResolveNameWithError(a.child, name); found || err != nil {
+		return object, found, err
+	}
+	return ResolveNameWithError(a.parent, name)
+}
+
 // NewHierarchicalActivation takes two activations and produces a new one which prioritizes
 // resolution in the child first and parent(s) second.
 func NewHierarchicalActivation(parent Activation, child Activation) Activation {
@@ -173,6 +223,11 @@ func (a *partActivation) UnknownAttributePatterns() []*AttributePattern {
 	return a.unknowns
 }
 
+// ResolveNameWithError implements the ErrorResolver interface method.
+func (a *partActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(a.Activation, name)
+}
+
 // AsPartialActivation returns the partActivation as a PartialActivation interface.
 func (a *partActivation) AsPartialActivation() (PartialActivation, bool) {
 	return a, true
@@ -15,6 +15,7 @@
 package interpreter
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -82,6 +83,46 @@ func TestActivation_ResolveLazyAny(t *testing.T) {
 	}
 }
 
+func TestActivation_ResolveNameWithError(t *testing.T) {
+	calls := 0
+	fetch := func() (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("fetch failed")
+		}
+		return "fetched", nil
+	}
+	a, _ := NewActivation(map[string]any{"lazy": fetch, "b": types.True})
+	combined := NewHierarchicalActivation(a, EmptyActivation())
+	if _, found, err := ResolveNameWithError(combined, "lazy"); !found || err == nil || err.Error() != "fetch failed" {
+		t.Errorf("ResolveNameWithError('lazy') got found=%t, err=%v, wanted fetch failed", found, err)
+	}
+	// Failed lazy bindings are retried.
+	if val, found, err := ResolveNameWithError(combined, "lazy"); !found || err != nil || val != "fetched" {
+		t.Errorf("ResolveNameWithError('lazy') got %v, %t, %v, wanted 'fetched'", val, found, err)
+	}
+	if val, found, err := ResolveNameWithError(combined, "b"); !found || err != nil || val != types.True {
+		t.Errorf("ResolveNameWithError('b') got %v, %t, %v, wanted true", val, found, err)
+	}
+	if _, found, err := ResolveNameWithError(combined, "c"); found || err != nil {
+		t.Errorf("ResolveNameWithError('c') got found=%t, err=%v, wanted not found", found, err)
+	}
+	// Activations which do not implement the ErrorResolver report no error.
+	if val, found, err := ResolveNameWithError(EmptyActivation(), "b"); val != nil || found || err != nil {
+		t.Errorf("ResolveNameWithError(EmptyActivation()) got %v, %t, %v, wanted not found", val, found, err)
+	}
+}
+
+func TestActivation_ResolveLazyError(t *testing.T) {
+	a, _ := NewActivation(map[string]any{
+		"lazy": func() (any, error) { return nil, errors.New("fetch failed") },
+	})
+	val, found := a.ResolveName("lazy")
+	if !found || !types.IsError(val.(ref.Val)) {
+		t.Errorf("ResolveName('lazy') got %v, %t, wanted error", val, found)
+	}
+}
+
 func TestHierarchicalActivation(t *testing.T) {
 	// compose a parent with more properties than the child
 	parent, _ := NewActivation(map[string]any{
@@ -16,6 +16,7 @@ package interpreter
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/cel-go/common/containers"
@@ -95,6 +96,11 @@ func (apat *AttributePattern) Wildcard() *AttributePattern {
 	return apat
 }
 
+// Variable returns the fully qualified variable name of the AttributePattern.
+func (apat *AttributePattern) Variable() string {
+	return apat.variable
+}
+
 // VariableMatches returns true if the fully qualified variable matches the AttributePattern
 // fully qualified variable name.
 func (apat *AttributePattern) VariableMatches(variable string) bool {
@@ -106,12 +112,65 @@ func (apat *AttributePattern) QualifierPatterns() []*AttributeQualifierPattern {
 	return apat.qualifierPatterns
 }
 
+// String renders the AttributePattern in the notation of the AttributePattern examples, e.g.
+// `ns.myvar["complex-value"].*.name`.
+func (apat *AttributePattern) String() string {
+	var str strings.Builder
+	str.WriteString(apat.variable)
+	for _, qual := range apat.qualifierPatterns {
+		str.WriteString(qual.String())
+	}
+	return str.String()
+}
+
 // AttributeQualifierPattern holds a wildcard or valued qualifier pattern.
 type AttributeQualifierPattern struct {
 	wildcard bool
 	value    any
 }
 
+// IsWildcard returns whether the qualifier pattern matches any qualifier.
+func (qpat *AttributeQualifierPattern) IsWildcard() bool {
+	return qpat.wildcard
+}
+
+// Value returns the string, int64, uint64, or bool value of the qualifier pattern, or nil if the
+// pattern is a wildcard.
+func (qpat *AttributeQualifierPattern) Value() any {
+	return qpat.value
+}
+
+// String renders the qualifier pattern as a field selection when the qualifier is a wildcard or a
+// string which is a valid identifier, and as an index otherwise.
+func (qpat *AttributeQualifierPattern) String() string {
+	if qpat.wildcard {
+		return ".*"
+	}
+	switch v := qpat.value.(type) {
+	case string:
+		if isIdentifier(v) {
+			return "." + v
+		}
+		return "[" + strconv.Quote(v) + "]"
+	case uint64:
+		return fmt.Sprintf("[%du]", v)
+	}
+	return fmt.Sprintf("[%v]", qpat.value)
+}
+
+// isIdentifier returns whether the string may be used as a field name within a select expression.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && (i == 0 || !(r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
 // Matches returns true if the qualifier pattern is a wildcard, or the Qualifier implements the
 // qualifierValueEquator interface and its IsValueEqualTo returns true for the qualifier pattern.
 func (qpat *AttributeQualifierPattern) Matches(q Qualifier) bool {
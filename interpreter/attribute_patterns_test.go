@@ -336,3 +336,24 @@ func genAttr(fac AttributeFactory, a attr) Attribute {
 	}
 	return attr
 }
+
+func TestAttributePatternString(t *testing.T) {
+	tests := []struct {
+		pattern *AttributePattern
+		out     string
+	}{
+		{pattern: NewAttributePattern("a"), out: "a"},
+		{pattern: NewAttributePattern("ns.a").QualString("b").Wildcard().QualString("name"), out: "ns.a.b.*.name"},
+		{pattern: NewAttributePattern("a").QualString("complex-value").QualInt(-1), out: `a["complex-value"][-1]`},
+		{pattern: NewAttributePattern("a").QualUint(2).QualBool(true).QualString(""), out: `a[2u][true][""]`},
+	}
+	for _, tc := range tests {
+		if got := tc.pattern.String(); got != tc.out {
+			t.Errorf("String() got %q, wanted %q", got, tc.out)
+		}
+	}
+	qual := NewAttributePattern("a").Wildcard().QualString("b").QualifierPatterns()
+	if !qual[0].IsWildcard() || qual[0].Value() != nil || qual[1].IsWildcard() || qual[1].Value() != "b" {
+		t.Errorf("QualifierPatterns() got %v, %v, wanted wildcard and 'b'", qual[0], qual[1])
+	}
+}
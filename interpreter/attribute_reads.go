@@ -0,0 +1,297 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// blockFunction is the name of the function whose list of slot expressions is evaluated lazily
+// when referenced from the result expression.
+const blockFunction = "cel.@block"
+
+// blockSlotPrefix is the prefix of the identifiers which refer to the slots of a cel.@block call.
+const blockSlotPrefix = "@index"
+
+// AttributeRead describes an attribute which an expression may read during evaluation.
+type AttributeRead struct {
+	// Pattern identifies the variable and the chain of qualifiers applied to it, with wildcards for
+	// qualifiers which are only known during evaluation.
+	Pattern *AttributePattern
+
+	// MustRead indicates whether the attribute is read by every evaluation of the expression. An
+	// attribute which is not a must-read is only read on some evaluation paths, such as within
+	// a conditional branch, on the right-hand side of a logical operator, or within the loop step
+	// of a comprehension.
+	MustRead bool
+}
+
+// AttributeReads returns the attributes which the expression may read during evaluation, sorted by
+// the string form of their patterns.
+//
+// Each attribute is reported using the longest chain of field selections and indices applied to
+// the variable. Indices computed during evaluation are reported as wildcards, and the expressions
+// which compute them are analyzed in turn. Variable names are resolved using the reference map of
+// checked expressions, and comprehension variables are not reported. The slots of a cel.@block
+// call are treated as local variables whose expressions are read where the slot is referenced.
+//
+// The patterns may be supplied to NewPartialActivation in order to treat the attributes as unknown.
+func AttributeReads(a *ast.AST) []*AttributeRead {
+	b := &attributeReadsBuilder{
+		refMap: a.ReferenceMap(),
+		locals: map[string]int{},
+		reads:  map[string]*AttributeRead{},
+	}
+	b.visit(a.Expr(), true)
+	reads := make([]*AttributeRead, 0, len(b.reads))
+	for _, read := range b.reads {
+		reads = append(reads, read)
+	}
+	sort.Slice(reads, func(i, j int) bool {
+		return reads[i].Pattern.String() < reads[j].Pattern.String()
+	})
+	return reads
+}
+
+type attributeReadsBuilder struct {
+	refMap map[int64]*ast.ReferenceInfo
+	locals map[string]int
+	reads  map[string]*AttributeRead
+	block  *blockSlots
+}
+
+// blockSlots describes the slots of the enclosing cel.@block call.
+type blockSlots struct {
+	exprs []ast.Expr
+	// locals holds the comprehension variables in scope of the slot expressions.
+	locals map[string]int
+	// visited records the slots whose expressions have been visited, and whether they were
+	// visited as must-reads.
+	visited map[int]bool
+}
+
+// visit records the attributes read by the expression, where must indicates whether the expression
+// is evaluated by every evaluation of the overall expression.
+func (b *attributeReadsBuilder) visit(e ast.Expr, must bool) {
+	if ref, found := b.refMap[e.ID()]; found && ref.Value != nil {
+		// Constants such as enum values and type names are not attributes.
+		return
+	}
+	if pat, ok := b.attributePattern(e, must); ok {
+		b.record(pat, must)
+		return
+	}
+	switch e.Kind() {
+	case ast.SelectKind:
+		b.visit(e.AsSelect().Operand(), must)
+	case ast.CallKind:
+		b.visitCall(e, must)
+	case ast.ListKind:
+		for _, elem := range e.AsList().Elements() {
+			b.visit(elem, must)
+		}
+	case ast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			me := entry.AsMapEntry()
+			b.visit(me.Key(), must)
+			b.visit(me.Value(), must)
+		}
+	case ast.StructKind:
+		for _, field := range e.AsStruct().Fields() {
+			b.visit(field.AsStructField().Value(), must)
+		}
+	case ast.ComprehensionKind:
+		compre := e.AsComprehension()
+		b.visit(compre.IterRange(), must)
+		b.visit(compre.AccuInit(), must)
+		b.pushLocals(compre.AccuVar(), compre.IterVar(), compre.IterVar2())
+		// The loop condition and step are not evaluated when the range is empty.
+		b.visit(compre.LoopCondition(), false)
+		b.visit(compre.LoopStep(), false)
+		b.popLocals(compre.IterVar(), compre.IterVar2())
+		b.visit(compre.Result(), must)
+		b.popLocals(compre.AccuVar())
+	}
+}
+
+// visitCall records the attributes read by the target and arguments of a call, taking into account
+// the functions which do not evaluate all of their arguments.
+func (b *attributeReadsBuilder) visitCall(e ast.Expr, must bool) {
+	call := e.AsCall()
+	args := call.Args()
+	if call.IsMemberFunction() {
+		b.visit(call.Target(), must)
+		// The alternative of optional.or() and optional.orValue() is only evaluated when the
+		// target optional is empty.
+		if (call.FunctionName() == "or" || call.FunctionName() == "orValue") && len(args) == 1 {
+			b.visit(args[0], false)
+			return
+		}
+	}
+	switch call.FunctionName() {
+	case operators.LogicalAnd, operators.LogicalOr:
+		for i, arg := range args {
+			b.visit(arg, must && i == 0)
+		}
+	case operators.Conditional:
+		b.visit(args[0], must)
+		b.visit(args[1], false)
+		b.visit(args[2], false)
+	case blockFunction:
+		if len(args) == 2 && args[0].Kind() == ast.ListKind {
+			// The slot expressions are visited when the slots are referenced.
+			outer := b.block
+			b.block = &blockSlots{
+				exprs:   args[0].AsList().Elements(),
+				locals:  maps.Clone(b.locals),
+				visited: map[int]bool{},
+			}
+			b.visit(args[1], must)
+			b.block = outer
+			return
+		}
+		fallthrough
+	default:
+		for _, arg := range args {
+			b.visit(arg, must)
+		}
+	}
+}
+
+// attributePattern returns the attribute pattern of an expression which consists of a variable
+// followed by a chain of field selections and indices, recording the attributes read by any
+// computed indices.
+func (b *attributeReadsBuilder) attributePattern(e ast.Expr, must bool) (*AttributePattern, bool) {
+	ref, hasRef := b.refMap[e.ID()]
+	switch e.Kind() {
+	case ast.IdentKind:
+		name := e.AsIdent()
+		if b.locals[name] > 0 {
+			return nil, false
+		}
+		if b.visitSlot(name, must) {
+			return nil, false
+		}
+		if hasRef {
+			name = ref.Name
+		}
+		return NewAttributePattern(name), true
+	case ast.SelectKind:
+		// Qualified variable names are resolved to a reference on the outermost select expression.
+		if hasRef && ref.Value == nil {
+			return NewAttributePattern(ref.Name), true
+		}
+		sel := e.AsSelect()
+		pat, ok := b.attributePattern(sel.Operand(), must)
+		if !ok {
+			return nil, false
+		}
+		return pat.QualString(sel.FieldName()), true
+	case ast.CallKind:
+		call := e.AsCall()
+		args := call.Args()
+		if call.IsMemberFunction() || len(args) != 2 {
+			return nil, false
+		}
+		switch call.FunctionName() {
+		case operators.OptSelect:
+			pat, ok := b.attributePattern(args[0], must)
+			if !ok || args[1].Kind() != ast.LiteralKind {
+				return nil, false
+			}
+			field, isString := args[1].AsLiteral().(types.String)
+			if !isString {
+				return nil, false
+			}
+			return pat.QualString(string(field)), true
+		case operators.Index, operators.OptIndex:
+			pat, ok := b.attributePattern(args[0], must)
+			if !ok {
+				return nil, false
+			}
+			if args[1].Kind() == ast.LiteralKind {
+				switch key := args[1].AsLiteral().(type) {
+				case types.String:
+					return pat.QualString(string(key)), true
+				case types.Int:
+					return pat.QualInt(int64(key)), true
+				case types.Uint:
+					return pat.QualUint(uint64(key)), true
+				case types.Bool:
+					return pat.QualBool(bool(key)), true
+				}
+			}
+			b.visit(args[1], must)
+			return pat.Wildcard(), true
+		}
+	}
+	return nil, false
+}
+
+// visitSlot records the attributes read by the expression of the cel.@block slot with the given
+// name, if any, where must indicates whether the slot reference is evaluated by every evaluation
+// of the overall expression.
+func (b *attributeReadsBuilder) visitSlot(name string, must bool) bool {
+	if b.block == nil || !strings.HasPrefix(name, blockSlotPrefix) {
+		return false
+	}
+	idx, err := strconv.Atoi(name[len(blockSlotPrefix):])
+	if err != nil || idx < 0 || idx >= len(b.block.exprs) {
+		return false
+	}
+	// A slot expression only needs to be visited again to upgrade its reads to must-reads.
+	if visitedMust, found := b.block.visited[idx]; found && (visitedMust || !must) {
+		return true
+	}
+	b.block.visited[idx] = must
+	locals := b.locals
+	b.locals = b.block.locals
+	b.visit(b.block.exprs[idx], must)
+	b.locals = locals
+	return true
+}
+
+// record adds the attribute to the read set, upgrading an existing read to a must-read as needed.
+func (b *attributeReadsBuilder) record(pat *AttributePattern, must bool) {
+	key := pat.String()
+	if read, found := b.reads[key]; found {
+		read.MustRead = read.MustRead || must
+		return
+	}
+	b.reads[key] = &AttributeRead{Pattern: pat, MustRead: must}
+}
+
+func (b *attributeReadsBuilder) pushLocals(names ...string) {
+	for _, name := range names {
+		if name != "" {
+			b.locals[name]++
+		}
+	}
+}
+
+func (b *attributeReadsBuilder) popLocals(names ...string) {
+	for _, name := range names {
+		if name != "" {
+			b.locals[name]--
+		}
+	}
+}
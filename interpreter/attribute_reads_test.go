@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/ast"
+)
+
+func TestAttributeReads(t *testing.T) {
+	tests := []struct {
+		expr  string
+		reads []string
+	}{
+		{
+			expr:  `request.user.groups.exists(g, g == 'admin')`,
+			reads: []string{"request.user.groups (must)"},
+		},
+		{
+			expr: `resource.labels['env'] == 'prod' && resource.labels['tier-1'] == 'web'`,
+			reads: []string{
+				`resource.labels.env (must)`,
+				`resource.labels["tier-1"] (may)`,
+			},
+		},
+		{
+			expr: `a[0][1u][true].b[x.y].c`,
+			reads: []string{
+				"a[0][1u][true].b.*.c (must)",
+				"x.y (must)",
+			},
+		},
+		{
+			expr: `cond ? x.a : y.b`,
+			reads: []string{
+				"cond (must)",
+				"x.a (may)",
+				"y.b (may)",
+			},
+		},
+		{
+			// A read on any path which is always evaluated is a must-read.
+			expr: `y.b > 0 ? y.b : x.a`,
+			reads: []string{
+				"x.a (may)",
+				"y.b (must)",
+			},
+		},
+		{
+			expr: `items.map(i, i.price * rate).filter(p, p > limit.value).size() + size(items)`,
+			reads: []string{
+				"items (must)",
+				"limit.value (may)",
+				"rate (may)",
+			},
+		},
+		{
+			expr: `has(req.auth.claims) && size(f(req).name) > 0`,
+			reads: []string{
+				"req (may)",
+				"req.auth.claims (must)",
+			},
+		},
+		{
+			expr:  `[1, 2, 3][idx]`,
+			reads: []string{"idx (must)"},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			var got []string
+			for _, read := range AttributeReads(mustParseWithMacros(t, tc.expr)) {
+				kind := "may"
+				if read.MustRead {
+					kind = "must"
+				}
+				got = append(got, read.Pattern.String()+" ("+kind+")")
+			}
+			if !reflect.DeepEqual(got, tc.reads) {
+				t.Errorf("AttributeReads() got %v, wanted %v", got, tc.reads)
+			}
+		})
+	}
+}
+
+func TestAttributeReadsBlock(t *testing.T) {
+	tests := []struct {
+		slots  string
+		result string
+		reads  []string
+	}{
+		{
+			slots:  `a.b`,
+			result: `@index0 > 1`,
+			reads:  []string{"a.b (must)"},
+		},
+		{
+			// Slots which are never referenced are not evaluated.
+			slots:  `a.b, c.d`,
+			result: `@index1.e == 1`,
+			reads:  []string{"c.d (must)"},
+		},
+		{
+			slots:  `a.b, c.d`,
+			result: `x ? @index0 : @index1.e`,
+			reads: []string{
+				"a.b (may)",
+				"c.d (may)",
+				"x (must)",
+			},
+		},
+		{
+			// A slot referenced from a later slot is read where the later slot is referenced.
+			slots:  `a.b, @index0.c + d`,
+			result: `x || @index1 > 0 || @index0 > 1`,
+			reads: []string{
+				"a.b (may)",
+				"d (may)",
+				"x (must)",
+			},
+		},
+		{
+			slots:  `a.b, @index0.c + d`,
+			result: `@index1 > 0 && @index0 > 1`,
+			reads: []string{
+				"a.b (must)",
+				"d (must)",
+			},
+		},
+		{
+			// Slot expressions are not within the scope of comprehension variables at the point of
+			// reference.
+			slots:  `x`,
+			result: `[1].all(x, x > @index0)`,
+			reads:  []string{"x (may)"},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.result, func(t *testing.T) {
+			var got []string
+			for _, read := range AttributeReads(mustParseBlock(t, tc.slots, tc.result)) {
+				kind := "may"
+				if read.MustRead {
+					kind = "must"
+				}
+				got = append(got, read.Pattern.String()+" ("+kind+")")
+			}
+			if !reflect.DeepEqual(got, tc.reads) {
+				t.Errorf("AttributeReads() got %v, wanted %v", got, tc.reads)
+			}
+		})
+	}
+}
+
+// mustParseBlock returns a cel.@block call with the given slot expressions and result, in which the
+// slots are referenced as @index0, @index1, and so on. Blocks are produced by optimizers, and the
+// parser does not accept the @ prefix, so the slot references are renamed after parsing.
+func mustParseBlock(t *testing.T, slots, result string) *ast.AST {
+	t.Helper()
+	expr := strings.ReplaceAll("[["+slots+"], "+result+"]", "@index", "index_")
+	parsed := mustParseWithMacros(t, expr)
+	fac := ast.NewExprFactory()
+	ast.PostOrderVisit(parsed.Expr(), ast.NewExprVisitor(func(e ast.Expr) {
+		if e.Kind() == ast.IdentKind && strings.HasPrefix(e.AsIdent(), "index_") {
+			e.SetKindCase(fac.NewIdent(e.ID(), blockSlotPrefix+strings.TrimPrefix(e.AsIdent(), "index_")))
+		}
+	}))
+	args := parsed.Expr().AsList().Elements()
+	return ast.NewAST(fac.NewCall(parsed.Expr().ID(), blockFunction, args...), parsed.SourceInfo())
+}
@@ -138,6 +138,17 @@ func EnableErrorOnBadPresenceTest(value bool) AttrFactoryOption {
 	}
 }
 
+// EnableSessionAttributes memoizes the values of attributes with constant qualifiers in the
+// EvalSession, if any, supplied as the evaluation input.
+//
+// Attributes only search the Activation hierarchy for an EvalSession when enabled.
+func EnableSessionAttributes(value bool) AttrFactoryOption {
+	return func(fac *attrFactory) *attrFactory {
+		fac.sessionAttributes = value
+		return fac
+	}
+}
+
 // NewAttributeFactory returns a default AttributeFactory which is produces Attribute values
 // capable of resolving types by simple names and qualify the values using the supported qualifier
 // types: bool, int, string, and uint.
@@ -159,6 +170,7 @@ type attrFactory struct {
 	provider  types.Provider
 
 	errorOnBadPresenceTest bool
+	sessionAttributes      bool
 }
 
 // AbsoluteAttribute refers to a variable value and an optional qualifier path.
@@ -182,6 +194,7 @@ func (r *attrFactory) AbsoluteAttribute(id int64, names ...string) NamespacedAtt
 		provider:               r.provider,
 		fac:                    r,
 		errorOnBadPresenceTest: r.errorOnBadPresenceTest,
+		sessionAttributes:      r.sessionAttributes,
 	}
 }
 
@@ -268,6 +281,7 @@ type absoluteAttribute struct {
 	fac        AttributeFactory
 
 	errorOnBadPresenceTest bool
+	sessionAttributes      bool
 }
 
 // ID implements the Attribute interface method.
@@ -312,6 +326,37 @@ func (a *absoluteAttribute) QualifyIfPresent(vars Activation, obj any, presenceO
 	return attrQualifyIfPresent(a.fac, vars, obj, a, presenceOnly)
 }
 
+// applyQualifiers applies the attribute qualifiers to the value of the named variable, memoizing
+// the result when session attributes are enabled and the variable was resolved from an EvalSession.
+func (a *absoluteAttribute) applyQualifiers(vars Activation, name string, obj any) (any, bool, error) {
+	if a.sessionAttributes && len(a.qualifiers) != 0 {
+		if sess, found := asEvalSession(vars); found {
+			return sess.qualify(vars, name, obj, a.qualifiers)
+		}
+	}
+	return applyQualifiers(vars, obj, a.qualifiers)
+}
+
+// qualifyVar applies the qualifiers of the attribute to the value of the variable with the given
+// name.
+func (a *absoluteAttribute) qualifyVar(vars Activation, name string, obj any) (any, error) {
+	if celErr, ok := obj.(*types.Err); ok {
+		return nil, celErr
+	}
+	obj, isOpt, err := a.applyQualifiers(vars, name, obj)
+	if err != nil {
+		return nil, err
+	}
+	if isOpt {
+		val := a.adapter.NativeToValue(obj)
+		if types.IsUnknown(val) {
+			return val, nil
+		}
+		return types.OptionalOf(val), nil
+	}
+	return obj, nil
+}
+
 // String implements the Stringer interface method.
 func (a *absoluteAttribute) String() string {
 	return fmt.Sprintf("id: %v, names: %v", a.id, a.namespaceNames)
@@ -323,6 +368,9 @@ func (a *absoluteAttribute) String() string {
 // If the variable name cannot be found as an Activation variable or in the TypeProvider as
 // a type, then the result is `nil`, `error` with the error indicating the name of the first
 // variable searched as missing.
+//
+// If the Activation implements the ErrorResolver interface and reports an error for a variable,
+// the error is returned as a `types.Err` labeled with the attribute's expression id.
 func (a *absoluteAttribute) Resolve(vars Activation) (any, error) {
 	// unwrap any local activations to ensure that we reach the variables provided as input
 	// to the expression in the event that we need to disambiguate between global and local
@@ -346,23 +394,12 @@ func (a *absoluteAttribute) Resolve(vars Activation) (any, error) {
 		if a.disambiguateNames {
 			v = inputVars
 		}
-		obj, found := v.ResolveName(nm)
+		obj, found, err := ResolveNameWithError(v, nm)
+		if err != nil {
+			return nil, types.LabelErrNode(a.id, types.WrapErr(err)).(*types.Err)
+		}
 		if found {
-			if celErr, ok := obj.(*types.Err); ok {
-				return nil, celErr
-			}
-			obj, isOpt, err := applyQualifiers(v, obj, a.qualifiers)
-			if err != nil {
-				return nil, err
-			}
-			if isOpt {
-				val := a.adapter.NativeToValue(obj)
-				if types.IsUnknown(val) {
-					return val, nil
-				}
-				return types.OptionalOf(val), nil
-			}
-			return obj, nil
+			return a.qualifyVar(v, nm, obj)
 		}
 		// Attempt to resolve the qualified type name if the name is not a variable identifier.
 		typ, found := a.provider.FindIdent(nm)
@@ -441,6 +478,11 @@ func (a *conditionalAttribute) Resolve(vars Activation) (any, error) {
 	if val == types.False {
 		return a.falsy.Resolve(vars)
 	}
+	return conditionResult(val)
+}
+
+// conditionResult returns the result of a conditional whose condition is not a boolean.
+func conditionResult(val ref.Val) (any, error) {
 	if types.IsUnknown(val) {
 		return val, nil
 	}
@@ -614,7 +656,11 @@ func (a *relativeAttribute) QualifyIfPresent(vars Activation, obj any, presenceO
 // Resolve expression value and qualifier relative to the expression result.
 func (a *relativeAttribute) Resolve(vars Activation) (any, error) {
 	// First, evaluate the operand.
-	v := a.operand.Eval(vars)
+	return a.resolveOperand(vars, a.operand.Eval(vars))
+}
+
+// resolveOperand applies the qualifiers to the evaluated operand.
+func (a *relativeAttribute) resolveOperand(vars Activation, v ref.Val) (any, error) {
 	if types.IsError(v) {
 		return nil, v.(*types.Err)
 	}
@@ -104,6 +104,38 @@ func TestAttributesAbsoluteAttrError(t *testing.T) {
 	}
 }
 
+func TestAttributesAbsoluteAttrResolveNameWithError(t *testing.T) {
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(containers.DefaultContainer, reg, reg)
+	fetchErr := errors.New("fetch failed")
+	vars, err := NewActivation(map[string]any{
+		"req": func() (any, error) { return nil, fetchErr },
+	})
+	if err != nil {
+		t.Fatalf("NewActivation() failed: %v", err)
+	}
+	tests := []struct {
+		name string
+		attr Attribute
+	}{
+		{name: "absolute", attr: attrs.AbsoluteAttribute(1, "req")},
+		{name: "maybe", attr: attrs.MaybeAttribute(1, "req")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.attr.AddQualifier(makeQualifier(t, attrs, nil, 2, "body"))
+			out, err := tc.attr.Resolve(vars)
+			if !errors.Is(err, fetchErr) {
+				t.Fatalf("attr.Resolve() got %v, %v, wanted %v", out, err, fetchErr)
+			}
+			celErr, ok := err.(*types.Err)
+			if !ok || celErr.NodeID() != 1 {
+				t.Errorf("attr.Resolve() got error %#v, wanted types.Err with node id 1", err)
+			}
+		})
+	}
+}
+
 func TestAttributesRelativeAttr(t *testing.T) {
 	reg := newTestRegistry(t)
 	attrs := NewAttributeFactory(containers.DefaultContainer, reg, reg)
@@ -0,0 +1,895 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// BytecodeEval compiles programs which contain comprehensions into a flat sequence of instructions
+// which are executed by a register-based virtual machine rather than by recursively evaluating the
+// Interpretable tree.
+//
+// The expression is planned as usual, so function resolution, attribute planning, and decorators
+// behave the same as for tree-walking evaluation, and the plan is then compiled into instructions.
+// Logical operators, conditionals, function calls, attribute resolution, list, map, and message
+// construction, and comprehensions are executed by the virtual machine. Comprehension variables
+// are read from the comprehension state rather than resolved by name, and the standard integer and
+// logical operators are applied without overload dispatch when the operands have the expected
+// types. Program steps with no instruction equivalent, such as those produced by custom decorators
+// or by exhaustive evaluation, are executed as a single instruction which evaluates the
+// Interpretable. Programs without comprehensions are not compiled, since their steps are evaluated
+// only once.
+//
+// Results, including errors and unknowns, are identical to tree-walking evaluation, and the
+// evaluation may be observed for cost tracking, state tracking, and interrupts.
+func BytecodeEval() PlannerOption {
+	return func(p *planner) (*planner, error) {
+		p.bytecode = true
+		return p, nil
+	}
+}
+
+// vmOpcode identifies the operation performed by an instruction.
+type vmOpcode uint8
+
+const (
+	// opEval evaluates an Interpretable which has no instruction equivalent.
+	opEval vmOpcode = iota
+	// opEnter notifies observers that a program step has started.
+	opEnter
+	// opObserve notifies observers of the result of a program step.
+	opObserve
+	// opJump transfers control unconditionally.
+	opJump
+	// opBail stores the operand and transfers control if it is an unknown or error.
+	opBail
+	// opLogicalInit clears the unknown and error accumulated by a logical operator.
+	opLogicalInit
+	// opAndTerm short-circuits a logical AND on a false term, or accumulates unknowns and errors.
+	opAndTerm
+	// opOrTerm short-circuits a logical OR on a true term, or accumulates unknowns and errors.
+	opOrTerm
+	// opLogicalEnd produces the result of a logical operator which did not short-circuit.
+	opLogicalEnd
+	// opEq compares two operands for equality.
+	opEq
+	// opNe compares two operands for inequality.
+	opNe
+	// opUnary invokes a function with one argument.
+	opUnary
+	// opNot negates a boolean operand, or invokes the logical NOT function.
+	opNot
+	// opNotStrictlyFalse produces a boolean operand, or invokes the not-strictly-false function.
+	opNotStrictlyFalse
+	// opBinary invokes a function with two arguments.
+	opBinary
+	// opIntBinary applies an integer operator to two integer operands, or invokes the operator
+	// function.
+	opIntBinary
+	// opVarArgs invokes a function with any number of arguments.
+	opVarArgs
+	// opSetMembership tests membership within a constant set.
+	opSetMembership
+	// opListElem validates a list element, transferring control if the list cannot be created.
+	opListElem
+	// opList creates a list from the validated elements.
+	opList
+	// opMapValue validates a map entry value, transferring control if the map cannot be created.
+	opMapValue
+	// opMap creates a map from the validated entries.
+	opMap
+	// opObjField validates a field value, transferring control if the object cannot be created.
+	opObjField
+	// opObj creates an object from the validated fields.
+	opObj
+	// opCond selects the branch of a conditional, transferring control to the falsy branch or to
+	// the end of the conditional when the condition is not a boolean.
+	opCond
+	// opResolve resolves an attribute.
+	opResolve
+	// opAccu resolves an attribute of the accumulator of an enclosing comprehension.
+	opAccu
+	// opIter resolves an attribute of the iteration variable of an enclosing comprehension.
+	opIter
+	// opRelative applies the qualifiers of a relative attribute to an evaluated operand.
+	opRelative
+	// opFoldInit begins a comprehension over an evaluated iteration range.
+	opFoldInit
+	// opFoldNext advances the iteration of a comprehension.
+	opFoldNext
+	// opFoldCond tests the loop condition of a comprehension.
+	opFoldCond
+	// opFoldStep updates the accumulator of a comprehension and checks for interrupts.
+	opFoldStep
+	// opFoldResult begins the computation of the comprehension result.
+	opFoldResult
+	// opFoldEnd completes a comprehension.
+	opFoldEnd
+	// opInterrupt checks whether the evaluation has been interrupted before a function call.
+	opInterrupt
+)
+
+var vmOpcodeNames = [...]string{
+	opEval:             "eval",
+	opEnter:            "enter",
+	opObserve:          "observe",
+	opJump:             "jump",
+	opBail:             "bail",
+	opLogicalInit:      "logical_init",
+	opAndTerm:          "and_term",
+	opOrTerm:           "or_term",
+	opLogicalEnd:       "logical_end",
+	opEq:               "eq",
+	opNe:               "ne",
+	opUnary:            "unary",
+	opNot:              "not",
+	opNotStrictlyFalse: "not_strictly_false",
+	opBinary:           "binary",
+	opIntBinary:        "int_binary",
+	opVarArgs:          "varargs",
+	opSetMembership:    "set_membership",
+	opListElem:         "list_elem",
+	opList:             "list",
+	opMapValue:         "map_value",
+	opMap:              "map",
+	opObjField:         "obj_field",
+	opObj:              "obj",
+	opCond:             "cond",
+	opResolve:          "resolve",
+	opAccu:             "accu",
+	opIter:             "iter",
+	opRelative:         "relative",
+	opFoldInit:         "fold_init",
+	opFoldNext:         "fold_next",
+	opFoldCond:         "fold_cond",
+	opFoldStep:         "fold_step",
+	opFoldResult:       "fold_result",
+	opFoldEnd:          "fold_end",
+	opInterrupt:        "interrupt",
+}
+
+// String returns the mnemonic of the opcode.
+func (op vmOpcode) String() string {
+	return vmOpcodeNames[op]
+}
+
+// vmInst is a single instruction. The operands are register indices unless noted otherwise.
+type vmInst struct {
+	op  vmOpcode
+	dst int
+	a   int
+	b   int
+	// c is an element index for constructor instructions and a comprehension slot for fold
+	// instructions.
+	c int
+	// jump is the instruction index to which control may be transferred.
+	jump int
+	args []int
+	id   int64
+	val  ref.Val
+	node any
+	// intOp is the integer operator applied by an opIntBinary instruction.
+	intOp func(types.Int, ref.Val) ref.Val
+}
+
+// vmUnaryOps maps the overloads of the standard unary operators to the instructions which evaluate
+// them without dispatch when the operand has the expected type.
+var vmUnaryOps = map[string]vmOpcode{
+	overloads.LogicalNot:       opNot,
+	overloads.NotStrictlyFalse: opNotStrictlyFalse,
+}
+
+// vmIntOps maps the overloads of the standard integer operators to the operators applied by an
+// opIntBinary instruction. The operators are applied only to integer operands, so they produce the
+// same result as the standard function bindings without checking the operand types against the
+// overload signature.
+var vmIntOps = map[string]func(types.Int, ref.Val) ref.Val{
+	overloads.AddInt64:      types.Int.Add,
+	overloads.SubtractInt64: types.Int.Subtract,
+	overloads.MultiplyInt64: types.Int.Multiply,
+	overloads.DivideInt64:   types.Int.Divide,
+	overloads.ModuloInt64:   types.Int.Modulo,
+	overloads.LessInt64: func(l types.Int, r ref.Val) ref.Val {
+		return types.Bool(l < r.(types.Int))
+	},
+	overloads.LessEqualsInt64: func(l types.Int, r ref.Val) ref.Val {
+		return types.Bool(l <= r.(types.Int))
+	},
+	overloads.GreaterInt64: func(l types.Int, r ref.Val) ref.Val {
+		return types.Bool(l > r.(types.Int))
+	},
+	overloads.GreaterEqualsInt64: func(l types.Int, r ref.Val) ref.Val {
+		return types.Bool(l >= r.(types.Int))
+	},
+}
+
+// vmStep describes an observed program step.
+type vmStep struct {
+	observers stepObservers
+	id        int64
+	step      any
+}
+
+// vmAttr describes the attribute resolved by an evalAttr.
+type vmAttr struct {
+	eval *evalAttr
+	attr Attribute
+	abs  *absoluteAttribute
+	rel  *relativeAttribute
+}
+
+// resolveLocal applies the qualifiers of the attribute to the value of a comprehension variable.
+func (va *vmAttr) resolveLocal(vars Activation, name string, val ref.Val) ref.Val {
+	if len(va.abs.qualifiers) == 0 {
+		if _, isErr := val.(*types.Err); !isErr {
+			return val
+		}
+	}
+	return va.eval.result(va.abs.qualifyVar(vars, name, val))
+}
+
+// vmScope describes a comprehension whose variables are visible to the instructions being
+// compiled.
+type vmScope struct {
+	fold *evalFold
+	slot int
+	// result is set while the result of the comprehension is compiled, after which the iteration
+	// variable is no longer visible.
+	result bool
+}
+
+// vmCompiler compiles an Interpretable plan into instructions.
+//
+// Registers are allocated as a stack: the registers used to compute an operand are released once
+// the instruction which consumes the operand has been emitted, so the number of registers is
+// bounded by the depth of the plan rather than by its size. Constants occupy the first registers
+// and are loaded once per evaluation rather than by an instruction. References to comprehension
+// variables are read from the comprehension slots rather than resolved through the activation.
+type vmCompiler struct {
+	code      []vmInst
+	consts    []ref.Val
+	constRegs map[ref.Val]int
+	scopes    []vmScope
+	top       int
+	numRegs   int
+	numFolds  int
+}
+
+// compileBytecode compiles the planned Interpretable into a vmProgram, or returns the Interpretable
+// unchanged if the program contains no comprehension which the virtual machine executes. Outside of
+// comprehensions, each program step is evaluated once, so executing it as an instruction saves
+// nothing over evaluating the Interpretable.
+func compileBytecode(i Interpretable) Interpretable {
+	prg := newVMProgram(i)
+	if prg.numFolds == 0 {
+		return i
+	}
+	return prg
+}
+
+// newVMProgram compiles the planned Interpretable into a vmProgram.
+func newVMProgram(i Interpretable) *vmProgram {
+	// The constants are counted by a first compilation so that the registers which hold them
+	// precede the registers allocated for intermediate results.
+	count := &vmCompiler{}
+	count.compile(i)
+	numConsts := len(count.consts)
+	c := &vmCompiler{consts: make([]ref.Val, 0, numConsts), top: numConsts, numRegs: numConsts}
+	result := c.compile(i)
+	return &vmProgram{
+		id:       i.ID(),
+		code:     c.code,
+		consts:   c.consts,
+		result:   result,
+		numRegs:  c.numRegs,
+		numFolds: c.numFolds,
+	}
+}
+
+func (c *vmCompiler) newReg() int {
+	r := c.top
+	c.top++
+	if c.top > c.numRegs {
+		c.numRegs = c.top
+	}
+	return r
+}
+
+// release frees the registers allocated after the given register.
+func (c *vmCompiler) release(r int) {
+	c.top = r + 1
+}
+
+// resultReg frees the registers allocated for the operands of an instruction, the first of which is
+// the given register, and allocates that register to hold the result of the instruction.
+// Instructions read their operands before writing the result, or write the result only when
+// transferring control past the remaining instructions which read the operands.
+func (c *vmCompiler) resultReg(dst int) int {
+	c.top = dst
+	return c.newReg()
+}
+
+// constReg returns the register which holds the constant. Registers are shared by equal constants
+// whose types are comparable and whose equality is identity, which excludes doubles since 0.0 and
+// -0.0 are equal.
+func (c *vmCompiler) constReg(val ref.Val) int {
+	switch val.(type) {
+	case types.Bool, types.Int, types.Uint, types.String, types.Null:
+		if r, found := c.constRegs[val]; found {
+			return r
+		}
+		if c.constRegs == nil {
+			c.constRegs = make(map[ref.Val]int)
+		}
+		c.constRegs[val] = len(c.consts)
+	}
+	c.consts = append(c.consts, val)
+	return len(c.consts) - 1
+}
+
+func (c *vmCompiler) emit(inst vmInst) int {
+	c.code = append(c.code, inst)
+	return len(c.code) - 1
+}
+
+// patch sets the jump target of the given instructions to the next instruction to be emitted.
+func (c *vmCompiler) patch(pcs ...int) {
+	for _, pc := range pcs {
+		c.code[pc].jump = len(c.code)
+	}
+}
+
+// compile emits the instructions which evaluate the Interpretable and returns the register which
+// holds the result. Registers allocated after the result register are free once compile returns.
+func (c *vmCompiler) compile(i Interpretable) int {
+	switch n := i.(type) {
+	case *evalConst:
+		return c.constReg(n.val)
+	case *evalWatch:
+		return c.compileWatch(n.observers, n.ID(), n.Interpretable, true)
+	case *evalWatchAttr:
+		return c.compileWatch(n.observers, n.ID(), n.InterpretableAttribute, true)
+	case *evalWatchConstructor:
+		return c.compileWatch(n.observers, n.ID(), n.constructor, true)
+	case *evalWatchConst:
+		return c.compileWatch(n.observers, n.ID(), n.InterpretableConst, false)
+	case *evalAnd:
+		return c.compileLogical(n.id, n.terms, opAndTerm, types.True)
+	case *evalOr:
+		return c.compileLogical(n.id, n.terms, opOrTerm, types.False)
+	case *evalEq:
+		return c.compileCall(opEq, n, n.lhs, n.rhs)
+	case *evalNe:
+		return c.compileCall(opNe, n, n.lhs, n.rhs)
+	case *evalUnary:
+		if op, found := vmUnaryOps[n.overload]; found {
+			return c.compileCall(op, n, n.arg)
+		}
+		return c.compileCall(opUnary, n, n.arg)
+	case *evalBinary:
+		if intOp, found := vmIntOps[n.overload]; found {
+			r := c.compileCall(opIntBinary, n, n.lhs, n.rhs)
+			c.code[len(c.code)-1].intOp = intOp
+			return r
+		}
+		return c.compileCall(opBinary, n, n.lhs, n.rhs)
+	case *evalVarArgs:
+		return c.compileVarArgs(n)
+	case *evalSetMembership:
+		return c.compileCall(opSetMembership, n, n.arg)
+	case *evalList:
+		return c.compileList(n)
+	case *evalMap:
+		return c.compileMap(n)
+	case *evalObj:
+		return c.compileObj(n)
+	case *evalAttr:
+		return c.compileAttr(n)
+	case *evalInterruptableCall:
+		c.emit(vmInst{op: opInterrupt, id: n.ID()})
+		return c.compile(n.InterpretableCall)
+	case *evalFold:
+		// Comprehensions which iterate over map entries, evaluate every iteration, evaluate
+		// their iterations in parallel, or record the state of each iteration are evaluated as
+		// a whole.
+		if n.iterVar2 == "" && !n.exhaustive && n.parallel == nil && !n.trackIterations {
+			return c.compileFold(n)
+		}
+	}
+	dst := c.newReg()
+	c.emit(vmInst{op: opEval, dst: dst, node: i})
+	return dst
+}
+
+func (c *vmCompiler) compileWatch(observers stepObservers, id int64, step Interpretable, enter bool) int {
+	st := &vmStep{observers: observers, id: id, step: step}
+	if enter && observers.hasEnter() {
+		c.emit(vmInst{op: opEnter, node: st})
+	}
+	r := c.compile(step)
+	c.emit(vmInst{op: opObserve, a: r, node: st})
+	return r
+}
+
+func (c *vmCompiler) compileLogical(id int64, terms []Interpretable, op vmOpcode, result types.Bool) int {
+	dst := c.newReg()
+	unk := c.newReg()
+	err := c.newReg()
+	c.emit(vmInst{op: opLogicalInit, b: unk, c: err})
+	exits := make([]int, len(terms))
+	for i, term := range terms {
+		r := c.compile(term)
+		exits[i] = c.emit(vmInst{op: op, dst: dst, a: r, b: unk, c: err, id: id})
+		c.release(err)
+	}
+	c.emit(vmInst{op: opLogicalEnd, dst: dst, b: unk, c: err, val: result})
+	c.patch(exits...)
+	c.release(dst)
+	return dst
+}
+
+func (c *vmCompiler) compileCall(op vmOpcode, node any, args ...Interpretable) int {
+	dst := c.top
+	a := c.compile(args[0])
+	b := 0
+	if len(args) > 1 {
+		b = c.compile(args[1])
+	}
+	c.emit(vmInst{op: op, dst: dst, a: a, b: b, node: node})
+	return c.resultReg(dst)
+}
+
+func (c *vmCompiler) compileVarArgs(n *evalVarArgs) int {
+	dst := c.top
+	args := make([]int, len(n.args))
+	var exits []int
+	for i, arg := range n.args {
+		args[i] = c.compile(arg)
+		// Strict functions are not invoked, and the remaining arguments are not evaluated, when an
+		// argument is unknown or error.
+		if !n.nonStrict {
+			exits = append(exits, c.emit(vmInst{op: opBail, dst: dst, a: args[i]}))
+		}
+	}
+	c.emit(vmInst{op: opVarArgs, dst: dst, args: args, node: n})
+	c.patch(exits...)
+	return c.resultReg(dst)
+}
+
+func (c *vmCompiler) compileList(n *evalList) int {
+	dst := c.top
+	elems := make([]int, len(n.elems))
+	exits := make([]int, len(n.elems))
+	for i, elem := range n.elems {
+		elems[i] = c.compile(elem)
+		exits[i] = c.emit(vmInst{op: opListElem, dst: dst, a: elems[i], c: i, node: n})
+	}
+	c.emit(vmInst{op: opList, dst: dst, args: elems, node: n})
+	c.patch(exits...)
+	return c.resultReg(dst)
+}
+
+func (c *vmCompiler) compileMap(n *evalMap) int {
+	dst := c.top
+	entries := make([]int, 0, len(n.keys)*2)
+	exits := make([]int, 0, len(n.keys)*2)
+	for i, key := range n.keys {
+		k := c.compile(key)
+		exits = append(exits, c.emit(vmInst{op: opBail, dst: dst, a: k}))
+		v := c.compile(n.vals[i])
+		exits = append(exits, c.emit(vmInst{op: opMapValue, dst: dst, a: k, b: v, c: i, node: n}))
+		entries = append(entries, k, v)
+	}
+	c.emit(vmInst{op: opMap, dst: dst, args: entries, node: n})
+	c.patch(exits...)
+	return c.resultReg(dst)
+}
+
+func (c *vmCompiler) compileObj(n *evalObj) int {
+	dst := c.top
+	fields := make([]int, len(n.vals))
+	exits := make([]int, len(n.vals))
+	for i, val := range n.vals {
+		fields[i] = c.compile(val)
+		exits[i] = c.emit(vmInst{op: opObjField, dst: dst, a: fields[i], c: i, node: n})
+	}
+	c.emit(vmInst{op: opObj, dst: dst, args: fields, node: n})
+	c.patch(exits...)
+	return c.resultReg(dst)
+}
+
+func (c *vmCompiler) compileAttr(n *evalAttr) int {
+	dst := c.top
+	switch attr := n.attr.(type) {
+	case *relativeAttribute:
+		c.compileBranch(n, attr, dst)
+	case *conditionalAttribute:
+		cond := c.compile(attr.expr)
+		branch := c.emit(vmInst{op: opCond, dst: dst, a: cond, node: &vmAttr{eval: n}})
+		c.top = dst
+		c.compileBranch(n, attr.truthy, dst)
+		exit := c.emit(vmInst{op: opJump})
+		c.patch(branch)
+		c.top = dst
+		c.compileBranch(n, attr.falsy, dst)
+		c.patch(exit)
+		c.code[branch].b = len(c.code)
+	default:
+		if op, slot, abs, found := c.localVar(attr); found {
+			c.emit(vmInst{op: op, dst: dst, c: slot, node: &vmAttr{eval: n, abs: abs}})
+			break
+		}
+		c.emit(vmInst{op: opResolve, dst: dst, node: &vmAttr{eval: n, attr: attr}})
+	}
+	return c.resultReg(dst)
+}
+
+// localVar determines whether the attribute refers to a variable of an enclosing comprehension, and
+// if so returns the opcode and comprehension slot from which the variable is read.
+//
+// Only attributes with a single candidate name are considered, since names which must be
+// disambiguated or qualified by a container may also resolve to a variable of the activation.
+func (c *vmCompiler) localVar(attr Attribute) (vmOpcode, int, *absoluteAttribute, bool) {
+	abs, ok := attr.(*absoluteAttribute)
+	if !ok || abs.disambiguateNames || len(abs.namespaceNames) != 1 {
+		return 0, 0, nil, false
+	}
+	name := abs.namespaceNames[0]
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		sc := c.scopes[i]
+		if name == sc.fold.accuVar {
+			return opAccu, sc.slot, abs, true
+		}
+		if !sc.result && name == sc.fold.iterVar {
+			return opIter, sc.slot, abs, true
+		}
+	}
+	return 0, 0, nil, false
+}
+
+// compileBranch emits the instructions which resolve the attribute as though it were the attribute
+// of the evalAttr, storing the result in the dst register.
+func (c *vmCompiler) compileBranch(n *evalAttr, attr Attribute, dst int) {
+	if rel, ok := attr.(*relativeAttribute); ok {
+		r := c.compile(rel.operand)
+		c.emit(vmInst{op: opRelative, dst: dst, a: r, node: &vmAttr{eval: n, rel: rel}})
+		return
+	}
+	c.emit(vmInst{op: opResolve, dst: dst, node: &vmAttr{eval: n, attr: attr}})
+}
+
+func (c *vmCompiler) compileFold(n *evalFold) int {
+	dst := c.newReg()
+	it := c.newReg()
+	slot := c.numFolds
+	c.numFolds++
+	init := c.emit(vmInst{op: opFoldInit, dst: dst, a: c.compile(n.iterRange), b: it, c: slot, node: n})
+	c.release(it)
+	c.scopes = append(c.scopes, vmScope{fold: n, slot: slot})
+	loop := c.emit(vmInst{op: opFoldNext, a: it, c: slot})
+	cond := c.emit(vmInst{op: opFoldCond, a: c.compile(n.cond), c: slot})
+	c.release(it)
+	c.emit(vmInst{op: opFoldStep, a: c.compile(n.step), b: loop, c: slot})
+	c.release(it)
+	c.patch(loop, cond)
+	c.emit(vmInst{op: opFoldResult, dst: dst, c: slot})
+	c.scopes[len(c.scopes)-1].result = true
+	c.emit(vmInst{op: opFoldEnd, dst: dst, a: c.compile(n.result), c: slot})
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.patch(init)
+	c.release(dst)
+	return dst
+}
+
+// vmProgram is an Interpretable which executes compiled instructions.
+type vmProgram struct {
+	id       int64
+	code     []vmInst
+	consts   []ref.Val
+	result   int
+	numRegs  int
+	numFolds int
+}
+
+const (
+	// vmStackRegs and vmStackFolds bound the size of the frames which are allocated on the stack of
+	// the evaluating goroutine rather than on the heap.
+	vmStackRegs  = 32
+	vmStackFolds = 4
+)
+
+// ID implements the Interpretable interface method.
+func (p *vmProgram) ID() int64 {
+	return p.id
+}
+
+// Eval implements the Interpretable interface method.
+func (p *vmProgram) Eval(vars Activation) ref.Val {
+	if p.numRegs <= vmStackRegs && p.numFolds <= vmStackFolds {
+		var regs [vmStackRegs]ref.Val
+		var folds [vmStackFolds]*folder
+		return p.run(vars, regs[:p.numRegs], folds[:p.numFolds])
+	}
+	return p.run(vars, make([]ref.Val, p.numRegs), make([]*folder, p.numFolds))
+}
+
+// String returns a listing of the program instructions.
+func (p *vmProgram) String() string {
+	var sb strings.Builder
+	for r, val := range p.consts {
+		fmt.Fprintf(&sb, "r%d = %v\n", r, val)
+	}
+	for pc, in := range p.code {
+		fmt.Fprintf(&sb, "%d: %s", pc, in.op)
+		switch in.op {
+		case opEval, opResolve:
+			fmt.Fprintf(&sb, " r%d = %T", in.dst, in.node)
+		case opEnter, opObserve:
+			st := in.node.(*vmStep)
+			fmt.Fprintf(&sb, " r%d %T@%d", in.a, st.step, st.id)
+		case opJump:
+			fmt.Fprintf(&sb, " -> %d", in.jump)
+		case opFoldNext, opFoldCond:
+			fmt.Fprintf(&sb, " r%d fold%d -> %d", in.a, in.c, in.jump)
+		case opFoldStep:
+			fmt.Fprintf(&sb, " r%d fold%d -> %d", in.a, in.c, in.b)
+		case opFoldResult:
+			fmt.Fprintf(&sb, " fold%d", in.c)
+		case opAccu, opIter:
+			fmt.Fprintf(&sb, " r%d = fold%d", in.dst, in.c)
+		case opInterrupt:
+			fmt.Fprintf(&sb, " @%d", in.id)
+		default:
+			fmt.Fprintf(&sb, " r%d = r%d r%d %v", in.dst, in.a, in.b, in.args)
+			if in.jump != 0 {
+				fmt.Fprintf(&sb, " -> %d", in.jump)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// run executes the program instructions using the given registers and comprehension slots, and
+// returns the result register.
+//
+// The frame is passed as separate slices, rather than as a struct, so that the registers of small
+// programs do not escape to the heap.
+func (p *vmProgram) run(vars Activation, regs []ref.Val, folds []*folder) ref.Val {
+	code := p.code
+	for r, val := range p.consts {
+		regs[r] = val
+	}
+	for pc := 0; pc < len(code); pc++ {
+		in := &code[pc]
+		switch in.op {
+		case opEval:
+			regs[in.dst] = in.node.(Interpretable).Eval(vars)
+		case opEnter:
+			st := in.node.(*vmStep)
+			st.observers.enter(vars, st.id, st.step)
+		case opObserve:
+			st := in.node.(*vmStep)
+			st.observers.observe(vars, st.id, st.step, regs[in.a])
+		case opJump:
+			pc = in.jump - 1
+		case opBail:
+			if v := regs[in.a]; types.IsUnknownOrError(v) {
+				regs[in.dst] = v
+				pc = in.jump - 1
+			}
+		case opLogicalInit:
+			regs[in.b] = nil
+			regs[in.c] = nil
+		case opAndTerm, opOrTerm:
+			v := regs[in.a]
+			if b, isBool := v.(types.Bool); isBool {
+				// The short-circuit value is false for a logical AND and true for a logical OR.
+				if b == types.Bool(in.op == opOrTerm) {
+					regs[in.dst] = v
+					pc = in.jump - 1
+				}
+				continue
+			}
+			unk, _ := regs[in.b].(*types.Unknown)
+			unk, err := mergeLogicalTerm(in.id, v, unk, regs[in.c])
+			if unk != nil {
+				regs[in.b] = unk
+			}
+			regs[in.c] = err
+		case opLogicalEnd:
+			unk, _ := regs[in.b].(*types.Unknown)
+			regs[in.dst] = logicalResult(unk, regs[in.c], in.val.(types.Bool))
+		case opEq:
+			regs[in.dst] = in.node.(*evalEq).call(regs[in.a], regs[in.b])
+		case opNe:
+			regs[in.dst] = in.node.(*evalNe).call(regs[in.a], regs[in.b])
+		case opUnary:
+			regs[in.dst] = in.node.(*evalUnary).call(regs[in.a])
+		case opNot:
+			if b, isBool := regs[in.a].(types.Bool); isBool {
+				regs[in.dst] = !b
+				continue
+			}
+			regs[in.dst] = in.node.(*evalUnary).call(regs[in.a])
+		case opNotStrictlyFalse:
+			if b, isBool := regs[in.a].(types.Bool); isBool {
+				regs[in.dst] = b
+				continue
+			}
+			regs[in.dst] = in.node.(*evalUnary).call(regs[in.a])
+		case opBinary:
+			regs[in.dst] = in.node.(*evalBinary).call(regs[in.a], regs[in.b])
+		case opIntBinary:
+			bin := in.node.(*evalBinary)
+			l, lInt := regs[in.a].(types.Int)
+			if _, rInt := regs[in.b].(types.Int); lInt && rInt {
+				regs[in.dst] = types.LabelErrNode(bin.id, in.intOp(l, regs[in.b]))
+				continue
+			}
+			regs[in.dst] = bin.call(regs[in.a], regs[in.b])
+		case opVarArgs:
+			argVals := make([]ref.Val, len(in.args))
+			for i, r := range in.args {
+				argVals[i] = regs[r]
+			}
+			regs[in.dst] = in.node.(*evalVarArgs).call(argVals)
+		case opSetMembership:
+			regs[in.dst] = in.node.(*evalSetMembership).contains(regs[in.a])
+		case opListElem:
+			if _, err := in.node.(*evalList).initElem(in.c, regs[in.a]); err != nil {
+				regs[in.dst] = err
+				pc = in.jump - 1
+			}
+		case opList:
+			// The elements are validated, and their registers may hold constants, so optional
+			// elements are unwrapped while the list is created.
+			l := in.node.(*evalList)
+			elemVals := make([]ref.Val, 0, len(in.args))
+			for i, r := range in.args {
+				if v, _ := l.initElem(i, regs[r]); v != nil {
+					elemVals = append(elemVals, v)
+				}
+			}
+			regs[in.dst] = l.adapter.NativeToValue(elemVals)
+		case opMapValue:
+			if _, err := in.node.(*evalMap).initValue(in.c, regs[in.a], regs[in.b]); err != nil {
+				regs[in.dst] = err
+				pc = in.jump - 1
+			}
+		case opMap:
+			m := in.node.(*evalMap)
+			entries := make(map[ref.Val]ref.Val, len(in.args)/2)
+			for i := 0; i < len(in.args); i += 2 {
+				k := regs[in.args[i]]
+				v, _ := m.initValue(i/2, k, regs[in.args[i+1]])
+				if v == nil {
+					delete(entries, k)
+					continue
+				}
+				entries[k] = v
+			}
+			regs[in.dst] = m.adapter.NativeToValue(entries)
+		case opObjField:
+			if _, err := in.node.(*evalObj).initField(in.c, regs[in.a]); err != nil {
+				regs[in.dst] = err
+				pc = in.jump - 1
+			}
+		case opObj:
+			o := in.node.(*evalObj)
+			fieldVals := make(map[string]ref.Val, len(in.args))
+			for i, r := range in.args {
+				if v, _ := o.initField(i, regs[r]); v != nil {
+					fieldVals[o.fields[i]] = v
+					continue
+				}
+				delete(fieldVals, o.fields[i])
+			}
+			regs[in.dst] = o.newValue(fieldVals)
+		case opCond:
+			switch v := regs[in.a]; v {
+			case types.True:
+			case types.False:
+				pc = in.jump - 1
+			default:
+				regs[in.dst] = in.node.(*vmAttr).eval.result(conditionResult(v))
+				pc = in.b - 1
+			}
+		case opResolve:
+			va := in.node.(*vmAttr)
+			regs[in.dst] = va.eval.result(va.attr.Resolve(vars))
+		case opAccu:
+			f := folds[in.c]
+			regs[in.dst] = in.node.(*vmAttr).resolveLocal(vars, f.accuVar, f.accuValue())
+		case opIter:
+			f := folds[in.c]
+			regs[in.dst] = in.node.(*vmAttr).resolveLocal(vars, f.iterVar, f.iterValue())
+		case opRelative:
+			va := in.node.(*vmAttr)
+			regs[in.dst] = va.eval.result(va.rel.resolveOperand(vars, regs[in.a]))
+		case opFoldInit:
+			rng := regs[in.a]
+			if types.IsUnknownOrError(rng) {
+				regs[in.dst] = rng
+				pc = in.jump - 1
+				continue
+			}
+			if !rng.Type().HasTrait(traits.IterableType) {
+				regs[in.dst] = types.ValOrErr(rng, "got '%T', expected iterable type", rng)
+				pc = in.jump - 1
+				continue
+			}
+			f := newFolder(in.node.(*evalFold), vars)
+			folds[in.c] = f
+			vars = f
+			regs[in.b] = rng.(traits.Iterable).Iterator()
+		case opFoldNext:
+			it := regs[in.a].(traits.Iterator)
+			if it.HasNext() != types.True {
+				pc = in.jump - 1
+				continue
+			}
+			folds[in.c].iterVar1Val = it.Next()
+		case opFoldCond:
+			cond, ok := regs[in.a].(types.Bool)
+			if ok && cond != types.True {
+				pc = in.jump - 1
+			}
+		case opFoldStep:
+			f := folds[in.c]
+			f.accuVal = regs[in.a]
+			f.initialized = true
+			if f.interruptable {
+				checkInterruptCancelled(f.activation)
+			}
+			pc = in.b - 1
+		case opFoldResult:
+			folds[in.c].computeResult = true
+		case opFoldEnd:
+			regs[in.dst] = folds[in.c].immutableResult(regs[in.a])
+			vars = endFold(folds, in.c)
+		case opInterrupt:
+			checkInterruptCancelled(vars)
+		}
+	}
+	return regs[p.result]
+}
+
+// endFold releases the comprehension state and returns the activation which was in effect prior to
+// the comprehension.
+func endFold(folds []*folder, slot int) Activation {
+	f := folds[slot]
+	folds[slot] = nil
+	vars := f.activation
+	releaseFolder(f)
+	return vars
+}
+
+// hasEnter indicates whether any of the observers is notified when a program step starts.
+func (so stepObservers) hasEnter() bool {
+	for _, o := range so {
+		if o.enter != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,357 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+var bytecodeTestExprs = []string{
+	`x + 1 == 4 && s.startsWith('he')`,
+	`x > 5 || s.size() == 5`,
+	`x / 0 == 1 || true`,
+	`x / 0 == 1 && false`,
+	`x / 0 == 1 || x / 0 == 2`,
+	`x != 3 || s == 'hello' && !(x < 0)`,
+	`x == 3 ? m.a : m.b`,
+	`(x == 3 ? m : {'a': 0}).a`,
+	`x / 0 == 1 ? 1 : 2`,
+	`x == 3 ? x / 0 : 2`,
+	`[x, x * 2, s][2]`,
+	`{s: x, 'b': [x]}.b[0] == x`,
+	`[1, x / 0, 3]`,
+	`{'a': 1, x / 0: 2}`,
+	`max(x, 7, 2)`,
+	`max(x, x / 0, 2)`,
+	`xs.filter(i, i % 2 == 0).map(i, i * i).size()`,
+	`xs.exists(i, i > 10 && i / (i - 15) > 0)`,
+	`xs.exists(i, i / (i - 15) > 100)`,
+	`xs.all(i, i < 15)`,
+	`xs.exists_one(i, i == 7)`,
+	`xs.map(i, xs.filter(j, j < i).size()).all(n, n >= 0)`,
+	`one.all(k, one[k] > 0) && one.exists(k, k == 'a')`,
+	`x.all(i, i > 0)`,
+	`x in [1, 2, 3] && !(s in ['a', 'b'])`,
+}
+
+func TestBytecodeEval(t *testing.T) {
+	xs := make([]int, 20)
+	for i := range xs {
+		xs[i] = i + 1
+	}
+	in := map[string]any{"x": 3, "s": "hello", "xs": xs, "m": map[string]int{"a": 1, "b": 2},
+		"one": map[string]int{"a": 1}}
+	for _, tst := range bytecodeTestExprs {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			for _, opts := range [][]PlannerOption{{}, {Optimize()}} {
+				treeState := NewEvalState()
+				tree := newTestInterpretable(t, expr, append(opts,
+					EvalStateObserver(EvalStateFactory(func() EvalState { return treeState })))...)
+				// Programs are compiled whether or not they contain comprehensions in order to test
+				// every instruction.
+				vmState := NewEvalState()
+				vm := newVMProgram(newTestInterpretable(t, expr, append(opts,
+					EvalStateObserver(EvalStateFactory(func() EvalState { return vmState })))...))
+				want := tree.Eval(newTestActivation(t, in))
+				got := vm.Eval(newTestActivation(t, in))
+				if !evalResultsEqual(got, want) {
+					t.Errorf("Eval() got %v, wanted %v", got, want)
+				}
+				gotIDs, wantIDs := vmState.IDs(), treeState.IDs()
+				slices.Sort(gotIDs)
+				slices.Sort(wantIDs)
+				if !reflect.DeepEqual(gotIDs, wantIDs) {
+					t.Errorf("evaluation state got ids %v, wanted %v", gotIDs, wantIDs)
+				}
+				for _, id := range treeState.IDs() {
+					wantVal, _ := treeState.Value(id)
+					gotVal, _ := vmState.Value(id)
+					if !evalResultsEqual(gotVal, wantVal) {
+						t.Errorf("evaluation state for id %d got %v, wanted %v", id, gotVal, wantVal)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBytecodeEvalPlan(t *testing.T) {
+	tests := []struct {
+		expr string
+		ops  []vmOpcode
+	}{
+		{
+			expr: `x > 1 && y < 2 || z`,
+			ops:  []vmOpcode{opResolve, opAndTerm, opOrTerm, opLogicalEnd, opBinary},
+		},
+		{
+			expr: `x ? 'a' : y.z`,
+			ops:  []vmOpcode{opCond, opJump, opResolve, opRelative},
+		},
+		{
+			expr: `[1, x].map(i, i + 1)`,
+			ops: []vmOpcode{opList, opFoldInit, opFoldNext, opIter, opAccu, opFoldCond, opFoldStep,
+				opFoldResult, opFoldEnd},
+		},
+		{
+			expr: `xs.exists(x, [x].all(y, y == x))`,
+			ops:  []vmOpcode{opResolve, opIter, opAccu, opEq},
+		},
+		{
+			expr: `max(x, y, z)`,
+			ops:  []vmOpcode{opBail, opVarArgs},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			prg := newVMProgram(newTestInterpretable(t, tc.expr))
+			listing := prg.String()
+			for _, op := range tc.ops {
+				if !strings.Contains(listing, ": "+op.String()+" ") {
+					t.Errorf("program missing %s instruction:\n%s", op, listing)
+				}
+			}
+			// Attributes and constants are compiled into instructions and registers rather than
+			// evaluated as Interpretables.
+			if evals := strings.Count(listing, ": eval "); evals != 0 {
+				t.Errorf("program got %d eval instructions, wanted none:\n%s", evals, listing)
+			}
+		})
+	}
+}
+
+func TestBytecodeEvalOperators(t *testing.T) {
+	tests := []struct {
+		expr string
+		ops  []vmOpcode
+	}{
+		{
+			expr: `xs.all(i, i + 1 > i && i * 2 >= i && i - 1 < i && i / 2 <= i && i % 2 != 2)`,
+			ops:  []vmOpcode{opIntBinary, opNotStrictlyFalse},
+		},
+		{
+			expr: `xs.exists(i, 9223372036854775807 + i > 0)`,
+			ops:  []vmOpcode{opIntBinary},
+		},
+		{
+			expr: `xs.exists(i, 10 / i == 1 || 10 % i == 1)`,
+			ops:  []vmOpcode{opIntBinary},
+		},
+		{
+			expr: `ds.exists(d, d + 1 > 2)`,
+			ops:  []vmOpcode{opIntBinary},
+		},
+		{
+			expr: `!xs.exists(i, !(i > 2))`,
+			ops:  []vmOpcode{opNot},
+		},
+		{
+			expr: `ds.all(d, !d)`,
+			ops:  []vmOpcode{opNot},
+		},
+	}
+	vars := []*decls.VariableDecl{
+		decls.NewVariable("xs", types.NewListType(types.IntType)),
+		decls.NewVariable("ds", types.NewListType(types.DynType)),
+	}
+	in := map[string]any{"xs": []int{0, 1, 2, 3}, "ds": []any{1, 2.5, "a", true}}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			tree, act, err := program(t, &testCase{expr: tc.expr, vars: vars, in: in})
+			if err != nil {
+				t.Fatalf("program(%q) failed: %v", tc.expr, err)
+			}
+			vm, _, err := program(t, &testCase{expr: tc.expr, vars: vars, in: in}, BytecodeEval())
+			if err != nil {
+				t.Fatalf("program(%q) failed: %v", tc.expr, err)
+			}
+			listing := vm.(*vmProgram).String()
+			for _, op := range tc.ops {
+				if !strings.Contains(listing, ": "+op.String()+" ") {
+					t.Errorf("program missing %s instruction:\n%s", op, listing)
+				}
+			}
+			want := tree.Eval(act)
+			if got := vm.Eval(act); !evalResultsEqual(got, want) {
+				t.Errorf("Eval() got %v, wanted %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBytecodeEvalFrame(t *testing.T) {
+	// Programs without comprehensions are not compiled.
+	for _, expr := range []string{`1`, `x`, `x.y`, `x + 1 > y && z`} {
+		if prg, isVM := newTestInterpretable(t, expr, BytecodeEval()).(*vmProgram); isVM {
+			t.Errorf("%s compiled to a program:\n%s", expr, prg)
+		}
+	}
+	// Registers are reused once the operands which they hold have been consumed, and equal
+	// constants share a register.
+	expr := `x` + strings.Repeat(` + x * 2`, 50) + ` > 0 && [1, 2, x].exists(i, i == x + 1)`
+	prg := newTestInterpretable(t, expr, BytecodeEval()).(*vmProgram)
+	if prg.numRegs > vmStackRegs || prg.numFolds > vmStackFolds {
+		t.Errorf("program got %d registers and %d comprehension slots, wanted a stack frame:\n%s",
+			prg.numRegs, prg.numFolds, prg)
+	}
+	if len(prg.consts) != 3 || prg.numRegs > 16 {
+		t.Errorf("program got %d constants and %d registers, wanted 3 and at most 16:\n%s",
+			len(prg.consts), prg.numRegs, prg)
+	}
+	if out := prg.Eval(newTestActivation(t, map[string]any{"x": 1})); out != types.True {
+		t.Errorf("Eval() got %v, wanted true", out)
+	}
+}
+
+func TestBytecodeEvalCost(t *testing.T) {
+	xs := make([]int, 50)
+	in := map[string]any{"x": 3, "s": "hello", "xs": xs, "m": map[string]int{"a": 1, "b": 2},
+		"one": map[string]int{"a": 1}}
+	for _, tst := range bytecodeTestExprs {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			costs := make([]uint64, 2)
+			for i, compile := range []bool{false, true} {
+				tracker, err := NewCostTracker(nil)
+				if err != nil {
+					t.Fatalf("NewCostTracker() failed: %v", err)
+				}
+				prg := newTestInterpretable(t, expr,
+					CostObserver(CostTrackerFactory(func() (*CostTracker, error) { return tracker, nil })))
+				if compile {
+					prg = newVMProgram(prg)
+				}
+				prg.Eval(newTestActivation(t, in))
+				costs[i] = tracker.ActualCost()
+			}
+			if costs[0] != costs[1] {
+				t.Errorf("bytecode evaluation cost %d, wanted tree evaluation cost %d", costs[1], costs[0])
+			}
+		})
+	}
+}
+
+func TestBytecodeEvalCostLimit(t *testing.T) {
+	tracker, err := NewCostTracker(nil, CostTrackerLimit(100))
+	if err != nil {
+		t.Fatalf("NewCostTracker() failed: %v", err)
+	}
+	prg := newTestInterpretable(t, `xs.map(i, i * 2).size() > 0`, BytecodeEval(),
+		CostObserver(CostTrackerFactory(func() (*CostTracker, error) { return tracker, nil })))
+	defer func() {
+		r := recover()
+		if cancelled, ok := r.(EvalCancelledError); !ok || cancelled.Cause != CostLimitExceeded {
+			t.Errorf("Eval() panicked with %v, wanted cost limit exceeded", r)
+		}
+	}()
+	prg.Eval(newTestActivation(t, map[string]any{"xs": make([]int, 100)}))
+	t.Error("Eval() did not exceed the cost limit")
+}
+
+func TestBytecodeEvalInterrupt(t *testing.T) {
+	prg := newTestInterpretable(t, `xs.map(i, xs.all(j, j >= 0)).size() > 0`, BytecodeEval(), InterruptableEval())
+	out := prg.Eval(newTestActivation(t, map[string]any{
+		"xs":           make([]int, 100),
+		"#interrupted": true,
+	}))
+	if !isInterruptCancelled(out) {
+		t.Errorf("Eval() got %v, wanted interrupt error", out)
+	}
+}
+
+func TestBytecodeEvalConcurrent(t *testing.T) {
+	prg := newTestInterpretable(t, `xs.filter(i, i % n == 0).size()`, BytecodeEval())
+	xs := make([]int, 60)
+	for i := range xs {
+		xs[i] = i
+	}
+	var wg sync.WaitGroup
+	for n := 1; n <= 10; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				out := prg.Eval(newTestActivation(t, map[string]any{"xs": xs, "n": n}))
+				if want := types.Int((59 / n) + 1); out != want {
+					t.Errorf("Eval() with n=%d got %v, wanted %v", n, out, want)
+					return
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+func BenchmarkBytecodeEval(b *testing.B) {
+	for _, tst := range testData(b) {
+		if tst.err != "" || tst.progErr != "" {
+			continue
+		}
+		prg, vars, err := program(b, &tst, BytecodeEval(), Optimize(), CompileRegexConstants(MatchesRegexOptimization))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(tst.name, func(b *testing.B) {
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				prg.Eval(vars)
+			}
+		})
+	}
+}
+
+func BenchmarkBytecodeEvalComprehensions(b *testing.B) {
+	xs := make([]ref.Val, 100)
+	for i := range xs {
+		xs[i] = types.Int(i)
+	}
+	vars := []*decls.VariableDecl{decls.NewVariable("xs", types.NewListType(types.IntType))}
+	for _, expr := range []string{
+		`xs.all(i, i >= 0 && i < 100)`,
+		`xs.exists(i, i % 7 == 3 && i * i > 10000)`,
+		`xs.exists_one(i, i == 42)`,
+		`xs.filter(i, i % 10 == 0).size() == 10`,
+		`xs.map(i, i + 1).size() == 100`,
+	} {
+		for _, backend := range []string{"tree", "bytecode"} {
+			opts := []PlannerOption{Optimize()}
+			if backend == "bytecode" {
+				opts = append(opts, BytecodeEval())
+			}
+			prg, act, err := program(b, &testCase{expr: expr, vars: vars, in: map[string]any{"xs": xs}}, opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(backend+"/"+expr, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					prg.Eval(act)
+				}
+			})
+		}
+	}
+}
@@ -27,45 +27,68 @@ type InterpretableDecorator func(Interpretable) (Interpretable, error)
 
 // decObserveEval records evaluation state into an EvalState object.
 func decObserveEval(observer EvalObserver) InterpretableDecorator {
+	return decObserveSteps(&stepObserver{exit: observer})
+}
+
+// decObserveSteps wraps Interpretable values with watchers which report the start and result of
+// each evaluation step to the observer.
+//
+// Interpretable values which are already being watched are augmented with the observer so that
+// multiple observers may be configured for the same program.
+func decObserveSteps(observer *stepObserver) InterpretableDecorator {
 	return func(i Interpretable) (Interpretable, error) {
 		switch inst := i.(type) {
-		case *evalWatch, *evalWatchAttr, *evalWatchConst, *evalWatchConstructor:
-			// these instruction are already watching, return straight-away.
-			return i, nil
+		case *evalWatch:
+			inst.observers = inst.observers.with(observer)
+			return inst, nil
+		case *evalWatchAttr:
+			inst.observers = inst.observers.with(observer)
+			return inst, nil
+		case *evalWatchConst:
+			inst.observers = inst.observers.with(observer)
+			return inst, nil
+		case *evalWatchConstructor:
+			inst.observers = inst.observers.with(observer)
+			return inst, nil
 		case InterpretableAttribute:
 			return &evalWatchAttr{
 				InterpretableAttribute: inst,
-				observer:               observer,
+				observers:              stepObservers{observer},
 			}, nil
 		case InterpretableConst:
 			return &evalWatchConst{
 				InterpretableConst: inst,
-				observer:           observer,
+				observers:          stepObservers{observer},
 			}, nil
 		case InterpretableConstructor:
 			return &evalWatchConstructor{
 				constructor: inst,
-				observer:    observer,
+				observers:   stepObservers{observer},
 			}, nil
 		default:
 			return &evalWatch{
 				Interpretable: i,
-				observer:      observer,
+				observers:     stepObservers{observer},
 			}, nil
 		}
 	}
 }
 
-// decInterruptFolds creates an intepretable decorator which marks comprehensions as interruptable
-// where the interrupt state is communicated via a hidden variable on the Activation.
-func decInterruptFolds() InterpretableDecorator {
+// decInterrupts creates an intepretable decorator which marks comprehensions as interruptable and
+// checks for interrupts before each function call, where the interrupt state is communicated via a
+// hidden variable on the Activation.
+func decInterrupts() InterpretableDecorator {
 	return func(i Interpretable) (Interpretable, error) {
-		fold, ok := i.(*evalFold)
-		if !ok {
-			return i, nil
+		switch inst := i.(type) {
+		case *evalFold:
+			inst.interruptable = true
+			return inst, nil
+		case *evalInterruptableCall:
+			return inst, nil
+		case InterpretableCall:
+			return &evalInterruptableCall{InterpretableCall: inst}, nil
 		}
-		fold.interruptable = true
-		return fold, nil
+		return i, nil
 	}
 }
 
@@ -37,6 +37,10 @@ type EvalState interface {
 // evalState permits the mutation of evaluation state for a given expression id.
 type evalState struct {
 	values map[int64]ref.Val
+
+	// iterations records the state of each iteration of the comprehensions evaluated with
+	// iteration tracking enabled, keyed by comprehension expression id.
+	iterations map[int64][]*foldIteration
 }
 
 // NewEvalState returns an EvalState instanced used to observe the intermediate
@@ -76,4 +80,60 @@ func (s *evalState) SetValue(exprID int64, val ref.Val) {
 // Reset implements the EvalState interface method.
 func (s *evalState) Reset() {
 	s.values = map[int64]ref.Val{}
+	s.iterations = nil
+}
+
+// beginFold clears the iterations previously recorded for the comprehension.
+func (s *evalState) beginFold(foldID int64) {
+	if s.iterations != nil {
+		delete(s.iterations, foldID)
+	}
+}
+
+// recordIteration records a new iteration of the comprehension with the given iteration variable
+// values and returns the state which holds the values observed during the iteration.
+func (s *evalState) recordIteration(foldID int64, iterVar1, iterVar2 ref.Val) *evalState {
+	if s.iterations == nil {
+		s.iterations = map[int64][]*foldIteration{}
+	}
+	iter := &foldIteration{
+		iterVar1: iterVar1,
+		iterVar2: iterVar2,
+		state:    &evalState{values: map[int64]ref.Val{}},
+	}
+	s.iterations[foldID] = append(s.iterations[foldID], iter)
+	return iter.state
+}
+
+// foldIteration records the iteration variable values and the expression values observed during a
+// single iteration of a comprehension.
+type foldIteration struct {
+	iterVar1 ref.Val
+	iterVar2 ref.Val
+	state    *evalState
+}
+
+// iterationEvalState records values observed during a comprehension iteration within the state of
+// the iteration as well as within the enclosing evaluation state.
+type iterationEvalState struct {
+	*evalState
+	parent EvalState
+}
+
+// SetValue implements the EvalState interface method.
+func (s *iterationEvalState) SetValue(exprID int64, val ref.Val) {
+	s.evalState.SetValue(exprID, val)
+	s.parent.SetValue(exprID, val)
+}
+
+// iterationStateOf returns the evalState which records comprehension iterations, if supported by
+// the EvalState implementation.
+func iterationStateOf(state EvalState) (*evalState, bool) {
+	switch s := state.(type) {
+	case *evalState:
+		return s, true
+	case *iterationEvalState:
+		return s.evalState, true
+	}
+	return nil, false
 }
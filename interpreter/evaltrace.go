@@ -0,0 +1,350 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// TraceStep records the evaluation of a single program step.
+type TraceStep struct {
+	// ID is the expression id associated with the step.
+	ID int64
+
+	// Function is the name of the function invoked by the step, if any.
+	Function string
+
+	// Overload is the overload id selected for the function invocation, if known.
+	Overload string
+
+	// Args contains the argument values supplied to the function. Arguments which were not
+	// evaluated, such as the right-hand side of a short-circuited logical operator, are nil.
+	Args []ref.Val
+
+	// Result is the value produced by the step.
+	//
+	// Values are recorded by reference, so the accumulators of comprehensions which build lists or
+	// maps may reflect later iterations.
+	Result ref.Val
+
+	// Iteration is the 0-based index of the iteration of the innermost enclosing comprehension in
+	// which the step was evaluated, or -1 if the step was not evaluated as part of an iteration.
+	Iteration int
+
+	// Elapsed is the wall time spent evaluating the step, including its children.
+	Elapsed time.Duration
+
+	// Children contains the steps evaluated on behalf of this step in evaluation order.
+	Children []*TraceStep
+}
+
+// EvalTrace records the steps of an evaluation as an ordered tree.
+//
+// Unlike the EvalState, which records only the most recent value computed for an expression id,
+// the EvalTrace records every step in the order in which evaluation began, including each
+// iteration of a comprehension.
+//
+// An EvalTrace is populated during a single evaluation and is not safe for concurrent use.
+type EvalTrace struct {
+	steps []*TraceStep
+	stack []*traceFrame
+}
+
+// NewEvalTrace returns an empty EvalTrace.
+func NewEvalTrace() *EvalTrace {
+	return &EvalTrace{}
+}
+
+// Steps returns the top-level steps of the evaluation.
+func (t *EvalTrace) Steps() []*TraceStep {
+	return t.steps
+}
+
+// MarshalJSON encodes the trace as a JSON document of the following form:
+//
+//	{"steps": [{
+//	  "id": number,
+//	  "function": string,    // omitted when the step is not a function call.
+//	  "overload": string,    // omitted when unknown.
+//	  "args": [Value],       // omitted when the step is not a function call.
+//	  "result": Value,
+//	  "iteration": number,   // omitted when not evaluated within a comprehension iteration.
+//	  "elapsedNanos": number,
+//	  "children": [Step]     // omitted when empty.
+//	}]}
+//
+// Values are encoded as {"type": string, "value": string} where the value is the human-readable
+// formatting of the CEL value, and unevaluated arguments are encoded as null.
+func (t *EvalTrace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&traceJSON{Steps: traceStepsJSON(t.steps)})
+}
+
+// enter records the start of a program step.
+func (t *EvalTrace) enter(id int64, programStep any) {
+	frame := &traceFrame{step: t.newStep(id, programStep), programStep: programStep, start: time.Now()}
+	if fold, ok := programStep.(*evalFold); ok {
+		frame.fold = fold
+	}
+	t.stack = append(t.stack, frame)
+}
+
+// exit records the result of a program step. Steps which were not entered, such as constants and
+// attribute qualifiers, are recorded as leaf steps of the step currently being evaluated.
+func (t *EvalTrace) exit(id int64, programStep any, val ref.Val) {
+	if n := len(t.stack); n > 0 && t.stack[n-1].step.ID == id && t.stack[n-1].programStep == programStep {
+		frame := t.stack[n-1]
+		t.stack = t.stack[:n-1]
+		frame.step.Result = val
+		frame.step.Elapsed = time.Since(frame.start)
+		frame.step.Args = traceArgs(frame.step, programStep)
+		t.stepCompleted(id)
+		return
+	}
+	step := t.newStep(id, programStep)
+	step.Result = val
+	step.Args = traceArgs(step, programStep)
+	t.stepCompleted(id)
+}
+
+// newStep creates a step and appends it to the children of the step currently being evaluated.
+func (t *EvalTrace) newStep(id int64, programStep any) *TraceStep {
+	step := &TraceStep{ID: id, Iteration: -1}
+	step.Function, step.Overload = traceFunction(programStep)
+	n := len(t.stack)
+	if n == 0 {
+		t.steps = append(t.steps, step)
+		return step
+	}
+	parent := t.stack[n-1]
+	parent.step.Children = append(parent.step.Children, step)
+	step.Iteration = parent.step.Iteration
+	if parent.fold != nil && parent.isIterationStep(id) {
+		step.Iteration = parent.iteration
+	}
+	return step
+}
+
+// stepCompleted advances the iteration count of the enclosing comprehension when the loop step
+// completes.
+func (t *EvalTrace) stepCompleted(id int64) {
+	n := len(t.stack)
+	if n == 0 {
+		return
+	}
+	parent := t.stack[n-1]
+	if parent.fold != nil && parent.fold.step != nil && parent.fold.step.ID() == id {
+		parent.iteration++
+	}
+}
+
+// traceFrame tracks a step which is currently being evaluated.
+type traceFrame struct {
+	step        *TraceStep
+	programStep any
+	start       time.Time
+
+	// fold and iteration track the iteration progress when the program step is a comprehension.
+	fold      *evalFold
+	iteration int
+}
+
+func (f *traceFrame) isIterationStep(id int64) bool {
+	return (f.fold.cond != nil && f.fold.cond.ID() == id) || (f.fold.step != nil && f.fold.step.ID() == id)
+}
+
+// traceFunction returns the function name and overload id associated with the program step.
+func traceFunction(programStep any) (string, string) {
+	switch step := programStep.(type) {
+	case InterpretableCall:
+		return step.Function(), step.OverloadID()
+	case *evalAnd, *evalExhaustiveAnd:
+		return operators.LogicalAnd, overloads.LogicalAnd
+	case *evalOr, *evalExhaustiveOr:
+		return operators.LogicalOr, overloads.LogicalOr
+	}
+	return "", ""
+}
+
+// traceArgs returns the argument values for function call steps using the results of the child
+// steps which correspond to each argument.
+func traceArgs(step *TraceStep, programStep any) []ref.Val {
+	var args []Interpretable
+	switch s := programStep.(type) {
+	case InterpretableCall:
+		args = s.Args()
+	case *evalAnd:
+		args = s.terms
+	case *evalExhaustiveAnd:
+		args = s.terms
+	case *evalOr:
+		args = s.terms
+	case *evalExhaustiveOr:
+		args = s.terms
+	default:
+		return nil
+	}
+	vals := make([]ref.Val, len(args))
+	for i, arg := range args {
+		for _, child := range step.Children {
+			if child.ID == arg.ID() {
+				vals[i] = child.Result
+			}
+		}
+	}
+	return vals
+}
+
+// evalTraceActivation hides the EvalTrace in the Activation in a manner not accessible to expressions.
+type evalTraceActivation struct {
+	vars  Activation
+	trace *EvalTrace
+}
+
+// ResolveName proxies variable lookups to the backing activation.
+func (eta evalTraceActivation) ResolveName(name string) (any, bool) {
+	return eta.vars.ResolveName(name)
+}
+
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (eta evalTraceActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(eta.vars, name)
+}
+
+// Parent proxies parent lookups to the backing activation.
+func (eta evalTraceActivation) Parent() Activation {
+	return eta.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (eta evalTraceActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(eta.vars)
+}
+
+// asEvalTrace walks the Activation hierarchy and returns the first EvalTrace found, if present.
+func asEvalTrace(vars Activation) (*EvalTrace, bool) {
+	if conv, ok := vars.(evalTraceActivation); ok {
+		return conv.trace, true
+	}
+	if wrapper, ok := vars.(activationWrapper); ok {
+		return asEvalTrace(wrapper.Unwrap())
+	}
+	if vars.Parent() != nil {
+		return asEvalTrace(vars.Parent())
+	}
+	return nil, false
+}
+
+// EvalTraceObserver provides an observer which records an EvalTrace for each evaluation.
+func EvalTraceObserver() PlannerOption {
+	tf := &evalTraceFactory{}
+	return func(p *planner) (*planner, error) {
+		p.observers = append(p.observers, tf)
+		p.decorators = append(p.decorators, decObserveSteps(&stepObserver{enter: tf.Enter, exit: tf.Observe}))
+		return p, nil
+	}
+}
+
+// evalTraceFactory produces an EvalTrace per evaluation.
+type evalTraceFactory struct{}
+
+// InitState produces an EvalTrace and bundles it into the Activation in a way which is not visible
+// to expression evaluation.
+func (tf *evalTraceFactory) InitState(vars Activation) (Activation, error) {
+	return evalTraceActivation{vars: vars, trace: NewEvalTrace()}, nil
+}
+
+// GetState extracts the EvalTrace from the Activation.
+func (tf *evalTraceFactory) GetState(vars Activation) any {
+	if trace, found := asEvalTrace(vars); found {
+		return trace
+	}
+	return nil
+}
+
+// Enter records the start of the evaluation of a program step.
+func (tf *evalTraceFactory) Enter(vars Activation, id int64, programStep any) {
+	if trace, found := asEvalTrace(vars); found {
+		trace.enter(id, programStep)
+	}
+}
+
+// Observe records the result of the evaluation of a program step.
+func (tf *evalTraceFactory) Observe(vars Activation, id int64, programStep any, val ref.Val) {
+	if trace, found := asEvalTrace(vars); found {
+		trace.exit(id, programStep, val)
+	}
+}
+
+type traceJSON struct {
+	Steps []*traceStepJSON `json:"steps"`
+}
+
+type traceStepJSON struct {
+	ID           int64            `json:"id"`
+	Function     string           `json:"function,omitempty"`
+	Overload     string           `json:"overload,omitempty"`
+	Args         []*traceValJSON  `json:"args,omitempty"`
+	Result       *traceValJSON    `json:"result"`
+	Iteration    *int             `json:"iteration,omitempty"`
+	ElapsedNanos int64            `json:"elapsedNanos"`
+	Children     []*traceStepJSON `json:"children,omitempty"`
+}
+
+type traceValJSON struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func traceStepsJSON(steps []*TraceStep) []*traceStepJSON {
+	out := make([]*traceStepJSON, len(steps))
+	for i, s := range steps {
+		js := &traceStepJSON{
+			ID:           s.ID,
+			Function:     s.Function,
+			Overload:     s.Overload,
+			Result:       traceValueJSON(s.Result),
+			ElapsedNanos: s.Elapsed.Nanoseconds(),
+		}
+		for _, arg := range s.Args {
+			js.Args = append(js.Args, traceValueJSON(arg))
+		}
+		if s.Iteration >= 0 {
+			iter := s.Iteration
+			js.Iteration = &iter
+		}
+		if len(s.Children) != 0 {
+			js.Children = traceStepsJSON(s.Children)
+		}
+		out[i] = js
+	}
+	return out
+}
+
+func traceValueJSON(val ref.Val) *traceValJSON {
+	if val == nil {
+		return nil
+	}
+	typeName := "error"
+	if t := val.Type(); t != nil {
+		typeName = t.TypeName()
+	}
+	return &traceValJSON{Type: typeName, Value: types.Format(val)}
+}
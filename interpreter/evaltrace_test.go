@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/parser"
+)
+
+func TestEvalTrace(t *testing.T) {
+	trace, out := evalWithTrace(t, `x > 0 || [1, 2].exists(i, i > x)`, map[string]any{"x": -1})
+	if out != types.True {
+		t.Fatalf("Eval() got %v, wanted true", out)
+	}
+	steps := trace.Steps()
+	if len(steps) != 1 {
+		t.Fatalf("trace.Steps() got %d root steps, wanted 1", len(steps))
+	}
+	root := steps[0]
+	if root.Function != "_||_" || root.Result != types.True || root.Iteration != -1 {
+		t.Errorf("root step got %+v, wanted _||_ evaluating to true", root)
+	}
+	if !reflect.DeepEqual(root.Args, []ref.Val{types.False, types.True}) {
+		t.Errorf("root step args got %v, wanted [false, true]", root.Args)
+	}
+
+	// Collect the `i > x` comparisons evaluated within the comprehension.
+	var comparisons []*TraceStep
+	walkTrace(steps, func(s *TraceStep) {
+		if s.Function == "_>_" && len(s.Args) == 2 && s.Args[1] == types.Int(-1) && s.Args[0] != types.Int(0) {
+			comparisons = append(comparisons, s)
+		}
+	})
+	if len(comparisons) != 1 {
+		t.Fatalf("got %d comparisons within the comprehension, wanted 1 due to short-circuiting", len(comparisons))
+	}
+	if comparisons[0].Iteration != 0 || comparisons[0].Args[0] != types.Int(1) {
+		t.Errorf("comprehension step got %+v, wanted iteration 0 with arg 1", comparisons[0])
+	}
+}
+
+func TestEvalTraceIterations(t *testing.T) {
+	trace, out := evalWithTrace(t, `[1, 2, 3].map(i, i * 2)`, map[string]any{})
+	if out.Equal(types.NewDynamicList(types.DefaultTypeAdapter, []int{2, 4, 6})) != types.True {
+		t.Fatalf("Eval() got %v, wanted [2, 4, 6]", out)
+	}
+	var iterations []int
+	var results []ref.Val
+	walkTrace(trace.Steps(), func(s *TraceStep) {
+		if s.Function == "_*_" {
+			iterations = append(iterations, s.Iteration)
+			results = append(results, s.Result)
+		}
+	})
+	if !reflect.DeepEqual(iterations, []int{0, 1, 2}) {
+		t.Errorf("got iterations %v, wanted [0, 1, 2]", iterations)
+	}
+	if !reflect.DeepEqual(results, []ref.Val{types.Int(2), types.Int(4), types.Int(6)}) {
+		t.Errorf("got results %v, wanted [2, 4, 6]", results)
+	}
+}
+
+func TestEvalTraceWithEvalState(t *testing.T) {
+	parsed := mustParseWithMacros(t, `a && b`)
+	state := NewEvalState()
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg)
+	intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+	i, err := intr.NewInterpretable(parsed,
+		EvalStateObserver(EvalStateFactory(func() EvalState { return state })),
+		EvalTraceObserver())
+	if err != nil {
+		t.Fatalf("NewInterpretable() failed: %v", err)
+	}
+	vars, _ := NewActivation(map[string]any{"a": true, "b": false})
+	var trace *EvalTrace
+	out := i.(*ObservableInterpretable).ObserveEval(vars, func(observed any) {
+		if tr, ok := observed.(*EvalTrace); ok {
+			trace = tr
+		}
+	})
+	if out != types.False {
+		t.Fatalf("Eval() got %v, wanted false", out)
+	}
+	if len(state.IDs()) != 3 {
+		t.Errorf("state.IDs() got %v, wanted 3 ids", state.IDs())
+	}
+	if trace == nil || len(trace.Steps()) != 1 || len(trace.Steps()[0].Children) != 2 {
+		t.Errorf("trace got %v, wanted a root step with 2 children", trace)
+	}
+}
+
+func TestEvalTraceJSON(t *testing.T) {
+	trace, _ := evalWithTrace(t, `[1].all(i, i == x)`, map[string]any{"x": 1})
+	out, err := json.Marshal(trace)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	var doc struct {
+		Steps []struct {
+			ID     int64 `json:"id"`
+			Result struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"result"`
+			Iteration *int              `json:"iteration"`
+			Children  []json.RawMessage `json:"children"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	if len(doc.Steps) != 1 {
+		t.Fatalf("got %d steps, wanted 1: %s", len(doc.Steps), out)
+	}
+	root := doc.Steps[0]
+	if root.Result.Type != "bool" || root.Result.Value != "true" || root.Iteration != nil || len(root.Children) == 0 {
+		t.Errorf("got root step %s, wanted bool result with children", out)
+	}
+}
+
+func evalWithTrace(t *testing.T, expr string, in map[string]any) (*EvalTrace, ref.Val) {
+	t.Helper()
+	parsed := mustParseWithMacros(t, expr)
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg)
+	intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+	i, err := intr.NewInterpretable(parsed, EvalTraceObserver())
+	if err != nil {
+		t.Fatalf("NewInterpretable() failed: %v", err)
+	}
+	vars, err := NewActivation(in)
+	if err != nil {
+		t.Fatalf("NewActivation() failed: %v", err)
+	}
+	var trace *EvalTrace
+	out := i.(*ObservableInterpretable).ObserveEval(vars, func(observed any) {
+		if tr, ok := observed.(*EvalTrace); ok {
+			trace = tr
+		}
+	})
+	if trace == nil {
+		t.Fatal("ObserveEval() did not report an EvalTrace")
+	}
+	return trace, out
+}
+
+func mustParseWithMacros(t *testing.T, expr string) *ast.AST {
+	t.Helper()
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...))
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	parsed, errs := p.Parse(common.NewTextSource(expr))
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Parse(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	return parsed
+}
+
+func walkTrace(steps []*TraceStep, visit func(*TraceStep)) {
+	for _, s := range steps {
+		visit(s)
+		walkTrace(s.Children, visit)
+	}
+}
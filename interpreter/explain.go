@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// ObservedExpr pairs an expression with the value observed for it during evaluation.
+type ObservedExpr struct {
+	Expr  ast.Expr
+	Value ref.Val
+}
+
+// Determinant is a sub-expression whose observed value determined the result of a boolean
+// expression, along with the values observed for its non-constant operands.
+type Determinant struct {
+	ObservedExpr
+
+	// Operands contains the non-constant arguments of a function call, the operand of a presence
+	// test, or the range of a comprehension, for which a value was observed.
+	Operands []ObservedExpr
+}
+
+// ExplainBool computes the smallest set of sub-expressions whose observed values determined the
+// boolean result of an expression.
+//
+// The explanation follows the short-circuiting behavior of the logical operators and the
+// conditional operator: a false conjunction is explained by its first false term, a true
+// disjunction by its first true term, and a conditional by its condition and the branch taken.
+// A false `all()` or a true `exists()` macro is explained by the predicate as evaluated on the
+// final iteration, since this is the iteration which determined the result.
+//
+// The EvalState must have been populated by the evaluation of the expression, for example using
+// the EvalStateObserver. If the expression did not evaluate to a boolean, the result is empty.
+func ExplainBool(expr ast.Expr, state EvalState) []*Determinant {
+	ex := &explainer{state: state, seen: map[int64]bool{}}
+	val, found := state.Value(expr.ID())
+	if !found {
+		return []*Determinant{}
+	}
+	if _, isBool := val.(types.Bool); !isBool {
+		return []*Determinant{}
+	}
+	ex.explain(expr)
+	return ex.determinants
+}
+
+type explainer struct {
+	state        EvalState
+	seen         map[int64]bool
+	determinants []*Determinant
+}
+
+func (ex *explainer) explain(e ast.Expr) {
+	val, found := ex.state.Value(e.ID())
+	if !found {
+		return
+	}
+	b, isBool := val.(types.Bool)
+	if !isBool {
+		ex.addDeterminant(e, val)
+		return
+	}
+	switch e.Kind() {
+	case ast.CallKind:
+		if ex.explainCall(e, b) {
+			return
+		}
+	case ast.ComprehensionKind:
+		if ex.explainComprehension(e, b) {
+			return
+		}
+	}
+	ex.addDeterminant(e, val)
+}
+
+// explainCall explains the logical operators in terms of their arguments, returning false if the
+// call is not a logical operator or the arguments were not observed.
+func (ex *explainer) explainCall(e ast.Expr, result types.Bool) bool {
+	call := e.AsCall()
+	args := call.Args()
+	switch call.FunctionName() {
+	case operators.LogicalAnd, operators.LogicalOr:
+		// A conjunction is decided by a false term, and a disjunction by a true term. When no such
+		// term exists, every term contributed to the result.
+		deciding := types.Bool(call.FunctionName() == operators.LogicalOr)
+		if result == deciding {
+			for _, arg := range args {
+				if ex.hasValue(arg, deciding) {
+					ex.explain(arg)
+					return true
+				}
+			}
+			return false
+		}
+		for _, arg := range args {
+			if !ex.hasValue(arg, !deciding) {
+				return false
+			}
+		}
+		for _, arg := range args {
+			ex.explain(arg)
+		}
+		return true
+	case operators.LogicalNot, operators.NotStrictlyFalse, operators.OldNotStrictlyFalse:
+		if _, found := ex.state.Value(args[0].ID()); !found {
+			return false
+		}
+		ex.explain(args[0])
+		return true
+	case operators.Conditional:
+		cond, found := ex.state.Value(args[0].ID())
+		condBool, isBool := cond.(types.Bool)
+		if !found || !isBool {
+			return false
+		}
+		branch := args[2]
+		if condBool == types.True {
+			branch = args[1]
+		}
+		ex.explain(args[0])
+		// Branches which are attributes are resolved by the conditional without being observed, in
+		// which case the value of the branch is the result of the conditional.
+		if _, found := ex.state.Value(branch.ID()); !found {
+			ex.addDeterminant(branch, result)
+			return true
+		}
+		ex.explain(branch)
+		return true
+	}
+	return false
+}
+
+// explainComprehension explains the all() and exists() macros in terms of their predicate when the
+// final iteration determined the result of the comprehension.
+func (ex *explainer) explainComprehension(e ast.Expr, result types.Bool) bool {
+	pred, deciding, found := QuantifierPredicate(e)
+	if !found || result != deciding || !ex.hasValue(pred, deciding) {
+		return false
+	}
+	ex.explain(pred)
+	return true
+}
+
+// QuantifierPredicate returns the predicate of a comprehension with the shape produced by the
+// all() or exists() macros, along with the predicate value which decides the comprehension result:
+// false for all(), and true for exists().
+func QuantifierPredicate(e ast.Expr) (ast.Expr, types.Bool, bool) {
+	if e.Kind() != ast.ComprehensionKind {
+		return nil, false, false
+	}
+	comp := e.AsComprehension()
+	step := comp.LoopStep()
+	if step.Kind() != ast.CallKind {
+		return nil, false, false
+	}
+	call := step.AsCall()
+	args := call.Args()
+	if len(args) != 2 || args[0].Kind() != ast.IdentKind || args[0].AsIdent() != comp.AccuVar() {
+		return nil, false, false
+	}
+	switch call.FunctionName() {
+	case operators.LogicalAnd:
+		return args[1], types.False, true
+	case operators.LogicalOr:
+		return args[1], types.True, true
+	}
+	return nil, false, false
+}
+
+func (ex *explainer) hasValue(e ast.Expr, want types.Bool) bool {
+	val, found := ex.state.Value(e.ID())
+	return found && val == want
+}
+
+func (ex *explainer) addDeterminant(e ast.Expr, val ref.Val) {
+	if ex.seen[e.ID()] {
+		return
+	}
+	ex.seen[e.ID()] = true
+	det := &Determinant{ObservedExpr: ObservedExpr{Expr: e, Value: val}}
+	var operands []ast.Expr
+	switch e.Kind() {
+	case ast.CallKind:
+		call := e.AsCall()
+		if call.IsMemberFunction() {
+			operands = append(operands, call.Target())
+		}
+		operands = append(operands, call.Args()...)
+	case ast.SelectKind:
+		if sel := e.AsSelect(); sel.IsTestOnly() {
+			operands = append(operands, sel.Operand())
+		}
+	case ast.ComprehensionKind:
+		operands = append(operands, e.AsComprehension().IterRange())
+	}
+	for _, op := range operands {
+		if op.Kind() == ast.LiteralKind {
+			continue
+		}
+		if v, found := ex.state.Value(op.ID()); found {
+			det.Operands = append(det.Operands, ObservedExpr{Expr: op, Value: v})
+		}
+	}
+	ex.determinants = append(ex.determinants, det)
+}
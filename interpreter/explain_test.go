@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/parser"
+)
+
+func TestExplainBool(t *testing.T) {
+	tests := []struct {
+		expr string
+		in   map[string]any
+		out  types.Bool
+		// want contains the unparsed determinants, each followed by its observed value.
+		want []string
+	}{
+		{
+			expr: `a && b`,
+			in:   map[string]any{"a": true, "b": false},
+			out:  types.False,
+			want: []string{"b", "false"},
+		},
+		{
+			expr: `a && b`,
+			in:   map[string]any{"a": true, "b": true},
+			out:  types.True,
+			want: []string{"a", "true", "b", "true"},
+		},
+		{
+			expr: `x > 1 || y == "b" || z`,
+			in:   map[string]any{"x": 0, "y": "b", "z": true},
+			out:  types.True,
+			want: []string{`y == "b"`, "true"},
+		},
+		{
+			expr: `!(x > 1)`,
+			in:   map[string]any{"x": 0},
+			out:  types.True,
+			want: []string{"x > 1", "false"},
+		},
+		{
+			expr: `x > 1 ? y == "a" : z`,
+			in:   map[string]any{"x": 0, "y": "b", "z": false},
+			out:  types.False,
+			want: []string{"x > 1", "false", "z", "false"},
+		},
+		{
+			expr: `[1, 2, 3].all(i, i < x)`,
+			in:   map[string]any{"x": 2},
+			out:  types.False,
+			want: []string{"i < x", "false"},
+		},
+		{
+			expr: `[1, 2, 3].exists(i, i > x)`,
+			in:   map[string]any{"x": 5},
+			out:  types.False,
+			want: []string{"[1, 2, 3].exists(i, i > x)", "false"},
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			parsed := mustParseWithMacroCalls(t, tc.expr)
+			state := NewEvalState()
+			cont := containers.DefaultContainer
+			reg := newTestRegistry(t)
+			attrs := NewAttributeFactory(cont, reg, reg)
+			intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+			i, err := intr.NewInterpretable(parsed, EvalStateObserver(EvalStateFactory(func() EvalState { return state })))
+			if err != nil {
+				t.Fatalf("NewInterpretable() failed: %v", err)
+			}
+			vars, err := NewActivation(tc.in)
+			if err != nil {
+				t.Fatalf("NewActivation() failed: %v", err)
+			}
+			if out := i.Eval(vars); out != tc.out {
+				t.Fatalf("Eval() got %v, wanted %v", out, tc.out)
+			}
+			var got []string
+			for _, det := range ExplainBool(parsed.Expr(), state) {
+				text, err := parser.Unparse(det.Expr, parsed.SourceInfo())
+				if err != nil {
+					t.Fatalf("Unparse() failed: %v", err)
+				}
+				got = append(got, text, types.Format(det.Value))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ExplainBool() got %v, wanted %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExplainBoolOperands(t *testing.T) {
+	parsed := mustParseWithMacros(t, `size(x) == 2 && has(y.z)`)
+	state := NewEvalState()
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg)
+	intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+	i, err := intr.NewInterpretable(parsed, EvalStateObserver(EvalStateFactory(func() EvalState { return state })))
+	if err != nil {
+		t.Fatalf("NewInterpretable() failed: %v", err)
+	}
+	vars, _ := NewActivation(map[string]any{"x": []int{1, 2, 3}, "y": map[string]int{}})
+	if out := i.Eval(vars); out != types.False {
+		t.Fatalf("Eval() got %v, wanted false", out)
+	}
+	dets := ExplainBool(parsed.Expr(), state)
+	if len(dets) != 1 {
+		t.Fatalf("ExplainBool() got %d determinants, wanted 1", len(dets))
+	}
+	// The literal operand of the equality is omitted.
+	ops := dets[0].Operands
+	if len(ops) != 1 || ops[0].Value != types.Int(3) {
+		t.Errorf("ExplainBool() got operands %v, wanted size(x) == 3", ops)
+	}
+}
+
+func TestExplainBoolNonBool(t *testing.T) {
+	parsed := mustParseWithMacros(t, `x + 1`)
+	state := NewEvalState()
+	state.SetValue(parsed.Expr().ID(), types.Int(2))
+	if dets := ExplainBool(parsed.Expr(), state); len(dets) != 0 {
+		t.Errorf("ExplainBool() got %v, wanted no determinants", dets)
+	}
+}
+
+// mustParseWithMacroCalls parses the expression with macro call tracking so that comprehensions
+// may be unparsed.
+func mustParseWithMacroCalls(t *testing.T, expr string) *ast.AST {
+	t.Helper()
+	p, err := parser.NewParser(parser.Macros(parser.AllMacros...), parser.PopulateMacroCalls(true))
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	parsed, errs := p.Parse(common.NewTextSource(expr))
+	if len(errs.GetErrors()) != 0 {
+		t.Fatalf("Parse(%q) failed: %v", expr, errs.ToDisplayString())
+	}
+	return parsed
+}
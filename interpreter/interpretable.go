@@ -15,6 +15,8 @@
 package interpreter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -256,31 +258,13 @@ func (or *evalOr) Eval(ctx Activation) ref.Val {
 	var unk *types.Unknown
 	for _, term := range or.terms {
 		val := term.Eval(ctx)
-		boolVal, ok := val.(types.Bool)
 		// short-circuit on true.
-		if ok && boolVal == types.True {
+		if val == types.True {
 			return types.True
 		}
-		if !ok {
-			isUnk := false
-			unk, isUnk = types.MaybeMergeUnknowns(val, unk)
-			if !isUnk && err == nil {
-				if types.IsError(val) {
-					err = val
-				} else {
-					err = types.MaybeNoSuchOverloadErr(val)
-				}
-				err = types.LabelErrNode(or.id, err)
-			}
-		}
-	}
-	if unk != nil {
-		return unk
+		unk, err = mergeLogicalTerm(or.id, val, unk, err)
 	}
-	if err != nil {
-		return err
-	}
-	return types.False
+	return logicalResult(unk, err, types.False)
 }
 
 type evalAnd struct {
@@ -299,31 +283,43 @@ func (and *evalAnd) Eval(ctx Activation) ref.Val {
 	var unk *types.Unknown
 	for _, term := range and.terms {
 		val := term.Eval(ctx)
-		boolVal, ok := val.(types.Bool)
 		// short-circuit on false.
-		if ok && boolVal == types.False {
+		if val == types.False {
 			return types.False
 		}
-		if !ok {
-			isUnk := false
-			unk, isUnk = types.MaybeMergeUnknowns(val, unk)
-			if !isUnk && err == nil {
-				if types.IsError(val) {
-					err = val
-				} else {
-					err = types.MaybeNoSuchOverloadErr(val)
-				}
-				err = types.LabelErrNode(and.id, err)
-			}
+		unk, err = mergeLogicalTerm(and.id, val, unk, err)
+	}
+	return logicalResult(unk, err, types.True)
+}
+
+// mergeLogicalTerm accumulates the unknowns and the first error produced by the non-boolean terms
+// of a logical operator.
+func mergeLogicalTerm(id int64, val ref.Val, unk *types.Unknown, err ref.Val) (*types.Unknown, ref.Val) {
+	if _, ok := val.(types.Bool); ok {
+		return unk, err
+	}
+	unk, isUnk := types.MaybeMergeUnknowns(val, unk)
+	if !isUnk && err == nil {
+		if types.IsError(val) {
+			err = val
+		} else {
+			err = types.MaybeNoSuchOverloadErr(val)
 		}
+		err = types.LabelErrNode(id, err)
 	}
+	return unk, err
+}
+
+// logicalResult returns the result of a logical operator which did not short-circuit: unknowns
+// take precedence over errors, and errors take precedence over the boolean result.
+func logicalResult(unk *types.Unknown, err ref.Val, result types.Bool) ref.Val {
 	if unk != nil {
 		return unk
 	}
 	if err != nil {
 		return err
 	}
-	return types.True
+	return result
 }
 
 type evalEq struct {
@@ -339,8 +335,11 @@ func (eq *evalEq) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (eq *evalEq) Eval(ctx Activation) ref.Val {
-	lVal := eq.lhs.Eval(ctx)
-	rVal := eq.rhs.Eval(ctx)
+	return eq.call(eq.lhs.Eval(ctx), eq.rhs.Eval(ctx))
+}
+
+// call compares the evaluated operands.
+func (eq *evalEq) call(lVal, rVal ref.Val) ref.Val {
 	if types.IsUnknownOrError(lVal) {
 		return lVal
 	}
@@ -378,8 +377,11 @@ func (ne *evalNe) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (ne *evalNe) Eval(ctx Activation) ref.Val {
-	lVal := ne.lhs.Eval(ctx)
-	rVal := ne.rhs.Eval(ctx)
+	return ne.call(ne.lhs.Eval(ctx), ne.rhs.Eval(ctx))
+}
+
+// call compares the evaluated operands.
+func (ne *evalNe) call(lVal, rVal ref.Val) ref.Val {
 	if types.IsUnknownOrError(lVal) {
 		return lVal
 	}
@@ -453,7 +455,11 @@ func (un *evalUnary) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (un *evalUnary) Eval(ctx Activation) ref.Val {
-	argVal := un.arg.Eval(ctx)
+	return un.call(un.arg.Eval(ctx))
+}
+
+// call invokes the function with the evaluated argument.
+func (un *evalUnary) call(argVal ref.Val) ref.Val {
 	// Early return if the argument to the function is unknown or error.
 	strict := !un.nonStrict
 	if strict && types.IsUnknownOrError(argVal) {
@@ -505,8 +511,11 @@ func (bin *evalBinary) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (bin *evalBinary) Eval(ctx Activation) ref.Val {
-	lVal := bin.lhs.Eval(ctx)
-	rVal := bin.rhs.Eval(ctx)
+	return bin.call(bin.lhs.Eval(ctx), bin.rhs.Eval(ctx))
+}
+
+// call invokes the function with the evaluated arguments.
+func (bin *evalBinary) call(lVal, rVal ref.Val) ref.Val {
 	// Early return if any argument to the function is unknown or error.
 	strict := !bin.nonStrict
 	if strict {
@@ -582,8 +591,15 @@ func (fn *evalVarArgs) Eval(ctx Activation) ref.Val {
 			return argVals[i]
 		}
 	}
+	return fn.call(argVals)
+}
+
+// call invokes the function with the evaluated arguments, which are known to be neither unknown
+// nor error when the function is strict.
+func (fn *evalVarArgs) call(argVals []ref.Val) ref.Val {
 	// If the implementation is bound and the argument value has the right traits required to
 	// invoke it, then call the implementation.
+	strict := !fn.nonStrict
 	arg0 := argVals[0]
 	if fn.impl != nil && (fn.trait == 0 || (!strict && types.IsUnknownOrError(arg0)) || arg0.Type().HasTrait(fn.trait)) {
 		return types.LabelErrNode(fn.id, fn.impl(argVals...))
@@ -611,6 +627,97 @@ func (fn *evalVarArgs) Args() []Interpretable {
 	return fn.args
 }
 
+// evalContextCall is a function call whose implementation receives the context of the evaluation.
+type evalContextCall struct {
+	id        int64
+	function  string
+	overload  string
+	args      []Interpretable
+	trait     int
+	impl      functions.ContextFunctionOp
+	nonStrict bool
+}
+
+// ID implements the Interpretable interface method.
+func (fn *evalContextCall) ID() int64 {
+	return fn.id
+}
+
+// Eval implements the Interpretable interface method.
+func (fn *evalContextCall) Eval(ctx Activation) ref.Val {
+	argVals := make([]ref.Val, len(fn.args))
+	// Early return if any argument to the function is unknown or error.
+	strict := !fn.nonStrict
+	for i, arg := range fn.args {
+		argVals[i] = arg.Eval(ctx)
+		if strict && types.IsUnknownOrError(argVals[i]) {
+			return argVals[i]
+		}
+	}
+	if len(argVals) == 0 {
+		return types.LabelErrNode(fn.id, callWithContext(ctx, fn.impl, argVals))
+	}
+	// If the argument value has the right traits required to invoke the implementation, then call
+	// the implementation.
+	arg0 := argVals[0]
+	if fn.trait == 0 || (!strict && types.IsUnknownOrError(arg0)) || arg0.Type().HasTrait(fn.trait) {
+		return types.LabelErrNode(fn.id, callWithContext(ctx, fn.impl, argVals))
+	}
+	// Otherwise, if the argument is a ReceiverType attempt to invoke the receiver method on the
+	// operand (arg0).
+	if arg0.Type().HasTrait(traits.ReceiverType) {
+		return types.LabelErrNode(fn.id, arg0.(traits.Receiver).Receive(fn.function, fn.overload, argVals[1:]))
+	}
+	return types.NewErrWithNodeID(fn.id, "no such overload: %s", fn.function)
+}
+
+// Function implements the InterpretableCall interface method.
+func (fn *evalContextCall) Function() string {
+	return fn.function
+}
+
+// OverloadID implements the InterpretableCall interface method.
+func (fn *evalContextCall) OverloadID() string {
+	return fn.overload
+}
+
+// Args returns the arguments to the function.
+func (fn *evalContextCall) Args() []Interpretable {
+	return fn.args
+}
+
+// evalInterruptableCall checks whether the evaluation has been interrupted before evaluating a
+// function call.
+type evalInterruptableCall struct {
+	InterpretableCall
+}
+
+// Eval implements the Interpretable interface method.
+func (fn *evalInterruptableCall) Eval(ctx Activation) ref.Val {
+	checkInterruptCancelled(ctx)
+	return fn.InterpretableCall.Eval(ctx)
+}
+
+// evalInterruptable reports interrupts which cancel the evaluation of an interruptable plan as an
+// `operation interrupted` error value.
+type evalInterruptable struct {
+	Interpretable
+}
+
+// Eval implements the Interpretable interface method.
+func (e *evalInterruptable) Eval(ctx Activation) (out ref.Val) {
+	defer func() {
+		if r := recover(); r != nil {
+			cancelled, ok := r.(EvalCancelledError)
+			if !ok || !errors.Is(cancelled, InterruptError{}) {
+				panic(r)
+			}
+			out = types.WrapErr(interruptedError{cancelled: cancelled})
+		}
+	}()
+	return e.Interpretable.Eval(ctx)
+}
+
 type evalList struct {
 	id           int64
 	elems        []Interpretable
@@ -629,25 +736,36 @@ func (l *evalList) Eval(ctx Activation) ref.Val {
 	elemVals := make([]ref.Val, 0, len(l.elems))
 	// If any argument is unknown or error early terminate.
 	for i, elem := range l.elems {
-		elemVal := elem.Eval(ctx)
-		if types.IsUnknownOrError(elemVal) {
-			return elemVal
+		elemVal, err := l.initElem(i, elem.Eval(ctx))
+		if err != nil {
+			return err
 		}
-		if l.hasOptionals && l.optionals[i] {
-			optVal, ok := elemVal.(*types.Optional)
-			if !ok {
-				return types.LabelErrNode(l.id, invalidOptionalElementInit(elemVal))
-			}
-			if !optVal.HasValue() {
-				continue
-			}
-			elemVal = optVal.GetValue()
+		if elemVal != nil {
+			elemVals = append(elemVals, elemVal)
 		}
-		elemVals = append(elemVals, elemVal)
 	}
 	return l.adapter.NativeToValue(elemVals)
 }
 
+// initElem returns the value of the list element at the given index, or nil if the element is an
+// optional without a value. An error or unknown is returned when the list cannot be constructed.
+func (l *evalList) initElem(i int, elemVal ref.Val) (ref.Val, ref.Val) {
+	if types.IsUnknownOrError(elemVal) {
+		return nil, elemVal
+	}
+	if l.hasOptionals && l.optionals[i] {
+		optVal, ok := elemVal.(*types.Optional)
+		if !ok {
+			return nil, types.LabelErrNode(l.id, invalidOptionalElementInit(elemVal))
+		}
+		if !optVal.HasValue() {
+			return nil, nil
+		}
+		elemVal = optVal.GetValue()
+	}
+	return elemVal, nil
+}
+
 func (l *evalList) InitVals() []Interpretable {
 	return l.elems
 }
@@ -679,26 +797,38 @@ func (m *evalMap) Eval(ctx Activation) ref.Val {
 		if types.IsUnknownOrError(keyVal) {
 			return keyVal
 		}
-		valVal := m.vals[i].Eval(ctx)
-		if types.IsUnknownOrError(valVal) {
-			return valVal
+		valVal, err := m.initValue(i, keyVal, m.vals[i].Eval(ctx))
+		if err != nil {
+			return err
 		}
-		if m.hasOptionals && m.optionals[i] {
-			optVal, ok := valVal.(*types.Optional)
-			if !ok {
-				return types.LabelErrNode(m.id, invalidOptionalEntryInit(keyVal, valVal))
-			}
-			if !optVal.HasValue() {
-				delete(entries, keyVal)
-				continue
-			}
-			valVal = optVal.GetValue()
+		if valVal == nil {
+			delete(entries, keyVal)
+			continue
 		}
 		entries[keyVal] = valVal
 	}
 	return m.adapter.NativeToValue(entries)
 }
 
+// initValue returns the value of the map entry at the given index, or nil if the entry is an
+// optional without a value. An error or unknown is returned when the map cannot be constructed.
+func (m *evalMap) initValue(i int, keyVal, valVal ref.Val) (ref.Val, ref.Val) {
+	if types.IsUnknownOrError(valVal) {
+		return nil, valVal
+	}
+	if m.hasOptionals && m.optionals[i] {
+		optVal, ok := valVal.(*types.Optional)
+		if !ok {
+			return nil, types.LabelErrNode(m.id, invalidOptionalEntryInit(keyVal, valVal))
+		}
+		if !optVal.HasValue() {
+			return nil, nil
+		}
+		valVal = optVal.GetValue()
+	}
+	return valVal, nil
+}
+
 func (m *evalMap) InitVals() []Interpretable {
 	if len(m.keys) != len(m.vals) {
 		return nil
@@ -739,23 +869,40 @@ func (o *evalObj) Eval(ctx Activation) ref.Val {
 	fieldVals := make(map[string]ref.Val)
 	// If any argument is unknown or error early terminate.
 	for i, field := range o.fields {
-		val := o.vals[i].Eval(ctx)
-		if types.IsUnknownOrError(val) {
-			return val
+		val, err := o.initField(i, o.vals[i].Eval(ctx))
+		if err != nil {
+			return err
 		}
-		if o.hasOptionals && o.optionals[i] {
-			optVal, ok := val.(*types.Optional)
-			if !ok {
-				return types.LabelErrNode(o.id, invalidOptionalEntryInit(field, val))
-			}
-			if !optVal.HasValue() {
-				delete(fieldVals, field)
-				continue
-			}
-			val = optVal.GetValue()
+		if val == nil {
+			delete(fieldVals, field)
+			continue
 		}
 		fieldVals[field] = val
 	}
+	return o.newValue(fieldVals)
+}
+
+// initField returns the value of the field at the given index, or nil if the field is an optional
+// without a value. An error or unknown is returned when the object cannot be constructed.
+func (o *evalObj) initField(i int, val ref.Val) (ref.Val, ref.Val) {
+	if types.IsUnknownOrError(val) {
+		return nil, val
+	}
+	if o.hasOptionals && o.optionals[i] {
+		optVal, ok := val.(*types.Optional)
+		if !ok {
+			return nil, types.LabelErrNode(o.id, invalidOptionalEntryInit(o.fields[i], val))
+		}
+		if !optVal.HasValue() {
+			return nil, nil
+		}
+		val = optVal.GetValue()
+	}
+	return val, nil
+}
+
+// newValue creates the object from the initialized field values.
+func (o *evalObj) newValue(fieldVals map[string]ref.Val) ref.Val {
 	return types.LabelErrNode(o.id, o.provider.NewValue(o.typeName, fieldVals))
 }
 
@@ -786,6 +933,12 @@ type evalFold struct {
 	// rather than make a throw-away computation.
 	exhaustive    bool
 	interruptable bool
+
+	// parallel is set when the comprehension may be evaluated across multiple goroutines.
+	parallel *parallelFold
+
+	// trackIterations is set when the evaluation state of each iteration is recorded.
+	trackIterations bool
 }
 
 // ID implements the Interpretable interface method.
@@ -803,6 +956,14 @@ func (fold *evalFold) Eval(ctx Activation) ref.Val {
 	if types.IsUnknownOrError(foldRange) {
 		return foldRange
 	}
+	if fold.trackIterations {
+		f.initIterationState()
+	}
+	if fold.parallel != nil {
+		if res, ok := fold.evalParallel(ctx, foldRange); ok {
+			return res
+		}
+	}
 	if fold.iterVar2 != "" {
 		var foldable traits.Foldable
 		switch r := foldRange.(type) {
@@ -842,7 +1003,11 @@ func (e *evalSetMembership) ID() int64 {
 
 // Eval implements the Interpretable interface method.
 func (e *evalSetMembership) Eval(ctx Activation) ref.Val {
-	val := e.arg.Eval(ctx)
+	return e.contains(e.arg.Eval(ctx))
+}
+
+// contains tests whether the evaluated argument is a member of the set.
+func (e *evalSetMembership) contains(val ref.Val) ref.Val {
 	if types.IsUnknownOrError(val) {
 		return val
 	}
@@ -856,23 +1021,64 @@ func (e *evalSetMembership) Eval(ctx Activation) ref.Val {
 // expression so that it may observe the computed value and send it to an observer.
 type evalWatch struct {
 	Interpretable
-	observer EvalObserver
+	observers stepObservers
 }
 
 // Eval implements the Interpretable interface method.
 func (e *evalWatch) Eval(vars Activation) ref.Val {
+	e.observers.enter(vars, e.ID(), e.Interpretable)
 	val := e.Interpretable.Eval(vars)
-	e.observer(vars, e.ID(), e.Interpretable, val)
+	e.observers.observe(vars, e.ID(), e.Interpretable, val)
 	return val
 }
 
+// stepObserver pairs the functions used to observe the start and the result of an evaluation step.
+type stepObserver struct {
+	// enter is optional, and when set is invoked prior to the evaluation of an Interpretable with
+	// child steps.
+	enter func(vars Activation, id int64, programStep any)
+	// exit is invoked with the result of each evaluation step.
+	exit EvalObserver
+}
+
+// stepObservers is the set of observers which watch a given program step.
+type stepObservers []*stepObserver
+
+// with returns a copy of the observer set which includes the given observer, if not already present.
+func (so stepObservers) with(obs *stepObserver) stepObservers {
+	for _, o := range so {
+		if o == obs {
+			return so
+		}
+	}
+	out := make(stepObservers, len(so), len(so)+1)
+	copy(out, so)
+	return append(out, obs)
+}
+
+// enter notifies the observers that evaluation of the program step has started.
+func (so stepObservers) enter(vars Activation, id int64, programStep any) {
+	for _, o := range so {
+		if o.enter != nil {
+			o.enter(vars, id, programStep)
+		}
+	}
+}
+
+// observe notifies the observers of the result of the program step.
+func (so stepObservers) observe(vars Activation, id int64, programStep any, val ref.Val) {
+	for _, o := range so {
+		o.exit(vars, id, programStep, val)
+	}
+}
+
 // evalWatchAttr describes a watcher of an InterpretableAttribute Interpretable.
 //
 // Since the watcher may be selected against at a later stage in program planning, the watcher
 // must implement the InterpretableAttribute interface by proxy.
 type evalWatchAttr struct {
 	InterpretableAttribute
-	observer EvalObserver
+	observers stepObservers
 }
 
 // AddQualifier creates a wrapper over the incoming qualifier which observes the qualification
@@ -885,7 +1091,7 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 		// Expose a method to test whether the qualifier matches the input pattern.
 		q = &evalWatchConstQual{
 			ConstantQualifier: qual,
-			observer:          e.observer,
+			observers:         e.observers,
 			adapter:           e.Adapter(),
 		}
 	case *evalWatchAttr:
@@ -893,7 +1099,7 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 		// QualifyIfPresent rather than Eval.
 		q = &evalWatchAttrQual{
 			Attribute: qual.InterpretableAttribute,
-			observer:  e.observer,
+			observers: e.observers,
 			adapter:   e.Adapter(),
 		}
 	case Attribute:
@@ -903,14 +1109,14 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 		// needed to trip the conversion to a constant.
 		q = &evalWatchAttrQual{
 			Attribute: qual,
-			observer:  e.observer,
+			observers: e.observers,
 			adapter:   e.Adapter(),
 		}
 	default:
 		// This is likely a custom qualifier type.
 		q = &evalWatchQual{
 			Qualifier: qual,
-			observer:  e.observer,
+			observers: e.observers,
 			adapter:   e.Adapter(),
 		}
 	}
@@ -920,8 +1126,9 @@ func (e *evalWatchAttr) AddQualifier(q Qualifier) (Attribute, error) {
 
 // Eval implements the Interpretable interface method.
 func (e *evalWatchAttr) Eval(vars Activation) ref.Val {
+	e.observers.enter(vars, e.ID(), e.InterpretableAttribute)
 	val := e.InterpretableAttribute.Eval(vars)
-	e.observer(vars, e.ID(), e.InterpretableAttribute, val)
+	e.observers.observe(vars, e.ID(), e.InterpretableAttribute, val)
 	return val
 }
 
@@ -929,8 +1136,8 @@ func (e *evalWatchAttr) Eval(vars Activation) ref.Val {
 // string, or uint.
 type evalWatchConstQual struct {
 	ConstantQualifier
-	observer EvalObserver
-	adapter  types.Adapter
+	observers stepObservers
+	adapter   types.Adapter
 }
 
 // Qualify observes the qualification of a object via a constant boolean, int, string, or uint.
@@ -942,7 +1149,7 @@ func (e *evalWatchConstQual) Qualify(vars Activation, obj any) (any, error) {
 	} else {
 		val = e.adapter.NativeToValue(out)
 	}
-	e.observer(vars, e.ID(), e.ConstantQualifier, val)
+	e.observers.observe(vars, e.ID(), e.ConstantQualifier, val)
 	return out, err
 }
 
@@ -958,7 +1165,7 @@ func (e *evalWatchConstQual) QualifyIfPresent(vars Activation, obj any, presence
 		val = types.Bool(present)
 	}
 	if present || presenceOnly {
-		e.observer(vars, e.ID(), e.ConstantQualifier, val)
+		e.observers.observe(vars, e.ID(), e.ConstantQualifier, val)
 	}
 	return out, present, err
 }
@@ -972,8 +1179,8 @@ func (e *evalWatchConstQual) QualifierValueEquals(value any) bool {
 // evalWatchAttrQual observes the qualification of an object by a value computed at runtime.
 type evalWatchAttrQual struct {
 	Attribute
-	observer EvalObserver
-	adapter  ref.TypeAdapter
+	observers stepObservers
+	adapter   ref.TypeAdapter
 }
 
 // Qualify observes the qualification of a object via a value computed at runtime.
@@ -985,7 +1192,7 @@ func (e *evalWatchAttrQual) Qualify(vars Activation, obj any) (any, error) {
 	} else {
 		val = e.adapter.NativeToValue(out)
 	}
-	e.observer(vars, e.ID(), e.Attribute, val)
+	e.observers.observe(vars, e.ID(), e.Attribute, val)
 	return out, err
 }
 
@@ -1001,7 +1208,7 @@ func (e *evalWatchAttrQual) QualifyIfPresent(vars Activation, obj any, presenceO
 		val = types.Bool(present)
 	}
 	if present || presenceOnly {
-		e.observer(vars, e.ID(), e.Attribute, val)
+		e.observers.observe(vars, e.ID(), e.Attribute, val)
 	}
 	return out, present, err
 }
@@ -1009,8 +1216,8 @@ func (e *evalWatchAttrQual) QualifyIfPresent(vars Activation, obj any, presenceO
 // evalWatchQual observes the qualification of an object by a value computed at runtime.
 type evalWatchQual struct {
 	Qualifier
-	observer EvalObserver
-	adapter  types.Adapter
+	observers stepObservers
+	adapter   types.Adapter
 }
 
 // Qualify observes the qualification of a object via a value computed at runtime.
@@ -1022,7 +1229,7 @@ func (e *evalWatchQual) Qualify(vars Activation, obj any) (any, error) {
 	} else {
 		val = e.adapter.NativeToValue(out)
 	}
-	e.observer(vars, e.ID(), e.Qualifier, val)
+	e.observers.observe(vars, e.ID(), e.Qualifier, val)
 	return out, err
 }
 
@@ -1038,7 +1245,7 @@ func (e *evalWatchQual) QualifyIfPresent(vars Activation, obj any, presenceOnly
 		val = types.Bool(present)
 	}
 	if present || presenceOnly {
-		e.observer(vars, e.ID(), e.Qualifier, val)
+		e.observers.observe(vars, e.ID(), e.Qualifier, val)
 	}
 	return out, present, err
 }
@@ -1046,13 +1253,13 @@ func (e *evalWatchQual) QualifyIfPresent(vars Activation, obj any, presenceOnly
 // evalWatchConst describes a watcher of an instConst Interpretable.
 type evalWatchConst struct {
 	InterpretableConst
-	observer EvalObserver
+	observers stepObservers
 }
 
 // Eval implements the Interpretable interface method.
 func (e *evalWatchConst) Eval(vars Activation) ref.Val {
 	val := e.Value()
-	e.observer(vars, e.ID(), e.InterpretableConst, val)
+	e.observers.observe(vars, e.ID(), e.InterpretableConst, val)
 	return val
 }
 
@@ -1217,7 +1424,12 @@ func (a *evalAttr) Adapter() types.Adapter {
 
 // Eval implements the Interpretable interface method.
 func (a *evalAttr) Eval(ctx Activation) ref.Val {
-	v, err := a.attr.Resolve(ctx)
+	return a.result(a.attr.Resolve(ctx))
+}
+
+// result converts the resolved attribute value, or the error which occurred during its resolution,
+// into the result of the Interpretable.
+func (a *evalAttr) result(v any, err error) ref.Val {
 	if err != nil {
 		return types.LabelErrNode(a.ID(), types.WrapErr(err))
 	}
@@ -1245,7 +1457,7 @@ func (a *evalAttr) Resolve(ctx Activation) (any, error) {
 
 type evalWatchConstructor struct {
 	constructor InterpretableConstructor
-	observer    EvalObserver
+	observers   stepObservers
 }
 
 // InitVals implements the InterpretableConstructor InitVals function.
@@ -1265,8 +1477,9 @@ func (c *evalWatchConstructor) ID() int64 {
 
 // Eval implements the Interpretable Eval function.
 func (c *evalWatchConstructor) Eval(vars Activation) ref.Val {
+	c.observers.enter(vars, c.ID(), c.constructor)
 	val := c.constructor.Eval(vars)
-	c.observer(vars, c.ID(), c.constructor, val)
+	c.observers.observe(vars, c.ID(), c.constructor, val)
 	return val
 }
 
@@ -1310,10 +1523,14 @@ type folder struct {
 	iterVar1Val any
 	iterVar2Val any
 
+	// iteration state tracking objects, set when the evaluation state of each iteration is recorded.
+	iterRecorder *evalState
+	iterParent   EvalState
+	iterState    *iterationEvalState
+
 	// bookkeeping flags to modify Activation and fold behaviors.
 	initialized   bool
 	mutableValue  bool
-	interrupted   bool
 	computeResult bool
 }
 
@@ -1321,19 +1538,21 @@ func (f *folder) foldIterable(iterable traits.Iterable) ref.Val {
 	it := iterable.Iterator()
 	for it.HasNext() == types.True {
 		f.iterVar1Val = it.Next()
+		if f.iterRecorder != nil {
+			f.beginIteration()
+		}
 
 		cond := f.cond.Eval(f)
 		condBool, ok := cond.(types.Bool)
-		if f.interrupted || (!f.exhaustive && ok && condBool != types.True) {
+		if !f.exhaustive && ok && condBool != types.True {
 			return f.evalResult()
 		}
 
 		// Update the accumulation value and check for eval interuption.
 		f.accuVal = f.step.Eval(f)
 		f.initialized = true
-		if f.interruptable && checkInterrupt(f.activation) {
-			f.interrupted = true
-			return f.evalResult()
+		if f.interruptable {
+			checkInterruptCancelled(f.activation)
 		}
 	}
 	return f.evalResult()
@@ -1345,51 +1564,72 @@ func (f *folder) FoldEntry(key, val any) bool {
 	// Default to referencing both values.
 	f.iterVar1Val = key
 	f.iterVar2Val = val
+	if f.iterRecorder != nil {
+		f.beginIteration()
+	}
 
-	// Terminate evaluation if evaluation is interrupted or the condition is not true and exhaustive
-	// eval is not enabled.
+	// Terminate evaluation if the condition is not true and exhaustive eval is not enabled.
 	cond := f.cond.Eval(f)
 	condBool, ok := cond.(types.Bool)
-	if f.interrupted || (!f.exhaustive && ok && condBool != types.True) {
+	if !f.exhaustive && ok && condBool != types.True {
 		return false
 	}
 
 	// Update the accumulation value and check for eval interuption.
 	f.accuVal = f.step.Eval(f)
 	f.initialized = true
-	if f.interruptable && checkInterrupt(f.activation) {
-		f.interrupted = true
-		return false
+	if f.interruptable {
+		checkInterruptCancelled(f.activation)
 	}
 	return true
 }
 
+// initIterationState locates the evaluation state in which the iterations of the comprehension are
+// recorded, clearing the iterations recorded by any prior evaluation of the comprehension.
+func (f *folder) initIterationState() {
+	state, found := asEvalState(f.activation)
+	if !found {
+		return
+	}
+	recorder, ok := iterationStateOf(state)
+	if !ok {
+		return
+	}
+	recorder.beginFold(f.id)
+	f.iterRecorder = recorder
+	f.iterParent = state
+}
+
+// beginIteration records a new iteration of the comprehension whose observed values are recorded
+// within the iteration state as well as the enclosing evaluation state.
+func (f *folder) beginIteration() {
+	var iterVar2 ref.Val
+	if f.iterVar2 != "" {
+		iterVar2 = f.adapter.NativeToValue(f.iterVar2Val)
+	}
+	iter := f.iterRecorder.recordIteration(f.id, f.adapter.NativeToValue(f.iterVar1Val), iterVar2)
+	f.iterState = &iterationEvalState{evalState: iter, parent: f.iterParent}
+}
+
+// asEvalState implements the evalStateConverter method, returning the state of the current
+// iteration when iteration state is tracked.
+func (f *folder) asEvalState() EvalState {
+	if f.iterState == nil {
+		return nil
+	}
+	return f.iterState
+}
+
 // ResolveName overrides the default Activation lookup to perform lazy initialization of the accumulator
 // and specialized lookups of iteration values with consideration for whether the final result is being
 // computed and the iteration variables should be ignored.
 func (f *folder) ResolveName(name string) (any, bool) {
 	if name == f.accuVar {
-		if !f.initialized {
-			f.initialized = true
-			initVal := f.accu.Eval(f.activation)
-			if !f.exhaustive {
-				if l, isList := initVal.(traits.Lister); isList && l.Size() == types.IntZero {
-					initVal = types.NewMutableList(f.adapter)
-					f.mutableValue = true
-				}
-				if m, isMap := initVal.(traits.Mapper); isMap && m.Size() == types.IntZero {
-					initVal = types.NewMutableMap(f.adapter, map[ref.Val]ref.Val{})
-					f.mutableValue = true
-				}
-			}
-			f.accuVal = initVal
-		}
-		return f.accuVal, true
+		return f.accuValue(), true
 	}
 	if !f.computeResult {
 		if name == f.iterVar {
-			f.iterVar1Val = f.adapter.NativeToValue(f.iterVar1Val)
-			return f.iterVar1Val, true
+			return f.iterValue(), true
 		}
 		if name == f.iterVar2 {
 			f.iterVar2Val = f.adapter.NativeToValue(f.iterVar2Val)
@@ -1399,6 +1639,48 @@ func (f *folder) ResolveName(name string) (any, bool) {
 	return f.activation.ResolveName(name)
 }
 
+// accuValue returns the accumulator value, initializing the accumulator on first use.
+func (f *folder) accuValue() ref.Val {
+	if !f.initialized {
+		f.initAccu(f.accu.Eval(f.activation))
+	}
+	return f.accuVal
+}
+
+// iterValue returns the value of the first iteration variable.
+func (f *folder) iterValue() ref.Val {
+	v := f.adapter.NativeToValue(f.iterVar1Val)
+	f.iterVar1Val = v
+	return v
+}
+
+// initAccu sets the initial accumulator value, substituting a mutable value for an empty list or
+// map when the fold is not exhaustive.
+func (f *folder) initAccu(initVal ref.Val) {
+	f.initialized = true
+	if !f.exhaustive {
+		if l, isList := initVal.(traits.Lister); isList && l.Size() == types.IntZero {
+			initVal = types.NewMutableList(f.adapter)
+			f.mutableValue = true
+		}
+		if m, isMap := initVal.(traits.Mapper); isMap && m.Size() == types.IntZero {
+			initVal = types.NewMutableMap(f.adapter, map[ref.Val]ref.Val{})
+			f.mutableValue = true
+		}
+	}
+	f.accuVal = initVal
+}
+
+// ResolveNameWithError implements the ErrorResolver interface method, proxying lookups of names
+// other than the accumulator and iteration variables to the backing activation.
+func (f *folder) ResolveNameWithError(name string) (any, bool, error) {
+	if name == f.accuVar || (!f.computeResult && (name == f.iterVar || name == f.iterVar2)) {
+		obj, found := f.ResolveName(name)
+		return obj, found, nil
+	}
+	return ResolveNameWithError(f.activation, name)
+}
+
 // Parent returns the activation embedded into the folder.
 func (f *folder) Parent() Activation {
 	return f.activation
@@ -1432,12 +1714,13 @@ func (f *folder) AsPartialActivation() (PartialActivation, bool) {
 // evalResult computes the final result of the fold after all entries have been folded and accumulated.
 func (f *folder) evalResult() ref.Val {
 	f.computeResult = true
-	if f.interrupted {
-		return types.WrapErr(InterruptError{})
-	}
-	res := f.result.Eval(f)
-	// Convert a mutable list or map to an immutable one if the comprehension has generated a list or
-	// map as a result.
+	f.iterState = nil
+	return f.immutableResult(f.result.Eval(f))
+}
+
+// immutableResult converts a mutable list or map to an immutable one if the comprehension has
+// generated a list or map as a result.
+func (f *folder) immutableResult(res ref.Val) ref.Val {
 	if !types.IsUnknownOrError(res) && f.mutableValue {
 		if _, ok := res.(traits.MutableLister); ok {
 			res = res.(traits.MutableLister).ToImmutableList()
@@ -1456,10 +1739,12 @@ func (f *folder) reset() {
 	f.accuVal = nil
 	f.iterVar1Val = nil
 	f.iterVar2Val = nil
+	f.iterRecorder = nil
+	f.iterParent = nil
+	f.iterState = nil
 
 	f.initialized = false
 	f.mutableValue = false
-	f.interrupted = false
 	f.computeResult = false
 }
 
@@ -1468,8 +1753,80 @@ func checkInterrupt(a Activation) bool {
 	return found && stop == true
 }
 
+// checkInterruptCancelled cancels the evaluation with an EvalCancelledError when the `#interrupted`
+// state of the Activation indicates that the evaluation has been interrupted.
+//
+// The error wraps an InterruptError along with the cause of the cancellation of the evaluation
+// context, if known.
+func checkInterruptCancelled(a Activation) {
+	if !checkInterrupt(a) {
+		return
+	}
+	var err error = InterruptError{}
+	if cause := context.Cause(evalContext(a)); cause != nil {
+		err = fmt.Errorf("%w: %w", err, cause)
+	}
+	panic(EvalCancelledError{
+		Message: fmt.Sprintf("operation cancelled: %v", err),
+		Cause:   ContextCancelled,
+		err:     err,
+	})
+}
+
+// evalContext returns the context.Context of the evaluation which is exposed by the Activation as
+// the `#context` variable, or context.Background() if the evaluation has no context.
+func evalContext(a Activation) context.Context {
+	if obj, found := a.ResolveName("#context"); found {
+		if ctx, ok := obj.(context.Context); ok {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// callWithContext invokes the function with the context of the evaluation, cancelling the
+// evaluation with an EvalCancelledError if the context is done either before or after the call.
+func callWithContext(a Activation, impl functions.ContextFunctionOp, args []ref.Val) ref.Val {
+	ctx := evalContext(a)
+	checkContextCancelled(ctx)
+	out := impl(ctx, args...)
+	checkContextCancelled(ctx)
+	return out
+}
+
+func checkContextCancelled(ctx context.Context) {
+	if ctx.Err() == nil {
+		return
+	}
+	cause := context.Cause(ctx)
+	panic(EvalCancelledError{
+		Message: fmt.Sprintf("operation cancelled: %v", cause),
+		Cause:   ContextCancelled,
+		err:     cause,
+	})
+}
+
+// interruptedError is the `operation interrupted` error which retains the EvalCancelledError with
+// which an interruptable evaluation was cancelled.
+type interruptedError struct {
+	cancelled EvalCancelledError
+}
+
+// Error returns operation interrupted.
+func (interruptedError) Error() string {
+	return InterruptError{}.Error()
+}
+
+// Unwrap returns the EvalCancelledError with which the evaluation was cancelled.
+func (e interruptedError) Unwrap() error {
+	return e.cancelled
+}
+
 // InterruptError is a specialized error type used to signal that program evaluation should check
 // whether a context cancellation is responsible for the error.
+//
+// Interrupted evaluations return an error value which wraps both an InterruptError and the
+// EvalCancelledError with which the evaluation was cancelled.
 type InterruptError struct{}
 
 // Error returns operation interrupted.
@@ -60,12 +60,20 @@ type EvalCancelledError struct {
 	Message string
 	// Type identifies the cause of the cancellation.
 	Cause CancellationCause
+
+	// err is the underlying error, such as the cause of a context cancellation, if known.
+	err error
 }
 
 func (e EvalCancelledError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the error which caused the cancellation, if known.
+func (e EvalCancelledError) Unwrap() error {
+	return e.err
+}
+
 // CancellationCause enumerates the ways a program evaluation operation can be cancelled.
 type CancellationCause int
 
@@ -76,6 +84,10 @@ const (
 	// CostLimitExceeded indicates that the operation was cancelled in response to the actual cost limit being
 	// exceeded.
 	CostLimitExceeded
+
+	// MemoryLimitExceeded indicates that the operation was cancelled in response to the memory allocated during
+	// evaluation exceeding the configured limit.
+	MemoryLimitExceeded
 )
 
 // evalStateOption configures the evalStateFactory behavior.
@@ -122,6 +134,11 @@ func (esa evalStateActivation) ResolveName(name string) (any, bool) {
 	return esa.vars.ResolveName(name)
 }
 
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (esa evalStateActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(esa.vars, name)
+}
+
 // Parent proxies parent lookups to the backing activation.
 func (esa evalStateActivation) Parent() Activation {
 	return esa.vars
@@ -147,7 +164,9 @@ type activationWrapper interface {
 // asEvalState walks the Activation hierarchy and returns the first EvalState found, if present.
 func asEvalState(vars Activation) (EvalState, bool) {
 	if conv, ok := vars.(evalStateConverter); ok {
-		return conv.asEvalState(), true
+		if state := conv.asEvalState(); state != nil {
+			return state, true
+		}
 	}
 	// Check if the current activation wraps another activation. This is used to support
 	// wrappers such as the @block() activation which may be composed of a dynamicSlotActivation or a
@@ -210,13 +229,23 @@ func ExhaustiveEval() PlannerOption {
 	return CustomDecorator(decDisableShortcircuits())
 }
 
-// InterruptableEval annotates comprehension loops with information that indicates they
-// should check the `#interrupted` state within a custom Activation.
+// InterruptableEval annotates comprehension loops and function calls with information that
+// indicates they should check the `#interrupted` state within a custom Activation. Comprehensions
+// check the state after each iteration and function calls check the state before dispatch.
+//
+// Interrupted evaluations return the `operation interrupted` error value, which wraps both an
+// InterruptError and an EvalCancelledError whose cause is ContextCancelled. Since decorators which
+// replace function calls, such as Optimize and CompileRegexConstants, drop the interrupt checks of
+// the calls they replace, this option should be configured after them.
 //
 // The custom activation is currently managed higher up in the stack within the 'cel' package
 // and should not require any custom support on behalf of callers.
 func InterruptableEval() PlannerOption {
-	return CustomDecorator(decInterruptFolds())
+	return func(p *planner) (*planner, error) {
+		p.decorators = append(p.decorators, decInterrupts())
+		p.interruptable = true
+		return p, nil
+	}
 }
 
 // Optimize will pre-compute operations such as list and map construction and optimize
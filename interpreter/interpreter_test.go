@@ -1764,7 +1764,11 @@ func TestInterpreter(t *testing.T) {
 				"optimize": {Optimize()},
 				"exhaustive": {ExhaustiveEval(),
 					EvalStateObserver(EvalStateFactory(func() EvalState { return state }))},
-				"track": {EvalStateObserver(EvalStateFactory(func() EvalState { return state }))},
+				"track":             {EvalStateObserver(EvalStateFactory(func() EvalState { return state }))},
+				"bytecode":          {BytecodeEval()},
+				"bytecode-optimize": {BytecodeEval(), Optimize()},
+				"bytecode-track": {BytecodeEval(),
+					EvalStateObserver(EvalStateFactory(func() EvalState { return state }))},
 			}
 			for mode, opt := range opts {
 				opts := opt
@@ -1953,6 +1957,44 @@ func TestInterpreter_InterruptableEval(t *testing.T) {
 	if !types.IsError(out) || out.(*types.Err).String() != "operation interrupted" {
 		t.Errorf("Got %v, wanted operation interrupted error", out)
 	}
+	if !isInterruptCancelled(out) {
+		t.Errorf("Got %v, wanted error wrapping the interrupt cancellation", out)
+	}
+}
+
+func TestInterpreter_InterruptableCalls(t *testing.T) {
+	tc := testCase{
+		expr: `s.startsWith('a') || s.endsWith('a')`,
+		vars: []*decls.VariableDecl{
+			decls.NewVariable("s", types.StringType),
+		},
+		in: map[string]any{
+			"s":            "banana",
+			"#interrupted": true,
+		},
+	}
+	for _, opts := range [][]PlannerOption{{}, {BytecodeEval()}} {
+		prg, vars, err := program(t, &tc, append(opts, InterruptableEval())...)
+		if err != nil {
+			t.Fatalf("program(%s) failed: %v", tc.expr, err)
+		}
+		// The interrupt is not absorbed by the logical-or.
+		out := prg.Eval(vars)
+		if !isInterruptCancelled(out) {
+			t.Errorf("Got %v, wanted operation interrupted error", out)
+		}
+	}
+}
+
+// isInterruptCancelled returns whether the value is an interrupt error which wraps the
+// EvalCancelledError with which the evaluation was cancelled.
+func isInterruptCancelled(out ref.Val) bool {
+	err, ok := out.(*types.Err)
+	if !ok {
+		return false
+	}
+	var cancelled EvalCancelledError
+	return errors.As(err, &cancelled) && cancelled.Cause == ContextCancelled && errors.Is(err, InterruptError{})
 }
 
 type contextActivation struct {
@@ -2445,6 +2487,42 @@ func newTestPartialActivation(t testing.TB, in any, unknowns ...*AttributePatter
 	return vars
 }
 
+func newTestActivation(t testing.TB, in map[string]any) Activation {
+	t.Helper()
+	vars, err := NewActivation(in)
+	if err != nil {
+		t.Fatalf("NewActivation(%v) failed: %v", in, err)
+	}
+	return vars
+}
+
+// newTestInterpretable parses the expression with the standard macros and plans it against the
+// standard library using the given planner options.
+func newTestInterpretable(t *testing.T, expr string, opts ...PlannerOption) Interpretable {
+	t.Helper()
+	parsed := mustParseWithMacros(t, expr)
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg)
+	intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+	i, err := intr.NewInterpretable(parsed, opts...)
+	if err != nil {
+		t.Fatalf("NewInterpretable(%q) failed: %v", expr, err)
+	}
+	return i
+}
+
+// evalResultsEqual reports whether two evaluation results are equal, treating errors as equal
+// when they have the same message and source expression id.
+func evalResultsEqual(got, want ref.Val) bool {
+	if types.IsError(got) || types.IsError(want) {
+		return types.IsError(got) && types.IsError(want) &&
+			got.(*types.Err).String() == want.(*types.Err).String() &&
+			got.(*types.Err).NodeID() == want.(*types.Err).NodeID()
+	}
+	return got.Equal(want) == types.True
+}
+
 // newStandardInterpreter builds a Dispatcher and TypeProvider with support for all of the CEL
 // builtins defined in the language definition.
 func newStandardInterpreter(t *testing.T,
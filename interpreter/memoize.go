@@ -0,0 +1,313 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// maxMemoArgs is the maximum number of arguments to a deterministic function whose result may be
+// memoized.
+const maxMemoArgs = 3
+
+// FunctionResultCache is a bounded, concurrency-safe cache of the results of calls to deterministic
+// function overloads which is shared by all evaluations of a program.
+//
+// Only calls whose arguments are null, bool, int, uint, double, string, bytes, duration, or
+// timestamp values are memoized, except for calls with NaN arguments. Errors and unknowns produced by a call are never cached. When the
+// cache is full, the least recently used result is evicted.
+type FunctionResultCache struct {
+	capacity int
+	stats    FunctionCacheStats
+
+	mu      sync.Mutex
+	entries map[memoKey]*list.Element
+	lru     *list.List
+}
+
+// NewFunctionResultCache creates a FunctionResultCache which holds at most capacity results.
+func NewFunctionResultCache(capacity int) (*FunctionResultCache, error) {
+	if capacity <= 0 {
+		return nil, errors.New("function result cache capacity must be positive")
+	}
+	return &FunctionResultCache{
+		capacity: capacity,
+		entries:  make(map[memoKey]*list.Element),
+		lru:      list.New(),
+	}, nil
+}
+
+// Len returns the number of results held by the cache.
+func (c *FunctionResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the hits and misses of the cache across all evaluations.
+func (c *FunctionResultCache) Stats() *FunctionCacheStats {
+	return &c.stats
+}
+
+func (c *FunctionResultCache) get(key memoKey) (ref.Val, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoEntry).val, true
+}
+
+func (c *FunctionResultCache) put(key memoKey, val ref.Val) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, found := c.entries[key]; found {
+		elem.Value.(*memoEntry).val = val
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&memoEntry{key: key, val: val})
+	if c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoEntry).key)
+	}
+}
+
+// FunctionCacheStats records the number of calls to deterministic functions which were served
+// from a FunctionResultCache, and the number of calls which invoked the function implementation.
+//
+// Calls whose arguments cannot be memoized are not counted.
+type FunctionCacheStats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Hits returns the number of calls served from the cache.
+func (s *FunctionCacheStats) Hits() uint64 {
+	return s.hits.Load()
+}
+
+// Misses returns the number of calls which invoked the function implementation.
+func (s *FunctionCacheStats) Misses() uint64 {
+	return s.misses.Load()
+}
+
+// HitRate returns the fraction of calls served from the cache, or zero if no calls were made.
+func (s *FunctionCacheStats) HitRate() float64 {
+	hits := s.Hits()
+	total := hits + s.Misses()
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// MemoizeDeterministicCalls configures the results of calls to overloads marked as Deterministic to
+// be memoized within the given cache.
+//
+// The hits and misses of each evaluation are reported as *FunctionCacheStats via the observed
+// state of the evaluation.
+func MemoizeDeterministicCalls(cache *FunctionResultCache) PlannerOption {
+	return func(p *planner) (*planner, error) {
+		if cache == nil {
+			return nil, errors.New("function result cache not configured")
+		}
+		p.memoCache = cache
+		p.observers = append(p.observers, memoStatsFactory{})
+		return p, nil
+	}
+}
+
+// memoKey identifies the result of a call to an overload with a set of arguments.
+type memoKey struct {
+	overload string
+	argCount int
+	args     [maxMemoArgs]any
+}
+
+type memoEntry struct {
+	key memoKey
+	val ref.Val
+}
+
+// memoBytes, memoDouble, memoDuration, and memoTimestamp distinguish the comparable key values of
+// bytes, double, duration, and timestamp arguments from the key values of other argument types.
+type memoBytes string
+
+// memoDouble holds the bits of a double so that 0.0 and -0.0 are distinct keys.
+type memoDouble uint64
+
+type memoDuration int64
+
+type memoTimestamp struct {
+	seconds int64
+	nanos   int
+}
+
+// newMemoKey returns the key of a call to the overload, or false if the arguments cannot be memoized.
+func newMemoKey(overload string, args []ref.Val) (memoKey, bool) {
+	key := memoKey{overload: overload, argCount: len(args)}
+	if len(args) > maxMemoArgs {
+		return key, false
+	}
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case types.Null, types.Bool, types.Int, types.Uint, types.String:
+			key.args[i] = v
+		case types.Double:
+			// NaN is not equal to itself, so a key containing it could never be found or evicted.
+			if math.IsNaN(float64(v)) {
+				return key, false
+			}
+			key.args[i] = memoDouble(math.Float64bits(float64(v)))
+		case types.Bytes:
+			key.args[i] = memoBytes(v)
+		case types.Duration:
+			key.args[i] = memoDuration(v.Duration)
+		case types.Timestamp:
+			key.args[i] = memoTimestamp{seconds: v.Unix(), nanos: v.Nanosecond()}
+		default:
+			return key, false
+		}
+	}
+	return key, true
+}
+
+// evalMemoCall is a call to a deterministic function whose results are memoized across evaluations.
+type evalMemoCall struct {
+	*evalVarArgs
+	cache *FunctionResultCache
+}
+
+// Eval implements the Interpretable interface method.
+func (fn *evalMemoCall) Eval(vars Activation) ref.Val {
+	argVals := make([]ref.Val, len(fn.args))
+	// Early return if any argument to the function is unknown or error.
+	strict := !fn.nonStrict
+	for i, arg := range fn.args {
+		argVals[i] = arg.Eval(vars)
+		if strict && types.IsUnknownOrError(argVals[i]) {
+			return argVals[i]
+		}
+	}
+	key, ok := newMemoKey(fn.memoID(), argVals)
+	if !ok {
+		return fn.call(argVals)
+	}
+	stats, _ := asFunctionCacheStats(vars)
+	if out, found := fn.cache.get(key); found {
+		fn.cache.stats.hits.Add(1)
+		if stats != nil {
+			stats.hits.Add(1)
+		}
+		return out
+	}
+	fn.cache.stats.misses.Add(1)
+	if stats != nil {
+		stats.misses.Add(1)
+	}
+	out := fn.call(argVals)
+	if !types.IsUnknownOrError(out) {
+		fn.cache.put(key, out)
+	}
+	return out
+}
+
+// memoID returns the identifier under which the call results are memoized.
+func (fn *evalMemoCall) memoID() string {
+	if fn.overload != "" {
+		return fn.overload
+	}
+	return fn.function
+}
+
+// functionCacheStatsConverter identifies an object which is convertible to a FunctionCacheStats instance.
+type functionCacheStatsConverter interface {
+	asFunctionCacheStats() *FunctionCacheStats
+}
+
+// memoStatsActivation hides the per-evaluation cache statistics in the Activation in a manner not
+// accessible to expressions.
+type memoStatsActivation struct {
+	vars  Activation
+	stats *FunctionCacheStats
+}
+
+// ResolveName proxies variable lookups to the backing activation.
+func (msa memoStatsActivation) ResolveName(name string) (any, bool) {
+	return msa.vars.ResolveName(name)
+}
+
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (msa memoStatsActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(msa.vars, name)
+}
+
+// Parent proxies parent lookups to the backing activation.
+func (msa memoStatsActivation) Parent() Activation {
+	return msa.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (msa memoStatsActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(msa.vars)
+}
+
+// asFunctionCacheStats implements the functionCacheStatsConverter method.
+func (msa memoStatsActivation) asFunctionCacheStats() *FunctionCacheStats {
+	return msa.stats
+}
+
+// asFunctionCacheStats walks the Activation hierarchy and returns the first cache statistics found, if present.
+func asFunctionCacheStats(vars Activation) (*FunctionCacheStats, bool) {
+	if conv, ok := vars.(functionCacheStatsConverter); ok {
+		return conv.asFunctionCacheStats(), true
+	}
+	if vars.Parent() != nil {
+		return asFunctionCacheStats(vars.Parent())
+	}
+	return nil, false
+}
+
+// memoStatsFactory produces new FunctionCacheStats instances on each Eval call.
+type memoStatsFactory struct{}
+
+// InitState produces a FunctionCacheStats and bundles it into an Activation in a way which is not
+// visible to expression evaluation.
+func (memoStatsFactory) InitState(vars Activation) (Activation, error) {
+	return memoStatsActivation{vars: vars, stats: &FunctionCacheStats{}}, nil
+}
+
+// GetState extracts the FunctionCacheStats from the Activation.
+func (memoStatsFactory) GetState(vars Activation) any {
+	if stats, found := asFunctionCacheStats(vars); found {
+		return stats
+	}
+	return nil
+}
+
+// Observe implements the StatefulObserver interface method. Cache statistics are recorded by the
+// memoized calls rather than by observing program steps.
+func (memoStatsFactory) Observe(Activation, int64, any, ref.Val) {}
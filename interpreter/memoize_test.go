@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestFunctionResultCache(t *testing.T) {
+	if _, err := NewFunctionResultCache(0); err == nil {
+		t.Error("NewFunctionResultCache(0) succeeded, wanted error")
+	}
+	cache, err := NewFunctionResultCache(2)
+	if err != nil {
+		t.Fatalf("NewFunctionResultCache(2) failed: %v", err)
+	}
+	keys := make([]memoKey, 3)
+	for i, arg := range []ref.Val{types.Int(1), types.Uint(1), types.Bytes("1")} {
+		key, ok := newMemoKey("f", []ref.Val{arg})
+		if !ok {
+			t.Fatalf("newMemoKey(%v) failed", arg)
+		}
+		keys[i] = key
+	}
+	cache.put(keys[0], types.String("int"))
+	cache.put(keys[1], types.String("uint"))
+	// Touch the first key so that the second is the least recently used.
+	if out, found := cache.get(keys[0]); !found || out != types.String("int") {
+		t.Errorf("cache.get(%v) got %v, %t, wanted 'int'", keys[0], out, found)
+	}
+	cache.put(keys[2], types.String("bytes"))
+	if cache.Len() != 2 {
+		t.Errorf("cache.Len() got %d, wanted 2", cache.Len())
+	}
+	if _, found := cache.get(keys[1]); found {
+		t.Errorf("cache.get(%v) found an evicted entry", keys[1])
+	}
+	if _, ok := newMemoKey("f", []ref.Val{types.NewDynamicList(types.DefaultTypeAdapter, []int{1})}); ok {
+		t.Error("newMemoKey() with a list argument succeeded, wanted no key")
+	}
+}
+
+func TestMemoizeDeterministicCalls(t *testing.T) {
+	for _, opts := range [][]PlannerOption{{}, {Optimize()}, {BytecodeEval()}} {
+		var calls, impureCalls int
+		tc := testCase{
+			expr: `[1, 2, 1].map(i, slow(i)) == [2, 4, 2] && impure(1) == impure(1) && slow(xs) == 2`,
+			vars: []*decls.VariableDecl{
+				decls.NewVariable("xs", types.NewListType(types.IntType)),
+			},
+			funcs: []*decls.FunctionDecl{
+				funcDecl(t, "slow",
+					decls.Overload("slow_int", []*types.Type{types.IntType}, types.IntType,
+						decls.OverloadIsDeterministic(),
+						decls.UnaryBinding(func(arg ref.Val) ref.Val {
+							calls++
+							return arg.(types.Int) * 2
+						}),
+					),
+					decls.Overload("slow_list", []*types.Type{types.NewListType(types.IntType)}, types.IntType,
+						decls.OverloadIsDeterministic(),
+						decls.UnaryBinding(func(arg ref.Val) ref.Val {
+							calls++
+							return types.Int(2)
+						}),
+					),
+				),
+				funcDecl(t, "impure",
+					decls.Overload("impure_int", []*types.Type{types.IntType}, types.IntType,
+						decls.UnaryBinding(func(arg ref.Val) ref.Val {
+							impureCalls++
+							return arg
+						}),
+					),
+				),
+			},
+			in:  map[string]any{"xs": []int{1}},
+			out: true,
+		}
+		cache, err := NewFunctionResultCache(10)
+		if err != nil {
+			t.Fatalf("NewFunctionResultCache() failed: %v", err)
+		}
+		prg, vars, err := program(t, &tc, append(opts, MemoizeDeterministicCalls(cache))...)
+		if err != nil {
+			t.Fatalf("program(%s) failed: %v", tc.expr, err)
+		}
+		observable, ok := prg.(*ObservableInterpretable)
+		if !ok {
+			t.Fatalf("program(%s) got %T, wanted ObservableInterpretable", tc.expr, prg)
+		}
+		wantHits := []uint64{1, 3}
+		for i, hits := range wantHits {
+			var stats *FunctionCacheStats
+			out := observable.ObserveEval(vars, func(state any) {
+				if s, ok := state.(*FunctionCacheStats); ok {
+					stats = s
+				}
+			})
+			if out != types.True {
+				t.Fatalf("prg.Eval() got %v, wanted true", out)
+			}
+			if stats == nil || stats.Hits() != hits || stats.Hits()+stats.Misses() != 3 {
+				t.Errorf("eval %d got stats %+v, wanted %d hits of 3 lookups", i, stats, hits)
+			}
+		}
+		// Calls with list arguments are not memoized, and non-deterministic functions are not cached.
+		if calls != 4 || impureCalls != 4 {
+			t.Errorf("got %d deterministic calls and %d impure calls, wanted 4 and 4", calls, impureCalls)
+		}
+		if rate := cache.Stats().HitRate(); rate != 4.0/6.0 {
+			t.Errorf("cache.Stats().HitRate() got %v, wanted %v", rate, 4.0/6.0)
+		}
+	}
+}
+
+func TestMemoizeDoubleArguments(t *testing.T) {
+	calls := 0
+	tc := testCase{
+		expr: `inv(x)`,
+		vars: []*decls.VariableDecl{decls.NewVariable("x", types.DoubleType)},
+		funcs: []*decls.FunctionDecl{
+			funcDecl(t, "inv",
+				decls.Overload("inv_double", []*types.Type{types.DoubleType}, types.DoubleType,
+					decls.OverloadIsDeterministic(),
+					decls.UnaryBinding(func(arg ref.Val) ref.Val {
+						calls++
+						return 1.0 / arg.(types.Double)
+					}),
+				),
+			),
+		},
+	}
+	cache, err := NewFunctionResultCache(2)
+	if err != nil {
+		t.Fatalf("NewFunctionResultCache() failed: %v", err)
+	}
+	prg, _, err := program(t, &tc, MemoizeDeterministicCalls(cache))
+	if err != nil {
+		t.Fatalf("program(%s) failed: %v", tc.expr, err)
+	}
+	eval := func(x float64) ref.Val {
+		t.Helper()
+		vars, err := NewActivation(map[string]any{"x": x})
+		if err != nil {
+			t.Fatalf("NewActivation() failed: %v", err)
+		}
+		return prg.Eval(vars)
+	}
+	// 0.0 and -0.0 are equal, but are distinct arguments.
+	if out := eval(0); out != types.Double(math.Inf(1)) {
+		t.Errorf("inv(0.0) got %v, wanted +Inf", out)
+	}
+	if out := eval(math.Copysign(0, -1)); out != types.Double(math.Inf(-1)) {
+		t.Errorf("inv(-0.0) got %v, wanted -Inf", out)
+	}
+	// Calls with NaN arguments are not memoized, since the key could never be found or evicted.
+	for i := 0; i < 5; i++ {
+		if out := eval(math.NaN()); !math.IsNaN(float64(out.(types.Double))) {
+			t.Errorf("inv(NaN) got %v, wanted NaN", out)
+		}
+	}
+	if calls != 7 || cache.Len() != 2 || len(cache.entries) != 2 {
+		t.Errorf("got %d calls, %d cached results, and %d keys, wanted 7, 2, and 2",
+			calls, cache.Len(), len(cache.entries))
+	}
+	eval(2)
+	eval(0)
+	if calls != 9 || cache.Len() != 2 {
+		t.Errorf("got %d calls and %d cached results, wanted 9 and 2", calls, cache.Len())
+	}
+}
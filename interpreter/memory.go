@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"errors"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+const (
+	// listElementMemorySize is the number of bytes accounted for each element of a list, the size
+	// of the interface value which refers to the element.
+	listElementMemorySize = 16
+
+	// mapEntryMemorySize is the number of bytes accounted for each entry of a map, the size of the
+	// interface values which refer to the key and value.
+	mapEntryMemorySize = 32
+)
+
+// memoryTrackPlanOption modifies the memory tracking factory associated with the MemoryObserver.
+type memoryTrackPlanOption func(*memoryTrackerFactory) *memoryTrackerFactory
+
+// MemoryTrackerFactory configures the factory method to generate a new memory tracker per-evaluation.
+func MemoryTrackerFactory(factory func() (*MemoryTracker, error)) memoryTrackPlanOption {
+	return func(fac *memoryTrackerFactory) *memoryTrackerFactory {
+		fac.factory = factory
+		return fac
+	}
+}
+
+// MemoryObserver provides an observer that tracks the memory allocated by the strings, bytes,
+// lists, and maps produced by function calls and constructors during evaluation.
+//
+// Values provided as inputs to the evaluation and constants are not accounted for. Comprehensions
+// are evaluated sequentially when memory is tracked.
+func MemoryObserver(opts ...memoryTrackPlanOption) PlannerOption {
+	mt := &memoryTrackerFactory{}
+	for _, o := range opts {
+		mt = o(mt)
+	}
+	return func(p *planner) (*planner, error) {
+		if mt.factory == nil {
+			return nil, errors.New("memory tracker factory not configured")
+		}
+		p.observers = append(p.observers, mt)
+		p.decorators = append(p.decorators, decObserveEval(mt.Observe))
+		return p, nil
+	}
+}
+
+// memoryTrackerConverter identifies an object which is convertible to a MemoryTracker instance.
+type memoryTrackerConverter interface {
+	asMemoryTracker() *MemoryTracker
+}
+
+// memoryTrackActivation hides state in the Activation in a manner not accessible to expressions.
+type memoryTrackActivation struct {
+	vars          Activation
+	memoryTracker *MemoryTracker
+}
+
+// ResolveName proxies variable lookups to the backing activation.
+func (mta memoryTrackActivation) ResolveName(name string) (any, bool) {
+	return mta.vars.ResolveName(name)
+}
+
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (mta memoryTrackActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(mta.vars, name)
+}
+
+// Parent proxies parent lookups to the backing activation.
+func (mta memoryTrackActivation) Parent() Activation {
+	return mta.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (mta memoryTrackActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(mta.vars)
+}
+
+// asMemoryTracker implements the memoryTrackerConverter method.
+func (mta memoryTrackActivation) asMemoryTracker() *MemoryTracker {
+	return mta.memoryTracker
+}
+
+// asMemoryTracker walks the Activation hierarchy and returns the first memory tracker found, if present.
+func asMemoryTracker(vars Activation) (*MemoryTracker, bool) {
+	if conv, ok := vars.(memoryTrackerConverter); ok {
+		return conv.asMemoryTracker(), true
+	}
+	if vars.Parent() != nil {
+		return asMemoryTracker(vars.Parent())
+	}
+	return nil, false
+}
+
+// memoryTrackerFactory holds a factory for producing new MemoryTracker instances on each Eval call.
+type memoryTrackerFactory struct {
+	factory func() (*MemoryTracker, error)
+}
+
+// InitState produces a MemoryTracker and bundles it into an Activation in a way which is not visible
+// to expression evaluation.
+func (mt *memoryTrackerFactory) InitState(vars Activation) (Activation, error) {
+	tracker, err := mt.factory()
+	if err != nil {
+		return nil, err
+	}
+	return memoryTrackActivation{vars: vars, memoryTracker: tracker}, nil
+}
+
+// GetState extracts the MemoryTracker from the Activation.
+func (mt *memoryTrackerFactory) GetState(vars Activation) any {
+	if tracker, found := asMemoryTracker(vars); found {
+		return tracker
+	}
+	return nil
+}
+
+// Observe records the memory allocated by the value produced by function calls and constructors
+// into the MemoryTracker associated with the evaluation.
+func (mt *memoryTrackerFactory) Observe(vars Activation, id int64, programStep any, val ref.Val) {
+	switch programStep.(type) {
+	case InterpretableCall, InterpretableConstructor:
+	default:
+		return
+	}
+	tracker, found := asMemoryTracker(vars)
+	if !found {
+		return
+	}
+	tracker.allocate(val)
+	if tracker.Limit != nil && tracker.allocated > *tracker.Limit {
+		panic(EvalCancelledError{Cause: MemoryLimitExceeded, Message: "operation cancelled: actual memory limit exceeded"})
+	}
+}
+
+// MemoryTrackerOption configures the behavior of MemoryTracker objects.
+type MemoryTrackerOption func(*MemoryTracker) error
+
+// MemoryTrackerLimit sets the limit on the number of bytes allocated during execution and will terminate
+// the expression evaluation if the limit is exceeded.
+func MemoryTrackerLimit(limit uint64) MemoryTrackerOption {
+	return func(tracker *MemoryTracker) error {
+		tracker.Limit = &limit
+		return nil
+	}
+}
+
+// NewMemoryTracker creates a new MemoryTracker with a set of functional MemoryTrackerOption values.
+func NewMemoryTracker(opts ...MemoryTrackerOption) (*MemoryTracker, error) {
+	tracker := &MemoryTracker{}
+	for _, opt := range opts {
+		err := opt(tracker)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tracker, nil
+}
+
+// MemoryTracker represents the information needed for tracking the memory allocated during evaluation.
+type MemoryTracker struct {
+	Limit *uint64
+
+	allocated uint64
+
+	// mutableSizes records the last observed size of the mutable lists and maps which accumulate
+	// the results of comprehensions, since these values grow in place.
+	mutableSizes map[ref.Val]uint64
+}
+
+// ActualMemory returns the number of bytes allocated during evaluation.
+func (m *MemoryTracker) ActualMemory() uint64 {
+	return m.allocated
+}
+
+// allocate records the memory used by a newly produced value. Only the growth of mutable values
+// since they were last observed is recorded.
+func (m *MemoryTracker) allocate(val ref.Val) {
+	size := memorySize(val)
+	switch val.(type) {
+	case traits.MutableLister, traits.MutableMapper:
+		if m.mutableSizes == nil {
+			m.mutableSizes = map[ref.Val]uint64{}
+		}
+		prev := m.mutableSizes[val]
+		m.mutableSizes[val] = size
+		if size <= prev {
+			return
+		}
+		size -= prev
+	}
+	m.allocated += size
+}
+
+// memorySize returns the number of bytes accounted for a string, bytes, list, or map value based on
+// its actualSize, and zero for all other values.
+func memorySize(val ref.Val) uint64 {
+	switch val.(type) {
+	case types.String, types.Bytes:
+		return actualSize(val)
+	case traits.Lister:
+		return actualSize(val) * listElementMemorySize
+	case traits.Mapper:
+		return actualSize(val) * mapEntryMemorySize
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"testing"
+)
+
+func TestMemoryTracker(t *testing.T) {
+	tests := []struct {
+		expr string
+		in   map[string]any
+		want uint64
+	}{
+		{
+			// Inputs and constants are not accounted for.
+			expr: `s == 'hello' && xs.size() == 3`,
+			in:   map[string]any{"s": "hello", "xs": []int{1, 2, 3}},
+			want: 0,
+		},
+		{
+			expr: `s + s`,
+			in:   map[string]any{"s": "hello"},
+			want: 10,
+		},
+		{
+			expr: `b'abc' + b'd'`,
+			want: 4,
+		},
+		{
+			expr: `xs + xs + xs`,
+			in:   map[string]any{"xs": []int{1, 2, 3}},
+			want: (6 + 9) * listElementMemorySize,
+		},
+		{
+			expr: `[x, x]`,
+			in:   map[string]any{"x": 1},
+			want: 2 * listElementMemorySize,
+		},
+		{
+			expr: `{'a': x, 'b': [x]}`,
+			in:   map[string]any{"x": 1},
+			want: 2*mapEntryMemorySize + listElementMemorySize,
+		},
+		{
+			// Each step allocates a single element list which is appended to the accumulator.
+			expr: `xs.map(x, x * 2)`,
+			in:   map[string]any{"xs": []int{1, 2, 3, 4}},
+			want: 4*listElementMemorySize + 4*listElementMemorySize,
+		},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			for _, opts := range [][]PlannerOption{{}, {Optimize()}, {BytecodeEval()}} {
+				tracker, err := NewMemoryTracker()
+				if err != nil {
+					t.Fatalf("NewMemoryTracker() failed: %v", err)
+				}
+				opts = append(opts, MemoryObserver(MemoryTrackerFactory(func() (*MemoryTracker, error) { return tracker, nil })))
+				newTestInterpretable(t, tc.expr, opts...).Eval(newTestActivation(t, tc.in))
+				if tracker.ActualMemory() != tc.want {
+					t.Errorf("ActualMemory() got %d, wanted %d", tracker.ActualMemory(), tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryTrackerLimit(t *testing.T) {
+	tracker, err := NewMemoryTracker(MemoryTrackerLimit(1000))
+	if err != nil {
+		t.Fatalf("NewMemoryTracker() failed: %v", err)
+	}
+	prg := newTestInterpretable(t, `[s + s, s + s + s].all(x, x.size() > 0)`,
+		MemoryObserver(MemoryTrackerFactory(func() (*MemoryTracker, error) { return tracker, nil })))
+	defer func() {
+		r := recover()
+		if cancelled, ok := r.(EvalCancelledError); !ok || cancelled.Cause != MemoryLimitExceeded {
+			t.Errorf("Eval() panicked with %v, wanted memory limit exceeded", r)
+		}
+	}()
+	prg.Eval(newTestActivation(t, map[string]any{"s": string(make([]byte, 300))}))
+	t.Error("Eval() did not exceed the memory limit")
+}
+
+func TestMemoryObserverNoFactory(t *testing.T) {
+	parsed := mustParseWithMacros(t, `1 + 1`)
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(testContainer(""), reg, reg)
+	intr := newStandardInterpreter(t, testContainer(""), reg, reg, attrs)
+	if _, err := intr.NewInterpretable(parsed, MemoryObserver()); err == nil {
+		t.Error("NewInterpretable() with an unconfigured memory tracker factory succeeded, wanted error")
+	}
+}
@@ -0,0 +1,599 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// ParallelComprehensions enables the evaluation of comprehension iterations across multiple
+// goroutines for comprehensions whose iteration range contains at least minSize elements.
+//
+// Only comprehensions whose accumulation is associative are evaluated in parallel: the `all` and
+// `exists` quantifiers, the `map` and `filter` macros, and the `transformList` and `transformMap`
+// macros. The iteration range is split into contiguous chunks which are evaluated independently and
+// merged in range order, so the result is the same as sequential evaluation, including the error or
+// unknown reported when an iteration fails. Constructions whose filtered iterations compute a new
+// value, and `transformMapEntry` whose keys may repeat across chunks, are evaluated sequentially
+// since the failure they report depends on the order in which the iterations fail.
+//
+// The parallelism sets the maximum number of goroutines used per comprehension and defaults to
+// runtime.GOMAXPROCS when non-positive. Comprehensions nested within a parallel comprehension are
+// evaluated sequentially.
+//
+// Parallel evaluation is compatible with cost tracking, interrupt checks, and the memoization of
+// deterministic calls, though the actual cost of a short-circuiting comprehension may include
+// iterations which sequential evaluation would have skipped. Programs which observe evaluation
+// state are always evaluated sequentially, and custom decorators must be safe for concurrent use.
+func ParallelComprehensions(minSize, parallelism int) PlannerOption {
+	return func(p *planner) (*planner, error) {
+		if minSize < 1 {
+			return nil, errors.New("parallel comprehensions require a positive minimum range size")
+		}
+		if parallelism <= 0 {
+			parallelism = runtime.GOMAXPROCS(0)
+		}
+		p.parallel = &parallelFoldConfig{minSize: minSize, parallelism: parallelism}
+		return p, nil
+	}
+}
+
+type parallelFoldConfig struct {
+	minSize     int
+	parallelism int
+}
+
+// parallelFoldKind identifies the accumulation pattern of a comprehension which may be evaluated
+// in parallel.
+type parallelFoldKind int
+
+const (
+	sequentialFold parallelFoldKind = iota
+	allFold
+	existsFold
+	listFold
+	mapFold
+)
+
+// mapInsertFunction is the name of the internal function used by the two-variable comprehension
+// macros to construct maps.
+const mapInsertFunction = "cel.@mapInsert"
+
+// parallelFold describes how a comprehension is split into chunks and merged.
+type parallelFold struct {
+	*parallelFoldConfig
+	kind parallelFoldKind
+	// filtered is set when the step of a list or map construction is guarded by a filter, in which
+	// case the error or unknown of the last failing chunk, rather than the first, is the result.
+	filtered bool
+}
+
+// planParallelFold returns the parallel evaluation plan of a comprehension, or nil if the
+// comprehension must be evaluated sequentially.
+func (p *planner) planParallelFold(fold ast.ComprehensionExpr) *parallelFold {
+	if p.parallel == nil {
+		return nil
+	}
+	// Evaluation state observers record a single value per expression id and cannot be shared
+	// across goroutines. The cost tracker and the function cache statistics are safe for
+	// concurrent use.
+	for _, obs := range p.observers {
+		switch obs.(type) {
+		case *costTrackerFactory, memoStatsFactory:
+		default:
+			return nil
+		}
+	}
+	kind := parallelFoldKindOf(fold)
+	if kind == sequentialFold {
+		return nil
+	}
+	filtered := false
+	if kind == listFold || kind == mapFold {
+		var mergeable bool
+		filtered, mergeable = isMergeableConstruction(fold, kind)
+		if !mergeable {
+			return nil
+		}
+	}
+	return &parallelFold{parallelFoldConfig: p.parallel, kind: kind, filtered: filtered}
+}
+
+// isMergeableConstruction returns whether the error or unknown reported by a list or map
+// construction can be determined from the results of its chunks, and whether its step is filtered.
+//
+// Once the accumulator is an error or unknown, the step of an unfiltered construction returns the
+// accumulator unchanged, so the first failing chunk determines the result. The filter of a
+// filtered construction replaces the accumulator with its own error or unknown, so the last failing
+// chunk determines the result provided that the value added by the step cannot fail.
+func isMergeableConstruction(fold ast.ComprehensionExpr, kind parallelFoldKind) (filtered, mergeable bool) {
+	step := unwrapFilter(fold.LoopStep(), fold.AccuVar())
+	filtered = step.ID() != fold.LoopStep().ID()
+	args := step.AsCall().Args()
+	var val ast.Expr
+	switch kind {
+	case listFold:
+		val = args[1].AsList().Elements()[0]
+	case mapFold:
+		// Keys computed by the step may repeat across chunks, and the duplicate reported by
+		// sequential evaluation depends on the order of the range.
+		if len(args) != 3 || !isIdent(args[1], fold.IterVar()) {
+			return false, false
+		}
+		val = args[2]
+	}
+	if filtered && !isIdent(val, fold.IterVar()) && (fold.IterVar2() == "" || !isIdent(val, fold.IterVar2())) {
+		return false, false
+	}
+	return filtered, true
+}
+
+// parallelFoldKindOf determines whether the comprehension matches one of the macro expansions
+// whose step combines the accumulator with a value computed independently of the accumulator.
+func parallelFoldKindOf(fold ast.ComprehensionExpr) parallelFoldKind {
+	accu := fold.AccuVar()
+	if !isIdent(fold.Result(), accu) {
+		return sequentialFold
+	}
+	init := fold.AccuInit()
+	cond := fold.LoopCondition()
+	step := fold.LoopStep()
+	switch init.Kind() {
+	case ast.LiteralKind:
+		if !isCall(cond, operators.NotStrictlyFalse, 1) {
+			return sequentialFold
+		}
+		condArg := cond.AsCall().Args()[0]
+		switch init.AsLiteral() {
+		case types.True:
+			if isIdent(condArg, accu) && isAccuCall(step, operators.LogicalAnd, accu) {
+				return allFold
+			}
+		case types.False:
+			if isCall(condArg, operators.LogicalNot, 1) && isIdent(condArg.AsCall().Args()[0], accu) &&
+				isAccuCall(step, operators.LogicalOr, accu) {
+				return existsFold
+			}
+		}
+	case ast.ListKind:
+		if init.AsList().Size() != 0 || !isTrueLiteral(cond) {
+			return sequentialFold
+		}
+		step = unwrapFilter(step, accu)
+		if isAccuCall(step, operators.Add, accu) {
+			elems := step.AsCall().Args()[1]
+			if elems.Kind() == ast.ListKind && elems.AsList().Size() == 1 &&
+				len(elems.AsList().OptionalIndices()) == 0 {
+				return listFold
+			}
+		}
+	case ast.MapKind:
+		if init.AsMap().Size() != 0 || !isTrueLiteral(cond) {
+			return sequentialFold
+		}
+		if isAccuCall(unwrapFilter(step, accu), mapInsertFunction, accu) {
+			return mapFold
+		}
+	}
+	return sequentialFold
+}
+
+// unwrapFilter returns the true branch of a step of the form `filter ? <step> : accu`, or the step
+// unchanged if it is not filtered.
+func unwrapFilter(step ast.Expr, accu string) ast.Expr {
+	if !isCall(step, operators.Conditional, 3) {
+		return step
+	}
+	args := step.AsCall().Args()
+	if referencesVar(args[0], accu) || !isIdent(args[2], accu) {
+		return step
+	}
+	return args[1]
+}
+
+// isAccuCall returns whether the expression is a global call whose first argument is the
+// accumulator and whose remaining arguments do not reference the accumulator.
+func isAccuCall(e ast.Expr, function, accu string) bool {
+	if e.Kind() != ast.CallKind || e.AsCall().FunctionName() != function || e.AsCall().IsMemberFunction() {
+		return false
+	}
+	args := e.AsCall().Args()
+	if len(args) < 2 || !isIdent(args[0], accu) {
+		return false
+	}
+	for _, arg := range args[1:] {
+		if referencesVar(arg, accu) {
+			return false
+		}
+	}
+	return true
+}
+
+func isCall(e ast.Expr, function string, argCount int) bool {
+	return e.Kind() == ast.CallKind && e.AsCall().FunctionName() == function && len(e.AsCall().Args()) == argCount
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	return e.Kind() == ast.IdentKind && e.AsIdent() == name
+}
+
+func isTrueLiteral(e ast.Expr) bool {
+	return e.Kind() == ast.LiteralKind && e.AsLiteral() == types.True
+}
+
+// referencesVar returns whether the variable is referenced within the expression, taking into
+// account the variables declared by nested comprehensions.
+func referencesVar(e ast.Expr, name string) bool {
+	switch e.Kind() {
+	case ast.IdentKind:
+		return e.AsIdent() == name
+	case ast.SelectKind:
+		return referencesVar(e.AsSelect().Operand(), name)
+	case ast.CallKind:
+		call := e.AsCall()
+		if call.IsMemberFunction() && referencesVar(call.Target(), name) {
+			return true
+		}
+		for _, arg := range call.Args() {
+			if referencesVar(arg, name) {
+				return true
+			}
+		}
+	case ast.ListKind:
+		for _, elem := range e.AsList().Elements() {
+			if referencesVar(elem, name) {
+				return true
+			}
+		}
+	case ast.MapKind:
+		for _, entry := range e.AsMap().Entries() {
+			me := entry.AsMapEntry()
+			if referencesVar(me.Key(), name) || referencesVar(me.Value(), name) {
+				return true
+			}
+		}
+	case ast.StructKind:
+		for _, field := range e.AsStruct().Fields() {
+			if referencesVar(field.AsStructField().Value(), name) {
+				return true
+			}
+		}
+	case ast.ComprehensionKind:
+		comp := e.AsComprehension()
+		if referencesVar(comp.IterRange(), name) || referencesVar(comp.AccuInit(), name) {
+			return true
+		}
+		if comp.AccuVar() == name {
+			return false
+		}
+		if referencesVar(comp.Result(), name) {
+			return true
+		}
+		if comp.IterVar() == name || comp.IterVar2() == name {
+			return false
+		}
+		return referencesVar(comp.LoopCondition(), name) || referencesVar(comp.LoopStep(), name)
+	}
+	return false
+}
+
+// evalParallel evaluates the comprehension over the range in chunks, returning false if the
+// comprehension must be evaluated sequentially instead.
+func (fold *evalFold) evalParallel(ctx Activation, foldRange ref.Val) (ref.Val, bool) {
+	if fold.exhaustive || inParallelFold(ctx) {
+		return nil, false
+	}
+	entries, ok := collectFoldEntries(foldRange, fold.iterVar2 != "")
+	if !ok || len(entries) < fold.parallel.minSize {
+		return nil, false
+	}
+	workers := fold.parallel.parallelism
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+	if workers < 2 {
+		return nil, false
+	}
+
+	// The accumulator initializer is a literal which is evaluated once so that it is observed in
+	// the same manner as sequential evaluation. Each chunk starts from its own copy.
+	initVal := fold.accu.Eval(ctx)
+	tracker, hasTracker := asCostTracker(ctx)
+	var forked *costFork
+	if hasTracker {
+		forked = tracker.newFork()
+	}
+	resolver := &parallelFoldResolver{vars: ctx}
+	var stop atomic.Bool
+	var panicked atomic.Value
+	results := make([]parallelChunk, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					panicked.CompareAndSwap(nil, parallelPanic{r})
+					stop.Store(true)
+				}
+			}()
+			vars := &parallelFoldActivation{resolver: resolver, resolved: map[string]resolvedName{}}
+			if hasTracker {
+				vars.costTracker = tracker.fork(forked)
+			}
+			lo, hi := w*len(entries)/workers, (w+1)*len(entries)/workers
+			results[w] = fold.evalChunk(vars, initVal, entries[lo:hi], &stop)
+		}(w)
+	}
+	wg.Wait()
+	if hasTracker {
+		tracker.join(forked)
+	}
+	if r, isPanic := panicked.Load().(parallelPanic); isPanic {
+		panic(r.value)
+	}
+
+	f := newFolder(fold, ctx)
+	defer releaseFolder(f)
+	f.initialized = true
+	switch fold.parallel.kind {
+	case allFold:
+		f.accuVal = mergeLogical(results, types.False)
+	case existsFold:
+		f.accuVal = mergeLogical(results, types.True)
+	case listFold:
+		if failed := fold.parallel.failedChunk(results); failed != nil {
+			f.accuVal = failed
+			break
+		}
+		merged := types.NewMutableList(fold.adapter)
+		for _, res := range results {
+			merged.Add(res.accuVal)
+		}
+		f.accuVal = merged
+		f.mutableValue = true
+	case mapFold:
+		if failed := fold.parallel.failedChunk(results); failed != nil {
+			f.accuVal = failed
+			break
+		}
+		merged := types.NewMutableMap(fold.adapter, map[ref.Val]ref.Val{})
+		for _, res := range results {
+			m := res.accuVal.(traits.Mapper)
+			it := m.Iterator()
+			for it.HasNext() == types.True {
+				k := it.Next()
+				merged.Insert(k, m.Get(k))
+			}
+		}
+		f.accuVal = merged
+		f.mutableValue = true
+	}
+	return f.evalResult(), true
+}
+
+// failedChunk returns the error or unknown which determines the result of a list or map
+// construction, or nil if every chunk completed its construction.
+func (fold *parallelFold) failedChunk(results []parallelChunk) ref.Val {
+	var failed ref.Val
+	for _, res := range results {
+		if !types.IsUnknownOrError(res.accuVal) {
+			continue
+		}
+		if !fold.filtered {
+			return res.accuVal
+		}
+		failed = res.accuVal
+	}
+	return failed
+}
+
+// evalChunk folds a contiguous range of entries starting from a copy of the initial accumulator.
+func (fold *evalFold) evalChunk(vars Activation, initVal ref.Val, entries []foldEntry, stop *atomic.Bool) parallelChunk {
+	f := newFolder(fold, vars)
+	defer releaseFolder(f)
+	f.initAccu(initVal)
+	for _, e := range entries {
+		if stop.Load() || !f.FoldEntry(e.key, e.val) {
+			break
+		}
+	}
+	// A chunk which reaches the short-circuit value of a quantifier determines the result of the
+	// whole comprehension. Interrupts cancel the evaluation by panicking, which stops the other
+	// chunks.
+	switch {
+	case fold.parallel.kind == allFold && f.accuVal == types.False,
+		fold.parallel.kind == existsFold && f.accuVal == types.True:
+		stop.Store(true)
+	}
+	return parallelChunk{accuVal: f.accuVal}
+}
+
+// mergeLogical combines the chunk results of a quantifier in range order with the same semantics
+// as the logical operator used by its step: the short-circuit value takes precedence, followed by
+// unknowns, and then the first error.
+func mergeLogical(results []parallelChunk, shortCircuit types.Bool) ref.Val {
+	var err ref.Val
+	var unk *types.Unknown
+	for _, res := range results {
+		if b, isBool := res.accuVal.(types.Bool); isBool {
+			if b == shortCircuit {
+				return b
+			}
+			continue
+		}
+		isUnk := false
+		unk, isUnk = types.MaybeMergeUnknowns(res.accuVal, unk)
+		if !isUnk && err == nil {
+			err = res.accuVal
+		}
+	}
+	if unk != nil {
+		return unk
+	}
+	if err != nil {
+		return err
+	}
+	return !shortCircuit
+}
+
+// collectFoldEntries gathers the entries of the range in the order in which a sequential fold would
+// visit them.
+func collectFoldEntries(foldRange ref.Val, twoVar bool) ([]foldEntry, bool) {
+	if twoVar {
+		var foldable traits.Foldable
+		switch r := foldRange.(type) {
+		case traits.Mapper:
+			foldable = types.ToFoldableMap(r)
+		case traits.Lister:
+			foldable = types.ToFoldableList(r)
+		default:
+			return nil, false
+		}
+		c := &foldEntryCollector{}
+		foldable.Fold(c)
+		return c.entries, true
+	}
+	iterable, ok := foldRange.(traits.Iterable)
+	if !ok {
+		return nil, false
+	}
+	var entries []foldEntry
+	if sz, ok := foldRange.(traits.Sizer); ok {
+		entries = make([]foldEntry, 0, int(sz.Size().(types.Int)))
+	}
+	it := iterable.Iterator()
+	for it.HasNext() == types.True {
+		entries = append(entries, foldEntry{key: it.Next()})
+	}
+	return entries, true
+}
+
+type foldEntry struct {
+	key any
+	val any
+}
+
+type foldEntryCollector struct {
+	entries []foldEntry
+}
+
+// FoldEntry implements the traits.Folder interface method.
+func (c *foldEntryCollector) FoldEntry(key, val any) bool {
+	c.entries = append(c.entries, foldEntry{key: key, val: val})
+	return true
+}
+
+type parallelChunk struct {
+	accuVal ref.Val
+}
+
+type parallelPanic struct {
+	value any
+}
+
+// parallelFoldResolver serializes the variable lookups which reach the activation of a parallel
+// fold, since activations may resolve and cache lazy values.
+type parallelFoldResolver struct {
+	vars Activation
+	lock sync.Mutex
+}
+
+func (r *parallelFoldResolver) resolve(name string) resolvedName {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	val, found, err := ResolveNameWithError(r.vars, name)
+	return resolvedName{val: val, found: found, err: err}
+}
+
+type resolvedName struct {
+	val   any
+	found bool
+	err   error
+}
+
+// parallelFoldActivation is the activation of a single chunk of a parallel fold. It caches the
+// variables resolved by the chunk, so that the shared resolver is consulted at most once per
+// variable, and provides each goroutine with its own cost tracker.
+type parallelFoldActivation struct {
+	resolver    *parallelFoldResolver
+	resolved    map[string]resolvedName
+	costTracker *CostTracker
+}
+
+// ResolveName implements the Activation interface method.
+func (a *parallelFoldActivation) ResolveName(name string) (any, bool) {
+	r := a.resolveName(name)
+	if r.err != nil {
+		return types.WrapErr(r.err), true
+	}
+	return r.val, r.found
+}
+
+// ResolveNameWithError implements the ErrorResolver interface method.
+func (a *parallelFoldActivation) ResolveNameWithError(name string) (any, bool, error) {
+	r := a.resolveName(name)
+	return r.val, r.found, r.err
+}
+
+func (a *parallelFoldActivation) resolveName(name string) resolvedName {
+	r, found := a.resolved[name]
+	if !found {
+		r = a.resolver.resolve(name)
+		a.resolved[name] = r
+	}
+	return r
+}
+
+// Parent implements the Activation interface method.
+func (a *parallelFoldActivation) Parent() Activation {
+	return a.resolver.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (a *parallelFoldActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(a.resolver.vars)
+}
+
+// asCostTracker implements the costTrackerConverter method, returning the tracker specific to the
+// goroutine if cost tracking is enabled.
+func (a *parallelFoldActivation) asCostTracker() *CostTracker {
+	if a.costTracker != nil {
+		return a.costTracker
+	}
+	tracker, _ := asCostTracker(a.resolver.vars)
+	return tracker
+}
+
+// inParallelFold returns whether the activation belongs to an iteration of a parallel fold.
+func inParallelFold(vars Activation) bool {
+	for vars != nil {
+		if _, ok := vars.(*parallelFoldActivation); ok {
+			return true
+		}
+		vars = vars.Parent()
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestParallelComprehensions(t *testing.T) {
+	tests := []string{
+		`xs.all(x, x < 1000)`,
+		`xs.all(x, x != 500)`,
+		`xs.all(x, x == 700 ? 1 / 0 == 0 : true)`,
+		`xs.all(x, x == 700 ? 1 / 0 == 0 : x != 900)`,
+		`xs.exists(x, x == 999)`,
+		`xs.exists(x, x < 0)`,
+		`xs.exists(x, x == 10 ? 1 / 0 == 0 : x == 900)`,
+		`xs.exists(x, x == 10 ? 1 / 0 == 0 : x < 0)`,
+		`xs.map(x, x * 2)`,
+		`xs.map(x, x % 3 == 0, x / 3)`,
+		`xs.filter(x, x % 7 == 0)`,
+		`xs.map(x, 10 / (x - 500))`,
+		`xs.filter(x, x > 10 ? 1 / (x - 900) > 0 : false)`,
+		`xs.filter(x, x % 100 == 0 ? 1 / (x % 300) > 0 : false)`,
+		`xs.map(x, x % 100 == 0 ? 1 / (x % 300) : x)`,
+		`xs.map(x, x > 0, 1 / (x % 300))`,
+		`xs.exists(x, ys.all(y, y < x))`,
+		`xs.map(x, ys.map(y, x + y)).size() == 1000`,
+		`m.all(k, m[k] == k * 10)`,
+		`m.map(k, m[k]).size() == 200`,
+	}
+	xs := make([]int, 1000)
+	for i := range xs {
+		xs[i] = i
+	}
+	m := make(map[int]int, 200)
+	for i := 0; i < 200; i++ {
+		m[i] = i * 10
+	}
+	in := map[string]any{"xs": xs, "ys": []int{3, 5, 8}, "m": m}
+	for _, tst := range tests {
+		expr := tst
+		t.Run(expr, func(t *testing.T) {
+			want := newTestInterpretable(t, expr).Eval(newTestActivation(t, in))
+			got := newTestInterpretable(t, expr, ParallelComprehensions(10, 4)).Eval(newTestActivation(t, in))
+			if !evalResultsEqual(got, want) {
+				t.Errorf("Eval() got %v, wanted %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParallelComprehensionsPlan(t *testing.T) {
+	tests := []struct {
+		expr string
+		kind parallelFoldKind
+	}{
+		{expr: `xs.all(x, x > 0)`, kind: allFold},
+		{expr: `xs.exists(x, x > 0)`, kind: existsFold},
+		{expr: `xs.map(x, x + 1)`, kind: listFold},
+		{expr: `xs.map(x, x > 0, x + 1)`, kind: listFold},
+		{expr: `xs.filter(x, x > 0)`, kind: listFold},
+		{expr: `xs.exists_one(x, x > 0)`, kind: sequentialFold},
+		// The accumulator of the nested comprehension shadows the outer accumulator.
+		{expr: `xs.all(x, [x].all(y, y > 0))`, kind: allFold},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			parsed := mustParseWithMacros(t, tc.expr)
+			if kind := parallelFoldKindOf(parsed.Expr().AsComprehension()); kind != tc.kind {
+				t.Errorf("parallelFoldKindOf() got %v, wanted %v", kind, tc.kind)
+			}
+		})
+	}
+}
+
+func TestParallelComprehensionsMergeable(t *testing.T) {
+	tests := []struct {
+		expr      string
+		filtered  bool
+		mergeable bool
+	}{
+		{expr: `xs.map(x, x + 1)`, mergeable: true},
+		{expr: `xs.filter(x, x > 0)`, filtered: true, mergeable: true},
+		{expr: `xs.map(x, x > 0, x)`, filtered: true, mergeable: true},
+		// The filter and the value of the step may fail in either order.
+		{expr: `xs.map(x, x > 0, x + 1)`},
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			parsed := mustParseWithMacros(t, tc.expr)
+			filtered, mergeable := isMergeableConstruction(parsed.Expr().AsComprehension(), listFold)
+			if filtered != tc.filtered || mergeable != tc.mergeable {
+				t.Errorf("isMergeableConstruction() got %t, %t, wanted %t, %t",
+					filtered, mergeable, tc.filtered, tc.mergeable)
+			}
+		})
+	}
+}
+
+func TestParallelComprehensionsCost(t *testing.T) {
+	xs := make([]int, 100)
+	for i := range xs {
+		xs[i] = i
+	}
+	in := map[string]any{"xs": xs}
+	for _, expr := range []string{
+		`xs.map(x, x + 1).size() == 100`,
+		`xs.map(x, 10 / (x - 50)).size() == 100`,
+		`xs.filter(x, x > 10 ? 1 / (x - 90) > 0 : false).size() == 100`,
+	} {
+		costs := make([]uint64, 2)
+		outs := make([]ref.Val, 2)
+		for i, opts := range [][]PlannerOption{{}, {ParallelComprehensions(10, 4)}} {
+			tracker, err := NewCostTracker(nil)
+			if err != nil {
+				t.Fatalf("NewCostTracker() failed: %v", err)
+			}
+			opts = append(opts, CostObserver(CostTrackerFactory(func() (*CostTracker, error) { return tracker, nil })))
+			outs[i] = newTestInterpretable(t, expr, opts...).Eval(newTestActivation(t, in))
+			costs[i] = tracker.ActualCost()
+		}
+		if !evalResultsEqual(outs[1], outs[0]) {
+			t.Errorf("%s: parallel evaluation got %v, wanted %v", expr, outs[1], outs[0])
+		}
+		if costs[0] != costs[1] {
+			t.Errorf("%s: parallel evaluation cost %d, wanted sequential cost %d", expr, costs[1], costs[0])
+		}
+	}
+
+	expr := `xs.map(x, x + 1).size() == 100`
+	tracker, err := NewCostTracker(nil, CostTrackerLimit(100))
+	if err != nil {
+		t.Fatalf("NewCostTracker() failed: %v", err)
+	}
+	i := newTestInterpretable(t, expr, ParallelComprehensions(10, 4),
+		CostObserver(CostTrackerFactory(func() (*CostTracker, error) { return tracker, nil })))
+	defer func() {
+		r := recover()
+		if cancelled, ok := r.(EvalCancelledError); !ok || cancelled.Cause != CostLimitExceeded {
+			t.Errorf("Eval() panicked with %v, wanted cost limit exceeded", r)
+		}
+	}()
+	i.Eval(newTestActivation(t, in))
+	t.Error("Eval() did not exceed the cost limit")
+}
+
+func TestParallelComprehensionsMemoize(t *testing.T) {
+	var calls atomic.Int32
+	xs := make([]int, 1000)
+	for i := range xs {
+		xs[i] = i % 10
+	}
+	tc := testCase{
+		expr: `xs.map(x, slow(x))`,
+		vars: []*decls.VariableDecl{
+			decls.NewVariable("xs", types.NewListType(types.IntType)),
+		},
+		funcs: []*decls.FunctionDecl{
+			funcDecl(t, "slow",
+				decls.Overload("slow_int", []*types.Type{types.IntType}, types.IntType,
+					decls.OverloadIsDeterministic(),
+					decls.UnaryBinding(func(arg ref.Val) ref.Val {
+						calls.Add(1)
+						return arg.(types.Int) * 2
+					}),
+				),
+			),
+		},
+		in: map[string]any{"xs": xs},
+	}
+	cache, err := NewFunctionResultCache(10)
+	if err != nil {
+		t.Fatalf("NewFunctionResultCache() failed: %v", err)
+	}
+	prg, vars, err := program(t, &tc, ParallelComprehensions(10, 4), MemoizeDeterministicCalls(cache))
+	if err != nil {
+		t.Fatalf("program(%s) failed: %v", tc.expr, err)
+	}
+	observable, ok := prg.(*ObservableInterpretable)
+	if !ok {
+		t.Fatalf("program(%s) got %T, wanted ObservableInterpretable", tc.expr, prg)
+	}
+	// Memoization does not disable the parallel evaluation of the comprehension.
+	if fold, ok := observable.Interpretable.(*evalFold); !ok || fold.parallel == nil {
+		t.Fatalf("program(%s) got %T, wanted a parallel fold", tc.expr, observable.Interpretable)
+	}
+	var stats *FunctionCacheStats
+	out := observable.ObserveEval(vars, func(state any) {
+		if s, ok := state.(*FunctionCacheStats); ok {
+			stats = s
+		}
+	})
+	want := make([]int, len(xs))
+	for i, x := range xs {
+		want[i] = x * 2
+	}
+	if out.Equal(types.DefaultTypeAdapter.NativeToValue(want)) != types.True {
+		t.Errorf("Eval() got %v, wanted %v", out, want)
+	}
+	if stats == nil || stats.Hits()+stats.Misses() != uint64(len(xs)) || stats.Misses() != uint64(calls.Load()) {
+		t.Errorf("Eval() got stats %+v, wanted %d lookups and %d misses", stats, len(xs), calls.Load())
+	}
+}
+
+func TestParallelComprehensionsInterrupt(t *testing.T) {
+	i := newTestInterpretable(t, `xs.all(x, x >= 0)`, ParallelComprehensions(10, 4), InterruptableEval())
+	out := i.Eval(newTestActivation(t, map[string]any{
+		"xs":           make([]int, 100),
+		"#interrupted": true,
+	}))
+	if !isInterruptCancelled(out) {
+		t.Errorf("Eval() got %v, wanted interrupt error", out)
+	}
+}
+
+func TestParallelComprehensionsLazyVariable(t *testing.T) {
+	var calls atomic.Int32
+	in := map[string]any{
+		"xs": make([]int, 1000),
+		"y": func() ref.Val {
+			calls.Add(1)
+			return types.Int(1)
+		},
+	}
+	i := newTestInterpretable(t, `xs.map(x, x + y).all(z, z == y)`, ParallelComprehensions(10, 4))
+	if out := i.Eval(newTestActivation(t, in)); out != types.True {
+		t.Errorf("Eval() got %v, wanted true", out)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("lazy variable resolved %d times, wanted 1", calls.Load())
+	}
+}
@@ -15,6 +15,7 @@
 package interpreter
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/google/cel-go/common/functions"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
 )
 
 // newPlanner creates an interpretablePlanner which references a Dispatcher, TypeProvider,
@@ -43,6 +45,7 @@ func newPlanner(disp Dispatcher,
 		container:   cont,
 		refMap:      exprAST.ReferenceMap(),
 		typeMap:     exprAST.TypeMap(),
+		sourceInfo:  exprAST.SourceInfo(),
 		decorators:  make([]InterpretableDecorator, 0),
 		observers:   make([]StatefulObserver, 0),
 	}
@@ -57,8 +60,16 @@ type planner struct {
 	container   *containers.Container
 	refMap      map[int64]*ast.ReferenceInfo
 	typeMap     map[int64]*types.Type
+	sourceInfo  *ast.SourceInfo
 	decorators  []InterpretableDecorator
 	observers   []StatefulObserver
+	parallel    *parallelFoldConfig
+	bytecode    bool
+	memoCache   *FunctionResultCache
+	recordCall  func(function string) bool
+
+	interruptable   bool
+	trackIterations bool
 }
 
 type planBuilder struct {
@@ -78,6 +89,12 @@ func (p *planner) Plan(expr ast.Expr) (Interpretable, error) {
 	if err != nil {
 		return nil, err
 	}
+	if p.bytecode {
+		i = compileBytecode(i)
+	}
+	if p.interruptable {
+		i = &evalInterruptable{Interpretable: i}
+	}
 	if len(p.observers) == 0 {
 		return i, nil
 	}
@@ -281,6 +298,15 @@ func (p *planBuilder) planCall(expr ast.Expr) (Interpretable, error) {
 	if fnDef == nil {
 		fnDef, _ = p.disp.FindOverload(fnName)
 	}
+	if p.recordCall != nil && fnDef != nil && p.recordCall(fnName) {
+		return p.planCallRecord(expr, fnName, oName, fnDef, args)
+	}
+	if fn := contextFunctionOp(fnDef, argCount); fn != nil {
+		return p.planCallContext(expr, fnName, oName, fnDef, fn, args)
+	}
+	if fn := p.memoizedFunctionOp(fnDef, argCount); fn != nil {
+		return p.planCallMemo(expr, fnName, oName, fnDef, fn, args)
+	}
 	switch argCount {
 	case 0:
 		return p.planCallZero(expr, fnName, oName, fnDef)
@@ -404,6 +430,109 @@ func (p *planBuilder) planCallVarArgs(expr ast.Expr,
 	}, nil
 }
 
+// planCallContext generates a callable Interpretable whose implementation receives the context of
+// the evaluation.
+func (p *planBuilder) planCallContext(expr ast.Expr,
+	function string,
+	overload string,
+	impl *functions.Overload,
+	fn functions.ContextFunctionOp,
+	args []Interpretable) (Interpretable, error) {
+	return &evalContextCall{
+		id:        expr.ID(),
+		function:  function,
+		overload:  overload,
+		args:      args,
+		trait:     impl.OperandTrait,
+		impl:      fn,
+		nonStrict: impl.NonStrict,
+	}, nil
+}
+
+// planCallMemo generates a callable Interpretable whose results are memoized across evaluations.
+func (p *planBuilder) planCallMemo(expr ast.Expr,
+	function string,
+	overload string,
+	impl *functions.Overload,
+	fn functions.FunctionOp,
+	args []Interpretable) (Interpretable, error) {
+	return &evalMemoCall{
+		evalVarArgs: &evalVarArgs{
+			id:        expr.ID(),
+			function:  function,
+			overload:  overload,
+			args:      args,
+			trait:     impl.OperandTrait,
+			impl:      fn,
+			nonStrict: impl.NonStrict,
+		},
+		cache: p.memoCache,
+	}, nil
+}
+
+// planCallRecord generates a callable Interpretable whose arguments and result are recorded, or
+// whose result is replayed from a recording.
+func (p *planBuilder) planCallRecord(expr ast.Expr,
+	function string,
+	overload string,
+	impl *functions.Overload,
+	args []Interpretable) (Interpretable, error) {
+	fn, contextFn := recordedFunctionOps(impl, len(args))
+	if fn == nil && contextFn == nil {
+		return nil, fmt.Errorf("no such overload: %s(...)", function)
+	}
+	return &evalRecordCall{
+		evalVarArgs: &evalVarArgs{
+			id:        expr.ID(),
+			function:  function,
+			overload:  overload,
+			args:      args,
+			trait:     impl.OperandTrait,
+			impl:      fn,
+			nonStrict: impl.NonStrict,
+		},
+		contextImpl: contextFn,
+	}, nil
+}
+
+// memoizedFunctionOp returns the implementation of a deterministic overload for the given number
+// of arguments when memoization is enabled.
+func (p *planner) memoizedFunctionOp(impl *functions.Overload, argCount int) functions.FunctionOp {
+	if p.memoCache == nil || impl == nil || !impl.Deterministic || argCount == 0 || argCount > maxMemoArgs {
+		return nil
+	}
+	switch {
+	case argCount == 1 && impl.Unary != nil:
+		return func(args ...ref.Val) ref.Val {
+			return impl.Unary(args[0])
+		}
+	case argCount == 2 && impl.Binary != nil:
+		return func(args ...ref.Val) ref.Val {
+			return impl.Binary(args[0], args[1])
+		}
+	}
+	return impl.Function
+}
+
+// contextFunctionOp returns the context-aware implementation of the overload for the given number
+// of arguments, if one is defined.
+func contextFunctionOp(impl *functions.Overload, argCount int) functions.ContextFunctionOp {
+	if impl == nil {
+		return nil
+	}
+	switch {
+	case argCount == 1 && impl.ContextUnary != nil:
+		return func(ctx context.Context, args ...ref.Val) ref.Val {
+			return impl.ContextUnary(ctx, args[0])
+		}
+	case argCount == 2 && impl.ContextBinary != nil:
+		return func(ctx context.Context, args ...ref.Val) ref.Val {
+			return impl.ContextBinary(ctx, args[0], args[1])
+		}
+	}
+	return impl.ContextFunction
+}
+
 // planCallEqual generates an equals (==) Interpretable.
 func (p *planBuilder) planCallEqual(expr ast.Expr, args []Interpretable) (Interpretable, error) {
 	return &evalEq{
@@ -625,16 +754,18 @@ func (p *planBuilder) planComprehension(expr ast.Expr) (Interpretable, error) {
 	}
 	p.popLocalVars(fold.AccuVar())
 	return &evalFold{
-		id:        expr.ID(),
-		accuVar:   fold.AccuVar(),
-		accu:      accu,
-		iterVar:   fold.IterVar(),
-		iterVar2:  fold.IterVar2(),
-		iterRange: iterRange,
-		cond:      cond,
-		step:      step,
-		result:    result,
-		adapter:   p.adapter,
+		id:              expr.ID(),
+		accuVar:         fold.AccuVar(),
+		accu:            accu,
+		iterVar:         fold.IterVar(),
+		iterVar2:        fold.IterVar2(),
+		iterRange:       iterRange,
+		cond:            cond,
+		step:            step,
+		result:          result,
+		adapter:         p.adapter,
+		parallel:        p.planParallelFold(fold),
+		trackIterations: p.trackIterations && isUnrollableFold(fold),
 	}, nil
 }
 
@@ -0,0 +1,632 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// ProfileStats summarizes the evaluations of an expression node or function overload.
+type ProfileStats struct {
+	// Count is the number of times the step was evaluated.
+	Count uint64
+
+	// WallTime is the wall time spent evaluating the step, including its child steps.
+	WallTime time.Duration
+
+	// SelfTime is the wall time spent evaluating the step, excluding its child steps.
+	SelfTime time.Duration
+
+	// Allocs is the number of heap allocations made while evaluating the step, including its child
+	// steps. Allocations are only counted when the profiler is configured with ProfileAllocations.
+	Allocs uint64
+
+	// SelfAllocs is the number of heap allocations made while evaluating the step, excluding its
+	// child steps.
+	SelfAllocs uint64
+}
+
+// ProfilerOption configures the behavior of a Profiler.
+type ProfilerOption func(*Profiler) error
+
+// ProfileAllocations enables the counting of heap allocations per evaluation step.
+//
+// Allocations are counted using runtime.ReadMemStats which briefly stops the world at the start
+// and end of each step, so the option adds significant overhead and the counts include the
+// allocations made by other goroutines during the evaluation.
+func ProfileAllocations() ProfilerOption {
+	return func(p *Profiler) error {
+		p.allocs = true
+		return nil
+	}
+}
+
+// NewProfiler creates a Profiler with a set of functional ProfilerOption values.
+func NewProfiler(opts ...ProfilerOption) (*Profiler, error) {
+	p := &Profiler{start: time.Now(), tree: newProfileTree()}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Profiler accumulates the number of evaluations, wall time, and heap allocations of each step
+// of a program across many evaluations, keyed by the expression id of the step and by the
+// function overload invoked by the step.
+//
+// The profile may be exported in the pprof format with WriteProfile, where each expression node
+// is a location labelled with its source position and each function overload is a function, so
+// that tools such as `go tool pprof` may render flame graphs of the expression.
+//
+// Steps which do not evaluate child steps, such as constants and attribute qualifiers, are not
+// profiled separately and are accounted for within the step which evaluates them. A Profiler is
+// safe for concurrent use, though it profiles a single expression. Each evaluation accumulates its
+// statistics separately, and they are merged into the Profiler when the evaluation completes, so
+// that concurrent evaluations are not serialized by the profiling of each step.
+type Profiler struct {
+	allocs bool
+
+	mu         sync.Mutex
+	start      time.Time
+	sourceInfo *ast.SourceInfo
+	tree       *profileTree
+}
+
+// ProfileObserver provides an observer which records the evaluation steps of a program into the
+// Profiler.
+func ProfileObserver(profiler *Profiler) PlannerOption {
+	return func(p *planner) (*planner, error) {
+		if profiler == nil {
+			return nil, errors.New("profiler not configured")
+		}
+		if err := profiler.setSourceInfo(p.sourceInfo); err != nil {
+			return nil, err
+		}
+		p.observers = append(p.observers, profiler)
+		p.decorators = append(p.decorators, decObserveSteps(&stepObserver{enter: profiler.Enter, exit: profiler.Observe}))
+		return p, nil
+	}
+}
+
+// ExprStats returns the profile statistics of each profiled expression node keyed by expression id.
+func (p *Profiler) ExprStats() map[int64]ProfileStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := map[int64]ProfileStats{}
+	p.tree.root.visit(func(n *profileNode, wall time.Duration, allocs uint64) {
+		stats[n.id] = stats[n.id].add(n, wall, allocs)
+	})
+	return stats
+}
+
+// OverloadStats returns the profile statistics of the function calls keyed by the overload id of
+// the call, or by the function name when the overload is determined at evaluation time.
+func (p *Profiler) OverloadStats() map[string]ProfileStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := map[string]ProfileStats{}
+	p.tree.root.visit(func(n *profileNode, wall time.Duration, allocs uint64) {
+		if n.function != "" {
+			stats[n.function] = stats[n.function].add(n, wall, allocs)
+		}
+	})
+	return stats
+}
+
+// Reset discards the profile data collected so far.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.start = time.Now()
+	p.tree = newProfileTree()
+}
+
+// WriteProfile writes the profile data collected so far in the gzip-compressed protocol buffer
+// format read by the pprof tool.
+//
+// Each sample corresponds to a distinct stack of evaluation steps, and records the number of
+// evaluations, the wall time, and when enabled the heap allocations of the innermost step, less
+// those of its child steps. Samples are labelled with the `expr_id` of the innermost step and its
+// `source` location in the form `<description>:<line>:<column>`.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	p.mu.Lock()
+	data := p.encodeProfile()
+	p.mu.Unlock()
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// InitState bundles the per-evaluation profile state into the Activation in a way which is not
+// visible to expression evaluation.
+func (p *Profiler) InitState(vars Activation) (Activation, error) {
+	p.mu.Lock()
+	tree := p.tree
+	numNodes := len(tree.nodes)
+	p.mu.Unlock()
+	state := &profileState{
+		profiler: p,
+		tree:     tree,
+		stack:    make([]profileFrame, 0, profileStackCapacity),
+		stats:    make([]profileNodeStats, numNodes),
+	}
+	return profileActivation{vars: vars, state: state}, nil
+}
+
+// GetState returns nil as the profile data is accumulated into the Profiler rather than reported
+// per evaluation.
+func (p *Profiler) GetState(vars Activation) any {
+	return nil
+}
+
+// Enter records the start of the evaluation of a program step.
+func (p *Profiler) Enter(vars Activation, id int64, programStep any) {
+	if state, found := asProfileState(vars); found {
+		state.enter(id, programStep)
+	}
+}
+
+// Observe records the completion of the evaluation of a program step.
+func (p *Profiler) Observe(vars Activation, id int64, programStep any, val ref.Val) {
+	if state, found := asProfileState(vars); found {
+		state.exit(id, programStep)
+	}
+}
+
+func (p *Profiler) setSourceInfo(info *ast.SourceInfo) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sourceInfo != nil && p.sourceInfo != info {
+		return errors.New("profiler is already in use by a different expression")
+	}
+	p.sourceInfo = info
+	return nil
+}
+
+// child returns the node for the step evaluated within the parent node, creating it as needed.
+// Existing nodes are found without locking, since the children of a node are replaced rather than
+// modified when a node is added.
+func (p *Profiler) child(tree *profileTree, parent *profileNode, id int64, programStep any) *profileNode {
+	if parent == nil {
+		parent = tree.root
+	}
+	if c := parent.child(id); c != nil {
+		return c
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c := parent.child(id); c != nil {
+		return c
+	}
+	c := &profileNode{id: id, index: len(tree.nodes)}
+	c.name, c.function = profileStepName(programStep)
+	tree.nodes = append(tree.nodes, c)
+	children := append(slices.Clip(parent.childNodes()), c)
+	parent.children.Store(&children)
+	return c
+}
+
+// merge accumulates the statistics of an evaluation into the profile nodes, unless the profile was
+// reset during the evaluation, and clears them.
+func (p *Profiler) merge(s *profileState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.tree == p.tree {
+		for i, st := range s.stats {
+			if st.calls == 0 {
+				continue
+			}
+			n := s.tree.nodes[i]
+			n.calls += st.calls
+			n.wall += st.wall
+			n.allocs += st.allocs
+		}
+	}
+	clear(s.stats)
+}
+
+func (p *Profiler) encodeProfile() []byte {
+	enc := &pprofEncoder{
+		strings:   map[string]int64{"": 0},
+		table:     []string{""},
+		functions: map[string]uint64{},
+		locations: map[int64]uint64{},
+	}
+	var out []byte
+	sampleTypes := [][2]string{{"calls", "count"}, {"wall", "nanoseconds"}}
+	if p.allocs {
+		sampleTypes = append(sampleTypes, [2]string{"alloc_objects", "count"})
+	}
+	for _, st := range sampleTypes {
+		out = enc.appendValueType(out, 1, st[0], st[1])
+	}
+	var stack []uint64
+	var encodeSamples func(n *profileNode)
+	encodeSamples = func(n *profileNode) {
+		stack = append(stack, enc.location(p.sourceInfo, n))
+		vals := []int64{int64(n.calls), int64(n.wall)}
+		if p.allocs {
+			vals = append(vals, int64(n.allocs))
+		}
+		var sample []byte
+		// Locations are ordered from the innermost step to the outermost.
+		var ids []byte
+		for i := len(stack) - 1; i >= 0; i-- {
+			ids = protowire.AppendVarint(ids, stack[i])
+		}
+		sample = appendBytesField(sample, 1, ids)
+		var values []byte
+		for _, v := range vals {
+			values = protowire.AppendVarint(values, uint64(v))
+		}
+		sample = appendBytesField(sample, 2, values)
+		var label []byte
+		label = appendVarintField(label, 1, uint64(enc.str("expr_id")))
+		label = appendVarintField(label, 3, uint64(n.id))
+		sample = appendBytesField(sample, 3, label)
+		label = nil
+		label = appendVarintField(label, 1, uint64(enc.str("source")))
+		label = appendVarintField(label, 2, uint64(enc.str(profileSource(p.sourceInfo, n.id))))
+		sample = appendBytesField(sample, 3, label)
+		out = appendBytesField(out, 2, sample)
+		for _, c := range n.childNodes() {
+			encodeSamples(c)
+		}
+		stack = stack[:len(stack)-1]
+	}
+	for _, c := range p.tree.root.childNodes() {
+		encodeSamples(c)
+	}
+	out = append(out, enc.locs...)
+	out = append(out, enc.funcs...)
+	for _, s := range enc.table {
+		out = protowire.AppendTag(out, 6, protowire.BytesType)
+		out = protowire.AppendString(out, s)
+	}
+	out = appendVarintField(out, 9, uint64(p.start.UnixNano()))
+	out = appendVarintField(out, 10, uint64(time.Since(p.start).Nanoseconds()))
+	out = enc.appendValueType(out, 11, "wall", "nanoseconds")
+	out = appendVarintField(out, 12, 1)
+	// The wall time is the default sample type.
+	out = appendVarintField(out, 14, uint64(enc.str("wall")))
+	return out
+}
+
+// profileTree holds the nodes of a profile, which are indexed in the order of their creation.
+type profileTree struct {
+	root  *profileNode
+	nodes []*profileNode
+}
+
+func newProfileTree() *profileTree {
+	return &profileTree{root: &profileNode{index: -1}}
+}
+
+// profileNode accumulates the evaluations of a step for a given stack of enclosing steps.
+type profileNode struct {
+	id       int64
+	index    int
+	name     string
+	function string
+	calls    uint64
+	// wall and allocs exclude the values of the child steps.
+	wall     time.Duration
+	allocs   uint64
+	children atomic.Pointer[[]*profileNode]
+}
+
+// childNodes returns the nodes of the steps evaluated within the node.
+func (n *profileNode) childNodes() []*profileNode {
+	if children := n.children.Load(); children != nil {
+		return *children
+	}
+	return nil
+}
+
+// child returns the node of the step with the given id evaluated within the node, if present.
+func (n *profileNode) child(id int64) *profileNode {
+	for _, c := range n.childNodes() {
+		if c.id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// profileNodeStats accumulates the evaluations of a step within a single evaluation.
+type profileNodeStats struct {
+	calls  uint64
+	wall   time.Duration
+	allocs uint64
+}
+
+// visit invokes the function with each node under the root along with the wall time and
+// allocations of the node including its children, and returns the totals for the node.
+func (n *profileNode) visit(fn func(n *profileNode, wall time.Duration, allocs uint64)) (time.Duration, uint64) {
+	wall, allocs := n.wall, n.allocs
+	for _, c := range n.childNodes() {
+		w, a := c.visit(fn)
+		wall += w
+		allocs += a
+	}
+	if n.id != 0 {
+		fn(n, wall, allocs)
+	}
+	return wall, allocs
+}
+
+func (s ProfileStats) add(n *profileNode, wall time.Duration, allocs uint64) ProfileStats {
+	s.Count += n.calls
+	s.WallTime += wall
+	s.SelfTime += n.wall
+	s.Allocs += allocs
+	s.SelfAllocs += n.allocs
+	return s
+}
+
+// profileStepName returns the name used to identify a program step in the profile and the
+// function name or overload id when the step is a function call.
+func profileStepName(programStep any) (string, string) {
+	if fn, overload := traceFunction(programStep); fn != "" {
+		if overload != "" {
+			return overload, overload
+		}
+		return fn, fn
+	}
+	switch programStep.(type) {
+	case *evalFold:
+		return "comprehension", ""
+	case *evalList:
+		return "list", ""
+	case *evalMap:
+		return "map", ""
+	case *evalObj:
+		return "struct", ""
+	case InterpretableAttribute:
+		return "attribute", ""
+	}
+	return "expr", ""
+}
+
+// profileSource formats the source location of an expression id.
+func profileSource(info *ast.SourceInfo, id int64) string {
+	loc := info.GetStartLocation(id)
+	return fmt.Sprintf("%s:%d:%d", info.Description(), loc.Line(), loc.Column()+1)
+}
+
+// profileStackCapacity is the initial capacity of the stack of steps being evaluated, sized so that
+// the growth of the stack rarely contributes to the allocations attributed to a step.
+const profileStackCapacity = 32
+
+// profileState tracks the steps currently being evaluated within a single evaluation.
+type profileState struct {
+	profiler *Profiler
+	tree     *profileTree
+	memStats runtime.MemStats
+
+	// stats holds the statistics of the evaluation indexed by profile node, which are merged into
+	// the profile when the outermost step completes.
+	stats []profileNodeStats
+
+	// stack holds frames by value to avoid allocations which would be attributed to the step being
+	// evaluated.
+	stack []profileFrame
+}
+
+// profileFrame tracks a step which is currently being evaluated.
+type profileFrame struct {
+	node        *profileNode
+	programStep any
+
+	// start and mallocs record the time and allocation count at the start of the step, excluding
+	// the profiling overhead.
+	start   time.Time
+	mallocs uint64
+
+	// entered records the time at which profiling of the step began, including the overhead.
+	entered time.Time
+
+	// childWall and childAllocs accumulate the totals of the child steps, including the overhead of
+	// profiling them.
+	childWall   time.Duration
+	childAllocs uint64
+}
+
+func (s *profileState) enter(id int64, programStep any) {
+	entered := time.Now()
+	var parent *profileNode
+	if n := len(s.stack); n > 0 {
+		parent = s.stack[n-1].node
+	}
+	s.stack = append(s.stack, profileFrame{
+		node:        s.profiler.child(s.tree, parent, id, programStep),
+		programStep: programStep,
+		entered:     entered,
+	})
+	frame := &s.stack[len(s.stack)-1]
+	if s.profiler.allocs {
+		runtime.ReadMemStats(&s.memStats)
+		frame.mallocs = s.memStats.Mallocs
+	}
+	frame.start = time.Now()
+}
+
+// exit records the completion of a program step. Steps which were not entered, such as constants,
+// are accounted for in the step which is currently being evaluated.
+func (s *profileState) exit(id int64, programStep any) {
+	end := time.Now()
+	n := len(s.stack)
+	if n == 0 || s.stack[n-1].node.id != id || s.stack[n-1].programStep != programStep {
+		return
+	}
+	frame := s.stack[n-1]
+	s.stack = s.stack[:n-1]
+	var allocs uint64
+	if s.profiler.allocs {
+		runtime.ReadMemStats(&s.memStats)
+		allocs = s.memStats.Mallocs - frame.mallocs
+	}
+	wall := end.Sub(frame.start)
+	s.record(frame.node, max(wall-frame.childWall, 0), allocs-min(frame.childAllocs, allocs))
+	if n > 1 {
+		parent := &s.stack[n-2]
+		parent.childWall += time.Since(frame.entered)
+		parent.childAllocs += allocs
+		return
+	}
+	s.profiler.merge(s)
+}
+
+// record accumulates the evaluation of a step into the statistics of the evaluation.
+func (s *profileState) record(n *profileNode, wall time.Duration, allocs uint64) {
+	if n.index >= len(s.stats) {
+		s.stats = append(s.stats, make([]profileNodeStats, n.index+1-len(s.stats))...)
+	}
+	st := &s.stats[n.index]
+	st.calls++
+	st.wall += wall
+	st.allocs += allocs
+}
+
+// profileActivation hides the profile state in the Activation in a manner not accessible to
+// expressions.
+type profileActivation struct {
+	vars  Activation
+	state *profileState
+}
+
+// ResolveName proxies variable lookups to the backing activation.
+func (pa profileActivation) ResolveName(name string) (any, bool) {
+	return pa.vars.ResolveName(name)
+}
+
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (pa profileActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(pa.vars, name)
+}
+
+// Parent proxies parent lookups to the backing activation.
+func (pa profileActivation) Parent() Activation {
+	return pa.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (pa profileActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(pa.vars)
+}
+
+// asProfileState walks the Activation hierarchy and returns the first profile state found, if present.
+func asProfileState(vars Activation) (*profileState, bool) {
+	if conv, ok := vars.(profileActivation); ok {
+		return conv.state, true
+	}
+	if wrapper, ok := vars.(activationWrapper); ok {
+		return asProfileState(wrapper.Unwrap())
+	}
+	if vars.Parent() != nil {
+		return asProfileState(vars.Parent())
+	}
+	return nil, false
+}
+
+// pprofEncoder builds the string table, functions, and locations of a pprof profile.
+type pprofEncoder struct {
+	strings   map[string]int64
+	table     []string
+	functions map[string]uint64
+	locations map[int64]uint64
+	funcs     []byte
+	locs      []byte
+}
+
+// str returns the index of the string within the string table.
+func (e *pprofEncoder) str(s string) int64 {
+	if idx, found := e.strings[s]; found {
+		return idx
+	}
+	idx := int64(len(e.table))
+	e.strings[s] = idx
+	e.table = append(e.table, s)
+	return idx
+}
+
+// function returns the id of the function with the given name.
+func (e *pprofEncoder) function(info *ast.SourceInfo, name string) uint64 {
+	if id, found := e.functions[name]; found {
+		return id
+	}
+	id := uint64(len(e.functions) + 1)
+	e.functions[name] = id
+	var fn []byte
+	fn = appendVarintField(fn, 1, id)
+	fn = appendVarintField(fn, 2, uint64(e.str(name)))
+	fn = appendVarintField(fn, 3, uint64(e.str(name)))
+	fn = appendVarintField(fn, 4, uint64(e.str(info.Description())))
+	e.funcs = appendBytesField(e.funcs, 5, fn)
+	return id
+}
+
+// location returns the id of the location of the expression node.
+func (e *pprofEncoder) location(info *ast.SourceInfo, n *profileNode) uint64 {
+	if id, found := e.locations[n.id]; found {
+		return id
+	}
+	id := uint64(len(e.locations) + 1)
+	e.locations[n.id] = id
+	loc := info.GetStartLocation(n.id)
+	var line []byte
+	line = appendVarintField(line, 1, e.function(info, n.name))
+	line = appendVarintField(line, 2, uint64(max(loc.Line(), 0)))
+	line = appendVarintField(line, 3, uint64(max(loc.Column()+1, 0)))
+	var l []byte
+	l = appendVarintField(l, 1, id)
+	l = appendBytesField(l, 4, line)
+	e.locs = appendBytesField(e.locs, 4, l)
+	return id
+}
+
+func (e *pprofEncoder) appendValueType(b []byte, num protowire.Number, typ, unit string) []byte {
+	var vt []byte
+	vt = appendVarintField(vt, 1, uint64(e.str(typ)))
+	vt = appendVarintField(vt, 2, uint64(e.str(unit)))
+	return appendBytesField(b, num, vt)
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/google/cel-go/common/operators"
+)
+
+func TestProfiler(t *testing.T) {
+	for _, opts := range [][]PlannerOption{{}, {Optimize()}, {BytecodeEval()}} {
+		prof, err := NewProfiler()
+		if err != nil {
+			t.Fatalf("NewProfiler() failed: %v", err)
+		}
+		prg := newTestInterpretable(t, `xs.map(x, x * 2).size() > 1 || x / 0 == 1`, append(opts, ProfileObserver(prof))...)
+		for i := 0; i < 10; i++ {
+			prg.Eval(newTestActivation(t, map[string]any{"xs": []int{1, 2, 3}}))
+		}
+		overloads := prof.OverloadStats()
+		wantCounts := map[string]uint64{
+			operators.Multiply: 30,
+			"size":             10,
+			operators.Greater:  10,
+			"logical_or":       10,
+		}
+		for fn, want := range wantCounts {
+			if got := overloads[fn].Count; got != want {
+				t.Errorf("OverloadStats()[%q].Count got %d, wanted %d", fn, got, want)
+			}
+		}
+		// The right-hand side of the logical-or is never evaluated.
+		if _, found := overloads[operators.Divide]; found {
+			t.Errorf("OverloadStats() got %v, wanted no divide calls", overloads)
+		}
+		for id, stats := range prof.ExprStats() {
+			if stats.Count == 0 || stats.SelfTime > stats.WallTime || stats.Allocs != 0 {
+				t.Errorf("ExprStats()[%d] got %+v, wanted non-zero count and no allocations", id, stats)
+			}
+		}
+		prof.Reset()
+		if stats := prof.ExprStats(); len(stats) != 0 {
+			t.Errorf("ExprStats() after Reset() got %v, wanted empty", stats)
+		}
+	}
+}
+
+func TestProfilerConcurrent(t *testing.T) {
+	prof, err := NewProfiler()
+	if err != nil {
+		t.Fatalf("NewProfiler() failed: %v", err)
+	}
+	prg := newTestInterpretable(t, `xs.map(x, x * 2).size() > 1`, ProfileObserver(prof))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				prg.Eval(newTestActivation(t, map[string]any{"xs": []int{1, 2, 3}}))
+			}
+		}()
+	}
+	wg.Wait()
+	overloads := prof.OverloadStats()
+	if got := overloads[operators.Multiply].Count; got != 8*50*3 {
+		t.Errorf("OverloadStats()[%q].Count got %d, wanted %d", operators.Multiply, got, 8*50*3)
+	}
+	if got := overloads["size"].Count; got != 8*50 {
+		t.Errorf("OverloadStats()['size'].Count got %d, wanted %d", got, 8*50)
+	}
+}
+
+func TestProfilerAllocations(t *testing.T) {
+	prof, err := NewProfiler(ProfileAllocations())
+	if err != nil {
+		t.Fatalf("NewProfiler() failed: %v", err)
+	}
+	prg := newTestInterpretable(t, `[s + s, s].size()`, ProfileObserver(prof))
+	prg.Eval(newTestActivation(t, map[string]any{"s": "hello"}))
+	stats := prof.OverloadStats()
+	add, size := stats[operators.Add], stats["size"]
+	if add.SelfAllocs == 0 {
+		t.Errorf("OverloadStats()[%q] got %+v, wanted allocations", operators.Add, add)
+	}
+	if size.Allocs < add.Allocs || size.SelfAllocs > size.Allocs {
+		t.Errorf("OverloadStats()['size'] got %+v, wanted to include allocations of %+v", size, add)
+	}
+}
+
+func TestProfilerWriteProfile(t *testing.T) {
+	prof, err := NewProfiler(ProfileAllocations())
+	if err != nil {
+		t.Fatalf("NewProfiler() failed: %v", err)
+	}
+	prg := newTestInterpretable(t, "xs.all(x, x > 0)\n&& xs.size() < 10", ProfileObserver(prof))
+	for i := 0; i < 5; i++ {
+		prg.Eval(newTestActivation(t, map[string]any{"xs": []int{1, 2}}))
+	}
+	var buf bytes.Buffer
+	if err := prof.WriteProfile(&buf); err != nil {
+		t.Fatalf("WriteProfile() failed: %v", err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() failed: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("io.ReadAll() failed: %v", err)
+	}
+	fields := map[protowire.Number]int{}
+	var strs []string
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			t.Fatalf("malformed profile: %v", protowire.ParseError(n))
+		}
+		data = data[n:]
+		if num == 6 {
+			s, m := protowire.ConsumeString(data)
+			strs = append(strs, s)
+			n = m
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			t.Fatalf("malformed profile: %v", protowire.ParseError(n))
+		}
+		data = data[n:]
+		fields[num]++
+	}
+	if len(strs) == 0 || strs[0] != "" {
+		t.Fatalf("string table got %v, wanted empty first entry", strs)
+	}
+	for _, want := range []string{"calls", "wall", "alloc_objects", "nanoseconds", "expr_id", "source",
+		"comprehension", "logical_and", operators.Greater, "<input>:2:1", "<input>:1:13"} {
+		if !slices.Contains(strs, want) {
+			t.Errorf("string table got %v, missing %q", strs, want)
+		}
+	}
+	// Sample types, samples, locations, and functions respectively.
+	if fields[1] != 3 || fields[2] == 0 || fields[4] == 0 || fields[5] == 0 {
+		t.Errorf("profile got field counts %v, wanted 3 sample types, and samples, locations, and functions", fields)
+	}
+}
+
+func TestProfileObserverDifferentExpr(t *testing.T) {
+	prof, err := NewProfiler()
+	if err != nil {
+		t.Fatalf("NewProfiler() failed: %v", err)
+	}
+	newTestInterpretable(t, `x + 1`, ProfileObserver(prof))
+	parsed := mustParseWithMacros(t, `x + 2`)
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(testContainer(""), reg, reg)
+	intr := newStandardInterpreter(t, testContainer(""), reg, reg, attrs)
+	if _, err := intr.NewInterpretable(parsed, ProfileObserver(prof)); err == nil {
+		t.Error("NewInterpretable() with a profiler of a different expression succeeded, wanted error")
+	}
+	if _, err := intr.NewInterpretable(parsed, ProfileObserver(nil)); err == nil {
+		t.Error("NewInterpretable() with a nil profiler succeeded, wanted error")
+	}
+}
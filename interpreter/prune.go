@@ -15,6 +15,8 @@
 package interpreter
 
 import (
+	"maps"
+
 	"github.com/google/cel-go/common/ast"
 	"github.com/google/cel-go/common/operators"
 	"github.com/google/cel-go/common/overloads"
@@ -67,11 +69,14 @@ type astPruner struct {
 // fold(and thus cache results of) some external calls, then they can prepare
 // the overloads accordingly.
 func PruneAst(expr ast.Expr, macroCalls map[int64]ast.Expr, state EvalState) *ast.AST {
-	pruneState := NewEvalState()
+	pruneState := &evalState{values: make(map[int64]ref.Val)}
 	for _, id := range state.IDs() {
 		v, _ := state.Value(id)
 		pruneState.SetValue(id, v)
 	}
+	if s, ok := iterationStateOf(state); ok {
+		pruneState.iterations = s.iterations
+	}
 	pruner := &astPruner{
 		ExprFactory: ast.NewExprFactory(),
 		expr:        expr,
@@ -86,6 +91,26 @@ func PruneAst(expr ast.Expr, macroCalls map[int64]ast.Expr, state EvalState) *as
 	return ast.NewAST(newExpr, newInfo)
 }
 
+// TrackIterationState records the evaluation state of each iteration of the comprehensions
+// generated by the `all` and `exists` macros, which permits PruneAst to unroll comprehensions over
+// known ranges whose result depends on unknown values.
+//
+// An unrolled comprehension is replaced by the conjunction (`all`) or disjunction (`exists`) of
+// the pruned loop bodies of the iterations whose result is unknown or an error, ordered by
+// iteration, with the iteration variables substituted by their values. Comprehensions whose body
+// contains a nested comprehension, or whose iteration variables cannot be expressed as literals,
+// are left intact.
+//
+// The option requires an EvalStateObserver using the default EvalState implementation. The state
+// of every iteration is retained for the lifetime of the EvalState, so memory use grows with the
+// number of iterations evaluated.
+func TrackIterationState() PlannerOption {
+	return func(p *planner) (*planner, error) {
+		p.trackIterations = true
+		return p, nil
+	}
+}
+
 func (p *astPruner) maybeCreateLiteral(id int64, val ref.Val) (ast.Expr, bool) {
 	switch v := val.(type) {
 	case types.Bool, types.Bytes, types.Double, types.Int, types.Null, types.String, types.Uint, *types.Optional:
@@ -432,6 +457,10 @@ func (p *astPruner) prune(node ast.Expr) (ast.Expr, bool) {
 			return p.NewStruct(node.ID(), obj.TypeName(), newFields), true
 		}
 	case ast.ComprehensionKind:
+		if unrolled, ok := p.maybeUnrollComprehension(node); ok {
+			delete(p.macroCalls, node.ID())
+			return unrolled, true
+		}
 		compre := node.AsComprehension()
 		// Only the range of the comprehension is pruned since the state tracking only records
 		// the last iteration of the comprehension and not each step in the evaluation which
@@ -465,6 +494,154 @@ func (p *astPruner) prune(node ast.Expr) (ast.Expr, bool) {
 	return node, false
 }
 
+// maybeUnrollComprehension replaces an `all` or `exists` comprehension whose result is unknown with
+// the conjunction or disjunction of the loop step bodies of the iterations whose result is not yet
+// known, provided the state of every iteration over a known range was recorded.
+func (p *astPruner) maybeUnrollComprehension(node ast.Expr) (ast.Expr, bool) {
+	compre := node.AsComprehension()
+	if val, found := p.value(node.ID()); !found || !types.IsUnknown(val) || !isUnrollableFold(compre) {
+		return nil, false
+	}
+	state, ok := iterationStateOf(p.state)
+	if !ok {
+		return nil, false
+	}
+	iterations := state.iterations[node.ID()]
+	rangeVal, found := p.maybeValue(compre.IterRange().ID())
+	if !found {
+		return nil, false
+	}
+	if sz, ok := rangeVal.(traits.Sizer); !ok || sz.Size() != types.Int(len(iterations)) {
+		return nil, false
+	}
+	function, identity := operators.LogicalAnd, types.True
+	if parallelFoldKindOf(compre) == existsFold {
+		function, identity = operators.LogicalOr, types.False
+	}
+	body := foldBody(compre)
+	var terms []ast.Expr
+	for _, iter := range iterations {
+		bodyVal, found := iter.state.Value(body.ID())
+		if !found {
+			return nil, false
+		}
+		if bodyVal == identity {
+			continue
+		}
+		if bodyVal == !identity {
+			return p.maybeCreateLiteral(node.ID(), !identity)
+		}
+		term, ok := p.unrollIteration(compre, body, iter)
+		if !ok {
+			return nil, false
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return p.maybeCreateLiteral(node.ID(), identity)
+	}
+	residual := terms[0]
+	for _, term := range terms[1:] {
+		residual = p.NewCall(p.nextID(), function, residual, term)
+	}
+	return residual, true
+}
+
+// unrollIteration prunes a copy of the loop step body using the state of a single iteration and
+// substitutes the iteration variable values for any remaining references to the iteration variables.
+func (p *astPruner) unrollIteration(compre ast.ComprehensionExpr, body ast.Expr, iter *foldIteration) (ast.Expr, bool) {
+	state := p.state
+	p.state = &evalState{values: maps.Clone(iter.state.values)}
+	pruned, _ := p.prune(body)
+	p.state = state
+	vars := map[string]ref.Val{compre.IterVar(): iter.iterVar1}
+	if compre.HasIterVar2() {
+		vars[compre.IterVar2()] = iter.iterVar2
+	}
+	return p.substituteVars(pruned, vars)
+}
+
+// substituteVars copies the expression with new expression ids, replacing references to the given
+// variables with literals of their values.
+func (p *astPruner) substituteVars(e ast.Expr, vars map[string]ref.Val) (ast.Expr, bool) {
+	switch e.Kind() {
+	case ast.IdentKind:
+		if val, found := vars[e.AsIdent()]; found {
+			return p.maybeCreateLiteral(p.nextID(), val)
+		}
+		return p.NewIdent(p.nextID(), e.AsIdent()), true
+	case ast.LiteralKind:
+		return p.NewLiteral(p.nextID(), e.AsLiteral()), true
+	case ast.SelectKind:
+		sel := e.AsSelect()
+		operand, ok := p.substituteVars(sel.Operand(), vars)
+		if !ok {
+			return nil, false
+		}
+		if sel.IsTestOnly() {
+			return p.NewPresenceTest(p.nextID(), operand, sel.FieldName()), true
+		}
+		return p.NewSelect(p.nextID(), operand, sel.FieldName()), true
+	case ast.CallKind:
+		call := e.AsCall()
+		args, ok := p.substituteAllVars(call.Args(), vars)
+		if !ok {
+			return nil, false
+		}
+		if !call.IsMemberFunction() {
+			return p.NewCall(p.nextID(), call.FunctionName(), args...), true
+		}
+		target, ok := p.substituteVars(call.Target(), vars)
+		if !ok {
+			return nil, false
+		}
+		return p.NewMemberCall(p.nextID(), call.FunctionName(), target, args...), true
+	case ast.ListKind:
+		list := e.AsList()
+		elems, ok := p.substituteAllVars(list.Elements(), vars)
+		if !ok {
+			return nil, false
+		}
+		return p.NewList(p.nextID(), elems, list.OptionalIndices()), true
+	case ast.MapKind:
+		entries := make([]ast.EntryExpr, len(e.AsMap().Entries()))
+		for i, entry := range e.AsMap().Entries() {
+			me := entry.AsMapEntry()
+			kv, ok := p.substituteAllVars([]ast.Expr{me.Key(), me.Value()}, vars)
+			if !ok {
+				return nil, false
+			}
+			entries[i] = p.NewMapEntry(p.nextID(), kv[0], kv[1], me.IsOptional())
+		}
+		return p.NewMap(p.nextID(), entries), true
+	case ast.StructKind:
+		obj := e.AsStruct()
+		fields := make([]ast.EntryExpr, len(obj.Fields()))
+		for i, field := range obj.Fields() {
+			f := field.AsStructField()
+			val, ok := p.substituteVars(f.Value(), vars)
+			if !ok {
+				return nil, false
+			}
+			fields[i] = p.NewStructField(p.nextID(), f.Name(), val, f.IsOptional())
+		}
+		return p.NewStruct(p.nextID(), obj.TypeName(), fields), true
+	}
+	return nil, false
+}
+
+func (p *astPruner) substituteAllVars(exprs []ast.Expr, vars map[string]ref.Val) ([]ast.Expr, bool) {
+	out := make([]ast.Expr, len(exprs))
+	for i, e := range exprs {
+		sub, ok := p.substituteVars(e, vars)
+		if !ok {
+			return nil, false
+		}
+		out[i] = sub
+	}
+	return out, true
+}
+
 func (p *astPruner) value(id int64) (ref.Val, bool) {
 	val, found := p.state.Value(id)
 	return val, (found && val != nil)
@@ -561,6 +738,28 @@ func visit(expr ast.Expr, visitor astVisitor) {
 	}
 }
 
+// isUnrollableFold returns whether the comprehension was generated by an `all` or `exists` macro
+// whose loop step body does not contain a nested comprehension.
+func isUnrollableFold(fold ast.ComprehensionExpr) bool {
+	kind := parallelFoldKindOf(fold)
+	if kind != allFold && kind != existsFold {
+		return false
+	}
+	nested := false
+	visit(foldBody(fold), astVisitor{
+		visitExpr: func(e ast.Expr) {
+			nested = nested || e.Kind() == ast.ComprehensionKind
+		},
+	})
+	return !nested
+}
+
+// foldBody returns the expression which the loop step of an `all` or `exists` comprehension
+// combines with the accumulator.
+func foldBody(fold ast.ComprehensionExpr) ast.Expr {
+	return fold.LoopStep().AsCall().Args()[1]
+}
+
 func isCelBindMacro(macro ast.Expr) bool {
 	if macro.Kind() != ast.CallKind {
 		return false
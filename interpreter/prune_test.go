@@ -531,6 +531,96 @@ func TestPrune(t *testing.T) {
 	}
 }
 
+func TestPruneUnrollComprehensions(t *testing.T) {
+	tests := []testInfo{
+		{
+			in:   unknownActivation("x"),
+			expr: `[1, 2, 3].all(i, i < 2 || i < x)`,
+			out:  `2 < x && 3 < x`,
+		},
+		{
+			in:   unknownActivation("x"),
+			expr: `[1, 2, 3].exists(i, i > 2 && x)`,
+			out:  `x`,
+		},
+		{
+			in:   unknownActivation("x"),
+			expr: `[1, 2].all(i, i == 1 && x)`,
+			out:  `false`,
+		},
+		{
+			in:   partialActivation(map[string]any{"y": "b"}, "x"),
+			expr: `{"a": 1, "b": 2}.all(k, k == y || k == x)`,
+			out:  `"a" == x`,
+		},
+		{
+			in: partialActivation(map[string]any{"xs": []int{1, 2, 3}, "m": map[int]int{1: 10, 3: 5}},
+				NewAttributePattern("m").QualInt(2)),
+			expr: `xs.exists(i, m[i] == 10 && i > 1)`,
+			out:  `m[2] == 10`,
+		},
+		{
+			// Type values cannot be represented as literals.
+			in:   unknownActivation("x"),
+			expr: `[int, string].all(t, t == x)`,
+			out:  `[int, string].all(t, t == x)`,
+		},
+		{
+			// Nested comprehensions are not unrolled.
+			in:   unknownActivation("x"),
+			expr: `[1, 2].exists(i, [i].all(j, j == x))`,
+			out:  `[1, 2].exists(i, [i].all(j, j == x))`,
+		},
+		{
+			// Comprehensions which are not quantifiers are not unrolled.
+			in:   unknownActivation("x"),
+			expr: `[1, 2].map(i, i + x)`,
+			out:  `[1, 2].map(i, i + x)`,
+		},
+	}
+	p, err := parser.NewParser(
+		parser.PopulateMacroCalls(true),
+		parser.Macros(parser.AllMacros...),
+	)
+	if err != nil {
+		t.Fatalf("parser.NewParser() failed: %v", err)
+	}
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			parsed, iss := p.Parse(common.NewStringSource(tc.expr, "<input>"))
+			if len(iss.GetErrors()) > 0 {
+				t.Fatalf("Parse(%q) failed: %v", tc.expr, iss.ToDisplayString())
+			}
+			for _, exhaustive := range []bool{false, true} {
+				state := NewEvalState()
+				reg := newTestRegistry(t)
+				attrs := NewPartialAttributeFactory(containers.DefaultContainer, reg, reg)
+				dispatcher := NewDispatcher()
+				addFunctionBindings(t, dispatcher)
+				interp := NewInterpreter(dispatcher, containers.DefaultContainer, reg, reg, attrs)
+				opts := []PlannerOption{TrackIterationState(), EvalStateObserver(EvalStateFactory(func() EvalState { return state }))}
+				if exhaustive {
+					opts = append(opts, ExhaustiveEval())
+				}
+				interpretable, err := interp.NewInterpretable(parsed, opts...)
+				if err != nil {
+					t.Fatalf("NewInterpretable() failed: %v", err)
+				}
+				interpretable.Eval(testActivation(t, tc.in))
+				pruned := PruneAst(parsed.Expr(), parsed.SourceInfo().MacroCalls(), state)
+				actual, err := parser.Unparse(pruned.Expr(), pruned.SourceInfo())
+				if err != nil {
+					t.Fatalf("parser.Unparse() failed: %v", err)
+				}
+				if actual != tc.out {
+					t.Errorf("PruneAst() with exhaustive=%t got %s, wanted %s", exhaustive, actual, tc.out)
+				}
+			}
+		})
+	}
+}
+
 func unknownActivation(vars ...string) PartialActivation {
 	pats := make([]*AttributePattern, len(vars))
 	for i, v := range vars {
@@ -0,0 +1,452 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/common/functions"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// RecordedVariable records the resolution of a variable name during an evaluation.
+type RecordedVariable struct {
+	// Name is the variable name which was resolved.
+	Name string
+
+	// Found indicates whether the variable was present in the activation.
+	Found bool
+
+	// Value is the value of the variable, or nil if the variable was not found. Errors produced
+	// while resolving the variable are recorded as *types.Err values.
+	Value ref.Val
+}
+
+// RecordedAttribute records the value of an attribute after all of its qualifiers were applied.
+type RecordedAttribute struct {
+	// ID is the expression id of the attribute.
+	ID int64
+
+	// Value is the value of the attribute.
+	Value ref.Val
+}
+
+// RecordedCall records the invocation of a function.
+type RecordedCall struct {
+	// ID is the expression id of the call.
+	ID int64
+
+	// Function is the name of the function invoked.
+	Function string
+
+	// Overload is the overload id of the function, if known.
+	Overload string
+
+	// Args contains the argument values supplied to the function.
+	Args []ref.Val
+
+	// Result is the value produced by the function.
+	Result ref.Val
+}
+
+// EvalRecording records the variable resolutions, attribute values, and function calls observed
+// during a single evaluation in the order in which they occurred.
+//
+// Each variable name is recorded once, when it is first resolved. Names which are not valid
+// identifiers, such as `#interrupted`, carry evaluation state and are not recorded.
+type EvalRecording struct {
+	Variables  []*RecordedVariable
+	Attributes []*RecordedAttribute
+	Calls      []*RecordedCall
+
+	resolved map[string]struct{}
+}
+
+// NewEvalRecording returns an empty EvalRecording.
+func NewEvalRecording() *EvalRecording {
+	return &EvalRecording{resolved: make(map[string]struct{})}
+}
+
+func (r *EvalRecording) addVariable(name string, found bool, val ref.Val) {
+	if _, seen := r.resolved[name]; seen {
+		return
+	}
+	r.resolved[name] = struct{}{}
+	r.Variables = append(r.Variables, &RecordedVariable{Name: name, Found: found, Value: val})
+}
+
+// Divergence describes a difference between a replayed evaluation and its recording.
+type Divergence struct {
+	// ID is the expression id at which the evaluations diverged, or zero if the divergence is
+	// not associated with an expression, such as the resolution of a variable.
+	ID int64
+
+	// Message describes the divergence.
+	Message string
+}
+
+// String returns the message of the divergence along with its expression id, if any.
+func (d *Divergence) String() string {
+	if d.ID == 0 {
+		return d.Message
+	}
+	return fmt.Sprintf("expr %d: %s", d.ID, d.Message)
+}
+
+// RecordEval configures the planner to record the variable resolutions, attribute values, and
+// invocations of the functions selected by recordCall in an EvalRecording for each evaluation.
+//
+// The recording is reported via the observed state of the evaluation. When the program is
+// evaluated with a ReplayActivation, the selected functions are not invoked. Instead the
+// recorded results are returned, and any difference between the evaluation and the recording
+// is reported as a Divergence by the ReplayActivation.
+//
+// Attributes whose values are optional, such as those selected with the `.?` and `[?]` operators,
+// are neither recorded nor compared against the recording.
+func RecordEval(recordCall func(function string) bool) PlannerOption {
+	return func(p *planner) (*planner, error) {
+		if recordCall == nil {
+			return nil, errors.New("recorded function filter not configured")
+		}
+		rf := &evalRecordFactory{adapter: p.adapter}
+		p.recordCall = recordCall
+		p.observers = append(p.observers, rf)
+		p.decorators = append(p.decorators, decObserveEval(rf.Observe))
+		return p, nil
+	}
+}
+
+// ReplayActivation resolves variables from an EvalRecording and replays the recorded function
+// calls and attribute values of a program configured with RecordEval.
+//
+// A ReplayActivation replays a single evaluation and is not safe for concurrent use.
+type ReplayActivation struct {
+	vars        map[string]*RecordedVariable
+	attributes  map[int64][]*replayEntry
+	calls       map[int64][]*replayEntry
+	divergences []*Divergence
+}
+
+// NewReplayActivation creates a ReplayActivation from the recording of an evaluation.
+func NewReplayActivation(rec *EvalRecording) *ReplayActivation {
+	ra := &ReplayActivation{
+		vars:       make(map[string]*RecordedVariable, len(rec.Variables)),
+		attributes: make(map[int64][]*replayEntry),
+		calls:      make(map[int64][]*replayEntry),
+	}
+	for _, v := range rec.Variables {
+		ra.vars[v.Name] = v
+	}
+	for _, a := range rec.Attributes {
+		ra.attributes[a.ID] = append(ra.attributes[a.ID], &replayEntry{result: a.Value})
+	}
+	for _, c := range rec.Calls {
+		ra.calls[c.ID] = append(ra.calls[c.ID], &replayEntry{call: c, result: c.Result})
+	}
+	return ra
+}
+
+// ResolveName returns the recorded value of the variable.
+func (ra *ReplayActivation) ResolveName(name string) (any, bool) {
+	if strings.HasPrefix(name, "#") {
+		return nil, false
+	}
+	v, found := ra.vars[name]
+	if !found {
+		ra.diverge(0, "variable %q was not resolved by the recorded evaluation", name)
+		return nil, false
+	}
+	return v.Value, v.Found
+}
+
+// Parent implements the Activation interface method.
+func (ra *ReplayActivation) Parent() Activation {
+	return nil
+}
+
+// Divergences returns the differences between the replayed evaluation and the recording,
+// including the recorded function calls and attributes which were not replayed.
+func (ra *ReplayActivation) Divergences() []*Divergence {
+	divergences := ra.divergences
+	for _, entries := range ra.calls {
+		for _, e := range entries {
+			if !e.replayed {
+				divergences = append(divergences, &Divergence{ID: e.call.ID,
+					Message: fmt.Sprintf("recorded call to %s was not replayed", e.call.Function)})
+			}
+		}
+	}
+	for id, entries := range ra.attributes {
+		for _, e := range entries {
+			if !e.replayed {
+				divergences = append(divergences, &Divergence{ID: id,
+					Message: "recorded attribute was not replayed"})
+			}
+		}
+	}
+	return divergences
+}
+
+func (ra *ReplayActivation) diverge(id int64, format string, args ...any) {
+	ra.divergences = append(ra.divergences, &Divergence{ID: id, Message: fmt.Sprintf(format, args...)})
+}
+
+// replayCall returns the recorded result of the first call at the expression id with the same
+// arguments which has not been replayed.
+func (ra *ReplayActivation) replayCall(id int64, function string, args []ref.Val) ref.Val {
+	var pending *replayEntry
+	for _, e := range ra.calls[id] {
+		if e.replayed {
+			continue
+		}
+		if replayArgsEqual(e.call.Args, args) {
+			e.replayed = true
+			return e.result
+		}
+		if pending == nil {
+			pending = e
+		}
+	}
+	if pending == nil {
+		ra.diverge(id, "call to %s was not recorded", function)
+		return types.NewErrWithNodeID(id, "no recorded result for call to %s", function)
+	}
+	ra.diverge(id, "call to %s got args %v, recorded %v", function, args, pending.call.Args)
+	pending.replayed = true
+	return pending.result
+}
+
+// replayAttribute compares the value of the attribute with the next recorded value.
+func (ra *ReplayActivation) replayAttribute(id int64, val ref.Val) {
+	for _, e := range ra.attributes[id] {
+		if e.replayed {
+			continue
+		}
+		e.replayed = true
+		if !replayValEqual(e.result, val) {
+			ra.diverge(id, "attribute got %v, recorded %v", val, e.result)
+		}
+		return
+	}
+	ra.diverge(id, "attribute was not recorded")
+}
+
+type replayEntry struct {
+	call     *RecordedCall
+	result   ref.Val
+	replayed bool
+}
+
+func replayArgsEqual(recorded, args []ref.Val) bool {
+	if len(recorded) != len(args) {
+		return false
+	}
+	for i, arg := range args {
+		if !replayValEqual(recorded[i], arg) {
+			return false
+		}
+	}
+	return true
+}
+
+func replayValEqual(recorded, val ref.Val) bool {
+	switch v := val.(type) {
+	case *types.Err:
+		r, ok := recorded.(*types.Err)
+		return ok && r.Error() == v.Error()
+	case *types.Unknown:
+		_, ok := recorded.(*types.Unknown)
+		return ok
+	}
+	if types.IsUnknownOrError(recorded) {
+		return false
+	}
+	return recorded.Equal(val) == types.True
+}
+
+// asReplayActivation walks the Activation hierarchy and returns the ReplayActivation, if present.
+func asReplayActivation(vars Activation) (*ReplayActivation, bool) {
+	if ra, ok := vars.(*ReplayActivation); ok {
+		return ra, true
+	}
+	if vars.Parent() != nil {
+		return asReplayActivation(vars.Parent())
+	}
+	return nil, false
+}
+
+// evalRecordCall is a function call whose arguments and result are recorded, or whose result is
+// replayed from a recording.
+type evalRecordCall struct {
+	*evalVarArgs
+	contextImpl functions.ContextFunctionOp
+}
+
+// Eval implements the Interpretable interface method.
+func (fn *evalRecordCall) Eval(vars Activation) ref.Val {
+	argVals := make([]ref.Val, len(fn.args))
+	// Early return if any argument to the function is unknown or error.
+	strict := !fn.nonStrict
+	for i, arg := range fn.args {
+		argVals[i] = arg.Eval(vars)
+		if strict && types.IsUnknownOrError(argVals[i]) {
+			return argVals[i]
+		}
+	}
+	var out ref.Val
+	if ra, found := asReplayActivation(vars); found {
+		out = ra.replayCall(fn.id, fn.function, argVals)
+	} else if fn.contextImpl != nil {
+		out = types.LabelErrNode(fn.id, callWithContext(vars, fn.contextImpl, argVals))
+	} else if len(argVals) == 0 {
+		// Calls without arguments have no operand whose traits or receiver methods are consulted.
+		out = types.LabelErrNode(fn.id, fn.impl())
+	} else {
+		out = fn.call(argVals)
+	}
+	if rec, found := asEvalRecording(vars); found {
+		rec.Calls = append(rec.Calls, &RecordedCall{
+			ID:       fn.id,
+			Function: fn.function,
+			Overload: fn.overload,
+			Args:     argVals,
+			Result:   out,
+		})
+	}
+	return out
+}
+
+// recordedFunctionOps returns the implementation of the overload for the given number of
+// arguments, adapted to accept the arguments as a list.
+func recordedFunctionOps(impl *functions.Overload, argCount int) (functions.FunctionOp, functions.ContextFunctionOp) {
+	if fn := contextFunctionOp(impl, argCount); fn != nil {
+		return nil, fn
+	}
+	switch {
+	case argCount == 1 && impl.Unary != nil:
+		return func(args ...ref.Val) ref.Val {
+			return impl.Unary(args[0])
+		}, nil
+	case argCount == 2 && impl.Binary != nil:
+		return func(args ...ref.Val) ref.Val {
+			return impl.Binary(args[0], args[1])
+		}, nil
+	}
+	return impl.Function, nil
+}
+
+// evalRecordingConverter identifies an object which is convertible to an EvalRecording instance.
+type evalRecordingConverter interface {
+	asEvalRecording() *EvalRecording
+}
+
+// evalRecordActivation records the variables resolved through the activation and hides the
+// EvalRecording in the Activation in a manner not accessible to expressions.
+type evalRecordActivation struct {
+	vars      Activation
+	adapter   types.Adapter
+	recording *EvalRecording
+}
+
+// ResolveName records the variable resolved by the backing activation.
+func (era evalRecordActivation) ResolveName(name string) (any, bool) {
+	obj, found, err := era.ResolveNameWithError(name)
+	if err != nil {
+		return types.WrapErr(err), true
+	}
+	return obj, found
+}
+
+// ResolveNameWithError records the variable resolved by the backing activation.
+func (era evalRecordActivation) ResolveNameWithError(name string) (any, bool, error) {
+	obj, found, err := ResolveNameWithError(era.vars, name)
+	if strings.HasPrefix(name, "#") {
+		return obj, found, err
+	}
+	switch {
+	case err != nil:
+		era.recording.addVariable(name, true, types.WrapErr(err))
+	case found:
+		era.recording.addVariable(name, true, era.adapter.NativeToValue(obj))
+	default:
+		era.recording.addVariable(name, false, nil)
+	}
+	return obj, found, err
+}
+
+// Parent proxies parent lookups to the backing activation.
+func (era evalRecordActivation) Parent() Activation {
+	return era.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (era evalRecordActivation) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(era.vars)
+}
+
+// asEvalRecording implements the evalRecordingConverter method.
+func (era evalRecordActivation) asEvalRecording() *EvalRecording {
+	return era.recording
+}
+
+// asEvalRecording walks the Activation hierarchy and returns the first recording found, if present.
+func asEvalRecording(vars Activation) (*EvalRecording, bool) {
+	if conv, ok := vars.(evalRecordingConverter); ok {
+		return conv.asEvalRecording(), true
+	}
+	if vars.Parent() != nil {
+		return asEvalRecording(vars.Parent())
+	}
+	return nil, false
+}
+
+// evalRecordFactory produces a new EvalRecording on each Eval call.
+type evalRecordFactory struct {
+	adapter types.Adapter
+}
+
+// InitState produces an EvalRecording and bundles it into an Activation which records the
+// variables resolved during evaluation.
+func (rf *evalRecordFactory) InitState(vars Activation) (Activation, error) {
+	return evalRecordActivation{vars: vars, adapter: rf.adapter, recording: NewEvalRecording()}, nil
+}
+
+// GetState extracts the EvalRecording from the Activation.
+func (rf *evalRecordFactory) GetState(vars Activation) any {
+	if rec, found := asEvalRecording(vars); found {
+		return rec
+	}
+	return nil
+}
+
+// Observe records the values of attributes, and compares them against the recorded values when
+// the evaluation is replayed.
+func (rf *evalRecordFactory) Observe(vars Activation, id int64, programStep any, val ref.Val) {
+	if _, ok := programStep.(InterpretableAttribute); !ok {
+		return
+	}
+	if _, isOpt := val.(*types.Optional); isOpt {
+		return
+	}
+	if ra, found := asReplayActivation(vars); found {
+		ra.replayAttribute(id, val)
+	}
+	if rec, found := asEvalRecording(vars); found {
+		rec.Attributes = append(rec.Attributes, &RecordedAttribute{ID: id, Value: val})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/common/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func TestRecordEval(t *testing.T) {
+	for _, opts := range [][]PlannerOption{{}, {Optimize()}, {BytecodeEval()}} {
+		var calls int
+		tc := testCase{
+			expr: `lookup(id) + m.x > 10 && [1, 2].all(i, lookup(i) > 0)`,
+			vars: []*decls.VariableDecl{
+				decls.NewVariable("id", types.IntType),
+				decls.NewVariable("m", types.NewMapType(types.StringType, types.IntType)),
+			},
+			funcs: []*decls.FunctionDecl{
+				funcDecl(t, "lookup",
+					decls.Overload("lookup_int", []*types.Type{types.IntType}, types.IntType,
+						decls.UnaryBinding(func(arg ref.Val) ref.Val {
+							calls++
+							return arg.(types.Int) * 10
+						}),
+					),
+				),
+			},
+			in:  map[string]any{"id": 1, "m": map[string]int{"x": 1}},
+			out: true,
+		}
+		recordCall := func(function string) bool { return function == "lookup" }
+		prg, vars, err := program(t, &tc, append(opts, RecordEval(recordCall))...)
+		if err != nil {
+			t.Fatalf("program(%s) failed: %v", tc.expr, err)
+		}
+		observable, ok := prg.(*ObservableInterpretable)
+		if !ok {
+			t.Fatalf("program(%s) got %T, wanted ObservableInterpretable", tc.expr, prg)
+		}
+		evalRecorded := func(vars Activation) (ref.Val, *EvalRecording) {
+			var rec *EvalRecording
+			out := observable.ObserveEval(vars, func(state any) {
+				if r, ok := state.(*EvalRecording); ok {
+					rec = r
+				}
+			})
+			return out, rec
+		}
+		out, rec := evalRecorded(vars)
+		if out != types.True || rec == nil {
+			t.Fatalf("prg.Eval() got %v, %v, wanted true with a recording", out, rec)
+		}
+		if calls != 3 || len(rec.Calls) != 3 {
+			t.Fatalf("got %d calls and %d recorded calls, wanted 3", calls, len(rec.Calls))
+		}
+		if c := rec.Calls[0]; c.Function != "lookup" || c.Overload != "lookup_int" ||
+			len(c.Args) != 1 || c.Args[0] != types.Int(1) || c.Result != types.Int(10) {
+			t.Errorf("rec.Calls[0] got %+v, wanted lookup_int(1) = 10", c)
+		}
+		varNames := []string{}
+		for _, v := range rec.Variables {
+			varNames = append(varNames, v.Name)
+		}
+		if strings.Join(varNames, ",") != "id,m" {
+			t.Errorf("rec.Variables got %v, wanted [id m]", varNames)
+		}
+		if len(rec.Attributes) == 0 {
+			t.Error("rec.Attributes is empty, wanted recorded attribute values")
+		}
+
+		// Replaying the recording returns the recorded results without invoking the function.
+		replay := NewReplayActivation(rec)
+		out, replayed := evalRecorded(replay)
+		if out != types.True {
+			t.Errorf("replay got %v, wanted true", out)
+		}
+		if calls != 3 {
+			t.Errorf("replay invoked the function, got %d calls, wanted 3", calls)
+		}
+		if d := replay.Divergences(); len(d) != 0 {
+			t.Errorf("replay.Divergences() got %v, wanted none", d)
+		}
+		if len(replayed.Calls) != len(rec.Calls) || len(replayed.Attributes) != len(rec.Attributes) {
+			t.Errorf("replay recorded %+v, wanted %+v", replayed, rec)
+		}
+
+		// Changing a recorded variable causes the call arguments and attributes to diverge.
+		rec.Variables[0] = &RecordedVariable{Name: "id", Found: true, Value: types.Int(2)}
+		replay = NewReplayActivation(rec)
+		evalRecorded(replay)
+		divergences := replay.Divergences()
+		if len(divergences) == 0 {
+			t.Fatal("replay.Divergences() got none, wanted divergences")
+		}
+		found := false
+		for _, d := range divergences {
+			if strings.Contains(d.String(), "call to lookup got args [2], recorded [1]") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("replay.Divergences() got %v, wanted a divergent call to lookup", divergences)
+		}
+
+		// Variables which were not recorded are reported as divergences.
+		replay = NewReplayActivation(&EvalRecording{})
+		if out, _ := evalRecorded(replay); !types.IsError(out) {
+			t.Errorf("replay of an empty recording got %v, wanted error", out)
+		}
+		if d := replay.Divergences(); len(d) == 0 || d[0].String() != `variable "id" was not resolved by the recorded evaluation` {
+			t.Errorf("replay.Divergences() got %v, wanted unresolved variable", d)
+		}
+	}
+}
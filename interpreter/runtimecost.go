@@ -17,6 +17,7 @@ package interpreter
 import (
 	"errors"
 	"math"
+	"sync/atomic"
 
 	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/overloads"
@@ -78,6 +79,11 @@ func (cta costTrackActivation) ResolveName(name string) (any, bool) {
 	return cta.vars.ResolveName(name)
 }
 
+// ResolveNameWithError proxies variable lookups to the backing activation.
+func (cta costTrackActivation) ResolveNameWithError(name string) (any, bool, error) {
+	return ResolveNameWithError(cta.vars, name)
+}
+
 // Parent proxies parent lookups to the backing activation.
 func (cta costTrackActivation) Parent() Activation {
 	return cta.vars
@@ -134,6 +140,7 @@ func (ct *costTrackerFactory) Observe(vars Activation, id int64, programStep any
 	if !found {
 		return
 	}
+	startCost := tracker.cost
 	switch t := programStep.(type) {
 	case ConstantQualifier:
 		// TODO: Push identifiers on to the stack before observing constant qualifiers that apply to them
@@ -198,7 +205,11 @@ func (ct *costTrackerFactory) Observe(vars Activation, id int64, programStep any
 	}
 	tracker.stack.push(val, id)
 
-	if tracker.Limit != nil && tracker.cost > *tracker.Limit {
+	cost := tracker.cost
+	if tracker.forked != nil {
+		cost = tracker.forked.add(tracker.cost - startCost)
+	}
+	if tracker.Limit != nil && cost > *tracker.Limit {
 		panic(EvalCancelledError{Cause: CostLimitExceeded, Message: "operation cancelled: actual cost limit exceeded"})
 	}
 }
@@ -263,6 +274,10 @@ type CostTracker struct {
 
 	cost  uint64
 	stack refValStack
+
+	// forked is set when the tracker records the cost of steps evaluated concurrently with other
+	// trackers on behalf of a parent tracker.
+	forked *costFork
 }
 
 // ActualCost returns the runtime cost
@@ -270,6 +285,41 @@ func (c *CostTracker) ActualCost() uint64 {
 	return c.cost
 }
 
+// newFork creates a costFork which accumulates the cost of concurrently evaluated steps on top of
+// the current cost of the tracker.
+func (c *CostTracker) newFork() *costFork {
+	return &costFork{base: c.cost}
+}
+
+// fork returns a CostTracker for use by a single goroutine which shares the cost limit of the
+// tracker with the other trackers in the fork.
+func (c *CostTracker) fork(forked *costFork) *CostTracker {
+	return &CostTracker{
+		Estimator:           c.Estimator,
+		overloadTrackers:    c.overloadTrackers,
+		Limit:               c.Limit,
+		presenceTestHasCost: c.presenceTestHasCost,
+		forked:              forked,
+	}
+}
+
+// join adds the cost accumulated by the fork to the tracker.
+func (c *CostTracker) join(forked *costFork) {
+	c.cost += forked.cost.Load()
+}
+
+// costFork accumulates the cost of steps evaluated concurrently on behalf of a CostTracker.
+type costFork struct {
+	base uint64
+	cost atomic.Uint64
+}
+
+// add records the incremental cost of a step and returns the total cost including the cost of
+// the parent tracker at the time of the fork.
+func (f *costFork) add(delta uint64) uint64 {
+	return f.base + f.cost.Add(delta)
+}
+
 func (c *CostTracker) costCall(call InterpretableCall, args []ref.Val, result ref.Val) uint64 {
 	var cost uint64
 	if len(c.overloadTrackers) != 0 {
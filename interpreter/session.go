@@ -0,0 +1,249 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/cel-go/common/types"
+)
+
+// EvalSession is an Activation which memoizes the variables resolved from an underlying Activation
+// as well as the values of attributes with constant qualifiers, such as `request.auth.claims`, so
+// that the work of resolving lazy variables and selecting their fields is performed once across
+// all of the evaluations which share the session.
+//
+// An EvalSession is typically created once per request and supplied as the input to many program
+// evaluations. It is safe for concurrent use, provided the underlying Activation is only used
+// through the session. Calls to the underlying Activation are serialized.
+//
+// Variables which fail to resolve are memoized along with their error, so each variable is
+// resolved at most once per session. Attribute values are only memoized by interpretables planned
+// with an attribute factory configured with EnableSessionAttributes.
+type EvalSession struct {
+	vars Activation
+
+	// resolveMu serializes calls to the underlying activation, which may mutate its bindings when
+	// resolving lazy variables.
+	resolveMu sync.Mutex
+
+	mu        sync.Mutex
+	variables map[string]*sessionVariable
+	paths     map[string]*sessionPath
+
+	variableLookups  atomic.Int64
+	variableMisses   atomic.Int64
+	attributeLookups atomic.Int64
+	attributeMisses  atomic.Int64
+}
+
+// NewEvalSession returns an EvalSession which memoizes the values resolved from the Activation.
+func NewEvalSession(vars Activation) *EvalSession {
+	return &EvalSession{
+		vars:      vars,
+		variables: map[string]*sessionVariable{},
+		paths:     map[string]*sessionPath{},
+	}
+}
+
+// EvalSessionStats reports the work performed and saved by an EvalSession.
+type EvalSessionStats struct {
+	// Variables contains the names of the variables resolved by the session in sorted order.
+	Variables []string
+
+	// VariableLookups is the number of variable lookups served by the session.
+	VariableLookups int64
+
+	// VariableMisses is the number of variable lookups which required resolution by the
+	// underlying Activation.
+	VariableMisses int64
+
+	// AttributeLookups is the number of attribute values with constant qualifiers served by the
+	// session.
+	AttributeLookups int64
+
+	// AttributeMisses is the number of attribute lookups which required the qualifiers to be
+	// applied to the variable value.
+	AttributeMisses int64
+}
+
+// Stats returns a snapshot of the session statistics.
+func (s *EvalSession) Stats() EvalSessionStats {
+	s.mu.Lock()
+	var names []string
+	for name, v := range s.variables {
+		if v.resolved.Load() && (v.found || v.err != nil) {
+			names = append(names, name)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+	return EvalSessionStats{
+		Variables:        names,
+		VariableLookups:  s.variableLookups.Load(),
+		VariableMisses:   s.variableMisses.Load(),
+		AttributeLookups: s.attributeLookups.Load(),
+		AttributeMisses:  s.attributeMisses.Load(),
+	}
+}
+
+// ResolveName implements the Activation interface method.
+func (s *EvalSession) ResolveName(name string) (any, bool) {
+	obj, found, err := s.ResolveNameWithError(name)
+	if err != nil {
+		return types.WrapErr(err), true
+	}
+	return obj, found
+}
+
+// ResolveNameWithError implements the ErrorResolver interface method.
+func (s *EvalSession) ResolveNameWithError(name string) (any, bool, error) {
+	// Names which are not valid identifiers, such as `#interrupted`, carry evaluation state rather
+	// than variable values and are never memoized.
+	if strings.HasPrefix(name, "#") {
+		s.resolveMu.Lock()
+		defer s.resolveMu.Unlock()
+		return ResolveNameWithError(s.vars, name)
+	}
+	s.variableLookups.Add(1)
+	s.mu.Lock()
+	v, found := s.variables[name]
+	if !found {
+		v = &sessionVariable{}
+		s.variables[name] = v
+	}
+	s.mu.Unlock()
+	v.once.Do(func() {
+		s.variableMisses.Add(1)
+		s.resolveMu.Lock()
+		defer s.resolveMu.Unlock()
+		v.value, v.found, v.err = ResolveNameWithError(s.vars, name)
+		v.resolved.Store(true)
+	})
+	return v.value, v.found, v.err
+}
+
+// Parent implements the Activation interface method.
+func (s *EvalSession) Parent() Activation {
+	return s.vars
+}
+
+// AsPartialActivation supports conversion to a partial activation in order to detect unknown attributes.
+func (s *EvalSession) AsPartialActivation() (PartialActivation, bool) {
+	return AsPartialActivation(s.vars)
+}
+
+// qualify applies the qualifiers to the value of the named variable, memoizing the result when the
+// value was resolved by the session and all of the qualifiers are constant.
+//
+// Comparing the value against the one memoized for the variable ensures that local variables
+// which shadow the name are never confused with the session's variable.
+func (s *EvalSession) qualify(vars Activation, name string, obj any, qualifiers []Qualifier) (any, bool, error) {
+	key, ok := s.pathKey(name, obj, qualifiers)
+	if !ok {
+		return applyQualifiers(vars, obj, qualifiers)
+	}
+	s.attributeLookups.Add(1)
+	s.mu.Lock()
+	p, found := s.paths[key]
+	s.mu.Unlock()
+	if found {
+		return p.value, p.isOpt, nil
+	}
+	s.attributeMisses.Add(1)
+	val, isOpt, err := applyQualifiers(vars, obj, qualifiers)
+	// Errors are labeled with the ids of the expression which produced them, and so they are
+	// not shared across evaluations.
+	if err != nil {
+		return val, isOpt, err
+	}
+	s.mu.Lock()
+	s.paths[key] = &sessionPath{value: val, isOpt: isOpt}
+	s.mu.Unlock()
+	return val, isOpt, nil
+}
+
+// pathKey returns a key describing the qualified path of a session variable, or false if the
+// value was not produced by the session or if any of the qualifiers is not constant.
+func (s *EvalSession) pathKey(name string, obj any, qualifiers []Qualifier) (string, bool) {
+	s.mu.Lock()
+	v, found := s.variables[name]
+	s.mu.Unlock()
+	if !found || !v.resolved.Load() || !v.found || !sameReference(v.value, obj) {
+		return "", false
+	}
+	var key strings.Builder
+	key.WriteString(name)
+	for _, q := range qualifiers {
+		cq, ok := q.(ConstantQualifier)
+		if !ok {
+			return "", false
+		}
+		if q.IsOptional() {
+			key.WriteString("?")
+		}
+		// The qualifier implementation distinguishes presence tests from field selections.
+		val := cq.Value()
+		fmt.Fprintf(&key, "[%T:%s:%q]", q, val.Type().TypeName(), fmt.Sprint(val.Value()))
+	}
+	return key.String(), true
+}
+
+// sameReference returns whether two values refer to the same map, list, or object.
+//
+// Values which are not references cannot be qualified in a manner worth memoizing.
+func sameReference(a, b any) bool {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Map, reflect.Pointer:
+		return va.UnsafePointer() == vb.UnsafePointer()
+	case reflect.Slice:
+		return va.UnsafePointer() == vb.UnsafePointer() && va.Len() == vb.Len()
+	}
+	return false
+}
+
+// asEvalSession walks the Activation hierarchy and returns the first EvalSession found, if present.
+func asEvalSession(vars Activation) (*EvalSession, bool) {
+	if s, ok := vars.(*EvalSession); ok {
+		return s, true
+	}
+	if vars.Parent() != nil {
+		return asEvalSession(vars.Parent())
+	}
+	return nil, false
+}
+
+type sessionVariable struct {
+	once     sync.Once
+	resolved atomic.Bool
+	value    any
+	found    bool
+	err      error
+}
+
+type sessionPath struct {
+	value any
+	isOpt bool
+}
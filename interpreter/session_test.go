@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interpreter
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/common/containers"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestEvalSession(t *testing.T) {
+	var calls int
+	vars, _ := NewActivation(map[string]any{
+		"req": func() any {
+			calls++
+			return map[string]any{"auth": map[string]any{"user": "alice", "groups": []string{"dev"}}}
+		},
+		"unused": func() any {
+			t.Error("unused variable was resolved")
+			return nil
+		},
+	})
+	sess := NewEvalSession(vars)
+	exprs := []string{
+		`req.auth.user == 'alice'`,
+		`'dev' in req.auth.groups`,
+		`req.auth.user.startsWith('a')`,
+		// The comprehension variable shadows the session variable.
+		`[{'auth': {'user': 'bob'}}].exists(req, req.auth.user == 'bob')`,
+	}
+	for _, expr := range exprs {
+		i := newSessionInterpretable(t, expr, EvalStateObserver())
+		if out := i.Eval(sess); out != types.True {
+			t.Errorf("Eval(%q) got %v, wanted true", expr, out)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("lazy variable resolved %d times, wanted 1", calls)
+	}
+	stats := sess.Stats()
+	if !reflect.DeepEqual(stats.Variables, []string{"req"}) {
+		t.Errorf("sess.Stats().Variables got %v, wanted [req]", stats.Variables)
+	}
+	// Unchecked expressions also look up the qualified names `req.auth.user`, `req.auth`, etc.
+	if stats.VariableMisses >= stats.VariableLookups {
+		t.Errorf("sess.Stats() got %d variable lookups, %d misses, wanted memoized lookups",
+			stats.VariableLookups, stats.VariableMisses)
+	}
+	// `req.auth.user` is computed once, and `req.auth.groups` once.
+	if stats.AttributeLookups != 3 || stats.AttributeMisses != 2 {
+		t.Errorf("sess.Stats() got %d attribute lookups, %d misses, wanted 3 lookups, 2 misses",
+			stats.AttributeLookups, stats.AttributeMisses)
+	}
+}
+
+func TestEvalSessionAttributesDisabled(t *testing.T) {
+	vars, _ := NewActivation(map[string]any{
+		"req": map[string]any{"auth": map[string]any{"user": "alice"}},
+	})
+	sess := NewEvalSession(vars)
+	i := newTestInterpretable(t, `req.auth.user == 'alice'`)
+	for n := 0; n < 2; n++ {
+		if out := i.Eval(sess); out != types.True {
+			t.Errorf("Eval() got %v, wanted true", out)
+		}
+	}
+	// Variables are still memoized, while attribute values are not.
+	stats := sess.Stats()
+	if !reflect.DeepEqual(stats.Variables, []string{"req"}) || stats.VariableMisses >= stats.VariableLookups {
+		t.Errorf("sess.Stats() got variables %v, %d lookups, %d misses, wanted memoized [req]",
+			stats.Variables, stats.VariableLookups, stats.VariableMisses)
+	}
+	if stats.AttributeLookups != 0 {
+		t.Errorf("sess.Stats().AttributeLookups got %d, wanted 0", stats.AttributeLookups)
+	}
+}
+
+func TestEvalSessionErrors(t *testing.T) {
+	var calls int
+	fetchErr := errors.New("fetch failed")
+	vars, _ := NewActivation(map[string]any{
+		"req": func() (any, error) {
+			calls++
+			return nil, fetchErr
+		},
+		"m": map[string]any{},
+	})
+	sess := NewEvalSession(vars)
+	for i := 0; i < 2; i++ {
+		out := newSessionInterpretable(t, `req.user == 'alice'`, EvalStateObserver()).Eval(sess)
+		if err, ok := out.(*types.Err); !ok || !errors.Is(err, fetchErr) {
+			t.Errorf("Eval() got %v, wanted %v", out, fetchErr)
+		}
+		out = newSessionInterpretable(t, `m.missing`, EvalStateObserver()).Eval(sess)
+		if !types.IsError(out) {
+			t.Errorf("Eval() got %v, wanted no such key error", out)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("failing variable resolved %d times, wanted 1", calls)
+	}
+	if stats := sess.Stats(); stats.AttributeMisses != 2 {
+		t.Errorf("sess.Stats().AttributeMisses got %d, wanted 2 as errors are not memoized", stats.AttributeMisses)
+	}
+}
+
+func TestEvalSessionConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	vars, _ := NewActivation(map[string]any{
+		"x": func() any {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return map[string]int{"y": 1}
+		},
+	})
+	sess := NewEvalSession(vars)
+	i := newSessionInterpretable(t, `x.y == 1`, EvalStateObserver())
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out := i.Eval(sess); out != types.True {
+				t.Errorf("Eval() got %v, wanted true", out)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("lazy variable resolved %d times, wanted 1", calls)
+	}
+}
+
+// newSessionInterpretable plans the expression like newTestInterpretable, with attribute values
+// memoized in the EvalSession supplied to Eval.
+func newSessionInterpretable(t *testing.T, expr string, opts ...PlannerOption) Interpretable {
+	t.Helper()
+	parsed := mustParseWithMacros(t, expr)
+	cont := containers.DefaultContainer
+	reg := newTestRegistry(t)
+	attrs := NewAttributeFactory(cont, reg, reg, EnableSessionAttributes(true))
+	intr := newStandardInterpreter(t, cont, reg, reg, attrs)
+	i, err := intr.NewInterpretable(parsed, opts...)
+	if err != nil {
+		t.Fatalf("NewInterpretable(%q) failed: %v", expr, err)
+	}
+	return i
+}
@@ -29,13 +29,13 @@ func (e *parseErrors) errorCount() int {
 }
 
 func (e *parseErrors) internalError(message string) {
-	e.errs.ReportErrorAtID(0, common.NoLocation, "%s", message)
+	e.errs.ReportErrorWithCode(0, common.NoLocation, common.ErrorCodeInternal, nil, "%s", message)
 }
 
 func (e *parseErrors) syntaxError(l common.Location, message string) {
-	e.errs.ReportErrorAtID(0, l, "Syntax error: %s", message)
+	e.errs.ReportErrorWithCode(0, l, common.ErrorCodeSyntax, nil, "Syntax error: %s", message)
 }
 
-func (e *parseErrors) reportErrorAtID(id int64, l common.Location, message string, args ...any) {
-	e.errs.ReportErrorAtID(id, l, message, args...)
+func (e *parseErrors) reportErrorAtID(id int64, l common.Location, code common.ErrorCode, details map[string]any, message string, args ...any) {
+	e.errs.ReportErrorWithCode(id, l, code, details, message, args...)
 }
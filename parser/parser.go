@@ -116,7 +116,8 @@ func (p *Parser) Parse(source common.Source) (*ast.AST, *common.Errors) {
 	}
 	var out ast.Expr
 	if buf.Len() > p.expressionSizeCodePointLimit {
-		out = impl.reportError(common.NoLocation,
+		out = impl.reportErrorWithDetails(common.NoLocation, common.ErrorCodeExpressionSizeLimit,
+			map[string]any{"size": buf.Len(), "limit": p.expressionSizeCodePointLimit},
 			"expression code point size exceeds limit: size: %d, limit %d",
 			buf.Len(), p.expressionSizeCodePointLimit)
 	} else {
@@ -455,7 +456,7 @@ func (p *parser) Visit(tree antlr.ParseTree) any {
 		if t != nil {
 			txt = fmt.Sprintf("<<%T>>", t)
 		}
-		return p.reportError(common.NoLocation, "unknown parse element encountered: %s", txt)
+		return p.reportError(common.NoLocation, common.ErrorCodeInternal, "unknown parse element encountered: %s", txt)
 	}
 	return p.helper.newExpr(common.NoLocation)
 
@@ -485,7 +486,7 @@ func (p *parser) VisitConditionalOr(ctx *gen.ConditionalOrContext) any {
 	rest := ctx.GetE1()
 	for i, op := range ctx.GetOps() {
 		if i >= len(rest) {
-			return p.reportError(ctx, "unexpected character, wanted '||'")
+			return p.reportError(ctx, common.ErrorCodeSyntax, "unexpected character, wanted '||'")
 		}
 		next := p.Visit(rest[i]).(ast.Expr)
 		opID := p.helper.id(op)
@@ -501,7 +502,7 @@ func (p *parser) VisitConditionalAnd(ctx *gen.ConditionalAndContext) any {
 	rest := ctx.GetE1()
 	for i, op := range ctx.GetOps() {
 		if i >= len(rest) {
-			return p.reportError(ctx, "unexpected character, wanted '&&'")
+			return p.reportError(ctx, common.ErrorCodeSyntax, "unexpected character, wanted '&&'")
 		}
 		next := p.Visit(rest[i]).(ast.Expr)
 		opID := p.helper.id(op)
@@ -522,7 +523,7 @@ func (p *parser) VisitRelation(ctx *gen.RelationContext) any {
 		rhs := p.Visit(ctx.Relation(1)).(ast.Expr)
 		return p.globalCallOrMacro(opID, op, lhs, rhs)
 	}
-	return p.reportError(ctx, "operator not found")
+	return p.reportError(ctx, common.ErrorCodeSyntax, "operator not found")
 }
 
 // Visit a parse tree produced by CELParser#calc.
@@ -537,7 +538,7 @@ func (p *parser) VisitCalc(ctx *gen.CalcContext) any {
 		rhs := p.Visit(ctx.Calc(1)).(ast.Expr)
 		return p.globalCallOrMacro(opID, op, lhs, rhs)
 	}
-	return p.reportError(ctx, "operator not found")
+	return p.reportError(ctx, common.ErrorCodeSyntax, "operator not found")
 }
 
 func (p *parser) VisitUnary(ctx *gen.UnaryContext) any {
@@ -572,11 +573,11 @@ func (p *parser) VisitSelect(ctx *gen.SelectContext) any {
 	}
 	id, err := p.normalizeIdent(ctx.GetId())
 	if err != nil {
-		p.reportError(ctx.GetId(), "%v", err)
+		p.reportIdentError(ctx.GetId(), ctx.GetId(), err)
 	}
 	if ctx.GetOpt() != nil {
 		if !p.enableOptionalSyntax {
-			return p.reportError(ctx.GetOp(), "unsupported syntax '.?'")
+			return p.reportUnsupportedSyntax(ctx.GetOp(), ".?")
 		}
 		return p.helper.newGlobalCall(
 			ctx.GetOp(),
@@ -611,7 +612,7 @@ func (p *parser) VisitIndex(ctx *gen.IndexContext) any {
 	operator := operators.Index
 	if ctx.GetOpt() != nil {
 		if !p.enableOptionalSyntax {
-			return p.reportError(ctx.GetOp(), "unsupported syntax '[?'")
+			return p.reportUnsupportedSyntax(ctx.GetOp(), "[?")
 		}
 		operator = operators.OptIndex
 	}
@@ -654,14 +655,14 @@ func (p *parser) VisitIFieldInitializerList(ctx gen.IFieldInitializerListContext
 		optField := f.(*gen.OptFieldContext)
 		optional := optField.GetOpt() != nil
 		if !p.enableOptionalSyntax && optional {
-			p.reportError(optField, "unsupported syntax '?'")
+			p.reportUnsupportedSyntax(optField, "?")
 			continue
 		}
 
 		// The field may be empty due to a prior error.
 		fieldName, err := p.normalizeIdent(optField.EscapeIdent())
 		if err != nil {
-			p.reportError(ctx, "%v", err)
+			p.reportIdentError(ctx, optField.EscapeIdent(), err)
 			continue
 		}
 
@@ -685,7 +686,8 @@ func (p *parser) VisitIdent(ctx *gen.IdentContext) any {
 	// Handle reserved identifiers.
 	id := ctx.GetId().GetText()
 	if _, ok := reservedIds[id]; ok {
-		return p.reportError(ctx, "reserved identifier: %s", id)
+		return p.reportErrorWithDetails(ctx, common.ErrorCodeReservedIdentifier,
+			map[string]any{"identifier": id}, "reserved identifier: %s", id)
 	}
 	identName += id
 	return p.helper.newIdent(ctx.GetId(), identName)
@@ -704,7 +706,8 @@ func (p *parser) VisitGlobalCall(ctx *gen.GlobalCallContext) any {
 	// Handle reserved identifiers.
 	id := ctx.GetId().GetText()
 	if _, ok := reservedIds[id]; ok {
-		return p.reportError(ctx, "reserved identifier: %s", id)
+		return p.reportErrorWithDetails(ctx, common.ErrorCodeReservedIdentifier,
+			map[string]any{"identifier": id}, "reserved identifier: %s", id)
 	}
 	identName += id
 	opID := p.helper.id(ctx.GetOp())
@@ -748,7 +751,7 @@ func (p *parser) VisitMapInitializerList(ctx *gen.MapInitializerListContext) any
 		optKey := keys[i]
 		optional := optKey.GetOpt() != nil
 		if !p.enableOptionalSyntax && optional {
-			p.reportError(optKey, "unsupported syntax '?'")
+			p.reportUnsupportedSyntax(optKey, "?")
 			continue
 		}
 		key := p.Visit(optKey.GetE()).(ast.Expr)
@@ -772,7 +775,7 @@ func (p *parser) VisitInt(ctx *gen.IntContext) any {
 	}
 	i, err := strconv.ParseInt(text, base, 64)
 	if err != nil {
-		return p.reportError(ctx, "invalid int literal")
+		return p.reportInvalidLiteral(ctx, "int")
 	}
 	return p.helper.newLiteralInt(ctx, i)
 }
@@ -789,7 +792,7 @@ func (p *parser) VisitUint(ctx *gen.UintContext) any {
 	}
 	i, err := strconv.ParseUint(text, base, 64)
 	if err != nil {
-		return p.reportError(ctx, "invalid uint literal")
+		return p.reportInvalidLiteral(ctx, "uint")
 	}
 	return p.helper.newLiteralUint(ctx, i)
 }
@@ -802,7 +805,7 @@ func (p *parser) VisitDouble(ctx *gen.DoubleContext) any {
 	}
 	f, err := strconv.ParseFloat(txt, 64)
 	if err != nil {
-		return p.reportError(ctx, "invalid double literal")
+		return p.reportInvalidLiteral(ctx, "double")
 	}
 	return p.helper.newLiteralDouble(ctx, f)
 
@@ -857,7 +860,7 @@ func (p *parser) visitListInit(ctx gen.IListInitContext) ([]ast.Expr, []int32) {
 		result[i] = ex
 		if e.GetOpt() != nil {
 			if !p.enableOptionalSyntax {
-				p.reportError(e.GetOpt(), "unsupported syntax '?'")
+				p.reportUnsupportedSyntax(e.GetOpt(), "?")
 				continue
 			}
 			optionals = append(optionals, int32(i))
@@ -881,7 +884,12 @@ func (p *parser) visitSlice(expressions []gen.IExprContext) []ast.Expr {
 func (p *parser) unquote(ctx any, value string, isBytes bool) string {
 	text, err := unescape(value, isBytes)
 	if err != nil {
-		p.reportError(ctx, "%s", err.Error())
+		literalKind := "string"
+		if isBytes {
+			literalKind = "bytes"
+		}
+		p.reportErrorWithDetails(ctx, common.ErrorCodeInvalidLiteral,
+			map[string]any{"literal_kind": literalKind}, "%s", err.Error())
 		return value
 	}
 	return text
@@ -894,7 +902,31 @@ func (p *parser) newLogicManager(function string, term ast.Expr) *logicManager {
 	return newBalancingLogicManager(p.exprFactory, function, term)
 }
 
-func (p *parser) reportError(ctx any, format string, args ...any) ast.Expr {
+func (p *parser) reportError(ctx any, code common.ErrorCode, format string, args ...any) ast.Expr {
+	return p.reportErrorWithDetails(ctx, code, nil, format, args...)
+}
+
+func (p *parser) reportUnsupportedSyntax(ctx any, syntax string) ast.Expr {
+	return p.reportErrorWithDetails(ctx, common.ErrorCodeUnsupportedSyntax,
+		map[string]any{"syntax": syntax}, "unsupported syntax '%s'", syntax)
+}
+
+func (p *parser) reportInvalidLiteral(ctx any, literalKind string) ast.Expr {
+	return p.reportErrorWithDetails(ctx, common.ErrorCodeInvalidLiteral,
+		map[string]any{"literal_kind": literalKind}, "invalid %s literal", literalKind)
+}
+
+// reportIdentError reports a failure to normalize an identifier, distinguishing the use of escaped
+// identifiers when the escape syntax has not been enabled.
+func (p *parser) reportIdentError(ctx any, ident gen.IEscapeIdentContext, err error) ast.Expr {
+	if _, isEscaped := ident.(*gen.EscapedIdentifierContext); isEscaped && !p.enableIdentEscapeSyntax {
+		return p.reportErrorWithDetails(ctx, common.ErrorCodeUnsupportedSyntax,
+			map[string]any{"syntax": "`"}, "%v", err)
+	}
+	return p.reportError(ctx, common.ErrorCodeInvalidIdentifier, "%v", err)
+}
+
+func (p *parser) reportErrorWithDetails(ctx any, code common.ErrorCode, details map[string]any, format string, args ...any) ast.Expr {
 	var location common.Location
 	err := p.helper.newExpr(ctx)
 	switch c := ctx.(type) {
@@ -904,7 +936,7 @@ func (p *parser) reportError(ctx any, format string, args ...any) ast.Expr {
 		location = p.helper.getLocation(err.ID())
 	}
 	// Provide arguments to the report error.
-	p.errors.reportErrorAtID(err.ID(), location, format, args...)
+	p.errors.reportErrorAtID(err.ID(), location, code, details, format, args...)
 	return err
 }
 
@@ -976,7 +1008,8 @@ func (p *parser) expandMacro(exprID int64, function string, target ast.Expr, arg
 			loc = p.helper.getLocation(exprID)
 		}
 		p.helper.deleteID(exprID)
-		return p.reportError(loc, "%s", err.Message), true
+		return p.reportErrorWithDetails(loc, common.ErrorCodeInvalidMacro,
+			map[string]any{"macro": function}, "%s", err.Message), true
 	}
 	// A nil value from the macro indicates that the macro implementation decided that
 	// an expansion should not be performed.
@@ -2391,6 +2391,71 @@ func TestParseErrorData(t *testing.T) {
 	}
 }
 
+func TestParseErrorCodes(t *testing.T) {
+	tests := []struct {
+		expr    string
+		code    common.ErrorCode
+		details map[string]any
+	}{
+		{expr: `a +`, code: common.ErrorCodeSyntax},
+		{expr: `a.?b`, code: common.ErrorCodeUnsupportedSyntax, details: map[string]any{"syntax": ".?"}},
+		{expr: `a[?b]`, code: common.ErrorCodeUnsupportedSyntax, details: map[string]any{"syntax": "[?"}},
+		{expr: `[?a]`, code: common.ErrorCodeUnsupportedSyntax, details: map[string]any{"syntax": "?"}},
+		{expr: "a.`b c", code: common.ErrorCodeSyntax},
+		{expr: `in`, code: common.ErrorCodeSyntax},
+		{expr: `1 + 99999999999999999999`, code: common.ErrorCodeInvalidLiteral, details: map[string]any{"literal_kind": "int"}},
+		{expr: `99999999999999999999u`, code: common.ErrorCodeInvalidLiteral, details: map[string]any{"literal_kind": "uint"}},
+		{expr: `'\ud800'`, code: common.ErrorCodeInvalidLiteral, details: map[string]any{"literal_kind": "string"}},
+		{expr: `b'\ud800'`, code: common.ErrorCodeInvalidLiteral, details: map[string]any{"literal_kind": "bytes"}},
+		{expr: `a.all(1, true)`, code: common.ErrorCodeInvalidMacro, details: map[string]any{"macro": "all"}},
+	}
+	p := newTestParser(t)
+	for _, tst := range tests {
+		tc := tst
+		t.Run(tc.expr, func(t *testing.T) {
+			_, iss := p.Parse(common.NewTextSource(tc.expr))
+			if len(iss.GetErrors()) == 0 {
+				t.Fatalf("Parse(%q) succeeded, wanted error", tc.expr)
+			}
+			celErr := iss.GetErrors()[0]
+			if celErr.Code != tc.code {
+				t.Errorf("Parse(%q) got code %q, wanted %q: %v", tc.expr, celErr.Code, tc.code, iss.ToDisplayString())
+			}
+			if tc.details != nil && !reflect.DeepEqual(celErr.Details, tc.details) {
+				t.Errorf("Parse(%q) got details %v, wanted %v", tc.expr, celErr.Details, tc.details)
+			}
+		})
+	}
+}
+
+func TestParseErrorCodeEscapedIdent(t *testing.T) {
+	p := newTestParser(t, EnableIdentEscapeSyntax(false))
+	_, iss := p.Parse(common.NewTextSource("a.`b c`"))
+	if len(iss.GetErrors()) != 1 {
+		t.Fatalf("Parse() got %v, wanted a single error", iss.ToDisplayString())
+	}
+	celErr := iss.GetErrors()[0]
+	want := map[string]any{"syntax": "`"}
+	if celErr.Code != common.ErrorCodeUnsupportedSyntax || !reflect.DeepEqual(celErr.Details, want) {
+		t.Errorf("Parse() got code %q with details %v, wanted %q with %v",
+			celErr.Code, celErr.Details, common.ErrorCodeUnsupportedSyntax, want)
+	}
+}
+
+func TestParseErrorCodeSizeLimit(t *testing.T) {
+	p := newTestParser(t, ExpressionSizeCodePointLimit(2))
+	_, iss := p.Parse(common.NewTextSource(`a + b`))
+	if len(iss.GetErrors()) != 1 {
+		t.Fatalf("Parse() got %v, wanted a single error", iss.ToDisplayString())
+	}
+	celErr := iss.GetErrors()[0]
+	want := map[string]any{"size": 5, "limit": 2}
+	if celErr.Code != common.ErrorCodeExpressionSizeLimit || !reflect.DeepEqual(celErr.Details, want) {
+		t.Errorf("Parse() got code %q with details %v, wanted %q with %v",
+			celErr.Code, celErr.Details, common.ErrorCodeExpressionSizeLimit, want)
+	}
+}
+
 func newTestParser(t *testing.T, options ...Option) *Parser {
 	t.Helper()
 	defaultOpts := []Option{
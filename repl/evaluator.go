@@ -167,7 +167,7 @@ func (l *letFunction) updateImpl(env *cel.Env) error {
 
 	ast, iss := l.fnEnv.Compile(l.src)
 
-	if iss != nil {
+	if iss.Err() != nil {
 		return iss.Err()
 	}
 
@@ -568,7 +568,7 @@ func updateContextPlans(ctx *EvaluationContext, env *cel.Env) error {
 		// Check if the let variable has a definition and needs to be re-planned
 		if el.prog == nil && el.src != "" {
 			ast, iss := env.Compile(el.src)
-			if iss != nil {
+			if iss.Err() != nil {
 				return fmt.Errorf("error updating %v\n%w", el, iss.Err())
 			}
 
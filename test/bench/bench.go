@@ -257,12 +257,13 @@ func RunReferenceDynamicEnvCases(b *testing.B) {
 	}
 }
 
-// RunCase evaluates a single test case against a custom environment, running three different
-// variants of the expression: optimized, unoptimized, and trace.
+// RunCase evaluates a single test case against a custom environment, running four different
+// variants of the expression: optimized, unoptimized, trace, and bytecode.
 //
 // * `optimized` - applies the cel.EvalOptions(cel.OptOptimize) flag.
 // * `unoptimized` - no optimization flags applied.
 // * `trace` - observes the evaluation state of an expression.
+// * `bytecode` - applies the cel.BytecodeEval() option along with cel.EvalOptions(cel.OptOptimize).
 //
 // In many cases the evaluation times may be similar, but when running comparisons against the
 // baseline CEL environment, it may be useful to characterize the performance of the custom
@@ -284,8 +285,9 @@ func RunCase(b *testing.B, env *cel.Env, bc *Case) {
 		"optimized":   {cel.EvalOptions(cel.OptOptimize)},
 		"unoptimized": {},
 		"trace":       {cel.EvalOptions(cel.OptTrackState)},
+		"bytecode":    {cel.BytecodeEval(), cel.EvalOptions(cel.OptOptimize)},
 	}
-	optOrder := []string{"optimized", "unoptimized", "trace"}
+	optOrder := []string{"optimized", "unoptimized", "trace", "bytecode"}
 	for _, name := range optOrder {
 		opt := opts[name]
 		b.Run(fmt.Sprintf("%s/%s", bc.Expr, name), func(b *testing.B) {
@@ -18,6 +18,7 @@ package celtest
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -60,6 +61,8 @@ var (
 	baseConfigPath        string
 	enableCoverage        bool
 	enableDebug           bool
+	diagnosticsFormat     string
+	diagnosticsOutput     string
 )
 
 func init() {
@@ -70,6 +73,8 @@ func init() {
 	flag.StringVar(&celExpression, "cel_expr", "", "CEL expression to test")
 	flag.BoolVar(&enableCoverage, "enable_coverage", false, "Enable coverage calculation and reporting.")
 	flag.BoolVar(&enableDebug, "celtest_debug", false, "Enables verbose logging of test case execution.")
+	flag.StringVar(&diagnosticsFormat, "diagnostics_format", "", "format of the diagnostics written for expressions which fail to compile: text, json, or sarif")
+	flag.StringVar(&diagnosticsOutput, "diagnostics_output", "", "path to the file the diagnostics are written to, defaults to stderr")
 }
 
 func updateRunfilesPathForFlags(testResourcesDir string) error {
@@ -145,6 +150,9 @@ func TriggerTests(t *testing.T, testRunnerOpts ...TestRunnerOption) {
 	}
 	programs, err := tr.Programs(t, tr.testProgramOptions...)
 	if err != nil {
+		if werr := tr.writeDiagnostics(err); werr != nil {
+			t.Logf("error writing diagnostics: %v", werr)
+		}
 		t.Fatalf("error creating programs: %v", err)
 	}
 	if len(programs) == 0 {
@@ -181,6 +189,8 @@ func TriggerTests(t *testing.T, testRunnerOpts ...TestRunnerOption) {
 //   - Test expression - The `cel_expr` flag is used to populate the test expressions which need to be
 //     evaluated by the test runner.
 //   - Enable coverage - The `enable_coverage` flag is used to enable coverage calculation and reporting.
+//   - Diagnostics - The `diagnostics_format` and `diagnostics_output` flags are used to write the
+//     diagnostics of expressions which fail to compile as text, JSON, or SARIF.
 func TestRunnerOptionsFromFlags(testResourcesDir string, testRunnerOpts []TestRunnerOption, testCompilerOpts ...any) TestRunnerOption {
 	if !flag.Parsed() {
 		flag.Parse()
@@ -198,6 +208,17 @@ func TestRunnerOptionsFromFlags(testResourcesDir string, testRunnerOpts []TestRu
 		if enableCoverage {
 			opts = append(opts, EnableCoverage())
 		}
+		if diagnosticsFormat != "" {
+			format, err := compiler.ParseDiagnosticsFormat(diagnosticsFormat)
+			if err != nil {
+				return nil, err
+			}
+			var w io.Writer = os.Stderr
+			if diagnosticsOutput != "" {
+				w = diagnosticsFile(diagnosticsOutput)
+			}
+			opts = append(opts, DiagnosticsOutput(format, w))
+		}
 		opts = append(opts, testRunnerOpts...)
 		var err error
 		for _, opt := range opts {
@@ -369,6 +390,8 @@ type TestRunner struct {
 	activationFactory  ActivationFactory
 	testSuiteParser    TestSuiteParser
 	testProgramOptions []cel.ProgramOption
+	diagnosticsFormat  compiler.DiagnosticsFormat
+	diagnosticsOutput  io.Writer
 }
 
 // Test represents a single test case to be executed. It encompasses the following:
@@ -550,6 +573,35 @@ func EnableCoverage() TestRunnerOption {
 	}
 }
 
+// DiagnosticsOutput returns a TestRunnerOption which writes the diagnostics of expressions which
+// fail to compile to the writer in the given format, e.g. for consumption by code review tooling.
+func DiagnosticsOutput(format compiler.DiagnosticsFormat, w io.Writer) TestRunnerOption {
+	return func(tr *TestRunner) (*TestRunner, error) {
+		tr.diagnosticsFormat = format
+		tr.diagnosticsOutput = w
+		return tr, nil
+	}
+}
+
+// writeDiagnostics writes the diagnostics of a program creation error to the configured output.
+func (tr *TestRunner) writeDiagnostics(err error) error {
+	if tr.diagnosticsOutput == nil {
+		return nil
+	}
+	return compiler.WriteDiagnostics(tr.diagnosticsOutput, tr.diagnosticsFormat, err)
+}
+
+// diagnosticsFile is an io.Writer which writes the diagnostics document to the file at the path.
+type diagnosticsFile string
+
+// Write implements the io.Writer interface method.
+func (f diagnosticsFile) Write(p []byte) (int, error) {
+	if err := os.WriteFile(string(f), p, 0644); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // Program represents the result of creating CEL programs for the configured expressions in the
 // test runner. It encompasses the following:
 // - CELProgram - the evaluable CEL program
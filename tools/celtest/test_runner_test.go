@@ -16,6 +16,9 @@
 package celtest
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/google/cel-go/cel"
@@ -208,6 +211,46 @@ func TestTriggerTests(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsOutput(t *testing.T) {
+	var buf bytes.Buffer
+	tr, err := NewTestRunner(
+		TestCompiler(compiler.EnvironmentFile("testdata/config.yaml")),
+		TestExpression("undeclared_var == 1"),
+		DiagnosticsOutput(compiler.SARIFDiagnostics, &buf),
+	)
+	if err != nil {
+		t.Fatalf("NewTestRunner() failed: %v", err)
+	}
+	_, err = tr.Programs(t)
+	if err == nil {
+		t.Fatal("tr.Programs() succeeded, wanted compile error")
+	}
+	if err := tr.writeDiagnostics(err); err != nil {
+		t.Fatalf("tr.writeDiagnostics() failed: %v", err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID  string `json:"ruleId"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("tr.writeDiagnostics() got %s, wanted a SARIF 2.1.0 log with one result", buf.String())
+	}
+	res := log.Runs[0].Results[0]
+	if res.RuleID != "undeclared_reference" || !strings.Contains(res.Message.Text, "undeclared_var") {
+		t.Errorf("tr.writeDiagnostics() got result %+v, wanted an undeclared reference to undeclared_var", res)
+	}
+}
+
 // TestCustomTestSuiteParser triggers the test runner where the tests are provided by a custom
 // test suite parser configured using TestSuiteParserOption.
 func TestCustomTestSuiteParser(t *testing.T) {
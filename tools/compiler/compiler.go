@@ -17,7 +17,9 @@
 package compiler
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -460,6 +462,96 @@ type InputExpression interface {
 	CreateAST(Compiler) (*cel.Ast, map[string]any, error)
 }
 
+// IssuesError is returned by InputExpression implementations when parsing or compiling an
+// expression or policy reports errors.
+//
+// The issues may be serialized using cel.Issues.ToJSON or cel.Issues.ToSARIF in order to surface
+// the diagnostics to editors or code review tooling:
+//
+//	var issErr *compiler.IssuesError
+//	if errors.As(err, &issErr) {
+//		out, err := issErr.Issues.ToSARIF(common.SARIFTool{Name: "celc"})
+//		...
+//	}
+type IssuesError struct {
+	// Op describes the operation which reported the issues.
+	Op string
+	// Issues contains the diagnostics reported by the operation.
+	Issues *cel.Issues
+}
+
+func newIssuesError(op string, iss *cel.Issues) *IssuesError {
+	return &IssuesError{Op: op, Issues: iss}
+}
+
+// Error implements the error interface method.
+func (e *IssuesError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Issues.Err())
+}
+
+// Unwrap returns the error produced by the issue set.
+func (e *IssuesError) Unwrap() error {
+	return e.Issues.Err()
+}
+
+// DiagnosticsFormat represents the format in which the diagnostics of an IssuesError are written.
+type DiagnosticsFormat int
+
+const (
+	// TextDiagnostics is used to write diagnostics in the human-readable format of cel.Issues.
+	TextDiagnostics DiagnosticsFormat = iota + 1
+	// JSONDiagnostics is used to write diagnostics as a JSON document, see common.Diagnostic.
+	JSONDiagnostics
+	// SARIFDiagnostics is used to write diagnostics as a SARIF 2.1.0 log.
+	SARIFDiagnostics
+)
+
+// ParseDiagnosticsFormat returns the diagnostics format with the given name: text, json, or sarif.
+func ParseDiagnosticsFormat(name string) (DiagnosticsFormat, error) {
+	switch name {
+	case "", "text":
+		return TextDiagnostics, nil
+	case "json":
+		return JSONDiagnostics, nil
+	case "sarif":
+		return SARIFDiagnostics, nil
+	default:
+		return 0, fmt.Errorf("unsupported diagnostics format: %q", name)
+	}
+}
+
+// WriteDiagnostics writes the diagnostics of an IssuesError in the given format.
+//
+// Errors which do not carry diagnostics are written as text, and are returned for the JSON and
+// SARIF formats since they cannot be represented in the document.
+func WriteDiagnostics(w io.Writer, format DiagnosticsFormat, err error) error {
+	var issErr *IssuesError
+	if !errors.As(err, &issErr) {
+		if format != TextDiagnostics {
+			return fmt.Errorf("error has no diagnostics: %w", err)
+		}
+		_, werr := fmt.Fprintln(w, err)
+		return werr
+	}
+	var out []byte
+	var merr error
+	switch format {
+	case TextDiagnostics:
+		out = []byte(issErr.Issues.String() + "\n")
+	case JSONDiagnostics:
+		out, merr = issErr.Issues.ToJSON()
+	case SARIFDiagnostics:
+		out, merr = issErr.Issues.ToSARIF(common.SARIFTool{})
+	default:
+		return fmt.Errorf("unsupported diagnostics format: %d", format)
+	}
+	if merr != nil {
+		return merr
+	}
+	_, werr := w.Write(out)
+	return werr
+}
+
 // CompiledExpression is an InputExpression which loads a CheckedExpr from a file.
 type CompiledExpression struct {
 	Path string
@@ -509,7 +601,7 @@ func (f *FileExpression) CreateAST(compiler Compiler) (*cel.Ast, map[string]any,
 		src := common.NewStringSource(string(data), f.Path)
 		ast, iss := e.CompileSource(src)
 		if iss.Err() != nil {
-			return nil, nil, fmt.Errorf("e.CompileSource(%q) failed: %w", src.Content(), iss.Err())
+			return nil, nil, newIssuesError(fmt.Sprintf("e.CompileSource(%q)", src.Content()), iss)
 		}
 		return ast, nil, nil
 	case CELPolicy, TextYAML:
@@ -524,7 +616,7 @@ func (f *FileExpression) CreateAST(compiler Compiler) (*cel.Ast, map[string]any,
 		}
 		p, iss := parser.Parse(src)
 		if iss.Err() != nil {
-			return nil, nil, fmt.Errorf("parser.Parse(%q) failed: %w", src.Content(), iss.Err())
+			return nil, nil, newIssuesError(fmt.Sprintf("parser.Parse(%q)", src.Content()), iss)
 		}
 		policyMetadata := clonePolicyMetadata(p)
 		if meta, ok := compiler.(CustomMetadataCompiler); ok {
@@ -536,7 +628,7 @@ func (f *FileExpression) CreateAST(compiler Compiler) (*cel.Ast, map[string]any,
 		}
 		ast, iss := policy.Compile(e, p, compiler.PolicyCompilerOptions()...)
 		if iss.Err() != nil {
-			return nil, nil, fmt.Errorf("policy.Compile(%q) failed: %w", src.Content(), iss.Err())
+			return nil, nil, newIssuesError(fmt.Sprintf("policy.Compile(%q)", src.Content()), iss)
 		}
 		return ast, policyMetadata, nil
 	default:
@@ -571,7 +663,7 @@ func (r *RawExpression) CreateAST(compiler Compiler) (*cel.Ast, map[string]any,
 	}
 	ast, iss := e.Compile(r.Value)
 	if iss.Err() != nil {
-		return nil, nil, fmt.Errorf("e.Compile(%q) failed: %w", r.Value, iss.Err())
+		return nil, nil, newIssuesError(fmt.Sprintf("e.Compile(%q)", r.Value), iss)
 	}
 	return ast, nil, nil
 }
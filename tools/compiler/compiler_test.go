@@ -15,10 +15,15 @@
 package compiler
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
 	"github.com/google/cel-go/common/env"
 	"github.com/google/cel-go/ext"
 	"github.com/google/cel-go/policy"
@@ -413,3 +418,126 @@ func TestRawExpressionCreateAst(t *testing.T) {
 		}
 	})
 }
+
+func TestRawExpressionIssuesError(t *testing.T) {
+	compiler, err := NewCompiler(EnvironmentFile("testdata/config.yaml"))
+	if err != nil {
+		t.Fatalf("NewCompiler() failed: %v", err)
+	}
+	rawExpr := &RawExpression{Value: "undeclared == 1"}
+	_, _, err = rawExpr.CreateAST(compiler)
+	var issErr *IssuesError
+	if !errors.As(err, &issErr) {
+		t.Fatalf("CreateAST() got error %v, wanted *IssuesError", err)
+	}
+	diags := issErr.Issues.Diagnostics()
+	if len(diags) != 1 || diags[0].Code != common.ErrorCodeUndeclaredReference {
+		t.Errorf("Issues.Diagnostics() got %v, wanted a single undeclared reference", diags)
+	}
+	if _, err := issErr.Issues.ToSARIF(common.SARIFTool{Name: "compiler-test"}); err != nil {
+		t.Errorf("Issues.ToSARIF() failed: %v", err)
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	compiler, err := NewCompiler(EnvironmentFile("testdata/config.yaml"))
+	if err != nil {
+		t.Fatalf("NewCompiler() failed: %v", err)
+	}
+	rawExpr := &RawExpression{Value: "undeclared == 1"}
+	_, _, compileErr := rawExpr.CreateAST(compiler)
+	if compileErr == nil {
+		t.Fatal("CreateAST() succeeded, wanted error")
+	}
+
+	t.Run("json", func(t *testing.T) {
+		format, err := ParseDiagnosticsFormat("json")
+		if err != nil {
+			t.Fatalf("ParseDiagnosticsFormat() failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := WriteDiagnostics(&buf, format, compileErr); err != nil {
+			t.Fatalf("WriteDiagnostics() failed: %v", err)
+		}
+		var doc struct {
+			Version     string `json:"version"`
+			Diagnostics []struct {
+				Severity string `json:"severity"`
+				Code     string `json:"code"`
+				ExprID   int64  `json:"exprId"`
+				Start    struct {
+					Line   int `json:"line"`
+					Column int `json:"column"`
+				} `json:"start"`
+				End struct {
+					Column int `json:"column"`
+				} `json:"end"`
+				Snippet string `json:"snippet"`
+			} `json:"diagnostics"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("json.Unmarshal() failed: %v\n%s", err, buf.String())
+		}
+		if doc.Version == "" || len(doc.Diagnostics) != 1 {
+			t.Fatalf("WriteDiagnostics() got %s, wanted a versioned document with one diagnostic", buf.String())
+		}
+		d := doc.Diagnostics[0]
+		if d.Severity != "ERROR" || d.Code != string(common.ErrorCodeUndeclaredReference) || d.ExprID == 0 {
+			t.Errorf("WriteDiagnostics() got diagnostic %+v, wanted an undeclared reference error", d)
+		}
+		if d.Start.Line != 1 || d.Start.Column != 1 || d.End.Column != 11 || d.Snippet != "undeclared == 1" {
+			t.Errorf("WriteDiagnostics() got range %+v, wanted 1:1-1:11 of undeclared == 1", d)
+		}
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		format, err := ParseDiagnosticsFormat("sarif")
+		if err != nil {
+			t.Fatalf("ParseDiagnosticsFormat() failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := WriteDiagnostics(&buf, format, compileErr); err != nil {
+			t.Fatalf("WriteDiagnostics() failed: %v", err)
+		}
+		var log struct {
+			Version string `json:"version"`
+			Runs    []struct {
+				Results []struct {
+					RuleID string `json:"ruleId"`
+					Level  string `json:"level"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+			t.Fatalf("json.Unmarshal() failed: %v\n%s", err, buf.String())
+		}
+		if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+			t.Fatalf("WriteDiagnostics() got %s, wanted a SARIF 2.1.0 log with one result", buf.String())
+		}
+		res := log.Runs[0].Results[0]
+		if res.RuleID != string(common.ErrorCodeUndeclaredReference) || res.Level != "error" {
+			t.Errorf("WriteDiagnostics() got result %+v, wanted an undeclared reference error", res)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDiagnostics(&buf, TextDiagnostics, compileErr); err != nil {
+			t.Fatalf("WriteDiagnostics() failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), "undeclared reference to 'undeclared'") {
+			t.Errorf("WriteDiagnostics() got %s, wanted the issues display string", buf.String())
+		}
+	})
+
+	t.Run("no diagnostics", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteDiagnostics(&buf, JSONDiagnostics, errors.New("file not found")); err == nil {
+			t.Error("WriteDiagnostics() succeeded for an error without diagnostics, wanted error")
+		}
+	})
+
+	if _, err := ParseDiagnosticsFormat("xml"); err == nil {
+		t.Error("ParseDiagnosticsFormat(xml) succeeded, wanted error")
+	}
+}